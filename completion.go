@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// cliCommand describes a subcommand for shell completion
+type cliCommand struct {
+	name  string
+	flags []cliFlag
+	args  func() []string // candidates for the first positional argument
+}
+
+// cliFlag is a flag and the candidates for its value (nil for a switch).
+// Values read from the repository, such as branch or remote names, would be
+// listed the same way once a command takes them.
+type cliFlag struct {
+	name   string
+	values func() []string
+}
+
+// rootFlags are the switches for the TUI itself; keep them in step with main
+var rootFlags = []cliFlag{
+	{name: "--presenter"},
+	{name: "--read-only"},
+	{name: "--debug"},
+}
+
+// cliCommands lists every subcommand; keep it in step with main's dispatch
+var cliCommands = []cliCommand{
+	{name: "prompt", flags: []cliFlag{
+		{name: "--color", values: fixed("auto", "always", "never")},
+		{name: "--shell", values: fixed("bash", "zsh")},
+	}},
+	{name: "completion", args: fixed("bash", "zsh", "fish")},
+}
+
+func fixed(values ...string) func() []string {
+	return func() []string { return values }
+}
+
+// complete returns the candidates for the last of words (the word being
+// typed, possibly empty), given the words before it
+func complete(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	current := words[len(words)-1]
+	before := words[:len(words)-1]
+
+	var candidates []string
+	// Switches for the TUI come before any subcommand
+	tui := !slices.ContainsFunc(before, func(word string) bool { return !strings.HasPrefix(word, "-") })
+	if tui && strings.HasPrefix(current, "-") {
+		for _, flag := range rootFlags {
+			candidates = append(candidates, flag.name)
+		}
+		return withPrefix(candidates, current)
+	}
+	if len(before) == 0 {
+		for _, cmd := range cliCommands {
+			candidates = append(candidates, cmd.name)
+		}
+		return withPrefix(candidates, current)
+	}
+
+	var cmd *cliCommand
+	for i := range cliCommands {
+		if cliCommands[i].name == before[0] {
+			cmd = &cliCommands[i]
+		}
+	}
+	if cmd == nil {
+		return nil
+	}
+
+	findFlag := func(name string) *cliFlag {
+		for i := range cmd.flags {
+			if cmd.flags[i].name == name || cmd.flags[i].name == "-"+name {
+				return &cmd.flags[i]
+			}
+		}
+		return nil
+	}
+
+	// The value of a flag
+	if flag := findFlag(before[len(before)-1]); flag != nil && flag.values != nil {
+		return withPrefix(flag.values(), current)
+	}
+	if strings.HasPrefix(current, "-") {
+		for _, flag := range cmd.flags {
+			candidates = append(candidates, flag.name)
+		}
+		return withPrefix(candidates, current)
+	}
+
+	// The first positional argument, skipping flags and their values
+	positional := 0
+	for i := 1; i < len(before); i++ {
+		if flag := findFlag(before[i]); flag != nil {
+			if flag.values != nil {
+				i++
+			}
+			continue
+		}
+		positional++
+	}
+	if positional == 0 && cmd.args != nil {
+		return withPrefix(cmd.args(), current)
+	}
+	return nil
+}
+
+func withPrefix(candidates []string, prefix string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// The scripts hand the command line to `gitty __complete`, so completions
+// stay in step with cliCommands without regenerating them
+const bashCompletion = `# bash completion for gitty
+_gitty() {
+    local IFS=$'\n'
+    COMPREPLY=($(gitty __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -F _gitty gitty
+`
+
+const zshCompletion = `#compdef gitty
+# zsh completion for gitty
+_gitty() {
+    local -a candidates
+    candidates=("${(@f)$(gitty __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    compadd -a candidates
+}
+compdef _gitty gitty
+`
+
+const fishCompletion = `# fish completion for gitty
+complete -c gitty -f -a '(gitty __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'
+`
+
+// runCompletion prints the completion script for a shell
+func runCompletion(args []string) int {
+	scripts := map[string]string{"bash": bashCompletion, "zsh": zshCompletion, "fish": fishCompletion}
+	if len(args) != 1 || scripts[args[0]] == "" {
+		fmt.Fprintln(os.Stderr, "Usage: gitty completion bash|zsh|fish")
+		return 2
+	}
+	fmt.Print(scripts[args[0]])
+	return 0
+}
+
+// runComplete prints the candidates for `gitty __complete <words...>`, one
+// per line
+func runComplete(args []string) int {
+	for _, candidate := range complete(args) {
+		fmt.Println(candidate)
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/LFroesch/gitty/internal/fixture"
+)
+
+// runDevtools dispatches `gitty devtools <command>`, helpers for working on
+// gitty itself. They are left out of shell completion.
+func runDevtools(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "make-fixture":
+			return runMakeFixture(args[1:])
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Usage: gitty devtools make-fixture [--list] <dir> [scenario...]")
+	return 2
+}
+
+// runMakeFixture builds fixture repositories under a directory: every
+// scenario, or the ones named
+func runMakeFixture(args []string) int {
+	flags := flag.NewFlagSet("make-fixture", flag.ExitOnError)
+	list := flags.Bool("list", false, "list the scenarios and exit")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gitty devtools make-fixture [--list] <dir> [scenario...]")
+		fmt.Fprintln(flags.Output(), "\nBuilds each scenario (all of them by default) as <dir>/<scenario>, replacing any already there.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *list {
+		for _, scenario := range fixture.Scenarios {
+			fmt.Printf("%-12s %s\n", scenario.Name, scenario.Description)
+		}
+		return 0
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	scenarios := fixture.Scenarios
+	if names := flags.Args()[1:]; len(names) > 0 {
+		scenarios = nil
+		for _, name := range names {
+			scenario, ok := fixture.Lookup(name)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Unknown scenario %q; see gitty devtools make-fixture --list\n", name)
+				return 2
+			}
+			scenarios = append(scenarios, scenario)
+		}
+	}
+
+	for _, scenario := range scenarios {
+		repo, err := fixture.Make(flags.Arg(0), scenario)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("%-12s %s\n", scenario.Name, repo)
+	}
+	return 0
+}
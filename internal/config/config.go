@@ -0,0 +1,370 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/LFroesch/gitty/suggest"
+)
+
+// Config is the user's gitty configuration, read from ~/.config/gitty/config.toml
+// and then overridden per repository by .gitty.toml (see WithRepo)
+type Config struct {
+	Commit   CommitConfig   `toml:"commit"`
+	Branches BranchesConfig `toml:"branches"`
+	Suggest  SuggestConfig  `toml:"suggest"`
+	Checks   ChecksConfig   `toml:"checks"`
+	Notify   NotifyConfig   `toml:"notify"`
+	Refresh  RefreshConfig  `toml:"refresh"`
+	Snapshot SnapshotConfig `toml:"snapshots"`
+	UI       UIConfig       `toml:"ui"`
+	Git      GitConfig      `toml:"git"`
+	// Keys remaps actions per key context, e.g.
+	//   [keys.files]
+	//   stage = ["x"]
+	Keys map[string]map[string][]string `toml:"keys"`
+}
+
+// CommitConfig controls the commit tab
+type CommitConfig struct {
+	// Types offered by the type picker, in order
+	Types []string `toml:"types"`
+	// Scopes always offered by the scope picker, ahead of scopes seen in history
+	Scopes []string `toml:"scopes"`
+	// Tickets requires an issue reference in commit messages
+	Tickets TicketsConfig `toml:"tickets"`
+}
+
+// TicketsConfig requires commit messages on some branches to reference an
+// issue, in the subject or a footer line
+type TicketsConfig struct {
+	// Pattern matches a reference, e.g. `[A-Z]+-\d+` or `#\d+`; empty turns
+	// the rule off
+	Pattern string `toml:"pattern"`
+	// Branches the rule applies to, as globs; empty means every branch
+	Branches []string `toml:"branches"`
+	// Insert is where a ticket taken from the branch name goes: "footer"
+	// (a "Refs:" line, the default) or "subject" (appended in parentheses)
+	Insert string `toml:"insert"`
+}
+
+// Required reports whether commits on branch need a ticket reference
+func (t TicketsConfig) Required(branch string) bool {
+	if t.Pattern == "" {
+		return false
+	}
+	if len(t.Branches) == 0 {
+		return true
+	}
+	for _, pattern := range t.Branches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchesConfig controls the branches tab and branch safety checks
+type BranchesConfig struct {
+	// Protected branches cannot be deleted or force-pushed from gitty, and
+	// committing on them asks for confirmation. Entries may be globs
+	// ("release/*").
+	Protected []string `toml:"protected"`
+	// Compare is the branch used for ahead/behind counts and "compare with
+	// default"; empty detects it from origin/HEAD
+	Compare string `toml:"compare"`
+	// Remote is where a branch without an upstream is published to with
+	// push -u
+	Remote string `toml:"remote"`
+}
+
+// IsProtected reports whether a branch (local or remote, e.g. "origin/main")
+// matches one of the protected patterns
+func (b BranchesConfig) IsProtected(branch string) bool {
+	for _, pattern := range b.Protected {
+		for _, name := range []string{branch, strings.TrimPrefix(branch, "origin/")} {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SuggestConfig tunes commit message suggestions
+type SuggestConfig struct {
+	// Rules are checked before the built-in heuristics, first match wins, e.g.
+	//   [[suggest.rules]]
+	//   path = "migrations/**"
+	//   type = "feat"
+	//   scope = "db"
+	Rules []SuggestRule `toml:"rules"`
+	// Classifiers recognise the project's domain and add it to the
+	// suggestions' context, optionally rewording them, e.g.
+	//   [[suggest.classifiers]]
+	//   name = "migration"
+	//   files = ["db/migrate/**"]
+	//   keywords = ["create_table", "add_column"]
+	//   describe = "add database migration"
+	Classifiers []SuggestClassifier `toml:"classifiers"`
+	// Max is how many suggestions are listed; the first nine have alt+1-9
+	// shortcuts, the rest are reached with the arrows. 0 lists them all.
+	Max int `toml:"max"`
+}
+
+// SuggestRule assigns a commit type and optional scope to matching paths
+type SuggestRule = suggest.Rule
+
+// SuggestClassifier adds domain context to suggestions for matching diffs
+type SuggestClassifier = suggest.Classifier
+
+// ChecksConfig holds commands gitty runs around its own operations
+type ChecksConfig struct {
+	// PreCommit runs through sh in the repo root before gitty commits; a
+	// non-zero exit stops the commit
+	PreCommit string `toml:"pre_commit"`
+	// TrustRepos are repositories (or directories of them) whose
+	// .gitty.toml may set pre_commit. A check runs shell commands from
+	// whatever was cloned, so it is ignored anywhere not listed here.
+	TrustRepos []string `toml:"trust_repos"`
+}
+
+// Trusts reports whether repoPath's .gitty.toml may set checks
+func (c ChecksConfig) Trusts(repoPath string) bool {
+	repoPath = filepath.Clean(repoPath)
+	for _, path := range c.TrustRepos {
+		if repoPath == path || strings.HasPrefix(repoPath, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyConfig controls alerts when slow push/pull/fetch/rebase operations finish
+type NotifyConfig struct {
+	// Mode is "bell", "osc" (desktop notification escape) or "off"
+	Mode string `toml:"mode"`
+	// AfterSeconds is how long an operation must take before it alerts
+	AfterSeconds int `toml:"after_seconds"`
+}
+
+// RefreshConfig controls the polling refresher that keeps the UI live
+type RefreshConfig struct {
+	// PollSeconds is how often .git/HEAD, .git/index and the branch ref are
+	// checked for changes; 0 turns polling off
+	PollSeconds int `toml:"poll_seconds"`
+}
+
+// SnapshotConfig controls background snapshots of uncommitted work, kept
+// under refs/gitty/snapshots so an accidental reset can be undone
+type SnapshotConfig struct {
+	// IntervalMinutes is how often the worktree is snapshotted while gitty
+	// runs; 0 (the default) turns snapshots off
+	IntervalMinutes int `toml:"interval_minutes"`
+	// Keep is how many snapshots are kept per repository
+	Keep int `toml:"keep"`
+}
+
+// UIConfig controls how gitty looks
+type UIConfig struct {
+	// Palette is "default" (green/red) or "colorblind" (blue/orange) for
+	// diffs, file status icons and ahead/behind counts
+	Palette string `toml:"palette"`
+	// DangerConfirm is "key" (press the key again) or "typed" (type the
+	// branch name, or "discard") to confirm hard resets, discards, force
+	// pushes and branch deletions
+	DangerConfirm string `toml:"danger_confirm"`
+	// Dates is "relative" ("2 days ago") or "absolute" (local time) for the
+	// commit lists; d switches between them
+	Dates string `toml:"dates"`
+	// TourDone is set once the first-launch tour is finished or skipped
+	TourDone bool `toml:"tour_done"`
+}
+
+// GitConfig controls how the git executable is run
+type GitConfig struct {
+	// Binary is the git executable; a bare name is looked up on PATH
+	Binary string `toml:"binary"`
+	// Env is added to the environment of every git command, e.g.
+	//   [git.env]
+	//   GIT_SSH_COMMAND = "ssh -i ~/.ssh/work_key"
+	Env map[string]string `toml:"env"`
+	// Repos adds environment for repositories at or under a path, e.g.
+	//   [git.repos."~/work".env]
+	//   HTTPS_PROXY = "http://proxy.internal:3128"
+	Repos map[string]RepoGitConfig `toml:"repos"`
+}
+
+// RepoGitConfig is the per-repository part of GitConfig
+type RepoGitConfig struct {
+	Env map[string]string `toml:"env"`
+}
+
+// RepoEnv flattens Repos into absolute path -> environment
+func (g GitConfig) RepoEnv() map[string]map[string]string {
+	env := make(map[string]map[string]string, len(g.Repos))
+	for path, repo := range g.Repos {
+		env[path] = repo.Env
+	}
+	return env
+}
+
+// DefaultCommitTypes are the conventional commit types
+var DefaultCommitTypes = []string{
+	"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert",
+}
+
+// Default returns the configuration used when no file exists
+func Default() Config {
+	return Config{
+		Commit: CommitConfig{
+			Types: append([]string{}, DefaultCommitTypes...),
+		},
+		Branches: BranchesConfig{
+			Remote: "origin",
+		},
+		Suggest: SuggestConfig{
+			Max: 9,
+		},
+		Notify: NotifyConfig{
+			Mode:         "bell",
+			AfterSeconds: 3,
+		},
+		Refresh: RefreshConfig{
+			PollSeconds: 2,
+		},
+		Snapshot: SnapshotConfig{
+			Keep: 50,
+		},
+		Git: GitConfig{
+			Binary: "git",
+		},
+	}
+}
+
+// Path returns the location of the config file
+func Path() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "gitty", "config.toml"), nil
+}
+
+// Load reads the config file, falling back to defaults for anything unset.
+// A missing file is not an error.
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(cfg.Commit.Types) == 0 {
+		cfg.Commit.Types = append([]string{}, DefaultCommitTypes...)
+	}
+	if cfg.Git.Binary == "" {
+		cfg.Git.Binary = "git"
+	}
+	cfg.Git.Binary = expandHome(cfg.Git.Binary)
+	repos := make(map[string]RepoGitConfig, len(cfg.Git.Repos))
+	for path, repo := range cfg.Git.Repos {
+		if abs, err := filepath.Abs(expandHome(path)); err == nil {
+			path = abs
+		}
+		repos[path] = repo
+	}
+	cfg.Git.Repos = repos
+	for i, path := range cfg.Checks.TrustRepos {
+		if abs, err := filepath.Abs(expandHome(path)); err == nil {
+			cfg.Checks.TrustRepos[i] = abs
+		}
+	}
+	return cfg, nil
+}
+
+// expandHome replaces a leading "~/" with the user's home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, rest)
+}
+
+// SaveTourDone records tour_done = true under [ui] in the config file,
+// creating it if needed. The file is edited line by line so the user's
+// comments and layout survive.
+func SaveTourDone() error {
+	return setValue("ui", "tour_done", "true")
+}
+
+// setValue sets key = value in [section] of the config file, replacing an
+// existing assignment, adding it under the section header, or appending the
+// section
+func setValue(section, key, value string) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	assignment := key + " = " + value
+
+	lines := strings.Split(string(data), "\n")
+	header := -1
+	current := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			current, _, _ = strings.Cut(strings.TrimPrefix(trimmed, "["), "]")
+			if strings.TrimSpace(current) == section {
+				header = i
+			}
+			continue
+		}
+		name, _, found := strings.Cut(trimmed, "=")
+		if found && strings.TrimSpace(current) == section && strings.TrimSpace(name) == key {
+			lines[i] = assignment
+			return writeConfig(path, strings.Join(lines, "\n"))
+		}
+	}
+
+	if header >= 0 {
+		lines = slices.Insert(lines, header+1, assignment)
+		return writeConfig(path, strings.Join(lines, "\n"))
+	}
+	content := string(data)
+	if content != "" {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	return writeConfig(path, content+"["+section+"]\n"+assignment+"\n")
+}
+
+func writeConfig(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
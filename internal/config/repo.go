@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// RepoFile is the per-repository config, committed at the repo root so a
+// team shares it
+const RepoFile = ".gitty.toml"
+
+// WithRepo applies repoPath's .gitty.toml on top of c. Only team-wide
+// sections are honoured; settings that run programs or change the
+// environment ([git], [keys], ...) stay under the user's control, since the
+// file arrives with whatever repository was cloned. [checks] is the
+// exception the user opts into per repository with checks.trust_repos. A
+// missing file is not an error.
+func (c Config) WithRepo(repoPath string) (Config, error) {
+	file := filepath.Join(repoPath, RepoFile)
+
+	// Decoding into the existing sections keeps every key the file leaves
+	// unset; slices are copied first because the decoder reuses their arrays
+	c.Commit.Types = slices.Clone(c.Commit.Types)
+	c.Commit.Scopes = slices.Clone(c.Commit.Scopes)
+	c.Commit.Tickets.Branches = slices.Clone(c.Commit.Tickets.Branches)
+	c.Branches.Protected = slices.Clone(c.Branches.Protected)
+	c.Suggest.Rules = slices.Clone(c.Suggest.Rules)
+	c.Suggest.Classifiers = slices.Clone(c.Suggest.Classifiers)
+	// Checks are read aside and only kept for trusted repositories; the
+	// file can't trust itself, as trust_repos isn't read from it
+	type repoChecks struct {
+		PreCommit string `toml:"pre_commit"`
+	}
+	var checks repoChecks
+	overrides := struct {
+		Commit   *CommitConfig   `toml:"commit"`
+		Branches *BranchesConfig `toml:"branches"`
+		Suggest  *SuggestConfig  `toml:"suggest"`
+		Checks   *repoChecks     `toml:"checks"`
+	}{&c.Commit, &c.Branches, &c.Suggest, &checks}
+
+	meta, err := toml.DecodeFile(file, &overrides)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return c, fmt.Errorf("%s: %w", RepoFile, err)
+	}
+
+	if len(c.Commit.Types) == 0 {
+		c.Commit.Types = append([]string{}, DefaultCommitTypes...)
+	}
+
+	var problems []string
+	if meta.IsDefined("checks") {
+		if c.Checks.Trusts(repoPath) {
+			c.Checks.PreCommit = checks.PreCommit
+		} else {
+			problems = append(problems, fmt.Sprintf("ignored [checks]; to run them, add %s to checks.trust_repos in your own config", repoPath))
+		}
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		var sections []string
+		for _, key := range undecoded {
+			if !slices.Contains(sections, key[0]) {
+				sections = append(sections, key[0])
+			}
+		}
+		sort.Strings(sections)
+		problems = append(problems, "ignored unsupported settings: "+strings.Join(sections, ", "))
+	}
+	if len(problems) > 0 {
+		return c, fmt.Errorf("%s: %s", RepoFile, strings.Join(problems, "; "))
+	}
+	return c, nil
+}
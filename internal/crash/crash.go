@@ -0,0 +1,135 @@
+// Package crash writes a report when gitty panics and remembers the crash
+// so the next launch in the same repository can offer to pick up where it
+// left off.
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report is what gitty knew when it crashed
+type Report struct {
+	Time      time.Time
+	Repo      string
+	Tab       string
+	View      string // sub-state of the tab: workspace view or tool
+	Operation string // merge, rebase, ... in progress; "" if none
+	Panic     string
+	Stack     string   `json:"-"`
+	Messages  []string // recent status messages, oldest first
+	Jobs      []string // recent and running git operations, oldest first
+	Path      string   // the report file
+}
+
+var (
+	once     sync.Once
+	lastPath string
+)
+
+// reportDir is where reports and the pending marker live, next to the
+// config
+func reportDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "gitty"), nil
+}
+
+// pendingFile marks a crash the next launch hasn't offered to recover yet
+func pendingFile() (string, error) {
+	dir, err := reportDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "crash.json"), nil
+}
+
+// Write saves report as crash-<time>.log and marks it pending for the next
+// launch. Only the first call in a process writes: a panic re-raised on its
+// way out must not overwrite its own report.
+func Write(report Report) (string, error) {
+	var err error
+	once.Do(func() {
+		var dir string
+		if dir, err = reportDir(); err != nil {
+			return
+		}
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return
+		}
+		report.Path = filepath.Join(dir, "crash-"+report.Time.Format("20060102-150405")+".log")
+		if err = os.WriteFile(report.Path, []byte(report.String()), 0644); err != nil {
+			return
+		}
+		lastPath = report.Path
+
+		var data []byte
+		if data, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return
+		}
+		var pending string
+		if pending, err = pendingFile(); err != nil {
+			return
+		}
+		err = os.WriteFile(pending, data, 0644)
+	})
+	return lastPath, err
+}
+
+// LastPath returns the report written by this process, "" if none
+func LastPath() string {
+	return lastPath
+}
+
+// TakePending returns the last crash in repo that hasn't been offered for
+// recovery yet, and clears it. ok is false when there is none.
+func TakePending(repo string) (report Report, ok bool) {
+	pending, err := pendingFile()
+	if err != nil {
+		return Report{}, false
+	}
+	data, err := os.ReadFile(pending)
+	if err != nil {
+		return Report{}, false
+	}
+	if json.Unmarshal(data, &report) != nil || report.Repo != repo {
+		return Report{}, false
+	}
+	os.Remove(pending)
+	return report, true
+}
+
+// String renders the report for reading and attaching to bug reports
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "gitty crashed at %s\n\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, "panic: %s\n\n", r.Panic)
+	fmt.Fprintf(&b, "repository: %s\n", r.Repo)
+	fmt.Fprintf(&b, "tab:        %s\n", r.Tab)
+	if r.View != "" {
+		fmt.Fprintf(&b, "view:       %s\n", r.View)
+	}
+	if r.Operation != "" {
+		fmt.Fprintf(&b, "operation:  %s in progress\n", r.Operation)
+	}
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	section("Operations", r.Jobs)
+	section("Status messages", r.Messages)
+	fmt.Fprintf(&b, "\n%s", r.Stack)
+	return b.String()
+}
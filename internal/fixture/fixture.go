@@ -0,0 +1,334 @@
+// Package fixture builds throwaway repositories in the awkward states gitty
+// has to handle - conflicts, renames, detached HEAD, a diverged upstream,
+// submodules - so features can be developed and checked against the same
+// repository every time.
+package fixture
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Scenario is one kind of repository Make can build
+type Scenario struct {
+	Name        string
+	Description string
+	build       func(f *fixture) error
+}
+
+// Scenarios lists everything Make can build, in the order it builds them
+var Scenarios = []Scenario{
+	{"conflict", "merge stopped on content, add/add and modify/delete conflicts", buildConflict},
+	{"rebase", "rebase stopped on a conflict at step 2 of 3", buildRebase},
+	{"renames", "staged renames and a copy, one renamed file edited again in the worktree", buildRenames},
+	{"detached", "HEAD detached two commits behind main, with a commit made there", buildDetached},
+	{"diverged", "main ahead 2 and behind 1 of origin, a branch strictly behind and one never pushed", buildDiverged},
+	{"submodules", "a submodule with new commits not yet recorded, and one not initialised", buildSubmodules},
+}
+
+// epoch is the date of the first fixture commit; each commit after it is a
+// minute later, so a fixture has the same hashes every time it is built
+var epoch = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// Now is when fixtures are looked at, a day after the first commit. Pin the
+// clock to it (GIT_TEST_DATE_NOW for git) and relative dates come out the
+// same every time.
+var Now = epoch.Add(24 * time.Hour)
+
+// Lookup finds a scenario by name
+func Lookup(name string) (Scenario, bool) {
+	for _, scenario := range Scenarios {
+		if scenario.Name == name {
+			return scenario, true
+		}
+	}
+	return Scenario{}, false
+}
+
+// Make builds scenario in dir/<name> and returns the repository to open.
+// Remotes and submodule sources it needs go next to it as dir/<name>-*.
+// Existing fixtures of the same name are replaced.
+func Make(dir string, scenario Scenario) (string, error) {
+	f := &fixture{dir: dir, repo: filepath.Join(dir, scenario.Name), tick: epoch}
+	matches, _ := filepath.Glob(filepath.Join(dir, scenario.Name+"-*"))
+	for _, path := range append(matches, f.repo) {
+		if err := os.RemoveAll(path); err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := f.init(f.repo); err != nil {
+		return "", err
+	}
+	if err := scenario.build(f); err != nil {
+		return "", fmt.Errorf("%s: %w", scenario.Name, err)
+	}
+	return f.repo, nil
+}
+
+// fixture runs git for one scenario with a fixed identity and clock,
+// ignoring the user's global and system config
+type fixture struct {
+	dir  string
+	repo string
+	tick time.Time
+}
+
+// git runs a git command in repo. Commands expected to stop on a conflict
+// go through gitMayFail.
+func (f *fixture) git(repo string, args ...string) error {
+	output, err := f.run(repo, args...)
+	if err != nil {
+		return fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return nil
+}
+
+// gitMayFail runs a command that is meant to stop part way, like a
+// conflicting merge
+func (f *fixture) gitMayFail(repo string, args ...string) {
+	f.run(repo, args...)
+}
+
+func (f *fixture) run(repo string, args ...string) ([]byte, error) {
+	date := f.tick.Format(time.RFC3339)
+	f.tick = f.tick.Add(time.Minute)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Fixture Author", "GIT_AUTHOR_EMAIL=author@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Fixture Author", "GIT_COMMITTER_EMAIL=author@example.com", "GIT_COMMITTER_DATE="+date,
+		"GIT_EDITOR=true",
+		"GIT_TERMINAL_PROMPT=0",
+	)
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	err := cmd.Run()
+	return output.Bytes(), err
+}
+
+// init creates a repository on main, with an identity of its own so gitty
+// can commit in it
+func (f *fixture) init(repo string, args ...string) error {
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		return err
+	}
+	if err := f.git(repo, append([]string{"init", "--quiet", "--initial-branch=main"}, args...)...); err != nil {
+		return err
+	}
+	if err := f.git(repo, "config", "user.name", "Fixture Author"); err != nil {
+		return err
+	}
+	return f.git(repo, "config", "user.email", "author@example.com")
+}
+
+// write creates or replaces files in repo, given as path, content pairs
+func (f *fixture) write(repo string, files ...string) error {
+	for i := 0; i+1 < len(files); i += 2 {
+		path := filepath.Join(repo, files[i])
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(files[i+1]), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commit writes files and commits everything in repo
+func (f *fixture) commit(repo, message string, files ...string) error {
+	if err := f.write(repo, files...); err != nil {
+		return err
+	}
+	if err := f.git(repo, "add", "--all"); err != nil {
+		return err
+	}
+	return f.git(repo, "commit", "--quiet", "--allow-empty", "-m", message)
+}
+
+// lines joins numbered lines, enough of them that git keeps edits at either
+// end of a file in separate hunks
+func lines(prefix string, n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%s %d\n", prefix, i)
+	}
+	return b.String()
+}
+
+func buildConflict(f *fixture) error {
+	r := f.repo
+	steps := []func() error{
+		func() error {
+			return f.commit(r, "Initial commit",
+				"README.md", "# Fixture\n",
+				"config.yaml", "port: 8080\nhost: localhost\ntimeout: 30\n",
+				"notes.txt", "Shared notes\n")
+		},
+		func() error { return f.git(r, "switch", "--quiet", "-c", "feature") },
+		func() error {
+			return f.commit(r, "Tune config for staging",
+				"config.yaml", "port: 9090\nhost: localhost\ntimeout: 60\n",
+				"schema.sql", "create table users (id integer);\n")
+		},
+		func() error { return f.git(r, "rm", "--quiet", "notes.txt") },
+		func() error { return f.git(r, "commit", "--quiet", "-m", "Drop notes") },
+		func() error { return f.git(r, "switch", "--quiet", "main") },
+		func() error {
+			return f.commit(r, "Serve on 8000",
+				"config.yaml", "port: 8000\nhost: 0.0.0.0\ntimeout: 30\n",
+				"schema.sql", "create table users (id integer primary key);\n",
+				"notes.txt", "Shared notes\nRemember to rotate keys\n")
+		},
+	}
+	if err := runSteps(steps); err != nil {
+		return err
+	}
+	f.gitMayFail(r, "merge", "--no-edit", "feature")
+	return nil
+}
+
+func buildRebase(f *fixture) error {
+	r := f.repo
+	steps := []func() error{
+		func() error { return f.commit(r, "Initial commit", "app.go", lines("line", 20)) },
+		func() error { return f.git(r, "switch", "--quiet", "-c", "topic") },
+		func() error { return f.commit(r, "Add helper", "helper.go", "package main\n") },
+		func() error {
+			return f.commit(r, "Rename greeting", "app.go", strings.Replace(lines("line", 20), "line 10\n", "topic 10\n", 1))
+		},
+		func() error {
+			return f.commit(r, "Document helper", "helper.go", "// Package main helps\npackage main\n")
+		},
+		func() error { return f.git(r, "switch", "--quiet", "main") },
+		func() error {
+			return f.commit(r, "Change greeting upstream", "app.go", strings.Replace(lines("line", 20), "line 10\n", "main 10\n", 1))
+		},
+		func() error { return f.git(r, "switch", "--quiet", "topic") },
+	}
+	if err := runSteps(steps); err != nil {
+		return err
+	}
+	f.gitMayFail(r, "rebase", "main")
+	return nil
+}
+
+func buildRenames(f *fixture) error {
+	r := f.repo
+	steps := []func() error{
+		func() error {
+			return f.commit(r, "Initial commit",
+				"src/util.go", lines("util", 12),
+				"src/parse.go", lines("parse", 12),
+				"docs/guide.md", lines("guide", 12),
+				"LICENSE", lines("license", 12))
+		},
+		func() error { return f.git(r, "mv", "src/util.go", "src/strings.go") },
+		func() error { return f.git(r, "mv", "docs/guide.md", "docs/getting-started.md") },
+		// Renamed and edited: still similar enough for git to pair them
+		func() error { return os.MkdirAll(filepath.Join(r, "internal"), 0755) },
+		func() error { return f.git(r, "mv", "src/parse.go", "internal/parse.go") },
+		func() error {
+			return f.write(r, "internal/parse.go", strings.Replace(lines("parse", 12), "parse 1\n", "parsed 1\n", 1))
+		},
+		func() error { return f.write(r, "COPYING", lines("license", 12)) },
+		func() error { return f.git(r, "add", "--all") },
+		// A further edit to a renamed file, left unstaged
+		func() error {
+			return f.write(r, "src/strings.go", lines("util", 12)+"util 13\n")
+		},
+	}
+	return runSteps(steps)
+}
+
+func buildDetached(f *fixture) error {
+	r := f.repo
+	steps := []func() error{
+		func() error { return f.commit(r, "Initial commit", "main.go", "package main\n") },
+		func() error { return f.commit(r, "Add version", "version.txt", "1.0\n") },
+		func() error { return f.commit(r, "Bump version", "version.txt", "1.1\n") },
+		func() error { return f.commit(r, "Bump version again", "version.txt", "1.2\n") },
+		func() error { return f.git(r, "tag", "v1.0", "HEAD~2") },
+		func() error { return f.git(r, "switch", "--quiet", "--detach", "v1.0") },
+		func() error { return f.commit(r, "Hotfix on the detached HEAD", "hotfix.txt", "patched\n") },
+	}
+	return runSteps(steps)
+}
+
+func buildDiverged(f *fixture) error {
+	r := f.repo
+	origin := filepath.Join(f.dir, "diverged-origin.git")
+	other := filepath.Join(f.dir, "diverged-other")
+	steps := []func() error{
+		func() error { return f.init(origin, "--bare") },
+		func() error { return f.commit(r, "Initial commit", "app.txt", "v1\n") },
+		func() error { return f.git(r, "remote", "add", "origin", origin) },
+		func() error { return f.git(r, "branch", "--quiet", "release") },
+		func() error { return f.git(r, "push", "--quiet", "--set-upstream", "origin", "main", "release") },
+		// Someone else pushes to main and release
+		func() error { return f.git(f.dir, "clone", "--quiet", origin, other) },
+		func() error { return f.commit(other, "Upstream change", "upstream.txt", "theirs\n") },
+		func() error { return f.git(other, "switch", "--quiet", "release") },
+		func() error { return f.commit(other, "Release notes", "NOTES.md", "# 1.0\n") },
+		func() error { return f.git(other, "push", "--quiet", "origin", "main", "release") },
+		// Meanwhile two local commits, and a branch never pushed
+		func() error { return f.commit(r, "Local change", "app.txt", "v2\n") },
+		func() error { return f.commit(r, "Another local change", "local.txt", "ours\n") },
+		func() error { return f.git(r, "branch", "--quiet", "experiment") },
+		func() error { return f.git(r, "fetch", "--quiet", "origin") },
+		// "fetched ... ago" is measured from FETCH_HEAD's time
+		func() error {
+			fetchHead := filepath.Join(r, ".git", "FETCH_HEAD")
+			return os.Chtimes(fetchHead, f.tick, f.tick)
+		},
+	}
+	return runSteps(steps)
+}
+
+func buildSubmodules(f *fixture) error {
+	r := f.repo
+	lib := filepath.Join(f.dir, "submodules-lib")
+	vendor := filepath.Join(f.dir, "submodules-vendor")
+	steps := []func() error{
+		func() error { return f.init(lib) },
+		func() error { return f.commit(lib, "Library v1", "lib.go", "package lib\n") },
+		func() error { return f.init(vendor) },
+		func() error { return f.commit(vendor, "Vendored code", "vendor.go", "package vendor\n") },
+		func() error { return f.commit(r, "Initial commit", "main.go", "package main\n") },
+		func() error {
+			return f.git(r, "-c", "protocol.file.allow=always", "submodule", "--quiet", "add", lib, "lib")
+		},
+		func() error {
+			return f.git(r, "-c", "protocol.file.allow=always", "submodule", "--quiet", "add", vendor, "vendor")
+		},
+		func() error { return f.git(r, "commit", "--quiet", "-m", "Add submodules") },
+		// Leave vendor registered but not checked out
+		func() error { return f.git(r, "submodule", "--quiet", "deinit", "vendor") },
+		// New commits inside lib the superproject hasn't recorded
+		func() error {
+			return f.commit(filepath.Join(r, "lib"), "Library v2", "lib.go", "package lib\n\nconst Version = 2\n")
+		},
+	}
+	return runSteps(steps)
+}
+
+// runSteps runs steps in order, stopping at the first error
+func runSteps(steps []func() error) error {
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package forge talks to GitHub and GitLab through their command-line
+// clients, gh and glab, so gitty needs no tokens of its own
+package forge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// CI states
+const (
+	CIPassing = "passing"
+	CIFailing = "failing"
+	CIPending = "pending"
+)
+
+// PR is an open pull request (GitHub) or merge request (GitLab)
+type PR struct {
+	Number    int
+	Title     string
+	Branch    string
+	URL       string
+	Draft     bool
+	CI        string // CIPassing, CIFailing, CIPending or "" without checks
+	Review    string // e.g. "approved", "changes requested"; "" when unknown
+	Conflicts bool
+}
+
+// Detect picks the client for a repository: glab for GitLab remotes, gh
+// otherwise, as long as it is installed. It returns "" when neither fits.
+func Detect(remoteURLs []string) string {
+	gitlab := false
+	for _, url := range remoteURLs {
+		if strings.Contains(url, "gitlab") {
+			gitlab = true
+		}
+	}
+	candidates := []string{"gh", "glab"}
+	if gitlab {
+		candidates = []string{"glab", "gh"}
+	}
+	for _, tool := range candidates {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool
+		}
+	}
+	return ""
+}
+
+// Noun is what the forge calls its change requests
+func Noun(tool string) string {
+	if tool == "glab" {
+		return "merge request"
+	}
+	return "pull request"
+}
+
+// ListMine lists the open requests authored by the logged-in user
+func ListMine(tool, repoPath string) ([]PR, error) {
+	switch tool {
+	case "gh":
+		output, err := run(repoPath, "gh", "pr", "list", "--author", "@me", "--state", "open",
+			"--json", "number,title,headRefName,url,isDraft,reviewDecision,statusCheckRollup,mergeable")
+		if err != nil {
+			return nil, err
+		}
+		return parseGH(output)
+	case "glab":
+		output, err := run(repoPath, "glab", "mr", "list", "--author=@me", "--output", "json")
+		if err != nil {
+			return nil, err
+		}
+		return parseGlab(output)
+	}
+	return nil, errors.New("no gh or glab found")
+}
+
+// Checkout checks out a request's branch locally
+func Checkout(tool, repoPath string, number int) error {
+	sub := "pr"
+	if tool == "glab" {
+		sub = "mr"
+	}
+	_, err := run(repoPath, tool, sub, "checkout", strconv.Itoa(number))
+	return err
+}
+
+// Open shows a request in the browser
+func Open(tool, repoPath string, number int) error {
+	sub := "pr"
+	if tool == "glab" {
+		sub = "mr"
+	}
+	_, err := run(repoPath, tool, sub, "view", strconv.Itoa(number), "--web")
+	return err
+}
+
+// run executes a forge client in the repository, turning its complaint on
+// failure into the error
+func run(repoPath, tool string, args ...string) ([]byte, error) {
+	cmd := exec.Command(tool, args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if lines := strings.Split(strings.TrimSpace(string(exitErr.Stderr)), "\n"); lines[0] != "" {
+				return nil, errors.New(lines[len(lines)-1])
+			}
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+func parseGH(output []byte) ([]PR, error) {
+	var raw []struct {
+		Number         int    `json:"number"`
+		Title          string `json:"title"`
+		HeadRefName    string `json:"headRefName"`
+		URL            string `json:"url"`
+		IsDraft        bool   `json:"isDraft"`
+		ReviewDecision string `json:"reviewDecision"`
+		Mergeable      string `json:"mergeable"`
+		Checks         []struct {
+			Status     string `json:"status"`     // check runs: QUEUED, IN_PROGRESS, COMPLETED
+			Conclusion string `json:"conclusion"` // check runs: SUCCESS, FAILURE, ...
+			State      string `json:"state"`      // commit statuses: SUCCESS, PENDING, FAILURE, ERROR
+		} `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("unexpected gh output: %v", err)
+	}
+
+	prs := make([]PR, 0, len(raw))
+	for _, r := range raw {
+		pr := PR{
+			Number:    r.Number,
+			Title:     r.Title,
+			Branch:    r.HeadRefName,
+			URL:       r.URL,
+			Draft:     r.IsDraft,
+			Review:    strings.ToLower(strings.ReplaceAll(r.ReviewDecision, "_", " ")),
+			Conflicts: r.Mergeable == "CONFLICTING",
+		}
+		failing, pending := false, false
+		for _, check := range r.Checks {
+			result := check.Conclusion
+			if check.State != "" {
+				result = check.State
+			}
+			switch result {
+			case "FAILURE", "ERROR", "CANCELLED", "TIMED_OUT", "ACTION_REQUIRED":
+				failing = true
+			case "PENDING", "EXPECTED":
+				pending = true
+			}
+			if check.Status != "" && check.Status != "COMPLETED" {
+				pending = true
+			}
+		}
+		switch {
+		case failing:
+			pr.CI = CIFailing
+		case pending:
+			pr.CI = CIPending
+		case len(r.Checks) > 0:
+			pr.CI = CIPassing
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func parseGlab(output []byte) ([]PR, error) {
+	var raw []struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		SourceBranch string `json:"source_branch"`
+		WebURL       string `json:"web_url"`
+		Draft        bool   `json:"draft"`
+		HasConflicts bool   `json:"has_conflicts"`
+		MergeStatus  string `json:"detailed_merge_status"`
+		Pipeline     *struct {
+			Status string `json:"status"`
+		} `json:"head_pipeline"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("unexpected glab output: %v", err)
+	}
+
+	prs := make([]PR, 0, len(raw))
+	for _, r := range raw {
+		pr := PR{
+			Number:    r.IID,
+			Title:     r.Title,
+			Branch:    r.SourceBranch,
+			URL:       r.WebURL,
+			Draft:     r.Draft,
+			Conflicts: r.HasConflicts,
+		}
+		switch r.MergeStatus {
+		case "not_approved":
+			pr.Review = "review required"
+		case "mergeable":
+			pr.Review = "approved"
+		case "discussions_not_resolved":
+			pr.Review = "unresolved discussions"
+		}
+		if r.Pipeline != nil {
+			switch r.Pipeline.Status {
+			case "success":
+				pr.CI = CIPassing
+			case "failed", "canceled":
+				pr.CI = CIFailing
+			case "":
+			default:
+				pr.CI = CIPending
+			}
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
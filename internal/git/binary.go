@@ -0,0 +1,138 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BinaryVersion describes one side of a binary file change
+type BinaryVersion struct {
+	Exists bool
+	Size   int64
+	Hash   string // short sha256 of the content
+	Format string // image format, "" if not a recognised image
+	Width  int
+	Height int
+}
+
+// IsBinaryDiff reports whether git printed a diff without text hunks
+func IsBinaryDiff(diff string) bool {
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBinaryVersions reads both sides of a binary change: HEAD vs index for
+// staged changes, index vs working tree otherwise. origPath is the source of
+// a staged rename, if any.
+func GetBinaryVersions(repoPath, filePath, origPath string, staged bool) (before, after BinaryVersion) {
+	oldPath := filePath
+	if origPath != "" {
+		oldPath = origPath
+	}
+
+	if staged {
+		before = describeBinary(readBlob(repoPath, "HEAD:"+oldPath))
+		after = describeBinary(readBlob(repoPath, ":"+filePath))
+	} else {
+		before = describeBinary(readBlob(repoPath, ":"+filePath))
+		after = describeBinary(os.ReadFile(filepath.Join(repoPath, filePath)))
+	}
+	return before, after
+}
+
+// FormatBinarySummary renders a binary change as text for the diff views.
+// byAttr notes that .gitattributes marks the file -diff, so git treats it
+// as binary whatever its content.
+func FormatBinarySummary(before, after BinaryVersion, byAttr bool) string {
+	lines := []string{"Binary change summary:"}
+	if byAttr {
+		lines[0] = "Binary per .gitattributes (-diff), change summary:"
+	}
+	lines = append(lines, "  old: "+before.String())
+	lines = append(lines, "  new: "+after.String())
+
+	switch {
+	case !before.Exists || !after.Exists:
+	case before.Hash == after.Hash:
+		lines = append(lines, "  content identical (mode or metadata change only)")
+	case before.Format != "" && before.Format == after.Format &&
+		(before.Width != after.Width || before.Height != after.Height):
+		lines = append(lines, fmt.Sprintf("  resized %dx%d → %dx%d", before.Width, before.Height, after.Width, after.Height))
+	default:
+		lines = append(lines, fmt.Sprintf("  size %+d bytes", after.Size-before.Size))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (v BinaryVersion) String() string {
+	if !v.Exists {
+		return "(none)"
+	}
+	s := fmt.Sprintf("%s  sha256 %s", formatSize(v.Size), v.Hash)
+	if v.Format != "" {
+		s += fmt.Sprintf("  %s %dx%d", v.Format, v.Width, v.Height)
+	}
+	return s
+}
+
+func readBlob(repoPath, rev string) ([]byte, error) {
+	cmd := command(repoPath, "cat-file", "blob", rev)
+	return cmd.Output()
+}
+
+func describeBinary(data []byte, err error) BinaryVersion {
+	if err != nil {
+		return BinaryVersion{}
+	}
+	sum := sha256.Sum256(data)
+	v := BinaryVersion{Exists: true, Size: int64(len(data)), Hash: fmt.Sprintf("%x", sum[:6])}
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		v.Format, v.Width, v.Height = format, cfg.Width, cfg.Height
+	}
+	return v
+}
+
+// GetDiffAttr reads a file's diff attribute from .gitattributes: "unset"
+// for -diff (always shown as binary), a diff driver's name, or "" when the
+// attribute is not specified
+func GetDiffAttr(repoPath, filePath string) string {
+	output, err := command(repoPath, "check-attr", "-z", "diff", "--", filePath).Output()
+	if err != nil {
+		return ""
+	}
+	// path NUL attribute NUL value NUL
+	fields := splitNul(output)
+	if len(fields) < 3 || fields[2] == "unspecified" || fields[2] == "set" {
+		return ""
+	}
+	return fields[2]
+}
+
+// GetTextconv returns the command a diff driver converts files to text
+// with, or "" when the driver has none
+func GetTextconv(repoPath, driver string) string {
+	output, _ := command(repoPath, "config", "diff."+driver+".textconv").Output()
+	return strings.TrimSpace(string(output))
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testRepo creates an empty repository that ignores the user's and the
+// system's git config
+func testRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	return repo
+}
+
+// runGit runs git in repo and returns its trimmed output, failing the test
+// on an error
+func runGit(t *testing.T, repo string, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func writeFile(t *testing.T, repo, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommitStagedPathsLiteral(t *testing.T) {
+	repo := testRepo(t)
+	writeFile(t, repo, "README", "hello\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "initial")
+
+	// "a*.txt" as a glob would take ab.txt too
+	writeFile(t, repo, "a*.txt", "star\n")
+	writeFile(t, repo, "ab.txt", "plain\n")
+	runGit(t, repo, "add", ".")
+
+	if err := CommitStagedPaths(repo, "add star", []string{"a*.txt"}, CommitOverride{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := runGit(t, repo, "show", "--name-only", "--format=", "HEAD"); got != "a*.txt" {
+		t.Errorf("committed %q, want a*.txt only", got)
+	}
+	if got := runGit(t, repo, "diff", "--cached", "--name-only"); got != "ab.txt" {
+		t.Errorf("left staged %q, want ab.txt", got)
+	}
+}
+
+func TestCommitDuringMergeRefused(t *testing.T) {
+	repo := testRepo(t)
+	writeFile(t, repo, "file.txt", "base\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "base")
+	runGit(t, repo, "checkout", "-q", "-b", "topic")
+	writeFile(t, repo, "file.txt", "topic\n")
+	writeFile(t, repo, "topic.txt", "topic only\n")
+	runGit(t, repo, "commit", "-q", "-am", "topic")
+	runGit(t, repo, "add", "topic.txt")
+	runGit(t, repo, "commit", "-q", "-m", "topic file")
+	runGit(t, repo, "checkout", "-q", "main")
+	writeFile(t, repo, "file.txt", "main\n")
+	runGit(t, repo, "commit", "-q", "-am", "main")
+	exec.Command("git", "-C", repo, "merge", "-q", "topic").Run() // conflicts
+
+	before := runGit(t, repo, "ls-files", "--stage")
+	if err := CommitStagedPaths(repo, "partial", []string{"topic.txt"}, CommitOverride{}); err == nil || !strings.Contains(err.Error(), "merge") {
+		t.Errorf("CommitStagedPaths during a merge: err = %v, want a refusal", err)
+	}
+	if _, err := CommitInGroups(repo, []CommitGroup{{Message: "split", Files: []string{"topic.txt"}}}); err == nil {
+		t.Error("CommitInGroups during a merge succeeded, want a refusal")
+	}
+	if after := runGit(t, repo, "ls-files", "--stage"); after != before {
+		t.Errorf("index changed:\n%s\nwant\n%s", after, before)
+	}
+	if stages := strings.Fields(runGit(t, repo, "ls-files", "--unmerged", "file.txt")); !slices.Contains(stages, "3") {
+		t.Errorf("conflict stages of file.txt lost: %v", stages)
+	}
+}
@@ -0,0 +1,286 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Identity is a commit author identity (user.name / user.email)
+type Identity struct {
+	Name   string
+	Email  string
+	Source string // config layer ("local", "global", ...) or "history"
+}
+
+// IsComplete reports whether both name and email are set
+func (id Identity) IsComplete() bool {
+	return id.Name != "" && id.Email != ""
+}
+
+func (id Identity) String() string {
+	return fmt.Sprintf("%s <%s>", id.Name, id.Email)
+}
+
+// GetConfigValue returns the effective value of a config key, or "" if unset
+func GetConfigValue(repoPath, key string) string {
+	cmd := command(repoPath, "config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// getScopedConfigValue returns the value of a key in one config layer (--local, --global, --system)
+func getScopedConfigValue(repoPath, scope, key string) string {
+	cmd := command(repoPath, "config", scope, "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// SetLocalConfig writes a key to the repository's own config
+func SetLocalConfig(repoPath, key, value string) error {
+	return SetConfig(repoPath, "local", key, value)
+}
+
+// SetConfig writes a key to the given config layer ("local" or "global")
+func SetConfig(repoPath, scope, key, value string) error {
+	output, err := Execute(repoPath, "config", "--"+scope, key, value)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UnsetConfig removes a key from the given config layer
+func UnsetConfig(repoPath, scope, key string) error {
+	output, err := Execute(repoPath, "config", "--"+scope, "--unset", key)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ConfigEntry is one key/value from `git config --list`
+type ConfigEntry struct {
+	Key   string
+	Value string
+	Scope string // "system", "global", "local", "worktree", "command"
+}
+
+// GetConfigEntries lists every config entry with the layer it comes from.
+// Later entries override earlier ones for the same key.
+func GetConfigEntries(repoPath string) []ConfigEntry {
+	var entries []ConfigEntry
+
+	cmd := command(repoPath, "config", "--list", "--show-scope", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return entries
+	}
+
+	// Format: scope NUL key LF value NUL
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, value, _ := strings.Cut(fields[i+1], "\n")
+		entries = append(entries, ConfigEntry{Key: key, Value: value, Scope: fields[i]})
+	}
+	return entries
+}
+
+// Alias is a user-defined git alias from the [alias] config section
+type Alias struct {
+	Name      string
+	Expansion string
+}
+
+// IsShell reports whether the alias runs a shell command (`!cmd`) rather than a git subcommand
+func (a Alias) IsShell() bool {
+	return strings.HasPrefix(a.Expansion, "!")
+}
+
+// GetAliases returns the effective aliases, sorted as they appear in config
+func GetAliases(repoPath string) []Alias {
+	var aliases []Alias
+	index := make(map[string]int)
+
+	for _, e := range GetConfigEntries(repoPath) {
+		name, ok := strings.CutPrefix(e.Key, "alias.")
+		if !ok {
+			continue
+		}
+		// Later layers override earlier ones
+		if i, exists := index[name]; exists {
+			aliases[i].Expansion = e.Value
+			continue
+		}
+		index[name] = len(aliases)
+		aliases = append(aliases, Alias{Name: name, Expansion: e.Value})
+	}
+	return aliases
+}
+
+// RunAlias runs an alias through git so shell and subcommand aliases behave
+// exactly as on the command line. It is serialised with gitty's own index
+// writes when the command behind it writes the index.
+func RunAlias(repoPath, name string) (string, error) {
+	output, err := execute(repoPath, aliasWritesIndex(repoPath, name), []string{name})
+	return string(output), err
+}
+
+// aliasWritesIndex follows an alias to the git subcommand it runs and
+// reports whether that takes index.lock. Shell aliases can run anything, so
+// they count as writers, as do alias loops.
+func aliasWritesIndex(repoPath, name string) bool {
+	seen := make(map[string]bool)
+	for !seen[name] {
+		seen[name] = true
+		expansion := GetConfigValue(repoPath, "alias."+name)
+		if expansion == "" {
+			return indexWriters[name]
+		}
+		if strings.HasPrefix(expansion, "!") {
+			return true
+		}
+		name = subcommand(strings.Fields(expansion))
+	}
+	return true
+}
+
+// EditableConfigKey describes a config key that can be edited from the TUI
+type EditableConfigKey struct {
+	Key     string
+	Desc    string
+	Options []string // allowed values; empty means free text
+}
+
+// EditableConfigKeys returns the common keys offered by the config editor
+func EditableConfigKeys() []EditableConfigKey {
+	return []EditableConfigKey{
+		{"user.name", "Commit author name", nil},
+		{"user.email", "Commit author email", nil},
+		{"pull.rebase", "Rebase instead of merge on pull", []string{"true", "false", "merges", "interactive"}},
+		{"push.default", "What `git push` pushes", []string{"simple", "current", "upstream", "matching", "nothing"}},
+		{"core.editor", "Editor for commit messages", nil},
+		{"fetch.prune", "Prune deleted remote branches on fetch", []string{"true", "false"}},
+		{"init.defaultBranch", "Branch name for new repositories", nil},
+	}
+}
+
+// ValidateConfigValue checks a value for one of the editable keys
+func ValidateConfigValue(key, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s cannot be empty (use unset instead)", key)
+	}
+	for _, k := range EditableConfigKeys() {
+		if k.Key != key || len(k.Options) == 0 {
+			continue
+		}
+		for _, opt := range k.Options {
+			if value == opt {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of: %s", key, strings.Join(k.Options, ", "))
+	}
+	switch key {
+	case "user.email":
+		if !strings.Contains(value, "@") || strings.ContainsAny(value, "<> ") {
+			return fmt.Errorf("invalid email address")
+		}
+	case "init.defaultBranch":
+		if strings.ContainsAny(value, " ~^:?*[\\") {
+			return fmt.Errorf("invalid branch name")
+		}
+	}
+	return nil
+}
+
+// GetIdentity returns the identity commits will be authored with, and the
+// config layer it comes from ("global/local" when name and email differ)
+func GetIdentity(repoPath string) Identity {
+	name, nameScope := getConfigWithScope(repoPath, "user.name")
+	email, emailScope := getConfigWithScope(repoPath, "user.email")
+	source := emailScope
+	if nameScope != emailScope && nameScope != "" && emailScope != "" {
+		source = nameScope + "/" + emailScope
+	}
+	return Identity{Name: name, Email: email, Source: source}
+}
+
+// getConfigWithScope returns the effective value of a key and the layer
+// that sets it ("local", "global", "system", "worktree" or "command").
+// Without git 2.26's --show-scope the scope is "".
+func getConfigWithScope(repoPath, key string) (value, scope string) {
+	output, err := command(repoPath, "config", "--show-scope", "--get", key).Output()
+	if err != nil {
+		return GetConfigValue(repoPath, key), ""
+	}
+	scope, value, _ = strings.Cut(strings.TrimSpace(string(output)), "\t")
+	return value, scope
+}
+
+// GetKnownIdentities collects identities from the local and global config and
+// from recent commit authors, de-duplicated by email
+func GetKnownIdentities(repoPath string) []Identity {
+	var identities []Identity
+	seen := make(map[string]bool)
+
+	add := func(id Identity) {
+		key := strings.ToLower(id.Email)
+		if !id.IsComplete() || seen[key] {
+			return
+		}
+		seen[key] = true
+		identities = append(identities, id)
+	}
+
+	for _, scope := range []string{"--local", "--global"} {
+		add(Identity{
+			Name:   getScopedConfigValue(repoPath, scope, "user.name"),
+			Email:  getScopedConfigValue(repoPath, scope, "user.email"),
+			Source: strings.TrimPrefix(scope, "--"),
+		})
+	}
+
+	cmd := command(repoPath, "log", "-200", "-z", "--format=%an%x1f%ae")
+	output, err := cmd.Output()
+	if err == nil {
+		for _, record := range splitNul(output) {
+			parts := strings.SplitN(record, fieldSep, 2)
+			if len(parts) == 2 {
+				add(Identity{Name: parts[0], Email: parts[1], Source: "history"})
+			}
+		}
+	}
+
+	return identities
+}
+
+// SetLocalIdentity writes user.name and user.email to the repository config
+func SetLocalIdentity(repoPath string, id Identity) error {
+	if err := SetLocalConfig(repoPath, "user.name", id.Name); err != nil {
+		return err
+	}
+	return SetLocalConfig(repoPath, "user.email", id.Email)
+}
+
+// ParseIdentity parses "Name <email>"
+func ParseIdentity(s string) (Identity, error) {
+	open := strings.Index(s, "<")
+	closeIdx := strings.LastIndex(s, ">")
+	if open == -1 || closeIdx < open {
+		return Identity{}, fmt.Errorf("expected format: Name <email>")
+	}
+	id := Identity{
+		Name:  strings.TrimSpace(s[:open]),
+		Email: strings.TrimSpace(s[open+1 : closeIdx]),
+	}
+	if id.Name == "" || !strings.Contains(id.Email, "@") {
+		return Identity{}, fmt.Errorf("expected format: Name <email>")
+	}
+	return id, nil
+}
@@ -0,0 +1,35 @@
+package git
+
+import "testing"
+
+func TestAliasWritesIndex(t *testing.T) {
+	repo := testRepo(t)
+	for _, args := range [][]string{
+		{"config", "alias.co", "checkout"},
+		{"config", "alias.st", "-c color.ui=never stash"},
+		{"config", "alias.c", "co"},
+		{"config", "alias.lg", "log --oneline"},
+		{"config", "alias.sh", "!echo hi"},
+		{"config", "alias.loop", "loop2"},
+		{"config", "alias.loop2", "loop"},
+	} {
+		runGit(t, repo, args...)
+	}
+
+	for _, tt := range []struct {
+		alias string
+		want  bool
+	}{
+		{"co", true},
+		{"st", true},
+		{"c", true},
+		{"lg", false},
+		{"sh", true},
+		{"loop", true},
+		{"missing", false},
+	} {
+		if got := aliasWritesIndex(repo, tt.alias); got != tt.want {
+			t.Errorf("aliasWritesIndex(%q) = %v, want %v", tt.alias, got, tt.want)
+		}
+	}
+}
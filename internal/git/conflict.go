@@ -0,0 +1,219 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ConflictBlock is one <<<<<<< ... >>>>>>> region of a conflicted file
+type ConflictBlock struct {
+	OursLabel   string
+	BaseLabel   string
+	TheirsLabel string
+	Ours        []string
+	Base        []string // common ancestor, valid when HasBase
+	Theirs      []string
+	HasBase     bool
+}
+
+// ConflictDoc is a conflicted file split into plain text and conflict blocks
+type ConflictDoc struct {
+	Path   string
+	Blocks []ConflictBlock
+	// parts[i] is the text before Blocks[i]; the last entry follows the last block
+	parts           [][]string
+	trailingNewline bool
+	eol             string // "\r\n" for files with CRLF line endings, else "\n"
+}
+
+// ReadConflict parses the conflict markers in a working tree file
+func ReadConflict(repoPath, path string) (*ConflictDoc, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, path))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseConflictMarkers(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Blocks) == 0 {
+		return nil, fmt.Errorf("%s has no conflict markers", path)
+	}
+	doc.Path = path
+
+	// Plain merge-style markers carry no ancestor; recover it by merging
+	// the index stages again with diff3 markers
+	if !doc.hasBase() {
+		if merged := mergeStages(repoPath, path); merged != nil {
+			doc.fillBase(merged)
+		}
+	}
+	return doc, nil
+}
+
+func (d *ConflictDoc) hasBase() bool {
+	for _, block := range d.Blocks {
+		if !block.HasBase {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeStages redoes the merge of path from its ours (:2), base (:1) and
+// theirs (:3) index stages with diff3 markers, which carry each block's
+// ancestor. It is nil when a stage is missing, as for files added on both
+// sides.
+func mergeStages(repoPath, path string) *ConflictDoc {
+	dir, err := os.MkdirTemp("", "gitty-merge-")
+	if err != nil {
+		return nil
+	}
+	defer os.RemoveAll(dir)
+
+	var files []string
+	for _, stage := range []string{"2", "1", "3"} {
+		blob, err := command(repoPath, "show", ":"+stage+":"+path).Output()
+		if err != nil {
+			return nil
+		}
+		file := filepath.Join(dir, stage)
+		if err := os.WriteFile(file, blob, 0600); err != nil {
+			return nil
+		}
+		files = append(files, file)
+	}
+
+	// merge-file exits with the number of conflicts, so only the output counts
+	args := append([]string{"merge-file", "-p", "--diff3", "-L", "ours", "-L", "merge base", "-L", "theirs"}, files...)
+	output, _ := command(repoPath, args...).Output()
+	merged, err := parseConflictMarkers(string(output))
+	if err != nil {
+		return nil
+	}
+	return merged
+}
+
+// fillBase takes each block's ancestor from the same block of merged, the
+// file merged again with diff3 markers. Blocks the user has edited, or that
+// the merge split differently, are left without one.
+func (d *ConflictDoc) fillBase(merged *ConflictDoc) {
+	if len(merged.Blocks) != len(d.Blocks) {
+		return
+	}
+	for i := range d.Blocks {
+		block, other := &d.Blocks[i], merged.Blocks[i]
+		if block.HasBase || !other.HasBase || !slices.Equal(block.Ours, other.Ours) || !slices.Equal(block.Theirs, other.Theirs) {
+			continue
+		}
+		block.Base = other.Base
+		block.BaseLabel = other.BaseLabel
+		block.HasBase = true
+	}
+}
+
+// parseConflictMarkers splits content into plain text and conflict blocks.
+// Lines are kept without their ending; a file whose first line ends in CRLF
+// is written back with CRLF.
+func parseConflictMarkers(content string) (*ConflictDoc, error) {
+	doc := &ConflictDoc{trailingNewline: strings.HasSuffix(content, "\n"), eol: "\n"}
+	lines := splitLines(content)
+	if first, _, ok := strings.Cut(content, "\n"); ok && strings.HasSuffix(first, "\r") {
+		doc.eol = "\r\n"
+	}
+
+	var text []string
+	var block *ConflictBlock
+	inBase, inTheirs := false, false
+	for i, line := range lines {
+		switch {
+		case block == nil && isMarker(line, "<<<<<<<"):
+			block = &ConflictBlock{OursLabel: markerLabel(line)}
+			inBase, inTheirs = false, false
+		case block != nil && !inBase && !inTheirs && isMarker(line, "|||||||"):
+			// diff3/zdiff3 style: the ancestor sits between ours and theirs
+			block.BaseLabel = markerLabel(line)
+			block.HasBase = true
+			inBase = true
+		case block != nil && !inTheirs && line == "=======":
+			inBase, inTheirs = false, true
+		case block != nil && inTheirs && isMarker(line, ">>>>>>>"):
+			block.TheirsLabel = markerLabel(line)
+			doc.parts = append(doc.parts, text)
+			doc.Blocks = append(doc.Blocks, *block)
+			text, block = nil, nil
+		case block != nil && isMarker(line, "<<<<<<<"):
+			return nil, fmt.Errorf("nested conflict marker on line %d", i+1)
+		case block != nil && inTheirs:
+			block.Theirs = append(block.Theirs, line)
+		case block != nil && inBase:
+			block.Base = append(block.Base, line)
+		case block != nil:
+			block.Ours = append(block.Ours, line)
+		default:
+			text = append(text, line)
+		}
+	}
+	if block != nil {
+		return nil, fmt.Errorf("unterminated conflict block")
+	}
+	doc.parts = append(doc.parts, text)
+	return doc, nil
+}
+
+// splitLines splits text into lines without their LF or CRLF endings
+func splitLines(text string) []string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func isMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+func markerLabel(line string) string {
+	return strings.TrimSpace(line[7:])
+}
+
+// Resolve replaces every conflict block with the matching resolution text.
+// An empty resolution drops the block.
+func (d *ConflictDoc) Resolve(resolutions []string) string {
+	var lines []string
+	for i, part := range d.parts {
+		lines = append(lines, part...)
+		if i < len(d.Blocks) && i < len(resolutions) && resolutions[i] != "" {
+			lines = append(lines, splitLines(resolutions[i])...)
+		}
+	}
+	content := strings.Join(lines, d.eol)
+	if d.trailingNewline && len(lines) > 0 {
+		content += d.eol
+	}
+	return content
+}
+
+// WriteResolution writes the resolved content and stages the file
+func WriteResolution(repoPath, path, content string) error {
+	if err := writable(); err != nil {
+		return err
+	}
+	full := filepath.Join(repoPath, path)
+	info, err := os.Stat(full)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(full, []byte(content), info.Mode().Perm()); err != nil {
+		return err
+	}
+	output, err := Execute(repoPath, "add", "--", path)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
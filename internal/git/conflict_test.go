@@ -0,0 +1,119 @@
+package git
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []ConflictBlock
+		parts   [][]string
+	}{
+		{
+			name:    "merge style",
+			content: "a\n<<<<<<< HEAD\nM2\n=======\nO2\n>>>>>>> topic\nb\n",
+			want:    []ConflictBlock{{OursLabel: "HEAD", TheirsLabel: "topic", Ours: []string{"M2"}, Theirs: []string{"O2"}}},
+			parts:   [][]string{{"a"}, {"b"}},
+		},
+		{
+			name:    "diff3 style",
+			content: "<<<<<<< ours\nM\n||||||| base\nB\n=======\nO\n>>>>>>> theirs\n",
+			want: []ConflictBlock{{OursLabel: "ours", BaseLabel: "base", TheirsLabel: "theirs",
+				Ours: []string{"M"}, Base: []string{"B"}, Theirs: []string{"O"}, HasBase: true}},
+			parts: [][]string{nil, nil},
+		},
+		{
+			name:    "CRLF",
+			content: "a\r\n<<<<<<< HEAD\r\nM2\r\n=======\r\nO2\r\n>>>>>>> topic\r\nb\r\n",
+			want:    []ConflictBlock{{OursLabel: "HEAD", TheirsLabel: "topic", Ours: []string{"M2"}, Theirs: []string{"O2"}}},
+			parts:   [][]string{{"a"}, {"b"}},
+		},
+		{
+			name:    "one side deleted",
+			content: "<<<<<<< HEAD\n=======\ngone\n>>>>>>> topic\n",
+			want:    []ConflictBlock{{OursLabel: "HEAD", TheirsLabel: "topic", Theirs: []string{"gone"}}},
+			parts:   [][]string{nil, nil},
+		},
+		{
+			name:    "no markers",
+			content: "plain\n======= not a marker\n",
+			parts:   [][]string{{"plain", "======= not a marker"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := parseConflictMarkers(test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(doc.Blocks, test.want, equalBlocks) {
+				t.Errorf("blocks = %+v, want %+v", doc.Blocks, test.want)
+			}
+			if !slices.EqualFunc(doc.parts, test.parts, slices.Equal) {
+				t.Errorf("text = %q, want %q", doc.parts, test.parts)
+			}
+		})
+	}
+}
+
+func TestParseConflictMarkersErrors(t *testing.T) {
+	for _, content := range []string{
+		"<<<<<<< HEAD\nM\n=======\nO\n",
+		"<<<<<<< HEAD\n<<<<<<< HEAD\n=======\n>>>>>>> topic\n",
+	} {
+		if _, err := parseConflictMarkers(content); err == nil {
+			t.Errorf("parseConflictMarkers(%q) succeeded, want an error", content)
+		}
+	}
+}
+
+func TestResolveKeepsLineEndings(t *testing.T) {
+	tests := []struct {
+		name, content, resolution, want string
+	}{
+		{"LF", "a\n<<<<<<< HEAD\nM\n=======\nO\n>>>>>>> topic\nb\n", "M\nO\n", "a\nM\nO\nb\n"},
+		{"CRLF", "a\r\n<<<<<<< HEAD\r\nM\r\n=======\r\nO\r\n>>>>>>> topic\r\nb\r\n", "M\nO\n", "a\r\nM\r\nO\r\nb\r\n"},
+		{"no trailing newline", "<<<<<<< HEAD\nM\n=======\nO\n>>>>>>> topic", "O", "O"},
+		{"dropped block", "a\n<<<<<<< HEAD\nM\n=======\nO\n>>>>>>> topic\nb\n", "", "a\nb\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := parseConflictMarkers(test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Resolve([]string{test.resolution}); got != test.want {
+				t.Errorf("Resolve() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFillBase(t *testing.T) {
+	merged, err := parseConflictMarkers("<<<<<<< ours\nM1\n||||||| merge base\nB1\n=======\nO1\n>>>>>>> theirs\nx\n" +
+		"<<<<<<< ours\nM2\n||||||| merge base\nB2\n=======\nO2\n>>>>>>> theirs\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second block was edited after the merge, so its base can't be trusted
+	doc, err := parseConflictMarkers("<<<<<<< HEAD\nM1\n=======\nO1\n>>>>>>> topic\nx\n" +
+		"<<<<<<< HEAD\nM2 edited\n=======\nO2\n>>>>>>> topic\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.fillBase(merged)
+	if first := doc.Blocks[0]; !first.HasBase || first.BaseLabel != "merge base" || !slices.Equal(first.Base, []string{"B1"}) {
+		t.Errorf("first block = %+v, want base B1", first)
+	}
+	if second := doc.Blocks[1]; second.HasBase {
+		t.Errorf("edited block got base %q", second.Base)
+	}
+}
+
+func equalBlocks(a, b ConflictBlock) bool {
+	return a.OursLabel == b.OursLabel && a.BaseLabel == b.BaseLabel && a.TheirsLabel == b.TheirsLabel &&
+		a.HasBase == b.HasBase && slices.Equal(a.Ours, b.Ours) && slices.Equal(a.Base, b.Base) && slices.Equal(a.Theirs, b.Theirs)
+}
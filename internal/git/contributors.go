@@ -0,0 +1,69 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Contributor is an author of the current branch, as git shortlog counts
+// them after .mailmap
+type Contributor struct {
+	Identity
+	Commits    int
+	LastActive time.Time
+}
+
+// GetContributors lists the authors of HEAD, most commits first, with the
+// date of each one's latest commit
+func GetContributors(repoPath string) ([]Contributor, error) {
+	output, err := Execute(repoPath, "shortlog", "-sne", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var contributors []Contributor
+	index := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		count, author, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		id, err := ParseIdentity(author)
+		if err != nil {
+			continue
+		}
+		commits, _ := strconv.Atoi(count)
+		index[id.String()] = len(contributors)
+		contributors = append(contributors, Contributor{Identity: id, Commits: commits})
+	}
+
+	// Newest first, so the first commit seen of each author is their latest
+	output, err = Execute(repoPath, "log", "--use-mailmap", "-z", "--format=%aN <%aE>%x1f%at", "HEAD")
+	if err != nil {
+		return contributors, nil
+	}
+	for _, record := range splitNul(output) {
+		author, stamp, _ := strings.Cut(strings.TrimPrefix(record, "\n"), fieldSep)
+		i, ok := index[author]
+		if !ok || !contributors[i].LastActive.IsZero() {
+			continue
+		}
+		if seconds, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+			contributors[i].LastActive = time.Unix(seconds, 0)
+		}
+	}
+	return contributors, nil
+}
+
+// GetAuthorLog returns the last count commits of ref ("" for HEAD) by the
+// author with email
+func GetAuthorLog(repoPath, ref, email string, count int) []Commit {
+	args := []string{"log", fmt.Sprintf("-%d", count), "--use-mailmap", "--author=<" + regexp.QuoteMeta(email) + ">"}
+	if ref != "" {
+		args = append(args, ref)
+	}
+	commits, _ := logCommits(repoPath, args...)
+	return commits
+}
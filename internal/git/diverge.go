@@ -0,0 +1,153 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Divergence describes a branch that is both ahead of and behind its
+// upstream, so neither a plain push nor a fast-forward pull works
+type Divergence struct {
+	Branch    string
+	Upstream  string   // remote-tracking ref, e.g. origin/main
+	RemoteAt  string   // full hash of the upstream when compared
+	MergeBase string   // short hash of the last shared commit
+	Local     []Commit // only on the branch, newest first
+	Remote    []Commit // only on the upstream, newest first
+}
+
+// Diverged reports whether both sides have commits the other lacks
+func (d Divergence) Diverged() bool {
+	return len(d.Local) > 0 && len(d.Remote) > 0
+}
+
+// GetUpstream returns the remote-tracking ref the current branch pushes to
+// and pulls from, e.g. origin/main
+func GetUpstream(repoPath string) (string, error) {
+	output, err := Execute(repoPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return "", fmt.Errorf("branch %s has no upstream", GetBranchName(repoPath))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// branchUpstream returns the remote a local branch tracks and the branch
+// there, e.g. "origin" and "refs/heads/main"
+func branchUpstream(repoPath, branch string) (remote, ref string, err error) {
+	output, err := Execute(repoPath, "for-each-ref", "--format=%(upstream:remotename)%1f%(upstream:remoteref)", "refs/heads/"+branch)
+	if err != nil {
+		return "", "", err
+	}
+	remote, ref, _ = strings.Cut(strings.TrimSpace(string(output)), fieldSep)
+	if remote == "" || ref == "" {
+		return "", "", fmt.Errorf("branch %s has no upstream", branch)
+	}
+	return remote, ref, nil
+}
+
+// FastForwardBranch fetches a local branch that is not checked out up to
+// its upstream, and returns how many commits it moved. Anything but a fast
+// forward is refused.
+func FastForwardBranch(repoPath, branch string) (int, error) {
+	remote, ref, err := branchUpstream(repoPath, branch)
+	if err != nil {
+		return 0, err
+	}
+	before, err := Execute(repoPath, "rev-parse", "refs/heads/"+branch)
+	if err != nil {
+		return 0, err
+	}
+	if output, err := Execute(repoPath, "fetch", remote, ref+":refs/heads/"+branch); err != nil {
+		if strings.Contains(string(output), "non-fast-forward") {
+			return 0, fmt.Errorf("%s has commits its upstream doesn't - check it out to merge or rebase", branch)
+		}
+		return 0, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	output, err := Execute(repoPath, "rev-list", "--count", strings.TrimSpace(string(before))+"..refs/heads/"+branch)
+	if err != nil {
+		return 0, nil
+	}
+	moved, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return moved, nil
+}
+
+// GetDivergence compares the current branch with its upstream as of the
+// last fetch
+func GetDivergence(repoPath string) (Divergence, error) {
+	div := Divergence{Branch: GetBranchName(repoPath)}
+
+	upstream, err := GetUpstream(repoPath)
+	if err != nil {
+		return div, err
+	}
+	div.Upstream = upstream
+
+	if output, err := Execute(repoPath, "rev-parse", "@{u}"); err == nil {
+		div.RemoteAt = strings.TrimSpace(string(output))
+	}
+	if output, err := Execute(repoPath, "merge-base", "HEAD", "@{u}"); err == nil {
+		div.MergeBase = shortHash(strings.TrimSpace(string(output)))
+	}
+	div.Local = commitRange(repoPath, "@{u}..HEAD")
+	div.Remote = commitRange(repoPath, "HEAD..@{u}")
+	return div, nil
+}
+
+// RebaseOntoUpstream replays the local commits on top of the upstream. On
+// conflicts the rebase is left in progress for the conflicts view.
+func RebaseOntoUpstream(repoPath string) error {
+	output, err := Execute(repoPath, "-c", "core.editor=true", "rebase", "@{u}")
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MergeUpstream merges the upstream into the current branch. On conflicts
+// the merge is left in progress for the conflicts view.
+func MergeUpstream(repoPath string) error {
+	output, err := Execute(repoPath, "merge", "--no-edit", "@{u}")
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ForcePushWithLease overwrites the upstream branch with the local one,
+// but only if the remote still points at expected (the commit the user was
+// shown), so commits pushed since then are never lost
+func ForcePushWithLease(repoPath, expected string) error {
+	branch := GetBranchName(repoPath)
+	remote, err := Execute(repoPath, "config", "--get", "branch."+branch+".remote")
+	if err != nil {
+		return fmt.Errorf("branch %s has no upstream remote", branch)
+	}
+	merge, err := Execute(repoPath, "config", "--get", "branch."+branch+".merge")
+	if err != nil {
+		return fmt.Errorf("branch %s has no upstream branch", branch)
+	}
+	remoteRef := strings.TrimSpace(string(merge))
+
+	output, err := Execute(repoPath, "push",
+		"--force-with-lease="+remoteRef+":"+expected,
+		strings.TrimSpace(string(remote)), "HEAD:"+remoteRef)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// IsPushRejected reports whether push output says the remote has commits
+// the local branch lacks
+func IsPushRejected(output string) bool {
+	return strings.Contains(output, "[rejected]") &&
+		(strings.Contains(output, "non-fast-forward") || strings.Contains(output, "fetch first"))
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
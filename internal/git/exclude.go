@@ -0,0 +1,78 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ExcludeFile returns the path of .git/info/exclude, the repository's
+// ignore file that is never committed
+func ExcludeFile(repoPath string) string {
+	return gitPath(repoPath, "info/exclude")
+}
+
+// GetExcludeLines reads info/exclude line by line, comments included. A
+// missing file reads as empty.
+func GetExcludeLines(repoPath string) ([]string, error) {
+	data, err := os.ReadFile(ExcludeFile(repoPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimRight(string(data), "\n")
+	if content == "" {
+		return nil, nil
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// WriteExcludeLines replaces info/exclude with lines
+func WriteExcludeLines(repoPath string, lines []string) error {
+	if err := writable(); err != nil {
+		return err
+	}
+	path := ExcludeFile(repoPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// AddExclude appends pattern to info/exclude unless it is already there
+func AddExclude(repoPath, pattern string) error {
+	lines, err := GetExcludeLines(repoPath)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(lines, pattern) {
+		return nil
+	}
+	return WriteExcludeLines(repoPath, append(lines, pattern))
+}
+
+// ExcludePattern turns a path from git status into a pattern matching only
+// that path: anchored to the repository root, with wildcards escaped
+func ExcludePattern(file string) string {
+	var pattern strings.Builder
+	pattern.WriteString("/")
+	for _, r := range file {
+		if strings.ContainsRune(`*?[\`, r) {
+			pattern.WriteRune('\\')
+		}
+		pattern.WriteRune(r)
+	}
+	result := pattern.String()
+	if strings.HasSuffix(result, " ") {
+		// Trailing spaces are dropped unless escaped
+		result = strings.TrimSuffix(result, " ") + `\ `
+	}
+	return result
+}
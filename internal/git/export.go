@@ -0,0 +1,73 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitRecord is a commit with what an export needs beyond Commit: the
+// full hash, the author's email, an absolute date, the body and line counts
+type CommitRecord struct {
+	Hash       string
+	Author     string
+	Email      string
+	Date       time.Time
+	Subject    string
+	Body       string
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// recordSep starts each commit in GetCommitRecords output, ahead of its
+// numstat lines
+const recordSep = "\x1e"
+
+// GetCommitRecords reads the commits named by hashes, in the order given.
+// Merge commits count no files, as in git log.
+func GetCommitRecords(repoPath string, hashes []string) ([]CommitRecord, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	args := append([]string{"log", "--no-walk=unsorted", "--numstat",
+		"--format=" + recordSep + "%H%x1f%an%x1f%ae%x1f%aI%x1f%s%x1f%b%x1f"}, hashes...)
+	output, err := Execute(repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitRecords(string(output)), nil
+}
+
+func parseCommitRecords(output string) []CommitRecord {
+	var records []CommitRecord
+	for _, chunk := range strings.Split(output, recordSep) {
+		parts := strings.SplitN(chunk, fieldSep, 7)
+		if len(parts) < 7 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, parts[3])
+		record := CommitRecord{
+			Hash:    parts[0],
+			Author:  parts[1],
+			Email:   parts[2],
+			Date:    date,
+			Subject: parts[4],
+			Body:    strings.TrimSpace(parts[5]),
+		}
+		// "added\tdeleted\tpath" per file; binary files count "-"
+		for _, line := range strings.Split(parts[6], "\n") {
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) < 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(fields[0])
+			deleted, _ := strconv.Atoi(fields[1])
+			record.Files++
+			record.Insertions += added
+			record.Deletions += deleted
+		}
+		records = append(records, record)
+	}
+	return records
+}
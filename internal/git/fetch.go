@@ -0,0 +1,163 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FetchResult summarises what fetching one remote changed
+type FetchResult struct {
+	Remote  string
+	New     int    // remote branches seen for the first time
+	Updated int    // remote branches that moved
+	Pruned  int    // remote branches deleted upstream and removed locally
+	Err     string // first error line when the remote could not be fetched
+}
+
+// Changed reports whether the fetch moved any remote-tracking ref
+func (r FetchResult) Changed() bool {
+	return r.New+r.Updated+r.Pruned > 0
+}
+
+// FetchAll fetches every remote with --prune and reports per remote what
+// changed, by comparing the remote-tracking refs before and after. A remote
+// that fails does not stop the others.
+func FetchAll(repoPath string) ([]FetchResult, error) {
+	remotes := GetRemotes(repoPath)
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("no remotes configured")
+	}
+
+	before := remoteRefs(repoPath)
+	output, _ := Execute(repoPath, "fetch", "--all", "--prune")
+	after := remoteRefs(repoPath)
+	errs := fetchErrors(string(output), remotes)
+
+	results := make([]FetchResult, 0, len(remotes))
+	for _, remote := range remotes {
+		result := FetchResult{Remote: remote, Err: errs[remote]}
+		prefix := remote + "/"
+		for ref, hash := range after {
+			if !strings.HasPrefix(ref, prefix) {
+				continue
+			}
+			if old, ok := before[ref]; !ok {
+				result.New++
+			} else if old != hash {
+				result.Updated++
+			}
+		}
+		for ref := range before {
+			if _, ok := after[ref]; !ok && strings.HasPrefix(ref, prefix) {
+				result.Pruned++
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetRemotes lists the configured remote names
+func GetRemotes(repoPath string) []string {
+	output, err := Execute(repoPath, "remote")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
+
+// GetRemoteURLs lists the URLs of all remotes
+func GetRemoteURLs(repoPath string) []string {
+	output, err := command(repoPath, "config", "--get-regexp", `^remote\..*\.url$`).Output()
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if _, url, ok := strings.Cut(line, " "); ok {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// CheckoutPullRequest fetches pull request number from the upstream remote
+// (origin when there is none) and checks it out as pr/<number>, fast-
+// forwarding that branch if it already exists. GitLab remotes use their
+// merge-requests refs.
+func CheckoutPullRequest(repoPath string, number int) (string, error) {
+	remote := "origin"
+	if slices.Contains(GetRemotes(repoPath), "upstream") {
+		remote = "upstream"
+	}
+	url, _ := command(repoPath, "remote", "get-url", remote).Output()
+	ref := fmt.Sprintf("pull/%d/head", number)
+	if strings.Contains(string(url), "gitlab") {
+		ref = fmt.Sprintf("merge-requests/%d/head", number)
+	}
+	branch := fmt.Sprintf("pr/%d", number)
+
+	if output, err := Execute(repoPath, "fetch", remote, ref); err != nil {
+		return branch, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+
+	steps := [][]string{{"checkout", "-b", branch, "FETCH_HEAD"}}
+	if refExists(repoPath, "refs/heads/"+branch) {
+		steps = [][]string{{"checkout", branch}, {"merge", "--ff-only", "FETCH_HEAD"}}
+	}
+	for _, args := range steps {
+		if output, err := Execute(repoPath, args...); err != nil {
+			return branch, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		}
+	}
+	return branch, nil
+}
+
+// remoteRefs maps remote-tracking refs (origin/main) to their hashes
+func remoteRefs(repoPath string) map[string]string {
+	refs := make(map[string]string)
+	output, err := Execute(repoPath, "for-each-ref", "--format=%(objectname) %(refname:short)", "refs/remotes")
+	if err != nil {
+		return refs
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		hash, ref, ok := strings.Cut(line, " ")
+		if ok && !strings.HasSuffix(ref, "/HEAD") {
+			refs[ref] = hash
+		}
+	}
+	return refs
+}
+
+// fetchErrors picks the first fatal/error line printed under each
+// "Fetching <remote>" heading of fetch --all. With a single remote git may
+// print no heading, so errors go to that remote.
+func fetchErrors(output string, remotes []string) map[string]string {
+	errs := make(map[string]string)
+	remote := ""
+	if len(remotes) == 1 {
+		remote = remotes[0]
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(line, "Fetching "); ok {
+			remote = strings.TrimSpace(name)
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "error: could not fetch "); ok {
+			name = strings.TrimSpace(name)
+			if errs[name] == "" {
+				errs[name] = "could not fetch"
+			}
+			continue
+		}
+		msg, ok := strings.CutPrefix(line, "fatal: ")
+		if !ok {
+			msg, ok = strings.CutPrefix(line, "error: ")
+		}
+		if ok && remote != "" && errs[remote] == "" {
+			errs[remote] = strings.TrimSpace(msg)
+		}
+	}
+	return errs
+}
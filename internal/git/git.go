@@ -1,23 +1,34 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
+
+	"github.com/LFroesch/gitty/internal/logger"
 )
 
 // Types
 
 type Change struct {
-	File   string
-	Status string
-	Type   string
-	Scope  string
+	File     string
+	OrigPath string // source of a staged rename or copy
+	Status   string
+	Type     string
+	Scope    string
+	// Octal git modes in HEAD, the index and the worktree ("100644",
+	// "100755", "120000" for symlinks, "000000" when absent); empty for
+	// untracked and conflicted files
+	HeadMode, IndexMode, WorktreeMode string
 }
 
 type Status struct {
@@ -25,8 +36,14 @@ type Status struct {
 	Clean         bool
 	StagedFiles   int
 	UnstagedFiles int
+	Conflicts     int // unmerged paths
 	Ahead         int
 	Behind        int
+	Upstream      string // remote-tracking branch; "" when there is none
+	UpstreamGone  bool   // the upstream is configured but deleted on the remote
+	Stashes       int
+	Operation     string    // see GetOperationInProgress
+	LastFetch     time.Time // zero if the repo was never fetched
 }
 
 type Branch struct {
@@ -34,15 +51,32 @@ type Branch struct {
 	IsCurrent bool
 	IsRemote  bool
 	Upstream  string
+	Gone      bool // Upstream was deleted on the remote
 	Ahead     int
 	Behind    int
+
+	// Ahead/behind relative to the repository's default branch, when
+	// BaseCounted
+	BaseAhead   int
+	BaseBehind  int
+	BaseCounted bool
 }
 
 type Commit struct {
-	Hash    string
-	Message string
-	Author  string
-	Date    string
+	Hash       string
+	Message    string
+	Author     string
+	Date       string // relative author date, e.g. "2 days ago"
+	CommitDate string // relative committer date
+
+	// When the commit was written and when it was last committed; they
+	// differ once it is amended, rebased or cherry-picked
+	Authored  time.Time
+	Committed time.Time
+
+	// Branches and tags pointing at the commit, from its decorations
+	Branches []string
+	Tags     []string
 }
 
 type ConflictFile struct {
@@ -55,7 +89,15 @@ type BranchComparison struct {
 	TargetBranch   string
 	AheadCommits   []Commit
 	BehindCommits  []Commit
-	DifferingFiles []string
+	DifferingFiles []DiffFile
+}
+
+// DiffFile is a file in a name-status diff with rename/copy detection
+type DiffFile struct {
+	Status     string // A, M, D, R (renamed) or C (copied)
+	Path       string
+	OldPath    string // source path for renames and copies
+	Similarity int    // percentage for renames and copies
 }
 
 type RebaseCommit struct {
@@ -81,123 +123,250 @@ type Tag struct {
 
 // Command execution
 
-func Execute(repoPath string, args ...string) ([]byte, error) {
-	maxRetries := 3
-	retryDelay := 100 * time.Millisecond
+// How git is run, set once from the user's config by Configure
+var (
+	gitBinary = "git"
+	gitEnv    []string
+	repoEnvs  map[string][]string
+)
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		lockFile := filepath.Join(repoPath, ".git", "index.lock")
-		if _, err := os.Stat(lockFile); err == nil {
-			time.Sleep(retryDelay)
-			continue
-		}
+// Configure sets the git executable and the extra environment passed to
+// every git command. repoEnv adds variables for repositories at or under
+// each (absolute) path; deeper paths win.
+func Configure(binary string, env map[string]string, repoEnv map[string]map[string]string) {
+	gitBinary = "git"
+	if binary != "" {
+		gitBinary = binary
+	}
+	gitEnv = envList(env)
+	repoEnvs = make(map[string][]string, len(repoEnv))
+	for path, vars := range repoEnv {
+		repoEnvs[filepath.Clean(path)] = envList(vars)
+	}
+}
 
-		cmd := exec.Command("git", args...)
-		cmd.Dir = repoPath
-		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pgid: 0}
+// CheckBinary reports whether the configured git executable can be found
+func CheckBinary() error {
+	if _, err := exec.LookPath(gitBinary); err != nil {
+		return fmt.Errorf("git executable %q not found: %w", gitBinary, err)
+	}
+	return nil
+}
 
-		output, err := cmd.CombinedOutput()
+// Environ returns the environment git commands in repoPath run with: the
+// process environment plus the configured global and per-repo variables
+func Environ(repoPath string) []string {
+	env := append(os.Environ(), gitEnv...)
 
-		if err != nil && strings.Contains(string(output), "index.lock") {
-			time.Sleep(retryDelay)
-			retryDelay *= 2
-			continue
+	var matches []string
+	for path := range repoEnvs {
+		if repoPath == path || strings.HasPrefix(repoPath, path+string(filepath.Separator)) {
+			matches = append(matches, path)
 		}
+	}
+	// Later entries override earlier ones, so apply the deepest path last
+	sort.Slice(matches, func(i, j int) bool { return len(matches[i]) < len(matches[j]) })
+	for _, path := range matches {
+		env = append(env, repoEnvs[path]...)
+	}
+	return env
+}
 
-		return output, err
+func envList(vars map[string]string) []string {
+	list := make([]string, 0, len(vars))
+	for key, value := range vars {
+		list = append(list, key+"="+value)
 	}
+	sort.Strings(list)
+	return list
+}
 
-	return nil, fmt.Errorf("git command failed after %d retries: index.lock conflict", maxRetries)
+// command builds a git invocation in dir with the configured binary and
+// environment
+func command(dir string, args ...string) *exec.Cmd {
+	// Paths come out as UTF-8 rather than octal escapes; the UI makes any
+	// other bytes printable
+	cmd := exec.Command(gitBinary, append([]string{"-c", "core.quotepath=false"}, args...)...)
+	cmd.Dir = dir
+	// Reads must not take index.lock behind a concurrent writer's back
+	cmd.Env = append(Environ(dir), "GIT_OPTIONAL_LOCKS=0")
+	// In read-only mode writes fail when run, wherever they come from
+	cmd.Err = checkWritable(args)
+	logger.Debug("git", "dir", dir, "args", args)
+	return cmd
+}
+
+// Execute runs git in repoPath and returns its combined output. Commands that
+// write the index wait for earlier writers (see lockIndex).
+func Execute(repoPath string, args ...string) ([]byte, error) {
+	return execute(repoPath, indexWriters[subcommand(args)], args)
+}
+
+// execute runs args as Execute does, holding the index lock when
+// writesIndex
+func execute(repoPath string, writesIndex bool, args []string) ([]byte, error) {
+	if err := checkWritable(args); err != nil {
+		return []byte(err.Error()), err
+	}
+	unlock, err := lockIndexIf(repoPath, writesIndex)
+	if err != nil {
+		// Callers often report the output rather than the error
+		return []byte(err.Error()), err
+	}
+	defer unlock()
+
+	cmd := command(repoPath, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pgid: 0}
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	logger.Debug("git done", "args", args, "took", time.Since(start), "err", err)
+	return output, err
 }
 
 func IsRepo(dir string) bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Dir = dir
+	cmd := command(dir, "rev-parse", "--git-dir")
 	return cmd.Run() == nil
 }
 
-// Status functions
+// GetTopLevel returns the root of the working tree containing dir
+func GetTopLevel(dir string) (string, error) {
+	output, err := command(dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-func GetBranchName(repoPath string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err == nil {
-		return strings.TrimSpace(string(output))
+// gitDirs caches GetGitDir, which Execute consults before every index write
+var gitDirs sync.Map
+
+// GetGitDir returns the absolute path of the repository's git directory.
+// For a linked worktree that is its private directory under
+// .git/worktrees; for a bare repository it is the repository itself.
+func GetGitDir(repoPath string) string {
+	if dir, ok := gitDirs.Load(repoPath); ok {
+		return dir.(string)
 	}
-	return "unknown"
+	output, err := command(repoPath, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return filepath.Join(repoPath, ".git")
+	}
+	dir := strings.TrimSpace(string(output))
+	gitDirs.Store(repoPath, dir)
+	return dir
 }
 
-func GetAheadBehindCount(repoPath string) (ahead, behind int) {
-	// Use git status -sb which reliably shows ahead/behind even without explicit upstream
-	cmd := exec.Command("git", "status", "-sb")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+// GetCommonDir returns the git directory shared by all worktrees, where
+// branches, tags and hooks live. It equals GetGitDir outside worktrees.
+func GetCommonDir(repoPath string) string {
+	output, err := command(repoPath, "rev-parse", "--git-common-dir").Output()
 	if err != nil {
-		return 0, 0
+		return GetGitDir(repoPath)
 	}
+	return absFrom(repoPath, strings.TrimSpace(string(output)))
+}
 
-	// Parse first line: ## branch...origin/branch [ahead N, behind M]
-	firstLine := strings.Split(string(output), "\n")[0]
+// IsBareRepo reports whether repoPath is a repository without a working tree
+func IsBareRepo(repoPath string) bool {
+	output, err := command(repoPath, "rev-parse", "--is-bare-repository").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
 
-	// Look for [ahead N] or [behind N] or [ahead N, behind M]
-	if idx := strings.Index(firstLine, "["); idx != -1 {
-		if endIdx := strings.Index(firstLine[idx:], "]"); endIdx != -1 {
-			trackInfo := firstLine[idx+1 : idx+endIdx]
-			// Parse "ahead N" and/or "behind M"
-			if strings.Contains(trackInfo, "ahead") {
-				fmt.Sscanf(trackInfo, "ahead %d", &ahead)
-			}
-			if strings.Contains(trackInfo, "behind") {
-				if strings.Contains(trackInfo, "ahead") {
-					// Format: "ahead N, behind M"
-					parts := strings.Split(trackInfo, ", ")
-					for _, part := range parts {
-						if strings.HasPrefix(part, "behind") {
-							fmt.Sscanf(part, "behind %d", &behind)
-						}
-					}
-				} else {
-					fmt.Sscanf(trackInfo, "behind %d", &behind)
-				}
-			}
+// IsLinkedWorktree reports whether repoPath is a worktree added with
+// `git worktree add` rather than the main checkout
+func IsLinkedWorktree(repoPath string) bool {
+	return GetGitDir(repoPath) != GetCommonDir(repoPath)
+}
+
+// gitPath resolves a path inside the git directory the way git does,
+// honouring worktrees and settings such as core.hooksPath
+func gitPath(repoPath, name string) string {
+	output, err := command(repoPath, "rev-parse", "--git-path", name).Output()
+	if err != nil {
+		return filepath.Join(GetGitDir(repoPath), name)
+	}
+	return absFrom(repoPath, strings.TrimSpace(string(output)))
+}
+
+// absFrom makes a path printed by rev-parse absolute; relative paths are
+// relative to the directory the command ran in
+func absFrom(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// StateStamp fingerprints HEAD, the index and the current branch ref by
+// size and mtime. It only stats files, so it is cheap enough to poll; a
+// changed stamp means status needs re-running. HEAD and the index live in
+// gitDir, refs in commonDir; the two differ for linked worktrees.
+func StateStamp(gitDir, commonDir string) string {
+	files := []string{
+		filepath.Join(gitDir, "HEAD"),
+		filepath.Join(gitDir, "index"),
+		filepath.Join(gitDir, "MERGE_HEAD"),
+		filepath.Join(gitDir, "FETCH_HEAD"),
+		filepath.Join(commonDir, "FETCH_HEAD"),
+		filepath.Join(commonDir, "packed-refs"),
+	}
+	if head, err := os.ReadFile(filepath.Join(gitDir, "HEAD")); err == nil {
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: "); ok {
+			files = append(files, filepath.Join(commonDir, ref))
+		}
+	}
+
+	var stamp strings.Builder
+	for _, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&stamp, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
 		}
 	}
+	return stamp.String()
+}
 
-	return ahead, behind
+// Status functions
+
+func GetBranchName(repoPath string) string {
+	cmd := command(repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+	output, err := cmd.Output()
+	if err == nil {
+		return strings.TrimSpace(string(output))
+	}
+	return "unknown"
 }
 
 func GetStatus(repoPath string) Status {
 	status := Status{Branch: GetBranchName(repoPath)}
-	status.Ahead, status.Behind = GetAheadBehindCount(repoPath)
+	status.Stashes = GetStashCount(repoPath)
+	status.Operation = GetOperationInProgress(repoPath)
+	if info, err := os.Stat(gitPath(repoPath, "FETCH_HEAD")); err == nil {
+		status.LastFetch = info.ModTime()
+	}
 
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "status", "--porcelain=v2", "--branch", "-z")
 	output, err := cmd.Output()
 	if err != nil {
 		return status
 	}
 
-	statusText := strings.TrimSpace(string(output))
-	status.Clean = statusText == ""
+	// Ahead/behind only mean something against an existing upstream
+	tracking := parsePromptStatus(output)
+	status.Ahead, status.Behind = tracking.Ahead, tracking.Behind
+	status.Upstream, status.UpstreamGone = tracking.Upstream, tracking.UpstreamGone
+	status.Conflicts = tracking.Conflicts
 
-	if !status.Clean {
-		lines := strings.Split(statusText, "\n")
-		for _, line := range lines {
-			if strings.TrimSpace(line) == "" {
-				continue
-			}
-			if len(line) >= 2 {
-				stagedStatus := line[0]
-				unstagedStatus := line[1]
-
-				if stagedStatus != ' ' && stagedStatus != '?' {
-					status.StagedFiles++
-				}
-				if unstagedStatus != ' ' {
-					status.UnstagedFiles++
-				}
-			}
+	changes := parseStatusV2(output)
+	status.Clean = len(changes) == 0
+	for _, change := range changes {
+		stagedStatus := change.Status[0]
+		unstagedStatus := change.Status[1]
+
+		if stagedStatus != ' ' && stagedStatus != '?' {
+			status.StagedFiles++
+		}
+		if unstagedStatus != ' ' {
+			status.UnstagedFiles++
 		}
 	}
 
@@ -205,41 +374,29 @@ func GetStatus(repoPath string) Status {
 }
 
 func GetChanges(repoPath string) []Change {
-	var changes []Change
-
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "status", "--porcelain=v2", "-z")
 	output, err := cmd.Output()
 	if err != nil {
-		return changes
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" || len(line) < 3 {
-			continue
-		}
-
-		status := line[:2]
-		file := strings.TrimSpace(line[3:])
-
-		changes = append(changes, Change{
-			File:   file,
-			Status: status,
-		})
+		return nil
 	}
-
-	return changes
+	return parseStatusV2(output)
 }
 
 // Branch functions
 
 func GetBranches(repoPath string) []Branch {
+	cmd := command(repoPath, "for-each-ref", branchFormat, "refs/heads")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parseBranches(output)
+}
+
+func GetRemoteBranches(repoPath string) []Branch {
 	var branches []Branch
 
-	// Local branches
-	cmd := exec.Command("git", "branch", "-vv")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "branch", "-r")
 	output, err := cmd.Output()
 	if err != nil {
 		return branches
@@ -247,79 +404,119 @@ func GetBranches(repoPath string) []Branch {
 
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Contains(line, "->") {
 			continue
 		}
 
-		branch := Branch{}
-		branch.IsCurrent = strings.HasPrefix(line, "*")
+		branches = append(branches, Branch{
+			Name:     line,
+			IsRemote: true,
+		})
+	}
 
-		line = strings.TrimPrefix(line, "* ")
-		line = strings.TrimPrefix(line, "  ")
+	return branches
+}
 
-		parts := strings.Fields(line)
-		if len(parts) >= 1 {
-			branch.Name = parts[0]
+// GetDefaultBranch returns the repository's default branch as a ref usable in
+// comparisons. It prefers origin/HEAD, then init.defaultBranch, then main/master.
+// The local branch is returned when it exists, otherwise the remote-tracking one.
+func GetDefaultBranch(repoPath string) string {
+	var candidates []string
+
+	cmd := command(repoPath, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if output, err := cmd.Output(); err == nil {
+		name := strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+		if name != "" {
+			candidates = append(candidates, name)
 		}
+	}
 
-		// Parse upstream tracking info [origin/main: ahead 1, behind 2]
-		if idx := strings.Index(line, "["); idx != -1 {
-			if endIdx := strings.Index(line[idx:], "]"); endIdx != -1 {
-				trackingInfo := line[idx+1 : idx+endIdx]
-				if colonIdx := strings.Index(trackingInfo, ":"); colonIdx != -1 {
-					branch.Upstream = strings.TrimSpace(trackingInfo[:colonIdx])
-					status := trackingInfo[colonIdx+1:]
-					if strings.Contains(status, "ahead") {
-						fmt.Sscanf(status, " ahead %d", &branch.Ahead)
-					}
-					if strings.Contains(status, "behind") {
-						if strings.Contains(status, "ahead") {
-							fmt.Sscanf(status, " ahead %d, behind %d", &branch.Ahead, &branch.Behind)
-						} else {
-							fmt.Sscanf(status, " behind %d", &branch.Behind)
-						}
-					}
-				} else {
-					branch.Upstream = strings.TrimSpace(trackingInfo)
-				}
-			}
+	cmd = command(repoPath, "config", "--get", "init.defaultBranch")
+	if output, err := cmd.Output(); err == nil {
+		if name := strings.TrimSpace(string(output)); name != "" {
+			candidates = append(candidates, name)
 		}
-
-		branches = append(branches, branch)
 	}
 
-	return branches
+	candidates = append(candidates, "main", "master")
+
+	for _, name := range candidates {
+		if refExists(repoPath, "refs/heads/"+name) {
+			return name
+		}
+		if refExists(repoPath, "refs/remotes/origin/"+name) {
+			return "origin/" + name
+		}
+	}
+	return ""
 }
 
-func GetRemoteBranches(repoPath string) []Branch {
-	var branches []Branch
+func refExists(repoPath, ref string) bool {
+	cmd := command(repoPath, "rev-parse", "--verify", "--quiet", ref)
+	return cmd.Run() == nil
+}
 
-	cmd := exec.Command("git", "branch", "-r")
-	cmd.Dir = repoPath
+// GetAheadBehind counts commits on ref that are not on base (ahead) and
+// commits on base that are not on ref (behind)
+func GetAheadBehind(repoPath, base, ref string) (ahead, behind int, ok bool) {
+	cmd := command(repoPath, "rev-list", "--left-right", "--count", base+"..."+ref)
 	output, err := cmd.Output()
 	if err != nil {
-		return branches
+		return 0, 0, false
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.Contains(line, "->") {
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	behind, _ = strconv.Atoi(fields[0])
+	ahead, _ = strconv.Atoi(fields[1])
+	return ahead, behind, true
+}
+
+// SetBaseAheadBehind fills BaseAhead/BaseBehind on every local branch
+// relative to base: in one for-each-ref on git 2.41 and later, a rev-list
+// per branch on older git. Remote-tracking branches are left uncounted, the
+// same on either.
+func SetBaseAheadBehind(repoPath, base string, branches []Branch) {
+	if base == "" {
+		return
+	}
+	counts, fast := baseCounts(repoPath, base)
+	for i := range branches {
+		if branches[i].Name == base || branches[i].IsRemote {
 			continue
 		}
-
-		branches = append(branches, Branch{
-			Name:     line,
-			IsRemote: true,
-		})
+		ahead, behind, ok := 0, 0, false
+		if fast {
+			var count [2]int
+			count, ok = counts[branches[i].Name]
+			ahead, behind = count[0], count[1]
+		} else {
+			ahead, behind, ok = GetAheadBehind(repoPath, base, branches[i].Name)
+		}
+		if ok {
+			branches[i].BaseAhead = ahead
+			branches[i].BaseBehind = behind
+			branches[i].BaseCounted = true
+		}
 	}
+}
 
-	return branches
+// baseCounts reads %(ahead-behind:base) of every local branch, by name. ok
+// is false on git older than 2.41, which doesn't know the atom.
+func baseCounts(repoPath, base string) (map[string][2]int, bool) {
+	output, err := command(repoPath, "for-each-ref",
+		"--format=%(refname:strip=2)%1f%(ahead-behind:"+base+")", "refs/heads").Output()
+	if err != nil {
+		return nil, false
+	}
+	return parseAheadBehind(output), true
 }
 
 func HasRemoteBranch(repoPath, branchName string) bool {
-	cmd := exec.Command("git", "ls-remote", "--heads", "origin", branchName)
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "ls-remote", "--heads", "origin", branchName)
 	output, err := cmd.Output()
 	return err == nil && len(strings.TrimSpace(string(output))) > 0
 }
@@ -327,65 +524,202 @@ func HasRemoteBranch(repoPath, branchName string) bool {
 // Commit functions
 
 func GetCommitLog(repoPath string, count int) []Commit {
-	var commits []Commit
+	commits, _ := logCommits(repoPath, "log", fmt.Sprintf("-%d", count))
+	return commits
+}
 
-	cmd := exec.Command("git", "log", fmt.Sprintf("-%d", count), "--pretty=format:%h|%s|%an|%ar")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+// GetBranchLog returns the last count commits of ref
+func GetBranchLog(repoPath, ref string, count int) []Commit {
+	commits, _ := logCommits(repoPath, "log", fmt.Sprintf("-%d", count), ref)
+	return commits
+}
+
+var conventionalScopeRe = regexp.MustCompile(`^[a-z]+\(([^)]+)\)!?:`)
+
+// GetRecentScopes returns the conventional commit scopes used in the last
+// count commits, most frequent first
+func GetRecentScopes(repoPath string, count int) []string {
+	output, err := Execute(repoPath, "log", fmt.Sprintf("-%d", count), "--pretty=format:%s")
 	if err != nil {
-		return commits
+		return nil
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if line == "" {
+	freq := make(map[string]int)
+	var scopes []string
+	for _, line := range strings.Split(string(output), "\n") {
+		match := conventionalScopeRe.FindStringSubmatch(line)
+		if match == nil {
 			continue
 		}
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) >= 4 {
-			commits = append(commits, Commit{
-				Hash:    parts[0],
-				Message: parts[1],
-				Author:  parts[2],
-				Date:    parts[3],
-			})
+		scope := strings.TrimSpace(match[1])
+		if freq[scope] == 0 {
+			scopes = append(scopes, scope)
 		}
+		freq[scope]++
 	}
 
-	return commits
+	sort.SliceStable(scopes, func(i, j int) bool {
+		return freq[scopes[i]] > freq[scopes[j]]
+	})
+	return scopes
 }
 
-func GetReflog(repoPath string, count int) []Commit {
-	var commits []Commit
+// CommitGroup is a set of staged paths to be committed together
+type CommitGroup struct {
+	Message string
+	Files   []string
+}
 
-	cmd := exec.Command("git", "reflog", fmt.Sprintf("-%d", count), "--pretty=format:%h|%s|%ar")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err != nil {
-		return commits
+// CommitInGroups commits each group's paths as a separate commit, using exactly
+// the content currently staged. It returns the number of commits created; on
+// failure the uncommitted groups are left staged.
+func CommitInGroups(repoPath string, groups []CommitGroup) (int, error) {
+	if !refExists(repoPath, "HEAD") {
+		return 0, fmt.Errorf("split commit needs at least one existing commit")
+	}
+	if err := refuseMidOperation(repoPath, "split commit"); err != nil {
+		return 0, err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
+	// Snapshot the index so each group can be restaged from it
+	tree, err := snapshotIndex(repoPath)
+	if err != nil {
+		return 0, err
+	}
+	restage := func(files []string) error { return restageFrom(repoPath, tree, files) }
+
+	for i, group := range groups {
+		err := restage(group.Files)
+		if err == nil {
+			var output []byte
+			if output, err = Execute(repoPath, "commit", "-m", group.Message); err != nil {
+				err = fmt.Errorf("%s", strings.TrimSpace(string(output)))
+			}
 		}
-		parts := strings.SplitN(line, "|", 3)
-		if len(parts) >= 3 {
-			commits = append(commits, Commit{
-				Hash:    parts[0],
-				Message: parts[1],
-				Date:    parts[2],
-			})
+		if err != nil {
+			var remaining []string
+			for _, g := range groups[i:] {
+				remaining = append(remaining, g.Files...)
+			}
+			restage(remaining)
+			return i, err
 		}
 	}
+	return len(groups), nil
+}
+
+// CommitStagedPaths commits only the given staged paths, leaving the rest of
+// the index staged. Unlike `git commit -- <paths>` it commits the staged
+// content rather than the working tree.
+func CommitStagedPaths(repoPath, message string, paths []string, override CommitOverride) error {
+	if !refExists(repoPath, "HEAD") {
+		return fmt.Errorf("partial commit needs at least one existing commit")
+	}
+	if err := refuseMidOperation(repoPath, "partial commit"); err != nil {
+		return err
+	}
+
+	var staged []string
+	for _, change := range GetStagedChanges(repoPath) {
+		staged = append(staged, change.File)
+	}
+	tree, err := snapshotIndex(repoPath)
+	if err != nil {
+		return err
+	}
+
+	if err := restageFrom(repoPath, tree, paths); err != nil {
+		restageFrom(repoPath, tree, staged)
+		return err
+	}
+	output, commitErr := Execute(repoPath, append([]string{"commit", "-m", message}, override.Args()...)...)
+
+	// Put back everything that was staged; committed paths now match HEAD
+	if err := restageFrom(repoPath, tree, staged); err != nil {
+		return err
+	}
+	if commitErr != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CommitEmpty records a commit that changes nothing, e.g. to trigger CI.
+// Anything staged stays staged.
+func CommitEmpty(repoPath, message string) error {
+	if output, err := Execute(repoPath, "commit", "--allow-empty", "--only", "-m", message); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetLastCommitMessage returns the full message of HEAD
+func GetLastCommitMessage(repoPath string) (string, error) {
+	output, err := Execute(repoPath, "log", "-1", "--format=%B")
+	if err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AmendMessage replaces the message of HEAD, keeping its tree; anything
+// staged stays staged rather than joining the commit
+func AmendMessage(repoPath, message string) error {
+	if output, err := Execute(repoPath, "commit", "--amend", "--only", "--allow-empty", "-m", message); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// IsHeadPushed reports whether HEAD is on any remote-tracking branch, so
+// rewriting it would need a force push
+func IsHeadPushed(repoPath string) bool {
+	output, err := Execute(repoPath, "branch", "-r", "--contains", "HEAD")
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// refuseMidOperation stops a commit that rebuilds the index while a merge,
+// rebase, cherry-pick or revert is underway: resetting the index would drop
+// its conflict stages and the other side's staged changes
+func refuseMidOperation(repoPath, what string) error {
+	if op := GetOperationInProgress(repoPath); op != "" {
+		return fmt.Errorf("%s isn't possible during a %s; commit everything staged to conclude it first", what, op)
+	}
+	return nil
+}
 
+// snapshotIndex writes the index to a tree object and returns its hash
+func snapshotIndex(repoPath string) (string, error) {
+	output, err := Execute(repoPath, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// restageFrom resets the index to HEAD, then stages files as they are in
+// tree. Paths are literal, so names with * or [ aren't taken as globs.
+func restageFrom(repoPath, tree string, files []string) error {
+	if output, err := Execute(repoPath, "reset", "-q"); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	args := append([]string{"--literal-pathspecs", "restore", "--staged", "--source=" + tree, "--"}, files...)
+	if output, err := Execute(repoPath, args...); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func GetReflog(repoPath string, count int) []Commit {
+	commits, _ := logCommits(repoPath, "reflog", fmt.Sprintf("-%d", count))
 	return commits
 }
 
 func GetCurrentCommitHash(repoPath string) string {
-	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "rev-parse", "--short", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -396,16 +730,8 @@ func GetCurrentCommitHash(repoPath string) string {
 // Staging functions
 
 func IsFileStaged(repoPath, filePath string) bool {
-	cmd := exec.Command("git", "diff", "--cached", "--name-only")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-
-	stagedFiles := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, f := range stagedFiles {
-		if strings.TrimSpace(f) == filePath {
+	for _, f := range GetStagedFiles(repoPath) {
+		if f == filePath {
 			return true
 		}
 	}
@@ -413,37 +739,84 @@ func IsFileStaged(repoPath, filePath string) bool {
 }
 
 func GetStagedFiles(repoPath string) []string {
-	cmd := exec.Command("git", "diff", "--cached", "--name-only")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "diff", "--cached", "--name-only", "-z")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
 	}
+	return splitNul(output)
+}
 
-	text := strings.TrimSpace(string(output))
-	if text == "" {
+// GetUnstagedFiles lists tracked paths whose worktree differs from the index
+func GetUnstagedFiles(repoPath string) []string {
+	output, err := Execute(repoPath, "diff", "--name-only", "-z")
+	if err != nil {
 		return nil
 	}
-	return strings.Split(text, "\n")
+	return splitNul(output)
+}
+
+// GetStagedChanges lists staged paths with their status (renames split into
+// delete + add so every path can be staged on its own)
+func GetStagedChanges(repoPath string) []Change {
+	output, err := Execute(repoPath, "diff", "--cached", "--name-status", "--no-renames", "-z")
+	if err != nil {
+		return nil
+	}
+
+	var changes []Change
+	for _, file := range parseNameStatus(output) {
+		changes = append(changes, Change{File: file.Path, Status: file.Status + " "})
+	}
+	return changes
 }
 
 func GetStagedDiff(repoPath string) string {
-	cmd := exec.Command("git", "diff", "--cached")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "diff", "--cached")
 	output, _ := cmd.Output()
 	return string(output)
 }
 
 // Diff functions
 
-func GetFileDiff(repoPath, filePath string, staged bool) string {
-	var cmd *exec.Cmd
+// DiffOptions controls how diffs are produced
+type DiffOptions struct {
+	IgnoreWhitespace bool // -w
+	IgnoreBlankLines bool // --ignore-blank-lines
+	Context          int  // lines of context; negative keeps git's default
+}
+
+// Args returns the git diff flags for the options
+func (o DiffOptions) Args() []string {
+	var args []string
+	if o.IgnoreWhitespace {
+		args = append(args, "-w")
+	}
+	if o.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+	if o.Context >= 0 {
+		args = append(args, fmt.Sprintf("-U%d", o.Context))
+	}
+	return args
+}
+
+// GetFileDiff diffs one file; origPath is the source of a staged rename,
+// which git needs alongside the new path to pair them up
+func GetFileDiff(repoPath, filePath, origPath string, staged bool, opts DiffOptions) string {
+	args := []string{"diff"}
 	if staged {
-		cmd = exec.Command("git", "diff", "--cached", filePath)
-	} else {
-		cmd = exec.Command("git", "diff", filePath)
+		args = append(args, "--cached")
+	}
+	// textconv drivers from .gitattributes turn notebooks, PDFs and the like
+	// into readable text
+	args = append(args, "-M", "-C", "--textconv")
+	args = append(args, opts.Args()...)
+	args = append(args, "--", filePath)
+	if origPath != "" {
+		args = append(args, origPath)
 	}
-	cmd.Dir = repoPath
+	cmd := command(repoPath, args...)
 	output, _ := cmd.Output()
 	return string(output)
 }
@@ -451,18 +824,12 @@ func GetFileDiff(repoPath, filePath string, staged bool) string {
 // Conflict functions
 
 func GetConflictFiles(repoPath string) []string {
-	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "diff", "--name-only", "--diff-filter=U", "-z")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
 	}
-
-	text := strings.TrimSpace(string(output))
-	if text == "" {
-		return nil
-	}
-	return strings.Split(text, "\n")
+	return splitNul(output)
 }
 
 // Comparison functions
@@ -473,82 +840,68 @@ func GetBranchComparison(repoPath, sourceBranch, targetBranch string) BranchComp
 		TargetBranch: targetBranch,
 	}
 
-	// Ahead commits
-	cmd := exec.Command("git", "log", "--pretty=format:%h|%s|%an|%ar", targetBranch+"..HEAD")
-	cmd.Dir = repoPath
+	comparison.AheadCommits, _ = logCommits(repoPath, "log", targetBranch+"..HEAD")
+	comparison.BehindCommits, _ = logCommits(repoPath, "log", "HEAD.."+targetBranch)
+
+	// Differing files
+	comparison.DifferingFiles = GetDiffFiles(repoPath, targetBranch+"...HEAD")
+
+	return comparison
+}
+
+// GetDiffFiles lists the files changed in a revision range, pairing renamed
+// and copied files with their source
+func GetDiffFiles(repoPath, revRange string) []DiffFile {
+	cmd := command(repoPath, "diff", "--name-status", "-M", "-C", "-z", revRange)
 	output, err := cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			parts := strings.SplitN(line, "|", 4)
-			if len(parts) >= 4 {
-				comparison.AheadCommits = append(comparison.AheadCommits, Commit{
-					Hash:    parts[0],
-					Message: parts[1],
-					Author:  parts[2],
-					Date:    parts[3],
-				})
-			}
-		}
+	if err != nil {
+		return nil
 	}
+	return parseNameStatus(output)
+}
 
-	// Behind commits
-	cmd = exec.Command("git", "log", "--pretty=format:%h|%s|%an|%ar", "HEAD.."+targetBranch)
-	cmd.Dir = repoPath
-	output, err = cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			parts := strings.SplitN(line, "|", 4)
-			if len(parts) >= 4 {
-				comparison.BehindCommits = append(comparison.BehindCommits, Commit{
-					Hash:    parts[0],
-					Message: parts[1],
-					Author:  parts[2],
-					Date:    parts[3],
-				})
-			}
-		}
-	}
+// DiffStat is the lines a file gained and lost in a diff
+type DiffStat struct {
+	Path    string // new path for renames and copies
+	Added   int
+	Deleted int
+	Binary  bool
+}
 
-	// Differing files
-	cmd = exec.Command("git", "diff", "--name-only", targetBranch+"...HEAD")
-	cmd.Dir = repoPath
-	output, err = cmd.Output()
-	if err == nil {
-		text := strings.TrimSpace(string(output))
-		if text != "" {
-			comparison.DifferingFiles = strings.Split(text, "\n")
-		}
+// GetDiffStat counts changed lines per file in a revision range, with the
+// same rename and copy detection as GetDiffFiles
+func GetDiffStat(repoPath, revRange string) []DiffStat {
+	cmd := command(repoPath, "diff", "--numstat", "-M", "-C", "-z", revRange)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
 	}
-
-	return comparison
+	return parseNumstat(output)
 }
 
 // Stash functions
 
+// GetStashCount counts stash entries without formatting them
+func GetStashCount(repoPath string) int {
+	output, err := command(repoPath, "rev-list", "--walk-reflogs", "--count", "refs/stash").Output()
+	if err != nil {
+		return 0
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return count
+}
+
 func GetStashList(repoPath string) []Stash {
 	var stashes []Stash
 
-	cmd := exec.Command("git", "stash", "list", "--format=%gd|%s|%ar")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "stash", "list", "-z", "--format=%gd%x1f%s%x1f%ar")
 	output, err := cmd.Output()
 	if err != nil {
 		return stashes
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for i, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "|", 3)
+	for i, record := range splitNul(output) {
+		parts := strings.SplitN(strings.TrimPrefix(record, "\n"), fieldSep, 3)
 		if len(parts) >= 3 {
 			stashes = append(stashes, Stash{
 				Index:   i,
@@ -588,20 +941,63 @@ func StashDrop(repoPath string, index int) error {
 }
 
 func StashShow(repoPath string, index int) string {
-	cmd := exec.Command("git", "stash", "show", "-p", fmt.Sprintf("stash@{%d}", index))
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "stash", "show", "-p", "--textconv", fmt.Sprintf("stash@{%d}", index))
 	output, _ := cmd.Output()
 	return string(output)
 }
 
+// GetStashFiles lists the tracked files a stash changes
+func GetStashFiles(repoPath string, index int) []DiffFile {
+	return GetDiffFiles(repoPath, fmt.Sprintf("stash@{%d}^1..stash@{%d}", index, index))
+}
+
+// StashCheckoutFiles restores paths from a stash into the working tree and
+// index, leaving the stash in place. deleted are paths the stash removed,
+// which are removed here too.
+func StashCheckoutFiles(repoPath string, index int, paths, deleted []string) error {
+	if len(paths) > 0 {
+		args := append([]string{"checkout", fmt.Sprintf("stash@{%d}", index), "--"}, paths...)
+		if output, err := Execute(repoPath, args...); err != nil {
+			return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		}
+	}
+	if len(deleted) > 0 {
+		args := append([]string{"rm", "--quiet", "--ignore-unmatch", "--"}, deleted...)
+		if output, err := Execute(repoPath, args...); err != nil {
+			return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// StashBranch creates branch at the commit a stash was made on, checks it
+// out and applies the stash there, dropping it if that succeeds
+func StashBranch(repoPath string, index int, branch string) error {
+	output, err := Execute(repoPath, "stash", "branch", branch, fmt.Sprintf("stash@{%d}", index))
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // Tag functions
 
+// GetLatestTag returns the most recent tag HEAD contains, i.e. the last
+// release the current work builds on
+func GetLatestTag(repoPath string) (string, error) {
+	output, err := command(repoPath, "describe", "--tags", "--abbrev=0", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("no tag reachable from HEAD")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func GetTags(repoPath string) []Tag {
 	var tags []Tag
 
-	// Get all tags with their details
-	cmd := exec.Command("git", "tag", "-l", "--format=%(refname:short)|%(objecttype)|%(creatordate:relative)|%(*objectname:short)%(objectname:short)")
-	cmd.Dir = repoPath
+	// Get all tags with their details; annotated tags are peeled to their commit
+	cmd := command(repoPath, "tag", "-l", "--format=%(refname:short)%1f%(objecttype)%1f%(creatordate:relative)%1f"+
+		"%(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)")
 	output, err := cmd.Output()
 	if err != nil {
 		return tags
@@ -612,7 +1008,7 @@ func GetTags(repoPath string) []Tag {
 		if line == "" {
 			continue
 		}
-		parts := strings.SplitN(line, "|", 4)
+		parts := strings.SplitN(line, fieldSep, 4)
 		if len(parts) >= 4 {
 			tag := Tag{
 				Name:        parts[0],
@@ -623,8 +1019,7 @@ func GetTags(repoPath string) []Tag {
 
 			// Get message for annotated tags
 			if tag.IsAnnotated {
-				msgCmd := exec.Command("git", "tag", "-l", "--format=%(contents:subject)", tag.Name)
-				msgCmd.Dir = repoPath
+				msgCmd := command(repoPath, "tag", "-l", "--format=%(contents:subject)", tag.Name)
 				msgOutput, _ := msgCmd.Output()
 				tag.Message = strings.TrimSpace(string(msgOutput))
 			}
@@ -652,6 +1047,84 @@ func DeleteTag(repoPath, name string) error {
 	return err
 }
 
+// PushResult is the range a push moved on the remote
+type PushResult struct {
+	Upstream string // remote-tracking ref, e.g. origin/main
+	From     string // short hash before the push; empty for a new branch
+	To       string // short hash after it
+	Commits  int    // commits in From..To
+
+	Published string // branch whose upstream the push set, see Publish
+}
+
+func (p PushResult) String() string {
+	switch {
+	case p.Published != "":
+		return fmt.Sprintf("Published %s as %s at %s; %s now tracks it", p.Published, p.Upstream, p.To, p.Published)
+	case p.From == p.To:
+		return fmt.Sprintf("%s already up to date at %s", p.Upstream, p.To)
+	case p.From == "":
+		return fmt.Sprintf("Pushed new branch %s at %s", p.Upstream, p.To)
+	}
+	noun := "commits"
+	if p.Commits == 1 {
+		noun = "commit"
+	}
+	return fmt.Sprintf("Pushed %s..%s to %s (%d %s)", p.From, p.To, p.Upstream, p.Commits, noun)
+}
+
+// Push pushes the current branch and reports the range it moved
+func Push(repoPath string) (PushResult, error) {
+	return push(repoPath, "@{u}")
+}
+
+// Publish pushes a branch that has no upstream yet to remote and makes the
+// pushed branch its upstream (push -u)
+func Publish(repoPath, remote, branch string) (PushResult, error) {
+	result, err := push(repoPath, branch+"@{u}", "-u", remote, branch)
+	if err == nil {
+		result.Published = branch
+	}
+	return result, err
+}
+
+// PushBranch pushes a local branch to its upstream without checking it out
+func PushBranch(repoPath, branch string) (PushResult, error) {
+	remote, ref, err := branchUpstream(repoPath, branch)
+	if err != nil {
+		return PushResult{}, err
+	}
+	return push(repoPath, branch+"@{u}", remote, "refs/heads/"+branch+":"+ref)
+}
+
+// push runs git push with args and reports how upstream (e.g. "@{u}") moved
+func push(repoPath, upstream string, args ...string) (PushResult, error) {
+	var result PushResult
+	upstreamHash := func() string {
+		output, err := command(repoPath, "rev-parse", "--short", upstream).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	result.From = upstreamHash()
+	if output, err := Execute(repoPath, append([]string{"push"}, args...)...); err != nil {
+		return result, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	result.To = upstreamHash()
+
+	if output, err := command(repoPath, "rev-parse", "--abbrev-ref", upstream).Output(); err == nil {
+		result.Upstream = strings.TrimSpace(string(output))
+	}
+	if result.From != "" && result.To != "" {
+		if output, err := command(repoPath, "rev-list", "--count", result.From+".."+result.To).Output(); err == nil {
+			result.Commits, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+		}
+	}
+	return result, nil
+}
+
 func PushTag(repoPath, name string) error {
 	_, err := Execute(repoPath, "push", "origin", name)
 	return err
@@ -684,6 +1157,39 @@ func RevertCommit(repoPath, commitHash string) error {
 	return err
 }
 
+// errRootRange is returned for ranges that start at the root commit, which
+// has no parent to start from
+var errRootRange = errors.New("the range can't start at the root commit")
+
+// CherryPickRange applies oldest through newest onto HEAD, oldest first.
+// On a conflict the cherry-pick stops there, to be continued or aborted.
+func CherryPickRange(repoPath, oldest, newest string) error {
+	if !refExists(repoPath, oldest+"^") {
+		return errRootRange
+	}
+	output, err := Execute(repoPath, "cherry-pick", oldest+"^.."+newest)
+	if err != nil {
+		// The first line names the conflict; the rest are hints
+		line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+		return fmt.Errorf("%s", line)
+	}
+	return nil
+}
+
+// RevertRange reverts oldest through newest with one commit each, newest
+// first so every revert applies cleanly on top of the previous one
+func RevertRange(repoPath, oldest, newest string) error {
+	if !refExists(repoPath, oldest+"^") {
+		return errRootRange
+	}
+	output, err := Execute(repoPath, "revert", "--no-edit", oldest+"^.."+newest)
+	if err != nil {
+		line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+		return fmt.Errorf("%s", line)
+	}
+	return nil
+}
+
 func RevertAbort(repoPath string) error {
 	_, err := Execute(repoPath, "revert", "--abort")
 	return err
@@ -702,7 +1208,7 @@ func CleanDryRun(repoPath string) ([]string, error) {
 	for _, line := range lines {
 		line = strings.TrimPrefix(line, "Would remove ")
 		if line != "" {
-			files = append(files, line)
+			files = append(files, UnquotePath(line))
 		}
 	}
 	return files, nil
@@ -716,14 +1222,13 @@ func CleanForce(repoPath string) error {
 // Clone and Init functions
 
 func Clone(url, targetPath string) (string, error) {
-	cmd := exec.Command("git", "clone", url, targetPath)
+	cmd := command("", "clone", url, targetPath)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
 func Init(path string) error {
-	cmd := exec.Command("git", "init")
-	cmd.Dir = path
+	cmd := command(path, "init")
 	_, err := cmd.CombinedOutput()
 	return err
 }
@@ -737,91 +1242,70 @@ type CommitDetail struct {
 	Author     string
 	Email      string
 	Date       string
-	Files      []string
+	CommitDate string // relative committer date
+	Authored   time.Time
+	Committed  time.Time
+	Files      []DiffStat
 	Insertions int
 	Deletions  int
 }
 
 func GetCommitLog2(repoPath string, count int, search string) []Commit {
-	var commits []Commit
-	args := []string{"log", fmt.Sprintf("-%d", count), "--pretty=format:%h|%s|%an|%ar"}
+	args := []string{"log", fmt.Sprintf("-%d", count)}
 	if search != "" {
 		args = append(args, "--grep="+search)
 	}
-
-	cmd := exec.Command("git", args...)
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err != nil {
-		return commits
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) >= 4 {
-			commits = append(commits, Commit{
-				Hash:    parts[0],
-				Message: parts[1],
-				Author:  parts[2],
-				Date:    parts[3],
-			})
-		}
-	}
+	commits, _ := logCommits(repoPath, args...)
 	return commits
 }
 
 func GetCommitDetail(repoPath, hash string) CommitDetail {
 	detail := CommitDetail{Hash: hash}
 
-	// Get commit info
-	cmd := exec.Command("git", "show", hash, "--pretty=format:%H|%s|%b|%an|%ae|%ar", "--stat")
-	cmd.Dir = repoPath
+	// Get commit info; the body goes last since it may span lines
+	cmd := command(repoPath, "show", "-s", "--format=%H%x1f%s%x1f%an%x1f%ae%x1f%ar%x1f%cr%x1f%at%x1f%ct%x1f%b", hash)
 	output, err := cmd.Output()
 	if err != nil {
 		return detail
 	}
 
-	lines := strings.Split(string(output), "\n")
-	if len(lines) > 0 {
-		parts := strings.SplitN(lines[0], "|", 6)
-		if len(parts) >= 6 {
-			detail.Hash = parts[0]
-			detail.Message = parts[1]
-			detail.Body = parts[2]
-			detail.Author = parts[3]
-			detail.Email = parts[4]
-			detail.Date = parts[5]
-		}
+	parts := strings.SplitN(string(output), fieldSep, 9)
+	if len(parts) >= 9 {
+		detail.Hash = parts[0]
+		detail.Message = parts[1]
+		detail.Author = parts[2]
+		detail.Email = parts[3]
+		detail.Date = parts[4]
+		detail.CommitDate = parts[5]
+		detail.Authored = unixTime(parts[6])
+		detail.Committed = unixTime(parts[7])
+		detail.Body = strings.TrimSpace(parts[8])
 	}
 
-	// Parse file stats
-	for _, line := range lines[1:] {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "---") {
-			continue
-		}
-		if strings.Contains(line, "changed") {
-			// Summary line: "2 files changed, 10 insertions(+), 5 deletions(-)"
-			fmt.Sscanf(line, "%*d file%*s changed, %d insertion%*s %d deletion", &detail.Insertions, &detail.Deletions)
-		} else if strings.Contains(line, "|") {
-			// File line: "filename | 5 ++-"
-			parts := strings.Split(line, "|")
-			if len(parts) >= 1 {
-				detail.Files = append(detail.Files, strings.TrimSpace(parts[0]))
-			}
-		}
+	// Per-file line counts, with renames under their new path
+	cmd = command(repoPath, "show", "--numstat", "-z", "--format=", hash)
+	output, err = cmd.Output()
+	if err != nil {
+		return detail
+	}
+	detail.Files = parseNumstat(output)
+	for _, stat := range detail.Files {
+		detail.Insertions += stat.Added
+		detail.Deletions += stat.Deleted
 	}
 
 	return detail
 }
 
 func GetCommitDiff(repoPath, hash string) string {
-	cmd := exec.Command("git", "show", hash, "--pretty=format:", "--patch")
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "show", hash, "--pretty=format:", "--patch", "--textconv")
+	output, _ := cmd.Output()
+	return string(output)
+}
+
+// GetCommitFileDiff returns what a commit changed in one file
+func GetCommitFileDiff(repoPath, hash, path string) string {
+	cmd := command(repoPath, "show", hash, "--pretty=format:", "--patch", "--textconv", "--", path)
 	output, _ := cmd.Output()
 	return string(output)
 }
@@ -859,14 +1343,20 @@ func ExecuteRebase(repoPath string, commits []RebaseCommit) error {
 	}
 	tmpFile.Close()
 
-	// Create editor script that copies our todo file
-	editorScript := fmt.Sprintf("cp %s \"$1\"", tmpPath)
+	// git runs the editor with the todo file appended, so this copies ours
+	// over it
+	editor := fmt.Sprintf("cp '%s'", tmpPath)
+
+	unlock, err := lockIndex(repoPath, []string{"rebase"})
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Run git rebase with our custom editor
 	count := len(commits)
-	cmd := exec.Command("git", "rebase", "-i", fmt.Sprintf("HEAD~%d", count))
-	cmd.Dir = repoPath
-	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=sh -c '"+editorScript+"'")
+	cmd := command(repoPath, "rebase", "-i", fmt.Sprintf("HEAD~%d", count))
+	cmd.Env = append(cmd.Env, "GIT_SEQUENCE_EDITOR="+editor)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -887,13 +1377,68 @@ func ContinueRebase(repoPath string) error {
 }
 
 func IsRebaseInProgress(repoPath string) bool {
-	rebaseMerge := filepath.Join(repoPath, ".git", "rebase-merge")
-	rebaseApply := filepath.Join(repoPath, ".git", "rebase-apply")
+	gitDir := GetGitDir(repoPath)
+	rebaseMerge := filepath.Join(gitDir, "rebase-merge")
+	rebaseApply := filepath.Join(gitDir, "rebase-apply")
 	_, err1 := os.Stat(rebaseMerge)
 	_, err2 := os.Stat(rebaseApply)
 	return err1 == nil || err2 == nil
 }
 
+// GetOperationInProgress reports which conflict-producing operation is
+// underway: "merge", "rebase", "cherry-pick", "revert" or ""
+func GetOperationInProgress(repoPath string) string {
+	gitDir := GetGitDir(repoPath)
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+	switch {
+	case IsRebaseInProgress(repoPath):
+		return "rebase"
+	case exists("CHERRY_PICK_HEAD"):
+		return "cherry-pick"
+	case exists("REVERT_HEAD"):
+		return "revert"
+	case exists("MERGE_HEAD"):
+		return "merge"
+	}
+	return ""
+}
+
+// ContinueOperation concludes the operation once conflicts are resolved,
+// keeping the prepared commit messages instead of opening an editor
+func ContinueOperation(repoPath, op string) error {
+	var args []string
+	switch op {
+	case "merge":
+		args = []string{"commit", "--no-edit"}
+	case "rebase", "cherry-pick", "revert":
+		args = []string{"-c", "core.editor=true", op, "--continue"}
+	default:
+		return fmt.Errorf("no merge, rebase, cherry-pick or revert in progress")
+	}
+	output, err := Execute(repoPath, args...)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AbortOperation abandons the operation and restores the previous state
+func AbortOperation(repoPath, op string) error {
+	switch op {
+	case "merge", "rebase", "cherry-pick", "revert":
+	default:
+		return fmt.Errorf("no merge, rebase, cherry-pick or revert in progress")
+	}
+	output, err := Execute(repoPath, op, "--abort")
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // Blame functions
 
 type BlameLine struct {
@@ -907,8 +1452,7 @@ type BlameLine struct {
 func GetBlame(repoPath, filePath string) []BlameLine {
 	var lines []BlameLine
 
-	cmd := exec.Command("git", "blame", "--porcelain", filePath)
-	cmd.Dir = repoPath
+	cmd := command(repoPath, "blame", "--porcelain", filePath)
 	output, err := cmd.Output()
 	if err != nil {
 		return lines
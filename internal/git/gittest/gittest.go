@@ -0,0 +1,48 @@
+// Package gittest provides an in-memory git.Repo for exercising the UI's
+// loaders and change analysis without running git.
+package gittest
+
+import (
+	"strings"
+
+	"github.com/LFroesch/gitty/internal/git"
+)
+
+// Repo serves recorded status and diff output
+type Repo struct {
+	Files  []git.Change      // what Changes returns
+	Staged string            // what StagedDiff returns
+	Diffs  map[string]string // diff against HEAD per path
+
+	State     git.Status   // what Status returns
+	Local     []git.Branch // what Branches returns
+	Remote    []git.Branch // what RemoteBranches returns
+	Commits   []git.Commit // HEAD's history, newest first
+	Conflicts []string     // what ConflictFiles returns
+}
+
+func (r Repo) Changes() []git.Change { return r.Files }
+
+func (r Repo) StagedDiff() string { return r.Staged }
+
+// Diff joins the recorded diffs of files in the order given
+func (r Repo) Diff(files []string) string {
+	var diff strings.Builder
+	for _, file := range files {
+		diff.WriteString(r.Diffs[file])
+	}
+	return diff.String()
+}
+
+func (r Repo) Status() git.Status { return r.State }
+
+func (r Repo) Branches() []git.Branch { return r.Local }
+
+func (r Repo) RemoteBranches() []git.Branch { return r.Remote }
+
+// Log returns the first count of Commits
+func (r Repo) Log(count int) []git.Commit { return r.Commits[:min(count, len(r.Commits))] }
+
+func (r Repo) ConflictFiles() []string { return r.Conflicts }
+
+var _ git.Repo = Repo{}
@@ -144,7 +144,7 @@ exit 0
 
 // IsHookInstalled checks if a git hook is installed
 func IsHookInstalled(repoPath, hookName string) bool {
-	hookPath := filepath.Join(repoPath, ".git", "hooks", hookName)
+	hookPath := filepath.Join(gitPath(repoPath, "hooks"), hookName)
 	info, err := os.Stat(hookPath)
 	if err != nil {
 		return false
@@ -155,7 +155,10 @@ func IsHookInstalled(repoPath, hookName string) bool {
 
 // InstallHook installs a git hook with the given content
 func InstallHook(repoPath, hookName, content string) error {
-	hooksDir := filepath.Join(repoPath, ".git", "hooks")
+	if err := writable(); err != nil {
+		return err
+	}
+	hooksDir := gitPath(repoPath, "hooks")
 
 	// Ensure hooks directory exists
 	if err := os.MkdirAll(hooksDir, 0755); err != nil {
@@ -174,7 +177,10 @@ func InstallHook(repoPath, hookName, content string) error {
 
 // RemoveHook removes a git hook
 func RemoveHook(repoPath, hookName string) error {
-	hookPath := filepath.Join(repoPath, ".git", "hooks", hookName)
+	if err := writable(); err != nil {
+		return err
+	}
+	hookPath := filepath.Join(gitPath(repoPath, "hooks"), hookName)
 	return os.Remove(hookPath)
 }
 
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Commands that write the index are serialised per repository, so operations
+// gitty runs concurrently never contend for index.lock. Everything else runs
+// freely: command() sets GIT_OPTIONAL_LOCKS=0, so status and diff never take
+// the lock just to refresh the index.
+var indexLocks sync.Map // git dir -> *sync.Mutex
+
+// indexWriters are the subcommands that take index.lock
+var indexWriters = map[string]bool{
+	"add": true, "am": true, "apply": true, "checkout": true, "cherry-pick": true,
+	"commit": true, "merge": true, "mv": true, "pull": true, "read-tree": true,
+	"rebase": true, "reset": true, "restore": true, "revert": true, "rm": true,
+	"stash": true, "switch": true, "update-index": true,
+}
+
+// LockWait bounds how long a write waits for an index.lock held by another
+// program (an editor's git integration, a terminal command)
+const LockWait = 3 * time.Second
+
+// subcommand returns the git subcommand in args, skipping global options
+// such as -c key=value
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-c" || arg == "-C":
+			i++
+		case len(arg) > 0 && arg[0] == '-':
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+// lockIndex serialises index writers of one repository and returns the
+// unlock function. Reads get a no-op.
+func lockIndex(repoPath string, args []string) (func(), error) {
+	return lockIndexIf(repoPath, indexWriters[subcommand(args)])
+}
+
+// lockIndexIf takes the index lock of repoPath when writes, for commands
+// whose subcommand isn't in their arguments (aliases)
+func lockIndexIf(repoPath string, writes bool) (func(), error) {
+	if !writes {
+		return func() {}, nil
+	}
+
+	gitDir := GetGitDir(repoPath)
+	mu, _ := indexLocks.LoadOrStore(gitDir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	if err := WaitForIndexLock(repoPath, LockWait); err != nil {
+		mu.(*sync.Mutex).Unlock()
+		return nil, err
+	}
+	return mu.(*sync.Mutex).Unlock, nil
+}
+
+// IndexLock describes an index.lock left in place by another program
+type IndexLock struct {
+	Path  string
+	Since time.Time
+	PID   int    // process holding the file open, 0 if none was found
+	Owner string // that process's command name
+	// OwnerKnown is false where open files cannot be inspected (no /proc),
+	// so a missing PID proves nothing
+	OwnerKnown bool
+}
+
+// staleAfter is how old a lock must be before it counts as stale when its
+// owner cannot be looked up
+const staleAfter = 10 * time.Minute
+
+// Stale reports whether the lock looks abandoned: nothing holds it open, or
+// (without /proc) it is old enough that no git command could still be running
+func (l IndexLock) Stale() bool {
+	if l.OwnerKnown {
+		return l.PID == 0
+	}
+	return time.Since(l.Since) > staleAfter
+}
+
+// LockError is returned when an index.lock stays in place too long
+type LockError struct {
+	Lock IndexLock
+}
+
+func (e *LockError) Error() string {
+	if e.Lock.PID != 0 {
+		return fmt.Sprintf("index.lock is held by %s (pid %d) since %s", e.Lock.Owner, e.Lock.PID, e.Lock.Since.Format("15:04:05"))
+	}
+	return fmt.Sprintf("index.lock exists since %s", e.Lock.Since.Format("15:04:05"))
+}
+
+// GetIndexLock returns the repository's index.lock, or nil if there is none
+func GetIndexLock(repoPath string) *IndexLock {
+	path := filepath.Join(GetGitDir(repoPath), "index.lock")
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	lock := &IndexLock{Path: path, Since: info.ModTime()}
+	lock.PID, lock.Owner, lock.OwnerKnown = lockOwner(path)
+	return lock
+}
+
+// WaitForIndexLock waits up to timeout for index.lock to go away and
+// returns a *LockError if it does not
+func WaitForIndexLock(repoPath string, timeout time.Duration) error {
+	path := filepath.Join(GetGitDir(repoPath), "index.lock")
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Only look for the owner once waiting is over: scanning /proc is slow
+	lock := GetIndexLock(repoPath)
+	if lock == nil {
+		return nil
+	}
+	return &LockError{Lock: *lock}
+}
+
+// RemoveIndexLock deletes a stale lock. It refuses if the lock is held by a
+// running process or was replaced since it was inspected.
+func RemoveIndexLock(lock IndexLock) error {
+	info, err := os.Stat(lock.Path)
+	if err != nil {
+		return nil
+	}
+	if !info.ModTime().Equal(lock.Since) {
+		return fmt.Errorf("the lock was re-created in the meantime")
+	}
+	if pid, owner, _ := lockOwner(lock.Path); pid != 0 {
+		return fmt.Errorf("the lock is held by %s (pid %d)", owner, pid)
+	}
+	if err := writable(); err != nil {
+		return err
+	}
+	return os.Remove(lock.Path)
+}
+
+// lockOwner finds a process with path open by scanning /proc. Only processes
+// of the same user are visible, which covers editors and terminals.
+func lockOwner(path string) (pid int, owner string, known bool) {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, "", false
+	}
+	// /proc shows resolved paths
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	for _, proc := range procs {
+		id, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && target == path {
+				comm, _ := os.ReadFile(filepath.Join("/proc", proc.Name(), "comm"))
+				return id, strings.TrimSpace(string(comm)), true
+			}
+		}
+	}
+	return 0, "", true
+}
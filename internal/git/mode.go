@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Git file modes
+const (
+	ModeFile       = "100644"
+	ModeExecutable = "100755"
+	ModeSymlink    = "120000"
+	ModeSubmodule  = "160000"
+	modeAbsent     = "000000"
+)
+
+// ModeChange is the mode of one file on both sides of a change: HEAD vs
+// index for staged changes, index vs worktree otherwise
+type ModeChange struct {
+	Old, New string
+}
+
+// ModeChange reads the staged or unstaged side of a change from its status
+func (c Change) ModeChange(staged bool) ModeChange {
+	if staged {
+		return ModeChange{Old: c.HeadMode, New: c.IndexMode}
+	}
+	return ModeChange{Old: c.IndexMode, New: c.WorktreeMode}
+}
+
+// Changed reports whether an existing file changed mode or type, which a
+// text diff alone does not show
+func (mc ModeChange) Changed() bool {
+	return mc.Old != "" && mc.New != "" && mc.Old != modeAbsent && mc.New != modeAbsent && mc.Old != mc.New
+}
+
+// Symlink reports whether either side is a symbolic link
+func (mc ModeChange) Symlink() bool {
+	return mc.Old == ModeSymlink || mc.New == ModeSymlink
+}
+
+// String describes the change, e.g. "100644 → 100755 (now executable)" or
+// "file → symlink"
+func (mc ModeChange) String() string {
+	oldKind, newKind := modeKind(mc.Old), modeKind(mc.New)
+	if oldKind != newKind {
+		return oldKind + " → " + newKind
+	}
+	switch mc.New {
+	case ModeExecutable:
+		return mc.Old + " → " + mc.New + " (now executable)"
+	case ModeFile:
+		return mc.Old + " → " + mc.New + " (no longer executable)"
+	}
+	return mc.Old + " → " + mc.New
+}
+
+// Short is a compact form for file lists: "+x", "-x" or "file→symlink"
+func (mc ModeChange) Short() string {
+	switch {
+	case mc.Old == ModeFile && mc.New == ModeExecutable:
+		return "+x"
+	case mc.Old == ModeExecutable && mc.New == ModeFile:
+		return "-x"
+	}
+	return modeKind(mc.Old) + "→" + modeKind(mc.New)
+}
+
+func modeKind(mode string) string {
+	switch mode {
+	case ModeFile, ModeExecutable:
+		return "file"
+	case ModeSymlink:
+		return "symlink"
+	case ModeSubmodule:
+		return "submodule"
+	}
+	return mode
+}
+
+// IsModeOnlyDiff reports whether git printed a mode change without any
+// content hunks
+func IsModeOnlyDiff(diff string) bool {
+	return strings.Contains(diff, "\nold mode ") && !strings.Contains(diff, "\n@@")
+}
+
+// GetSymlinkTargets reads where a symlink points on both sides of a change,
+// "" for a side where the file is not a link
+func GetSymlinkTargets(repoPath, filePath string, mc ModeChange, staged bool) (before, after string) {
+	if mc.Old == ModeSymlink {
+		rev := ":" + filePath
+		if staged {
+			rev = "HEAD:" + filePath
+		}
+		data, _ := readBlob(repoPath, rev)
+		before = string(data)
+	}
+	if mc.New == ModeSymlink {
+		if staged {
+			data, _ := readBlob(repoPath, ":"+filePath)
+			after = string(data)
+		} else {
+			after, _ = os.Readlink(filepath.Join(repoPath, filePath))
+		}
+	}
+	return before, after
+}
+
+// FormatModeSummary renders a mode, type or symlink target change as text
+// for the diff views; diff is git's diff of the file
+func FormatModeSummary(mc ModeChange, before, after, diff string) string {
+	var lines []string
+	switch {
+	case mc.Changed() && IsModeOnlyDiff(diff):
+		lines = append(lines, "Mode change only, content unchanged: "+mc.String())
+	case mc.Changed():
+		lines = append(lines, "Mode change: "+mc.String())
+	}
+	if mc.Symlink() {
+		switch {
+		case before != "" && after != "":
+			lines = append(lines, fmt.Sprintf("Symlink target: %s → %s", before, after))
+		case before != "":
+			lines = append(lines, "Was a symlink to "+before)
+		case after != "":
+			lines = append(lines, "Now a symlink to "+after)
+		}
+	}
+	if mc.Changed() && !mc.Symlink() && modeKind(mc.Old) == modeKind(mc.New) {
+		lines = append(lines, "  (set core.fileMode to false if the filesystem does not keep executable bits)")
+	}
+	return strings.Join(lines, "\n")
+}
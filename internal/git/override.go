@@ -0,0 +1,74 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CommitOverride replaces the author or the author date of a commit, for
+// backfilling work or committing on someone else's behalf. Zero fields keep
+// git's own: the configured identity and the current time.
+type CommitOverride struct {
+	Author Identity
+	Date   time.Time
+}
+
+// IsZero reports whether nothing is overridden
+func (o CommitOverride) IsZero() bool {
+	return o.Author == Identity{} && o.Date.IsZero()
+}
+
+// Args returns the git commit options applying the override
+func (o CommitOverride) Args() []string {
+	var args []string
+	if o.Author.IsComplete() {
+		args = append(args, "--author="+o.Author.String())
+	}
+	if !o.Date.IsZero() {
+		args = append(args, "--date="+o.Date.Format(time.RFC3339))
+	}
+	return args
+}
+
+// String describes the override for status messages, e.g.
+// "as Ada <ada@example.com>, dated 2024-03-01 09:00"
+func (o CommitOverride) String() string {
+	var parts []string
+	if o.Author.IsComplete() {
+		parts = append(parts, "as "+o.Author.String())
+	}
+	if !o.Date.IsZero() {
+		parts = append(parts, "dated "+o.Date.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// commitDateLayouts are the formats ParseCommitDate accepts, most specific
+// first
+var commitDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseCommitDate reads a date for --date in local time unless it carries
+// its own zone. Dates in the future or before 1970 are refused.
+func ParseCommitDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range commitDateLayouts {
+		date, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		switch {
+		case date.After(time.Now()):
+			return time.Time{}, fmt.Errorf("%s is in the future", s)
+		case date.Year() < 1970:
+			return time.Time{}, fmt.Errorf("%s is before 1970", s)
+		}
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("expected a date like 2024-03-01 or 2024-03-01 09:30")
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersPaths are the locations GitHub reads, in its order of precedence
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners is a parsed CODEOWNERS file
+type CodeOwners struct {
+	Path  string // relative to the repository root
+	rules []ownerRule
+}
+
+type ownerRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// GetCodeOwners reads the repository's CODEOWNERS file, or returns nil when
+// there is none
+func GetCodeOwners(repoPath string) *CodeOwners {
+	for _, rel := range codeOwnersPaths {
+		file, err := os.Open(filepath.Join(repoPath, rel))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		owners := &CodeOwners{Path: rel}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if i := strings.Index(line, "#"); i >= 0 {
+				line = line[:i]
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			pattern, err := ownerPattern(fields[0])
+			if err != nil {
+				continue
+			}
+			owners.rules = append(owners.rules, ownerRule{pattern: pattern, owners: fields[1:]})
+		}
+		return owners
+	}
+	return nil
+}
+
+// Owners lists who owns file. The last matching rule wins, and a rule with
+// no owners leaves the file unowned.
+func (c *CodeOwners) Owners(file string) []string {
+	if c == nil {
+		return nil
+	}
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(file) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// ownerPattern compiles a CODEOWNERS (gitignore-style) pattern. Patterns
+// with a leading or inner slash are anchored to the root; others match at
+// any depth. A match on a directory covers everything below it, except
+// that "dir/*" only covers the files directly in dir, as on GitHub.
+func ownerPattern(glob string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(glob, "/"), "/")
+	dirOnly := strings.HasSuffix(glob, "/")
+	shallow := strings.HasSuffix(glob, "/*")
+	glob = strings.Trim(glob, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case shallow:
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(expr.String())
+}
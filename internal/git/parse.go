@@ -0,0 +1,259 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output parsing. Everything here reads NUL-terminated output (-z) and
+// separates fields with the ASCII unit separator, so paths with spaces or
+// quotes and subjects containing "|" come through untouched.
+
+// fieldSep separates fields inside one record of formatted log output
+const fieldSep = "\x1f"
+
+// commitFormat yields hash, subject, author, relative author and committer
+// dates, author and committer timestamps and decorations for parseCommits
+const commitFormat = "--format=%h%x1f%s%x1f%an%x1f%ar%x1f%cr%x1f%at%x1f%ct%x1f%D"
+
+// branchFormat yields, for each local branch, "*" when it is checked out,
+// the full ref name, its upstream's full ref name and how far it is ahead
+// of or behind the upstream ("ahead 1, behind 2", "gone"), each record
+// ending in NUL, for parseBranches
+const branchFormat = "--format=%(HEAD)%1f%(refname)%1f%(upstream)%1f%(upstream:track,nobracket)%00"
+
+// splitNul splits -z output into records, dropping the trailing empty one
+func splitNul(output []byte) []string {
+	text := strings.TrimSuffix(string(output), "\x00")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\x00")
+}
+
+// logCommits runs git log (or reflog/stash list) with commitFormat and -z
+func logCommits(repoPath string, args ...string) ([]Commit, error) {
+	args = append(args, "-z", commitFormat)
+	cmd := command(repoPath, args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(output), nil
+}
+
+func parseCommits(output []byte) []Commit {
+	var commits []Commit
+	for _, record := range splitNul(output) {
+		parts := strings.SplitN(strings.TrimPrefix(record, "\n"), fieldSep, 8)
+		if len(parts) < 7 {
+			continue
+		}
+		commit := Commit{
+			Hash:       parts[0],
+			Message:    parts[1],
+			Author:     parts[2],
+			Date:       parts[3],
+			CommitDate: parts[4],
+			Authored:   unixTime(parts[5]),
+			Committed:  unixTime(parts[6]),
+		}
+		if len(parts) == 8 {
+			commit.Branches, commit.Tags = parseDecorations(parts[7])
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// parseBranches parses for-each-ref output in branchFormat. Names are taken
+// whole, so ones with commas, quotes or non-ASCII come through as they are.
+func parseBranches(output []byte) []Branch {
+	var branches []Branch
+	for _, record := range splitNul(output) {
+		parts := strings.Split(strings.TrimPrefix(record, "\n"), fieldSep)
+		if len(parts) != 4 {
+			continue
+		}
+		branch := Branch{
+			Name:      strings.TrimPrefix(parts[1], "refs/heads/"),
+			IsCurrent: parts[0] == "*",
+			Upstream:  shortRef(parts[2]),
+			Gone:      parts[3] == "gone",
+		}
+		for _, count := range strings.Split(parts[3], ", ") {
+			if n, ok := strings.CutPrefix(count, "ahead "); ok {
+				branch.Ahead, _ = strconv.Atoi(n)
+			} else if n, ok := strings.CutPrefix(count, "behind "); ok {
+				branch.Behind, _ = strconv.Atoi(n)
+			}
+		}
+		branches = append(branches, branch)
+	}
+	return branches
+}
+
+// parseAheadBehind reads for-each-ref lines of a branch name and
+// %(ahead-behind:...), "ahead behind", separated by fieldSep
+func parseAheadBehind(output []byte) map[string][2]int {
+	counts := make(map[string][2]int)
+	for _, line := range strings.Split(string(output), "\n") {
+		ref, count, ok := strings.Cut(line, fieldSep)
+		fields := strings.Fields(count)
+		if !ok || len(fields) != 2 {
+			continue
+		}
+		ahead, errAhead := strconv.Atoi(fields[0])
+		behind, errBehind := strconv.Atoi(fields[1])
+		if errAhead == nil && errBehind == nil {
+			counts[ref] = [2]int{ahead, behind}
+		}
+	}
+	return counts
+}
+
+// shortRef drops the refs/remotes/ or refs/heads/ of a full ref name, the
+// way git branch -vv names an upstream
+func shortRef(ref string) string {
+	for _, prefix := range []string{"refs/remotes/", "refs/heads/"} {
+		if short, ok := strings.CutPrefix(ref, prefix); ok {
+			return short
+		}
+	}
+	return ref
+}
+
+// unixTime reads a %at/%ct timestamp, zero if it isn't one
+func unixTime(secs string) time.Time {
+	n, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
+
+// parseDecorations splits %D output ("HEAD -> main, origin/main, tag: v1")
+// into branch and tag names. HEAD itself and origin/HEAD are left out.
+func parseDecorations(decorations string) (branches, tags []string) {
+	if decorations == "" {
+		return nil, nil
+	}
+	for _, ref := range strings.Split(decorations, ", ") {
+		ref = strings.TrimPrefix(ref, "HEAD -> ")
+		switch {
+		case strings.HasPrefix(ref, "tag: "):
+			tags = append(tags, strings.TrimPrefix(ref, "tag: "))
+		case ref == "HEAD" || strings.HasSuffix(ref, "/HEAD"):
+		default:
+			branches = append(branches, ref)
+		}
+	}
+	return branches, tags
+}
+
+// parseStatusV2 parses `git status --porcelain=v2 -z`. Status keeps the
+// two-letter porcelain v1 codes ("M ", " M", "??", "UU", ...) the UI uses.
+func parseStatusV2(output []byte) []Change {
+	var changes []Change
+	records := splitNul(output)
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 2 {
+			continue
+		}
+		switch record[0] {
+		case '1':
+			// 1 XY sub mH mI mW hH hI path
+			if fields := strings.SplitN(record, " ", 9); len(fields) == 9 {
+				changes = append(changes, Change{File: fields[8], Status: v1Status(fields[1]),
+					HeadMode: fields[3], IndexMode: fields[4], WorktreeMode: fields[5]})
+			}
+		case '2':
+			// 2 XY sub mH mI mW hH hI Xscore path, then origPath as its own record
+			if fields := strings.SplitN(record, " ", 10); len(fields) == 10 {
+				change := Change{File: fields[9], Status: v1Status(fields[1]),
+					HeadMode: fields[3], IndexMode: fields[4], WorktreeMode: fields[5]}
+				if i+1 < len(records) {
+					i++
+					change.OrigPath = records[i]
+				}
+				changes = append(changes, change)
+			}
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			if fields := strings.SplitN(record, " ", 11); len(fields) == 11 {
+				changes = append(changes, Change{File: fields[10], Status: v1Status(fields[1])})
+			}
+		case '?':
+			changes = append(changes, Change{File: record[2:], Status: "??"})
+		case '!':
+			changes = append(changes, Change{File: record[2:], Status: "!!"})
+		}
+	}
+	return changes
+}
+
+// UnquotePath undoes git's C-style quoting of a path ("a\tb", "caf\303\251"),
+// which it applies to names with special characters outside -z output
+func UnquotePath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// v1Status turns porcelain v2's "." for unchanged into v1's space
+func v1Status(xy string) string {
+	return strings.ReplaceAll(xy, ".", " ")
+}
+
+// parseNameStatus parses `git diff --name-status -z`, where renames and
+// copies carry a similarity score and two paths
+func parseNameStatus(output []byte) []DiffFile {
+	var files []DiffFile
+	records := splitNul(output)
+	for i := 0; i < len(records); i++ {
+		status := records[i]
+		if status == "" || i+1 >= len(records) {
+			continue
+		}
+		file := DiffFile{Status: status[:1]}
+		if (file.Status == "R" || file.Status == "C") && i+2 < len(records) {
+			file.Similarity, _ = strconv.Atoi(status[1:])
+			file.OldPath, file.Path = records[i+1], records[i+2]
+			i += 2
+		} else {
+			file.Path = records[i+1]
+			i++
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// parseNumstat parses `git diff --numstat -z`: "added\tdeleted\tpath"
+// records, where renames leave the path empty and follow with old and new
+// paths, and binary files count "-" lines
+func parseNumstat(output []byte) []DiffStat {
+	var stats []DiffStat
+	records := splitNul(output)
+	for i := 0; i < len(records); i++ {
+		fields := strings.SplitN(strings.TrimLeft(records[i], "\n"), "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		stat := DiffStat{Path: fields[2], Binary: fields[0] == "-"}
+		stat.Added, _ = strconv.Atoi(fields[0])
+		stat.Deleted, _ = strconv.Atoi(fields[1])
+		if stat.Path == "" && i+2 < len(records) {
+			stat.Path = records[i+2]
+			i += 2
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// statusZ is git status --porcelain=v2 -z of a repository with a new file
+// with an accent, a rename to a name with a pipe, a deleted file with a
+// quote, a modified file with a space and an untracked one with a tab
+const statusZ = "1 A. N... 000000 100644 100644 0000000000000000000000000000000000000000 f2ad6c76f0115a6ba5b00456a849810e7ec0af20 café.md\x00" +
+	"2 R. N... 100644 100644 100644 975fbec8256d3e8a3797e7a3611380f27c49f4ac 975fbec8256d3e8a3797e7a3611380f27c49f4ac R100 new|name.go\x00old name.go\x00" +
+	"1 .D N... 100644 100644 000000 bca70f35318f31dd1d1d1d2d2e64c19b880899ff bca70f35318f31dd1d1d1d2d2e64c19b880899ff quote\"d.txt\x00" +
+	"1 .M N... 100644 100644 100644 587be6b4c3f93f93c489c0111bba5596147a26cb 587be6b4c3f93f93c489c0111bba5596147a26cb with space.txt\x00" +
+	"? tab\there.txt\x00"
+
+func TestParseStatusV2(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Change
+	}{
+		{"clean", "", nil},
+		{
+			name:   "pathological names",
+			output: statusZ,
+			want: []Change{
+				{File: "café.md", Status: "A ", HeadMode: "000000", IndexMode: "100644", WorktreeMode: "100644"},
+				{File: "new|name.go", OrigPath: "old name.go", Status: "R ", HeadMode: "100644", IndexMode: "100644", WorktreeMode: "100644"},
+				{File: "quote\"d.txt", Status: " D", HeadMode: "100644", IndexMode: "100644", WorktreeMode: "000000"},
+				{File: "with space.txt", Status: " M", HeadMode: "100644", IndexMode: "100644", WorktreeMode: "100644"},
+				{File: "tab\there.txt", Status: "??"},
+			},
+		},
+		{
+			name:   "conflict and ignored",
+			output: "u UU N... 100644 100644 100644 100644 d00491fd7e5bb6fa28c517a0bb32b8b506539d4d 00750edc07d6415dcc07ae0351e9397b0222b7ba 0cfbf08886fca9a91cb753ec8734c84fcbe52c9f a b.txt\x00! build/\x00",
+			want: []Change{
+				{File: "a b.txt", Status: "UU"},
+				{File: "build/", Status: "!!"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseStatusV2([]byte(test.output)); !slices.Equal(got, test.want) {
+				t.Errorf("parseStatusV2() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCommits(t *testing.T) {
+	// git log -z in commitFormat; the first subject is full of pipes and
+	// the first record carries decorations
+	output := "cab005c\x1ffeat: a | b | c\x1fa\x1f2 days ago\x1f1 hour ago\x1f1792302934\x1f1792468534\x1fHEAD -> main, origin/main, tag: v1.0\x00" +
+		"\n4974b4e\x1fInitial commit\x1fJosé Ünïcode\x1f3 days ago\x1f3 days ago\x1f1792216534\x1f1792216534\x1f\x00"
+	want := []Commit{
+		{
+			Hash: "cab005c", Message: "feat: a | b | c", Author: "a", Date: "2 days ago", CommitDate: "1 hour ago",
+			Authored: time.Unix(1792302934, 0), Committed: time.Unix(1792468534, 0),
+			Branches: []string{"main", "origin/main"}, Tags: []string{"v1.0"},
+		},
+		{
+			Hash: "4974b4e", Message: "Initial commit", Author: "José Ünïcode", Date: "3 days ago", CommitDate: "3 days ago",
+			Authored: time.Unix(1792216534, 0), Committed: time.Unix(1792216534, 0),
+		},
+	}
+
+	got := parseCommits([]byte(output))
+	if len(got) != len(want) {
+		t.Fatalf("parseCommits() returned %d commits, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Hash != w.Hash || g.Message != w.Message || g.Author != w.Author || g.Date != w.Date ||
+			g.CommitDate != w.CommitDate || !g.Authored.Equal(w.Authored) || !g.Committed.Equal(w.Committed) ||
+			!slices.Equal(g.Branches, w.Branches) || !slices.Equal(g.Tags, w.Tags) {
+			t.Errorf("commit %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestParseBranches(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Branch
+	}{
+		{"empty", "", nil},
+		{
+			// for-each-ref output of a clone whose branches are checked out,
+			// diverged, untracked and tracking a deleted upstream
+			name: "tracking states",
+			output: " \x1frefs/heads/a,b|c\"d\x1frefs/remotes/origin/a,b|c\"d\x1f\x00\n" +
+				" \x1frefs/heads/feature/ünïcode-🚀\x1f\x1f\x00\n" +
+				" \x1frefs/heads/gone-later\x1frefs/remotes/origin/gone-later\x1fgone\x00\n" +
+				"*\x1frefs/heads/main\x1frefs/remotes/origin/main\x1fahead 1, behind 1\x00\n",
+			want: []Branch{
+				{Name: "a,b|c\"d", Upstream: "origin/a,b|c\"d"},
+				{Name: "feature/ünïcode-🚀"},
+				{Name: "gone-later", Upstream: "origin/gone-later", Gone: true},
+				{Name: "main", IsCurrent: true, Upstream: "origin/main", Ahead: 1, Behind: 1},
+			},
+		},
+		{
+			name: "behind only and local upstream",
+			output: " \x1frefs/heads/topic\x1frefs/heads/main\x1fbehind 12\x00\n" +
+				"*\x1frefs/heads/heads/main\x1frefs/remotes/up/stream/main\x1fahead 3\x00\n",
+			want: []Branch{
+				{Name: "topic", Upstream: "main", Behind: 12},
+				{Name: "heads/main", IsCurrent: true, Upstream: "up/stream/main", Ahead: 3},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseBranches([]byte(test.output)); !slices.Equal(got, test.want) {
+				t.Errorf("parseBranches() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseAheadBehind(t *testing.T) {
+	// for-each-ref --format=%(refname:strip=2)%1f%(ahead-behind:main) of
+	// refs/heads, git 2.41, and a line without counts
+	output := "main\x1f0 0\n" +
+		"feature/x\x1f3 12\n" +
+		"odd name\x1f0 1\n" +
+		"broken\x1f\n"
+	want := map[string][2]int{
+		"main":      {0, 0},
+		"feature/x": {3, 12},
+		"odd name":  {0, 1},
+	}
+	if got := parseAheadBehind([]byte(output)); !maps.Equal(got, want) {
+		t.Errorf("parseAheadBehind() = %v, want %v", got, want)
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []DiffFile
+	}{
+		{"no changes", "", nil},
+		{
+			name:   "added and renamed",
+			output: "A\x00café.md\x00R100\x00old name.go\x00new|name.go\x00",
+			want: []DiffFile{
+				{Status: "A", Path: "café.md"},
+				{Status: "R", Path: "new|name.go", OldPath: "old name.go", Similarity: 100},
+			},
+		},
+		{
+			name:   "copied and binary",
+			output: "C087\x00src.go\x00copy of src.go\x00M\x00img 1.png\x00",
+			want: []DiffFile{
+				{Status: "C", Path: "copy of src.go", OldPath: "src.go", Similarity: 87},
+				{Status: "M", Path: "img 1.png"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseNameStatus([]byte(test.output)); !slices.Equal(got, test.want) {
+				t.Errorf("parseNameStatus() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []DiffStat
+	}{
+		{"no changes", "", nil},
+		{
+			name:   "renamed and quoted",
+			output: "1\t0\tcafé.md\x000\t0\t\x00old name.go\x00new|name.go\x000\t1\tquote\"d.txt\x001\t0\twith space.txt\x00",
+			want: []DiffStat{
+				{Path: "café.md", Added: 1},
+				{Path: "new|name.go"},
+				{Path: "quote\"d.txt", Deleted: 1},
+				{Path: "with space.txt", Added: 1},
+			},
+		},
+		{
+			name:   "copied and binary",
+			output: "1\t0\t\x00src.go\x00copy of src.go\x00-\t-\timg 1.png\x00",
+			want: []DiffStat{
+				{Path: "copy of src.go", Added: 1},
+				{Path: "img 1.png", Binary: true},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseNumstat([]byte(test.output)); !slices.Equal(got, test.want) {
+				t.Errorf("parseNumstat() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PromptStatus is the summary `gitty prompt` prints
+type PromptStatus struct {
+	Branch    string // short commit hash when detached
+	Detached  bool
+	Staged    int
+	Unstaged  int
+	Untracked int
+	Conflicts int
+	Ahead     int
+	Behind    int
+
+	Upstream     string // "" when the branch tracks nothing
+	UpstreamGone bool   // tracked, but the remote branch no longer exists
+}
+
+// GetPromptStatus reads PromptStatus from a single git status call, so it is
+// cheap enough to run on every shell prompt. dir may be anywhere inside the
+// working tree.
+func GetPromptStatus(dir string) (PromptStatus, error) {
+	output, err := command(dir, "status", "--porcelain=v2", "--branch", "-z").Output()
+	if err != nil {
+		return PromptStatus{}, fmt.Errorf("not a git repository")
+	}
+	return parsePromptStatus(output), nil
+}
+
+// parsePromptStatus reads the "# branch.*" headers of porcelain v2 output
+// and counts its entries
+func parsePromptStatus(output []byte) PromptStatus {
+	var status PromptStatus
+	var oid string
+	for _, record := range splitNul(output) {
+		header, ok := strings.CutPrefix(record, "# ")
+		if !ok {
+			continue
+		}
+		key, value, _ := strings.Cut(header, " ")
+		switch key {
+		case "branch.oid":
+			oid = value
+		case "branch.head":
+			status.Branch = value
+		case "branch.upstream":
+			status.Upstream = value
+			// branch.ab only follows when the upstream exists
+			status.UpstreamGone = true
+		case "branch.ab":
+			status.UpstreamGone = false
+			// branch.ab +<ahead> -<behind>
+			ahead, behind, _ := strings.Cut(value, " ")
+			status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+			status.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+		}
+	}
+	if status.Branch == "(detached)" {
+		status.Detached = true
+		status.Branch = oid
+		if len(oid) > 7 {
+			status.Branch = oid[:7]
+		}
+	}
+
+	for _, change := range parseStatusV2(output) {
+		switch {
+		case change.Status == "??":
+			status.Untracked++
+		case strings.Contains(change.Status, "U") || change.Status == "AA" || change.Status == "DD":
+			status.Conflicts++
+		default:
+			if change.Status[0] != ' ' {
+				status.Staged++
+			}
+			if change.Status[1] != ' ' {
+				status.Unstaged++
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,113 @@
+package git
+
+import (
+	"errors"
+	"slices"
+	"sync/atomic"
+)
+
+// ErrReadOnly is returned for anything that would change the repository
+// while read-only mode is on
+var ErrReadOnly = errors.New("read-only mode: gitty was started with --read-only")
+
+var readOnly atomic.Bool
+
+// SetReadOnly turns read-only mode on or off. While on, git commands that
+// could change the repository fail with ErrReadOnly instead of running.
+func SetReadOnly(on bool) { readOnly.Store(on) }
+
+// ReadOnly reports whether read-only mode is on
+func ReadOnly() bool { return readOnly.Load() }
+
+// writable fails with ErrReadOnly when read-only mode is on, for writes
+// that don't go through git
+func writable() error {
+	if readOnly.Load() {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// checkWritable fails with ErrReadOnly when read-only mode is on and args
+// could change the repository
+func checkWritable(args []string) error {
+	if readOnly.Load() && !readsOnly(args) {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// readers are subcommands that never change the repository
+var readers = map[string]bool{
+	"blame": true, "cat-file": true, "check-attr": true, "check-ignore": true,
+	"cherry": true, "count-objects": true, "describe": true, "diff": true,
+	"diff-files": true, "diff-index": true, "diff-tree": true, "for-each-ref": true,
+	"fsck": true, "grep": true, "log": true, "ls-files": true, "ls-remote": true,
+	"ls-tree": true, "merge-base": true, "name-rev": true, "range-diff": true,
+	"rev-list": true, "rev-parse": true, "shortlog": true, "show": true,
+	"show-ref": true, "status": true, "var": true, "verify-commit": true,
+	"verify-tag": true, "version": true,
+}
+
+// readsOnly reports whether args is known not to change the repository.
+// Anything unrecognised, aliases included, counts as a write.
+func readsOnly(args []string) bool {
+	sub := subcommand(args)
+	if readers[sub] {
+		return true
+	}
+	rest := args[slices.Index(args, sub)+1:]
+	positional := positionalArgs(rest, sub == "config")
+	first := ""
+	if len(positional) > 0 {
+		first = positional[0]
+	}
+	has := func(flags ...string) bool {
+		return slices.ContainsFunc(rest, func(arg string) bool { return slices.Contains(flags, arg) })
+	}
+
+	switch sub {
+	case "stash":
+		return first == "list" || first == "show"
+	case "clean":
+		return has("-n", "--dry-run")
+	case "merge-file":
+		return has("-p", "--stdout")
+	case "reflog":
+		return first == "" || first == "show"
+	case "branch":
+		return !has("-d", "-D", "--delete", "-m", "-M", "--move", "-c", "-C", "--copy",
+			"-u", "--set-upstream-to", "--unset-upstream", "--edit-description") &&
+			(len(positional) == 0 || has("-l", "--list", "--contains", "--merged", "--no-merged", "--points-at"))
+	case "tag":
+		return !has("-d", "--delete") && (len(positional) == 0 || has("-l", "--list", "-v", "--verify", "--contains", "--points-at"))
+	case "config":
+		return !has("--unset", "--unset-all", "--add", "--replace-all", "--remove-section", "--rename-section", "-e", "--edit") &&
+			(len(positional) <= 1 || has("--get", "--get-all", "--get-regexp", "--get-urlmatch", "-l", "--list"))
+	case "remote":
+		return first == "" || first == "show" || first == "get-url"
+	case "symbolic-ref":
+		return len(positional) <= 1 && !has("-d", "--delete")
+	case "worktree":
+		return first == "list"
+	}
+	return false
+}
+
+// positionalArgs drops the options from a subcommand's arguments, and for
+// config the file an option names
+func positionalArgs(args []string, config bool) []string {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--":
+			return append(positional, args[i+1:]...)
+		case config && (arg == "-f" || arg == "--file" || arg == "--blob"):
+			i++
+		case len(arg) > 0 && arg[0] == '-':
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	return positional
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RebaseStep is one line of a rebase todo list, e.g. "pick 1a2b3c4 Subject"
+type RebaseStep struct {
+	Action  string
+	Hash    string // short hash, "" for steps like exec or break
+	Subject string
+}
+
+func (s RebaseStep) String() string {
+	return strings.Join(strings.Fields(s.Action+" "+s.Hash+" "+s.Subject), " ")
+}
+
+// RebaseProgress is how far a rebase in progress has got: the step it
+// stopped on out of how many, and what is left to replay
+type RebaseProgress struct {
+	Step    int
+	Total   int
+	Current RebaseStep // zero when git doesn't record it
+	Todo    []RebaseStep
+	Branch  string // branch being rebased, "" when detached
+	Onto    string // short hash
+}
+
+// rebaseActions expands the one-letter commands of rebase.abbreviateCommands
+var rebaseActions = map[string]string{
+	"p": "pick", "r": "reword", "e": "edit", "s": "squash", "f": "fixup",
+	"x": "exec", "b": "break", "d": "drop", "l": "label", "t": "reset", "m": "merge",
+}
+
+// GetRebaseProgress reads the state of a rebase in progress from the git
+// directory. ok is false when no rebase is underway.
+func GetRebaseProgress(repoPath string) (progress RebaseProgress, ok bool) {
+	gitDir := GetGitDir(repoPath)
+	read := func(dir, name string) string {
+		data, _ := os.ReadFile(filepath.Join(gitDir, dir, name))
+		return strings.TrimSpace(string(data))
+	}
+	number := func(dir, name string) int {
+		n, _ := strconv.Atoi(read(dir, name))
+		return n
+	}
+
+	dir := "rebase-merge"
+	if _, err := os.Stat(filepath.Join(gitDir, dir)); err != nil {
+		dir = "rebase-apply"
+		if _, err := os.Stat(filepath.Join(gitDir, dir)); err != nil {
+			return RebaseProgress{}, false
+		}
+	}
+
+	progress.Branch = strings.TrimPrefix(read(dir, "head-name"), "refs/heads/")
+	if progress.Branch == "detached HEAD" {
+		progress.Branch = ""
+	}
+	progress.Onto = shortHash(read(dir, "onto"))
+
+	if dir == "rebase-apply" {
+		// The apply backend numbers its patches instead of keeping a todo list
+		progress.Step, progress.Total = number(dir, "next"), number(dir, "last")
+		if hash := read(dir, "original-commit"); hash != "" {
+			progress.Current = RebaseStep{Action: "pick", Hash: shortHash(hash), Subject: commitSubject(repoPath, hash)}
+		}
+		return progress, true
+	}
+
+	progress.Step, progress.Total = number(dir, "msgnum"), number(dir, "end")
+	if done := parseRebaseTodo(read(dir, "done")); len(done) > 0 {
+		progress.Current = done[len(done)-1]
+	}
+	progress.Todo = parseRebaseTodo(read(dir, "git-rebase-todo"))
+	return progress, true
+}
+
+// parseRebaseTodo reads the lines of a rebase todo list, skipping comments
+func parseRebaseTodo(content string) []RebaseStep {
+	var steps []RebaseStep
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		step := RebaseStep{Action: fields[0]}
+		if action, ok := rebaseActions[step.Action]; ok {
+			step.Action = action
+		}
+		rest := fields[1:]
+		switch step.Action {
+		case "pick", "reword", "edit", "squash", "fixup", "drop":
+			// fixup -C/-c take the message of the commit they name
+			if len(rest) > 0 && strings.HasPrefix(rest[0], "-") {
+				rest = rest[1:]
+			}
+			if len(rest) > 0 {
+				step.Hash = shortHash(rest[0])
+				rest = rest[1:]
+			}
+			if len(rest) > 0 && rest[0] == "#" {
+				rest = rest[1:]
+			}
+		}
+		step.Subject = strings.Join(rest, " ")
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func commitSubject(repoPath, hash string) string {
+	output, err := Execute(repoPath, "log", "-1", "--format=%s", hash)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,50 @@
+package git
+
+// Repo is the read-only view of a repository that change analysis and the
+// UI's refresh loaders need: status, changes, branches, recent commits and
+// conflicts. Local runs git; gittest.Repo serves recorded output so they can
+// run without a repository on disk. Writes, and reads specific to one tool,
+// take the repository's path.
+type Repo interface {
+	// Changes lists staged, unstaged and untracked paths
+	Changes() []Change
+	// StagedDiff is the diff of the index against HEAD
+	StagedDiff() string
+	// Diff is the diff of files against HEAD, staged and unstaged together
+	Diff(files []string) string
+	// Status is the branch, stash count, operation and upstream state
+	Status() Status
+	// Branches lists local branches, RemoteBranches remote-tracking ones
+	Branches() []Branch
+	RemoteBranches() []Branch
+	// Log is the last count commits of HEAD, newest first
+	Log(count int) []Commit
+	// ConflictFiles lists the paths with unresolved conflicts
+	ConflictFiles() []string
+}
+
+// Local is a repository on disk, named by its path
+type Local string
+
+func (l Local) Changes() []Change { return GetChanges(string(l)) }
+
+func (l Local) StagedDiff() string { return GetStagedDiff(string(l)) }
+
+func (l Local) Diff(files []string) string {
+	output, err := Execute(string(l), append([]string{"diff", "HEAD", "--"}, files...)...)
+	if err != nil {
+		// No HEAD yet (initial commit): fall back to the staged diff
+		output, _ = Execute(string(l), append([]string{"diff", "--cached", "--"}, files...)...)
+	}
+	return string(output)
+}
+
+func (l Local) Status() Status { return GetStatus(string(l)) }
+
+func (l Local) Branches() []Branch { return GetBranches(string(l)) }
+
+func (l Local) RemoteBranches() []Branch { return GetRemoteBranches(string(l)) }
+
+func (l Local) Log(count int) []Commit { return GetCommitLog(string(l), count) }
+
+func (l Local) ConflictFiles() []string { return GetConflictFiles(string(l)) }
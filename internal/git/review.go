@@ -0,0 +1,40 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// GetRangeFileDiff diffs one file over a revision range such as
+// "main...HEAD"; oldPath is the source of a rename or copy
+func GetRangeFileDiff(repoPath, revRange, filePath, oldPath string) string {
+	args := []string{"diff", "-M", "-C", "--textconv", revRange, "--", filePath}
+	if oldPath != "" {
+		args = append(args, oldPath)
+	}
+	output, _ := command(repoPath, args...).Output()
+	return string(output)
+}
+
+// HasGH reports whether the GitHub CLI is installed
+func HasGH() bool {
+	_, err := exec.LookPath("gh")
+	return err == nil
+}
+
+// PostPRReview posts body as a review comment on the pull request for the
+// current branch with `gh pr review`
+func PostPRReview(repoPath, body string) error {
+	cmd := exec.Command("gh", "pr", "review", "--comment", "--body-file", "-")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(body)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return errors.New(lastLine(msg))
+		}
+		return err
+	}
+	return nil
+}
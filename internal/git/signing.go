@@ -0,0 +1,246 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SigningKey is a key git can sign commits with
+type SigningKey struct {
+	Format string // gpg.format: "openpgp" or "ssh"
+	ID     string // value for user.signingkey: GPG key id or SSH public key path
+	Desc   string // user id or key comment
+}
+
+// SigningConfig is the effective signing setup
+type SigningConfig struct {
+	Format      string // "" means openpgp (git's default)
+	Key         string
+	SignCommits bool
+}
+
+// GetSigningConfig reads the effective signing-related config
+func GetSigningConfig(repoPath string) SigningConfig {
+	return SigningConfig{
+		Format:      GetConfigValue(repoPath, "gpg.format"),
+		Key:         GetConfigValue(repoPath, "user.signingkey"),
+		SignCommits: GetConfigValue(repoPath, "commit.gpgsign") == "true",
+	}
+}
+
+// DetectSigningKeys lists GPG secret keys and SSH public keys in ~/.ssh
+func DetectSigningKeys() []SigningKey {
+	var keys []SigningKey
+
+	// GPG: sec lines carry the key id, the following uid line the owner
+	if output, err := exec.Command("gpg", "--list-secret-keys", "--with-colons", "--keyid-format=long").Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Split(line, ":")
+			switch {
+			case len(fields) > 4 && fields[0] == "sec":
+				keys = append(keys, SigningKey{Format: "openpgp", ID: fields[4]})
+			case len(fields) > 9 && fields[0] == "uid" && len(keys) > 0 && keys[len(keys)-1].Desc == "":
+				keys[len(keys)-1].Desc = fields[9]
+			}
+		}
+	}
+
+	// SSH: any public key next to a private key
+	if home, err := os.UserHomeDir(); err == nil {
+		pubs, _ := filepath.Glob(filepath.Join(home, ".ssh", "*.pub"))
+		for _, pub := range pubs {
+			data, err := os.ReadFile(pub)
+			if err != nil {
+				continue
+			}
+			parts := strings.Fields(string(data))
+			desc := filepath.Base(pub)
+			if len(parts) >= 3 {
+				desc = parts[0] + " " + strings.Join(parts[2:], " ")
+			} else if len(parts) > 0 {
+				desc = parts[0]
+			}
+			keys = append(keys, SigningKey{Format: "ssh", ID: pub, Desc: desc})
+		}
+	}
+
+	return keys
+}
+
+// ConfigureSigning points git at key in the given config layer and turns on
+// commit signing
+func ConfigureSigning(repoPath, scope string, key SigningKey) error {
+	if err := SetConfig(repoPath, scope, "gpg.format", key.Format); err != nil {
+		return err
+	}
+	if err := SetConfig(repoPath, scope, "user.signingkey", key.ID); err != nil {
+		return err
+	}
+	return SetConfig(repoPath, scope, "commit.gpgsign", "true")
+}
+
+// TestSigning signs a throwaway commit object (no ref is updated) using the
+// current config and turns failures into actionable messages
+func TestSigning(repoPath string) error {
+	output, err := Execute(repoPath, "write-tree")
+	if err != nil {
+		return fmt.Errorf("cannot prepare test object: %s", strings.TrimSpace(string(output)))
+	}
+	tree := strings.TrimSpace(string(output))
+
+	output, err = Execute(repoPath, "commit-tree", "-S", tree, "-m", "gitty signing test")
+	if err == nil {
+		return nil
+	}
+	return explainSigningError(strings.TrimSpace(string(output)))
+}
+
+func explainSigningError(output string) error {
+	lower := strings.ToLower(output)
+	var hint string
+	switch {
+	case strings.Contains(lower, "unsupported value for gpg.format"):
+		hint = "this git is too old for SSH signing (needs 2.34+)"
+	case strings.Contains(lower, "no secret key"), strings.Contains(lower, "secret key not available"):
+		hint = "user.signingkey does not match any GPG secret key"
+	case strings.Contains(lower, "inappropriate ioctl"), strings.Contains(lower, "cannot open tty"):
+		hint = "gpg cannot ask for the passphrase - run `export GPG_TTY=$(tty)` in your shell"
+	case strings.Contains(lower, "couldn't load public key"), strings.Contains(lower, "no such file"):
+		hint = "the SSH key file in user.signingkey does not exist"
+	case strings.Contains(lower, "ssh-keygen") && strings.Contains(lower, "not found"):
+		hint = "ssh-keygen is not installed"
+	case strings.Contains(lower, "gpg") && strings.Contains(lower, "not found"),
+		strings.Contains(lower, "cannot run gpg"):
+		hint = "gpg is not installed or not on PATH"
+	case strings.Contains(lower, "failed to sign"):
+		hint = "the signing program refused - check the key and its agent"
+	}
+
+	last := output
+	if lines := strings.Split(output, "\n"); len(lines) > 0 {
+		last = lines[len(lines)-1]
+	}
+	if hint == "" {
+		return fmt.Errorf("%s", last)
+	}
+	return fmt.Errorf("%s (%s)", hint, last)
+}
+
+// Signature is the result of verifying a signed tag or commit. Status uses
+// git's %G? letters: G good, U good but the key is not trusted, B bad,
+// X expired signature, Y expired key, R revoked key, E cannot be checked,
+// N no signature.
+type Signature struct {
+	Status string
+	Signer string // user id (GPG) or principal (SSH)
+	Key    string // key id or fingerprint
+	Trust  string // GPG trust level or "allowed signers" for SSH, if known
+	Detail string // why the signature could not be checked
+}
+
+// Valid reports a good signature from a trusted key
+func (s Signature) Valid() bool {
+	return s.Status == "G"
+}
+
+// Describe is a one-line human reading of the status
+func (s Signature) Describe() string {
+	switch s.Status {
+	case "G":
+		return "good signature"
+	case "U":
+		return "good signature, untrusted key"
+	case "B":
+		return "BAD signature"
+	case "X":
+		return "good signature, but it has expired"
+	case "Y":
+		return "signed with an expired key"
+	case "R":
+		return "signed with a revoked key"
+	case "E":
+		return "cannot be checked"
+	default:
+		return "not signed"
+	}
+}
+
+// VerifyTag checks the signature of an annotated tag
+func VerifyTag(repoPath, tag string) Signature {
+	output, err := Execute(repoPath, "verify-tag", "--raw", tag)
+	return parseVerify(string(output), err)
+}
+
+// VerifyCommit checks the signature of a commit
+func VerifyCommit(repoPath, rev string) Signature {
+	output, err := Execute(repoPath, "verify-commit", "--raw", rev)
+	return parseVerify(string(output), err)
+}
+
+// parseVerify reads the --raw output of verify-tag/verify-commit: GnuPG
+// status lines for OpenPGP, ssh-keygen messages for SSH signatures
+func parseVerify(output string, err error) Signature {
+	var sig Signature
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if status, ok := strings.CutPrefix(line, "[GNUPG:] "); ok {
+			keyword, rest, _ := strings.Cut(status, " ")
+			key, signer, _ := strings.Cut(rest, " ")
+			switch keyword {
+			case "GOODSIG", "BADSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG":
+				sig.Status = map[string]string{
+					"GOODSIG": "G", "BADSIG": "B", "EXPSIG": "X", "EXPKEYSIG": "Y", "REVKEYSIG": "R",
+				}[keyword]
+				sig.Key, sig.Signer = key, signer
+			case "ERRSIG":
+				sig.Status, sig.Key = "E", key
+			case "NO_PUBKEY":
+				sig.Detail = "public key " + key + " is not in your keyring"
+			case "TRUST_UNDEFINED", "TRUST_NEVER", "TRUST_MARGINAL", "TRUST_FULLY", "TRUST_ULTIMATE":
+				sig.Trust = strings.ToLower(strings.TrimPrefix(keyword, "TRUST_"))
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, `Good "git" signature for `):
+			// Good "git" signature for <principal> with <type> key <fingerprint>
+			rest := strings.TrimPrefix(line, `Good "git" signature for `)
+			principal, keyPart, _ := strings.Cut(rest, " with ")
+			sig.Status, sig.Signer, sig.Trust = "G", principal, "allowed signers"
+			sig.Key = keyPart[strings.LastIndex(keyPart, " ")+1:]
+		case strings.HasPrefix(line, `Good "git" signature with `):
+			// Valid signature, but the key is not in the allowed signers file
+			sig.Status = "U"
+			sig.Key = line[strings.LastIndex(line, " ")+1:]
+		case strings.Contains(line, "no signature found"):
+			sig.Status = "N"
+		case strings.Contains(line, "allowedSignersFile needs to be configured"):
+			sig.Status = "E"
+			sig.Detail = "set gpg.ssh.allowedSignersFile to check SSH signatures"
+		case strings.Contains(line, "Signature verification failed"):
+			sig.Status = "B"
+		}
+	}
+
+	// GnuPG reports a good signature from a key nobody vouched for as GOODSIG
+	if sig.Status == "G" && (sig.Trust == "undefined" || sig.Trust == "never") {
+		sig.Status = "U"
+	}
+	if sig.Status == "" {
+		sig.Status = "N"
+		if err != nil && strings.TrimSpace(output) != "" {
+			sig.Status = "E"
+			sig.Detail = lastLine(output)
+		}
+	}
+	return sig
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotRefs holds one ref per snapshot, named by its Unix time in
+// milliseconds: out of the stash list, yet reachable so gc keeps them until
+// they are pruned
+const snapshotRefs = "refs/gitty/snapshots/"
+
+// Snapshot is a recorded copy of the dirty worktree and index
+type Snapshot struct {
+	Ref     string
+	Hash    string
+	Message string // "WIP on main: abc1234 subject", as git stash writes it
+	Time    time.Time
+}
+
+// CreateSnapshot records tracked changes in the worktree and index as a
+// stash-like commit, without touching the stash list or any file, and keeps
+// the newest keep snapshots. created is false when there is nothing to
+// record or nothing changed since the last snapshot.
+func CreateSnapshot(repoPath string, keep int) (snap Snapshot, created bool, err error) {
+	output, err := Execute(repoPath, "stash", "create")
+	if err != nil {
+		return snap, false, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	hash := strings.TrimSpace(string(output))
+	if hash == "" {
+		return snap, false, nil
+	}
+
+	snapshots := GetSnapshots(repoPath)
+	if len(snapshots) > 0 && treeOf(repoPath, snapshots[0].Hash) == treeOf(repoPath, hash) {
+		return snapshots[0], false, nil
+	}
+
+	now := time.Now()
+	snap = Snapshot{
+		Ref:  snapshotRefs + strconv.FormatInt(now.UnixMilli(), 10),
+		Hash: hash,
+		Time: now,
+	}
+	if output, err := command(repoPath, "update-ref", snap.Ref, hash).CombinedOutput(); err != nil {
+		return snap, false, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+
+	if keep > 0 && len(snapshots)+1 > keep {
+		for _, old := range snapshots[keep-1:] {
+			DeleteSnapshot(repoPath, old)
+		}
+	}
+	return snap, true, nil
+}
+
+// GetSnapshots lists snapshots, newest first
+func GetSnapshots(repoPath string) []Snapshot {
+	output, err := command(repoPath, "for-each-ref", "--sort=-refname",
+		"--format=%(refname)%1f%(objectname)%1f%(subject)", snapshotRefs).Output()
+	if err != nil {
+		return nil
+	}
+
+	var snapshots []Snapshot
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.Split(line, fieldSep)
+		if len(parts) < 3 {
+			continue
+		}
+		millis, _ := strconv.ParseInt(strings.TrimPrefix(parts[0], snapshotRefs), 10, 64)
+		snapshots = append(snapshots, Snapshot{
+			Ref:     parts[0],
+			Hash:    parts[1],
+			Message: parts[2],
+			Time:    time.UnixMilli(millis),
+		})
+	}
+	return snapshots
+}
+
+// SnapshotDiff shows what a snapshot changed relative to the commit it was
+// taken on
+func SnapshotDiff(repoPath string, snap Snapshot) string {
+	output, _ := command(repoPath, "diff", "--textconv", snap.Hash+"^1", snap.Hash).Output()
+	return string(output)
+}
+
+// RestoreSnapshot applies a snapshot's changes to the worktree, merging
+// them if HEAD has moved since
+func RestoreSnapshot(repoPath string, snap Snapshot) error {
+	output, err := Execute(repoPath, "stash", "apply", snap.Hash)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteSnapshot removes a snapshot's ref
+func DeleteSnapshot(repoPath string, snap Snapshot) error {
+	output, err := command(repoPath, "update-ref", "-d", snap.Ref).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func treeOf(repoPath, commit string) string {
+	output, _ := command(repoPath, "rev-parse", commit+"^{tree}").Output()
+	return strings.TrimSpace(string(output))
+}
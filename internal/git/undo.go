@@ -0,0 +1,244 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PushUndo describes the last push of the current branch, as recorded in the
+// reflog of its remote-tracking ref
+type PushUndo struct {
+	Branch       string   // local branch
+	Remote       string   // remote name, e.g. origin
+	RemoteBranch string   // branch name on the remote
+	TrackingRef  string   // full remote-tracking ref, e.g. refs/remotes/origin/main
+	From         string   // remote state before the last update
+	To           string   // remote state after it
+	Commits      []Commit // commits in From..To, newest first
+	ByPush       bool     // the last update was a push (not a fetch)
+	LocalAtTo    bool     // local branch still points at To
+}
+
+// Tracking returns the remote-tracking ref name, e.g. origin/main
+func (p PushUndo) Tracking() string {
+	return shortRef(p.TrackingRef)
+}
+
+// GetLastPush finds the range pushed by the most recent update of the
+// current branch's upstream
+func GetLastPush(repoPath string) (PushUndo, error) {
+	var undo PushUndo
+	undo.Branch = GetBranchName(repoPath)
+
+	// The remote and its branch come from the branch's config rather than
+	// the short upstream name, which can't be split when the remote's name
+	// has a slash in it
+	output, err := Execute(repoPath, "rev-parse", "--symbolic-full-name", "@{u}")
+	if err != nil || undo.Branch == "" {
+		return undo, fmt.Errorf("branch %s has no upstream", undo.Branch)
+	}
+	undo.TrackingRef = strings.TrimSpace(string(output))
+	undo.Remote = GetConfigValue(repoPath, "branch."+undo.Branch+".remote")
+	undo.RemoteBranch = strings.TrimPrefix(GetConfigValue(repoPath, "branch."+undo.Branch+".merge"), "refs/heads/")
+	upstream := undo.Tracking()
+	if undo.Remote == "" || undo.Remote == "." || undo.RemoteBranch == "" || !strings.HasPrefix(undo.TrackingRef, "refs/remotes/") {
+		return undo, fmt.Errorf("upstream %s is not a remote branch", upstream)
+	}
+
+	output, err = Execute(repoPath, "reflog", "show", "-n", "2", "--format=%H%x09%gs", undo.TrackingRef)
+	if err != nil {
+		return undo, fmt.Errorf("no reflog for %s", upstream)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 2 {
+		return undo, fmt.Errorf("reflog of %s has no earlier state to go back to", upstream)
+	}
+
+	to, subject, _ := strings.Cut(lines[0], "\t")
+	from, _, _ := strings.Cut(lines[1], "\t")
+	undo.To, undo.From = to, from
+	undo.ByPush = strings.Contains(subject, "push")
+
+	if head, err := Execute(repoPath, "rev-parse", "HEAD"); err == nil {
+		undo.LocalAtTo = strings.TrimSpace(string(head)) == to
+	}
+
+	undo.Commits = commitRange(repoPath, from+".."+to)
+	if len(undo.Commits) == 0 {
+		return undo, fmt.Errorf("last update of %s did not add commits (was it a force-push?)", upstream)
+	}
+	return undo, nil
+}
+
+// RevertPush creates revert commits for the pushed range, newest first
+func RevertPush(repoPath string, undo PushUndo) error {
+	output, err := Execute(repoPath, "revert", "--no-edit", undo.From+".."+undo.To)
+	if err != nil {
+		RevertAbort(repoPath)
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ForcePushPrevious moves the remote branch back to its state before the last
+// push. --force-with-lease refuses if someone pushed on top in the meantime.
+func ForcePushPrevious(repoPath string, undo PushUndo) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", undo.RemoteBranch, undo.To)
+	refspec := fmt.Sprintf("%s:refs/heads/%s", undo.From, undo.RemoteBranch)
+	output, err := Execute(repoPath, "push", lease, undo.Remote, refspec)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ReflogPoint is a HEAD reflog entry
+type ReflogPoint struct {
+	Hash    string
+	Subject string
+	Time    time.Time
+}
+
+// TimeRestore previews resetting HEAD to where it was at a point in time
+type TimeRestore struct {
+	Target   ReflogPoint
+	Undone   []Commit // commits reachable now but not then
+	Restored []Commit // commits reachable then but not now
+	DiffStat string
+}
+
+// FindReflogAt returns the HEAD reflog entry that was current at the given time
+func FindReflogAt(repoPath string, at time.Time) (ReflogPoint, error) {
+	output, err := Execute(repoPath, "log", "-g", "--date=unix", "--format=%H%x09%gd%x09%gs", "HEAD")
+	if err != nil {
+		return ReflogPoint{}, fmt.Errorf("cannot read reflog: %s", strings.TrimSpace(string(output)))
+	}
+
+	var oldest ReflogPoint
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		// Selector looks like HEAD@{1700000000}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(parts[1], "HEAD@{"), "}")
+		secs, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		point := ReflogPoint{Hash: parts[0], Subject: parts[2], Time: time.Unix(secs, 0)}
+		if !point.Time.After(at) {
+			return point, nil
+		}
+		oldest = point
+	}
+
+	if oldest.Hash == "" {
+		return oldest, fmt.Errorf("reflog is empty")
+	}
+	return oldest, fmt.Errorf("reflog only goes back to %s", oldest.Time.Format("2006-01-02 15:04"))
+}
+
+// PreviewTimeRestore describes what resetting to target would change
+func PreviewTimeRestore(repoPath string, target ReflogPoint) TimeRestore {
+	restore := TimeRestore{Target: target}
+	restore.Undone = commitRange(repoPath, target.Hash+"..HEAD")
+	restore.Restored = commitRange(repoPath, "HEAD.."+target.Hash)
+	if output, err := Execute(repoPath, "diff", "--stat", "HEAD", target.Hash); err == nil {
+		restore.DiffStat = strings.TrimRight(string(output), "\n")
+	}
+	return restore
+}
+
+func commitRange(repoPath, revRange string) []Commit {
+	commits, _ := logCommits(repoPath, "log", revRange)
+	return commits
+}
+
+// RestoreToPoint saves the current HEAD on a backup branch, then resets to
+// target. --keep refuses rather than overwrite uncommitted changes.
+func RestoreToPoint(repoPath string, target ReflogPoint) (string, error) {
+	backup := "gitty-backup/" + time.Now().Format("20060102-150405")
+	if output, err := Execute(repoPath, "branch", backup, "HEAD"); err != nil {
+		return "", fmt.Errorf("backup branch failed: %s", strings.TrimSpace(string(output)))
+	}
+	if output, err := Execute(repoPath, "reset", "--keep", target.Hash); err != nil {
+		return backup, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return backup, nil
+}
+
+// LostCommit is a commit no branch or tag points to any more
+type LostCommit struct {
+	Commit
+	FullHash string
+	Source   string // "reflog" (still in HEAD's reflog) or "dangling"
+	Time     time.Time
+}
+
+// FindLostCommits lists dangling commits found by fsck, newest first.
+// Reflogs are ignored during the scan so commits only kept alive by them
+// (e.g. after a reset) are included.
+func FindLostCommits(repoPath string) ([]LostCommit, error) {
+	output, err := Execute(repoPath, "fsck", "--no-reflogs", "--dangling", "--no-progress")
+	if err != nil && len(output) == 0 {
+		return nil, fmt.Errorf("fsck failed: %v", err)
+	}
+
+	var hashes []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if hash, ok := strings.CutPrefix(line, "dangling commit "); ok {
+			hashes = append(hashes, strings.TrimSpace(hash))
+		}
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	inReflog := make(map[string]bool)
+	if reflog, err := Execute(repoPath, "log", "-g", "--format=%H", "HEAD"); err == nil {
+		for _, hash := range strings.Split(string(reflog), "\n") {
+			inReflog[hash] = true
+		}
+	}
+
+	args := append([]string{"show", "-s", "--format=%H%x09%h%x09%ct%x09%an%x09%ar%x09%s"}, hashes...)
+	output, err = Execute(repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+
+	var lost []LostCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 6)
+		if len(parts) < 6 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(parts[2], 10, 64)
+		source := "dangling"
+		if inReflog[parts[0]] {
+			source = "reflog"
+		}
+		lost = append(lost, LostCommit{
+			Commit:   Commit{Hash: parts[1], Author: parts[3], Date: parts[4], Message: parts[5]},
+			FullHash: parts[0],
+			Source:   source,
+			Time:     time.Unix(secs, 0),
+		})
+	}
+
+	sort.Slice(lost, func(i, j int) bool { return lost[i].Time.After(lost[j].Time) })
+	return lost, nil
+}
+
+// RecoverToBranch creates a branch pointing at a lost commit
+func RecoverToBranch(repoPath, name, hash string) error {
+	output, err := Execute(repoPath, "branch", name, hash)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
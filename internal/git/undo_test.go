@@ -0,0 +1,39 @@
+package git
+
+import "testing"
+
+func TestGetLastPushRemoteWithSlash(t *testing.T) {
+	repo := testRepo(t)
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q", "--bare")
+	runGit(t, repo, "remote", "add", "team/origin", remote)
+
+	writeFile(t, repo, "file.txt", "one\n")
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "one")
+	runGit(t, repo, "push", "-q", "-u", "team/origin", "main:shared")
+	writeFile(t, repo, "file.txt", "two\n")
+	runGit(t, repo, "commit", "-q", "-am", "two")
+	runGit(t, repo, "push", "-q", "team/origin", "HEAD:shared")
+
+	undo, err := GetLastPush(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Remote != "team/origin" || undo.RemoteBranch != "shared" {
+		t.Errorf("remote %q branch %q, want team/origin and shared", undo.Remote, undo.RemoteBranch)
+	}
+	if got := undo.Tracking(); got != "team/origin/shared" {
+		t.Errorf("Tracking() = %q, want team/origin/shared", got)
+	}
+	if len(undo.Commits) != 1 || undo.Commits[0].Message != "two" || !undo.ByPush || !undo.LocalAtTo {
+		t.Errorf("undo = %+v, want the push of commit two", undo)
+	}
+
+	if err := ForcePushPrevious(repo, undo); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := runGit(t, remote, "rev-parse", "shared"), undo.From; got != want {
+		t.Errorf("remote shared at %s, want %s", got, want)
+	}
+}
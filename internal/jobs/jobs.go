@@ -0,0 +1,175 @@
+// Package jobs tracks background git operations. Reads run concurrently;
+// jobs that change the repository (commit, push, pull, fetch...) wait for
+// the earlier ones in the order they were queued.
+package jobs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State of a job
+type State int
+
+const (
+	Queued State = iota
+	Running
+	Done
+	Failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Queued:
+		return "queued"
+	case Running:
+		return "running"
+	case Done:
+		return "done"
+	default:
+		return "failed"
+	}
+}
+
+// Job is a snapshot of one queued, running or finished operation
+type Job struct {
+	ID       int
+	Name     string
+	Write    bool
+	State    State
+	Err      string
+	Queued   time.Time
+	Started  time.Time
+	Finished time.Time
+}
+
+// Active reports whether the job has not finished yet
+func (j Job) Active() bool {
+	return j.State == Queued || j.State == Running
+}
+
+// Elapsed is how long the job ran, or has been running
+func (j Job) Elapsed() time.Duration {
+	switch {
+	case j.Started.IsZero():
+		return 0
+	case j.Finished.IsZero():
+		return time.Since(j.Started)
+	default:
+		return j.Finished.Sub(j.Started)
+	}
+}
+
+// keepFinished is how many finished jobs stay listed
+const keepFinished = 10
+
+// Queue runs and records jobs. It is safe for concurrent use; copies of a
+// model share one Queue through its pointer.
+type Queue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	jobs []*Job
+	next int
+
+	// Write jobs take tickets and run strictly in ticket order
+	tickets int
+	serving int
+}
+
+func New() *Queue {
+	q := &Queue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Do runs fn as a job named name and records its outcome. A write job first
+// waits for every write job queued before it. If fn panics the job fails and
+// the queue moves on before the panic carries on up.
+func (q *Queue) Do(name string, write bool, fn func() error) (err error) {
+	q.mu.Lock()
+	q.next++
+	job := &Job{ID: q.next, Name: name, Write: write, State: Queued, Queued: time.Now()}
+	q.jobs = append(q.jobs, job)
+	ticket := 0
+	if write {
+		ticket = q.tickets
+		q.tickets++
+		for q.serving != ticket {
+			q.cond.Wait()
+		}
+	}
+	job.State = Running
+	job.Started = time.Now()
+	q.mu.Unlock()
+
+	defer func() {
+		r := recover()
+		q.mu.Lock()
+		job.Finished = time.Now()
+		job.State = Done
+		switch {
+		case r != nil:
+			job.State = Failed
+			job.Err = fmt.Sprint("panic: ", r)
+		case err != nil:
+			job.State = Failed
+			job.Err = err.Error()
+		}
+		if write {
+			q.serving++
+			q.cond.Broadcast()
+		}
+		q.trim()
+		q.mu.Unlock()
+		if r != nil {
+			panic(r)
+		}
+	}()
+	return fn()
+}
+
+// trim drops the oldest finished jobs beyond keepFinished
+func (q *Queue) trim() {
+	finished := 0
+	for _, job := range q.jobs {
+		if job.State == Done || job.State == Failed {
+			finished++
+		}
+	}
+	kept := q.jobs[:0]
+	for _, job := range q.jobs {
+		if finished > keepFinished && (job.State == Done || job.State == Failed) {
+			finished--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	q.jobs = kept
+}
+
+// Jobs returns a snapshot of all listed jobs, oldest first
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, len(q.jobs))
+	for i, job := range q.jobs {
+		jobs[i] = *job
+	}
+	return jobs
+}
+
+// Counts returns how many jobs are running and how many are waiting
+func (q *Queue) Counts() (running, queued int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		switch job.State {
+		case Running:
+			running++
+		case Queued:
+			queued++
+		}
+	}
+	return running, queued
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoPanicReleasesQueue(t *testing.T) {
+	q := New()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Do swallowed the panic")
+			}
+		}()
+		q.Do("boom", true, func() error { panic("boom") })
+	}()
+
+	done := make(chan error)
+	go func() { done <- q.Do("next", true, func() error { return nil }) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("next write job: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("write job queued after a panic never ran")
+	}
+
+	if jobs := q.Jobs(); len(jobs) != 2 || jobs[0].State != Failed || jobs[0].Err != "panic: boom" {
+		t.Errorf("jobs = %+v, want the panicked one failed", jobs)
+	}
+}
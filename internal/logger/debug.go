@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+var (
+	debugLog  *slog.Logger // nil unless debug mode is on
+	debugFile *os.File
+)
+
+// DebugPath is where --debug writes its records unless told otherwise:
+// debug.log next to gitty.log
+func DebugPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "gitty", "debug.log"), nil
+}
+
+// InitDebug turns on debug mode, appending one JSON record per line to path
+func InitDebug(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot create log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open debug log: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	debugFile = file
+	debugLog = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return nil
+}
+
+// Debugging reports whether debug mode is on
+func Debugging() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return debugLog != nil
+}
+
+// Debug writes a structured record in debug mode; args are slog key-value
+// pairs. It does nothing otherwise.
+func Debug(msg string, args ...any) {
+	mu.Lock()
+	logger := debugLog
+	mu.Unlock()
+	if logger != nil {
+		logger.Debug(msg, args...)
+	}
+}
+
+func closeDebug() {
+	if debugFile != nil {
+		debugFile.Close()
+		debugFile = nil
+		debugLog = nil
+	}
+}
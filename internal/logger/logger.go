@@ -53,6 +53,7 @@ func Close() {
 		logFile.Close()
 		logFile = nil
 	}
+	closeDebug()
 }
 
 func Disable() {
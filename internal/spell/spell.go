@@ -0,0 +1,326 @@
+// Package spell is a small dictionary-based spell checker for commit messages.
+//
+// It favours silence over noise: a word is only reported when it is unknown
+// AND close to a known word, and anything that looks like an identifier
+// (camelCase, snake_case, paths, numbers) is skipped.
+package spell
+
+import (
+	_ "embed"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed words.txt
+var builtinWords string
+
+// DictionaryFile is the per-repo custom dictionary, one word per line. It
+// lives in the repository's git directory, so it is never committed by
+// accident; a committed TeamDictionaryFile at the top of the work tree is
+// read as well.
+const (
+	DictionaryFile     = "gitty-dictionary"
+	TeamDictionaryFile = ".gitty-dictionary"
+)
+
+// systemWordLists are loaded when present to widen the built-in list
+var systemWordLists = []string{
+	"/usr/share/dict/words",
+	"/usr/dict/words",
+}
+
+// Checker holds the set of known words: the shared built-in and system
+// lists, and the words added to this checker
+type Checker struct {
+	base  map[string]bool
+	words map[string]bool
+}
+
+// Issue is a suspect word in the checked text
+type Issue struct {
+	Word        string
+	Start       int // byte offsets into the checked text
+	End         int
+	Suggestions []string
+}
+
+var (
+	baseOnce  sync.Once
+	baseWords map[string]bool
+)
+
+// sharedWords reads the built-in and system word lists once per process;
+// the map is never written after that
+func sharedWords() map[string]bool {
+	baseOnce.Do(func() {
+		c := &Checker{words: make(map[string]bool)}
+		c.addText(builtinWords)
+		for _, path := range systemWordLists {
+			if data, err := os.ReadFile(path); err == nil {
+				c.addText(string(data))
+			}
+		}
+		baseWords = c.words
+	})
+	return baseWords
+}
+
+// New builds a checker from the built-in list, any system word list, and the
+// given extra dictionary files (missing files are ignored)
+func New(extraPaths ...string) *Checker {
+	c := &Checker{base: sharedWords(), words: make(map[string]bool)}
+	for _, path := range extraPaths {
+		if data, err := os.ReadFile(path); err == nil {
+			c.addText(string(data))
+		}
+	}
+	return c
+}
+
+// ForRepo builds a checker including the repo's custom dictionary (in
+// gitDir), its committed team dictionary and the user's global one
+// (~/.config/gitty/dictionary)
+func ForRepo(repoPath, gitDir string) *Checker {
+	paths := []string{RepoDictionary(gitDir), filepath.Join(repoPath, TeamDictionaryFile)}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "gitty", "dictionary"))
+	}
+	return New(paths...)
+}
+
+// RepoDictionary is the path of the custom dictionary of the repository
+// whose git directory is gitDir
+func RepoDictionary(gitDir string) string {
+	return filepath.Join(gitDir, DictionaryFile)
+}
+
+// known reports whether w, already lower case, is in the dictionary as is
+func (c *Checker) known(w string) bool {
+	return c.words[w] || c.base[w]
+}
+
+func (c *Checker) addText(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if w := strings.TrimSpace(line); w != "" && !strings.HasPrefix(w, "#") {
+			c.words[strings.ToLower(w)] = true
+		}
+	}
+}
+
+// Add marks words as known
+func (c *Checker) Add(words ...string) {
+	for _, w := range words {
+		c.words[strings.ToLower(w)] = true
+	}
+}
+
+// AddIdentifiers marks every word-like token in text (e.g. a diff or file
+// list) as known, so identifiers mentioned in the message are not flagged
+func (c *Checker) AddIdentifiers(text string) {
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(field) >= 3 {
+			c.words[strings.ToLower(field)] = true
+		}
+	}
+}
+
+// Known reports whether word (or a simple inflection of it) is in the dictionary
+func (c *Checker) Known(word string) bool {
+	w := strings.ToLower(strings.TrimSuffix(word, "'s"))
+	if c.known(w) {
+		return true
+	}
+
+	// Strip common inflections: fixes, fixed, fixing, quickly, renamed, copies...
+	for _, rule := range []struct{ suffix, replace string }{
+		{"ies", "y"}, {"ied", "y"}, {"es", ""}, {"s", ""}, {"ed", ""}, {"ed", "e"}, {"d", ""},
+		{"ing", ""}, {"ing", "e"}, {"ly", ""}, {"er", ""}, {"er", "e"}, {"ers", ""}, {"ers", "e"},
+	} {
+		stem, ok := strings.CutSuffix(w, rule.suffix)
+		if !ok || len(stem) < 2 {
+			continue
+		}
+		if c.known(stem + rule.replace) {
+			return true
+		}
+		// Doubled consonant: stopped -> stop, mapping -> map
+		if n := len(stem); rule.replace == "" && n >= 3 && stem[n-1] == stem[n-2] && c.known(stem[:n-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns suspect words in text
+func (c *Checker) Check(text string) []Issue {
+	var issues []Issue
+
+	start := -1
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != ' ' && text[i] != '\t' && text[i] != '\n' {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start == -1 {
+			continue
+		}
+		issues = append(issues, c.checkToken(text, start, i)...)
+		start = -1
+	}
+	return issues
+}
+
+// checkToken checks one whitespace-separated token text[start:end]
+func (c *Checker) checkToken(text string, start, end int) []Issue {
+	token := text[start:end]
+
+	// Trim surrounding punctuation
+	trimmed := strings.TrimLeft(token, "\"'([{")
+	start += len(token) - len(trimmed)
+	token = trimmed
+	token = strings.TrimRight(token, "\"'.,;:!?)]}")
+	end = start + len(token)
+
+	if !isPlainWord(token) {
+		return nil
+	}
+
+	var issues []Issue
+	offset := start
+	for _, part := range strings.Split(token, "-") {
+		if len(part) >= 3 && !c.Known(part) {
+			if suggestions := c.Suggest(part); len(suggestions) > 0 {
+				issues = append(issues, Issue{
+					Word:        part,
+					Start:       offset,
+					End:         offset + len(part),
+					Suggestions: suggestions,
+				})
+			}
+		}
+		offset += len(part) + 1
+	}
+	return issues
+}
+
+// isPlainWord filters out identifiers, paths, numbers and acronyms
+func isPlainWord(token string) bool {
+	if token == "" || strings.ContainsAny(token, "_./\\=<>[]{}()#@`:*+|~$%&0123456789") {
+		return false
+	}
+	for i, r := range token {
+		if !unicode.IsLetter(r) && r != '-' && r != '\'' {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			// camelCase / PascalCase beyond the first letter looks like an identifier
+			if i > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// Suggest returns up to three known words close to word, best first
+func (c *Checker) Suggest(word string) []string {
+	w := strings.ToLower(word)
+	seen := make(map[string]bool)
+	var found []string
+
+	collect := func(candidates []string) {
+		for _, cand := range candidates {
+			if c.known(cand) && !seen[cand] {
+				seen[cand] = true
+				found = append(found, cand)
+			}
+		}
+	}
+
+	first := edits(w)
+	collect(first)
+	if len(found) == 0 && len(w) <= 8 {
+		for _, e := range first {
+			collect(edits(e))
+			if len(found) >= 3 {
+				break
+			}
+		}
+	}
+
+	// Prefer candidates that keep the first letter and length
+	sort.SliceStable(found, func(i, j int) bool {
+		return score(w, found[i]) > score(w, found[j])
+	})
+	if len(found) > 3 {
+		found = found[:3]
+	}
+	return found
+}
+
+func score(word, cand string) int {
+	s := 0
+	if cand[0] == word[0] {
+		s += 2
+	}
+	if len(cand) == len(word) {
+		s++
+	}
+	return s
+}
+
+// edits returns all strings one deletion, transposition, replacement or insertion away
+func edits(w string) []string {
+	var out []string
+	for i := 0; i <= len(w); i++ {
+		left, right := w[:i], w[i:]
+		if right != "" {
+			out = append(out, left+right[1:])
+		}
+		if len(right) > 1 {
+			out = append(out, left+string(right[1])+string(right[0])+right[2:])
+		}
+		for _, ch := range alphabet {
+			if right != "" {
+				out = append(out, left+string(ch)+right[1:])
+			}
+			out = append(out, left+string(ch)+right)
+		}
+	}
+	return out
+}
+
+// AddToDictionary appends a word to a dictionary file, creating it if needed
+func AddToDictionary(path, word string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(strings.ToLower(word) + "\n")
+	return err
+}
+
+// ApplyFirst replaces the first issue in text with its best suggestion,
+// keeping the original capitalisation of the first letter
+func ApplyFirst(text string, issues []Issue) string {
+	if len(issues) == 0 || len(issues[0].Suggestions) == 0 {
+		return text
+	}
+	issue := issues[0]
+	fix := issue.Suggestions[0]
+	if r := rune(issue.Word[0]); unicode.IsUpper(r) {
+		fix = strings.ToUpper(fix[:1]) + fix[1:]
+	}
+	return text[:issue.Start] + fix + text[issue.End:]
+}
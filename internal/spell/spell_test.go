@@ -0,0 +1,41 @@
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestForRepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git")
+	if err := os.Mkdir(gitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToDictionary(RepoDictionary(gitDir), "Gittyfy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, TeamDictionaryFile), []byte("# team words\nfrobnicate\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := ForRepo(repo, gitDir)
+	for _, word := range []string{"gittyfy", "frobnicated", "commit"} {
+		if !checker.Known(word) {
+			t.Errorf("Known(%q) = false, want true", word)
+		}
+	}
+	if checker.Known("qzxv") {
+		t.Error(`Known("qzxv") = true, want false`)
+	}
+
+	// Words added to one checker stay out of the shared lists
+	checker.Add("onlyhere")
+	if ForRepo(repo, gitDir).Known("onlyhere") {
+		t.Error("a word added to one checker leaked into another")
+	}
+	if _, err := os.Stat(filepath.Join(repo, DictionaryFile)); !os.IsNotExist(err) {
+		t.Errorf("dictionary written to the work tree: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/LFroesch/gitty/internal/fixture"
+)
+
+var updateGolden = flag.Bool("update", false, "write the renders as the new golden files")
+
+// TestGolden renders goldenViews against fresh fixtures at every
+// goldenSizes and compares them with testdata/golden, so layout changes
+// show up as a diff. -update rewrites the files instead.
+func TestGolden(t *testing.T) {
+	if testing.Short() {
+		t.Skip("renders every view against fresh fixtures")
+	}
+	golden, err := filepath.Abs(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *updateGolden {
+		if err := os.MkdirAll(golden, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Render with default settings whatever the user's config says, and
+	// absolute dates in UTC wherever the machine is
+	work := t.TempDir()
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+	t.Setenv("HOME", filepath.Join(work, "home"))
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	// Registered so they are restored: one unset here, one set by RenderGolden
+	t.Setenv("XDG_CONFIG_HOME", "")
+	os.Unsetenv("XDG_CONFIG_HOME")
+	t.Setenv("GIT_TEST_DATE_NOW", "")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	fixtures := filepath.Join(work, "fixtures")
+	for _, view := range goldenViews {
+		scenario, ok := fixture.Lookup(view.scenario)
+		if !ok {
+			t.Fatalf("%s: unknown scenario %q", view.name, view.scenario)
+		}
+		for _, size := range goldenSizes {
+			name := view.name + "-" + size.String() + ".txt"
+			// A fresh fixture for every render, as opening a view can change
+			// the repository (a refreshed index, a checked-out tool state)
+			repo, err := fixture.Make(fixtures, scenario)
+			if err == nil {
+				err = os.Chdir(repo)
+			}
+			var got string
+			if err == nil {
+				got, err = renderGolden(view, size, fixture.Now)
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if overflow := overflows(got, size); overflow != "" {
+				t.Logf("OVERFLOW %s: %s", name, overflow)
+			}
+
+			path := filepath.Join(golden, name)
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := os.ReadFile(path)
+			switch {
+			case err != nil:
+				t.Errorf("%s is missing; run go test -run TestGolden ./internal/ui -update", name)
+			case string(want) != got:
+				t.Errorf("%s changed; if intended, run go test -run TestGolden ./internal/ui -update\n%s", name, firstDifference(string(want), got))
+			}
+		}
+	}
+}
+
+// overflows says how a render spills out of its terminal, "" if it fits.
+// Spills are logged rather than failed, as the golden file records them.
+func overflows(render string, size goldenSize) string {
+	var problems []string
+	lines := strings.Split(strings.TrimSuffix(render, "\n"), "\n")
+	if len(lines) > size.height {
+		problems = append(problems, fmt.Sprintf("%d lines", len(lines)))
+	}
+	for i, line := range lines {
+		if width := lipgloss.Width(line); width > size.width {
+			problems = append(problems, fmt.Sprintf("line %d is %d wide", i+1, width))
+			break
+		}
+	}
+	return strings.Join(problems, ", ")
+}
+
+// firstDifference shows the first line where a render departs from its
+// golden file
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("  line %d\n  - %s\n  + %s\n", i+1, w, g)
+		}
+	}
+	return ""
+}
+
+// goldenView is a screen whose layout is checked against golden files: the
+// fixture repository it opens and the keys pressed to get there
+type goldenView struct {
+	name     string
+	scenario string // fixture.Scenario name
+	keys     []string
+}
+
+// goldenViews covers every tab and the modes with the most layout to get
+// wrong
+var goldenViews = []goldenView{
+	{name: "workspace-renames", scenario: "renames"},
+	{name: "diff-renames", scenario: "renames", keys: []string{"enter"}},
+	{name: "workspace-detached", scenario: "detached"},
+	{name: "workspace-submodules", scenario: "submodules"},
+	{name: "conflicts", scenario: "conflict", keys: []string{"c"}},
+	{name: "commit", scenario: "renames", keys: []string{"2"}},
+	{name: "branches", scenario: "diverged", keys: []string{"3"}},
+	{name: "tools", scenario: "diverged", keys: []string{"4"}},
+	{name: "tools-scrolled", scenario: "diverged", keys: append([]string{"4"}, slices.Repeat([]string{"j"}, 21)...)},
+	{name: "log", scenario: "diverged", keys: []string{"4", "o"}},
+	{name: "history", scenario: "diverged", keys: []string{"4", "h"}},
+	{name: "history-absolute", scenario: "rebase", keys: []string{"4", "h", "d"}},
+	{name: "undo", scenario: "diverged", keys: []string{"4", "u"}},
+	{name: "compare-upstream", scenario: "diverged", keys: []string{"3", "j", "u"}},
+	{name: "tags", scenario: "detached", keys: []string{"4", "t"}},
+	{name: "rebase-stopped", scenario: "rebase", keys: []string{"4", "r"}},
+}
+
+// goldenSize is a terminal size views are rendered at
+type goldenSize struct {
+	width, height int
+}
+
+func (s goldenSize) String() string {
+	return fmt.Sprintf("%dx%d", s.width, s.height)
+}
+
+// goldenSizes are a small terminal (the compact layout), the classic 80x24,
+// a roomy window and a wide one
+var goldenSizes = []goldenSize{{60, 18}, {80, 24}, {120, 40}, {200, 50}}
+
+// goldenMessages bounds the messages one render may take to settle, in case
+// something keeps scheduling itself
+const goldenMessages = 1000
+
+// renderGolden opens the repository in the working directory as gitty
+// would, presses view's keys and renders the screen at size. Colour is off,
+// the clock (git's too) is pinned to now and trailing spaces are trimmed,
+// so the same tree renders the same text every time.
+func renderGolden(view goldenView, size goldenSize, now time.Time) (string, error) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	lipgloss.SetHasDarkBackground(true)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	os.Setenv("GIT_TEST_DATE_NOW", strconv.FormatInt(now.Unix(), 10))
+
+	m := initialModel()
+	m.touring = false
+	m.config.Refresh.PollSeconds = 0
+	m.config.Snapshot.IntervalMinutes = 0
+
+	next, err := settle(m, func() tea.Msg { return tea.WindowSizeMsg{Width: size.width, Height: size.height} })
+	if err != nil {
+		return "", err
+	}
+	if next, err = settle(next, next.Init()); err != nil {
+		return "", err
+	}
+	for _, key := range view.keys {
+		result, cmd := next.update(goldenKey(key))
+		if next, err = settle(result.(model), cmd); err != nil {
+			return "", fmt.Errorf("%s: key %q: %w", view.name, key, err)
+		}
+	}
+
+	lines := strings.Split(next.View(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// settle runs cmd and everything it leads to, one message at a time as
+// the program would, skipping timers: spinner frames, polls and status
+// expiry. It calls update rather than Update, which only adds timers.
+func settle(m model, cmd tea.Cmd) (model, error) {
+	queue := []tea.Cmd{cmd}
+	for handled := 0; len(queue) > 0; handled++ {
+		if handled > goldenMessages {
+			return m, fmt.Errorf("still busy after %d messages", goldenMessages)
+		}
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		msg := cmd()
+		switch msg := msg.(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+			continue
+		case spinner.TickMsg, jobsTickMsg, repoStampMsg, snapshotTickMsg, statusExpiredMsg, keysFadeMsg:
+			continue
+		}
+		// tea.Sequence's message is unexported; it is a list of commands
+		if value := reflect.ValueOf(msg); value.Kind() == reflect.Slice && value.Type().Elem() == reflect.TypeOf(tea.Cmd(nil)) {
+			for i := 0; i < value.Len(); i++ {
+				queue = append(queue, value.Index(i).Interface().(tea.Cmd))
+			}
+			continue
+		}
+		result, next := m.update(msg)
+		m = result.(model)
+		queue = append(queue, next)
+	}
+	return m, nil
+}
+
+// goldenKey turns a key name as the keymap writes it into a key press
+func goldenKey(key string) tea.KeyMsg {
+	special := map[string]tea.KeyType{
+		"enter": tea.KeyEnter, "esc": tea.KeyEsc, "tab": tea.KeyTab,
+		"up": tea.KeyUp, "down": tea.KeyDown, "left": tea.KeyLeft, "right": tea.KeyRight,
+	}
+	if keyType, ok := special[key]; ok {
+		return tea.KeyMsg{Type: keyType}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
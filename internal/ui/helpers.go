@@ -0,0 +1,3214 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"github.com/LFroesch/gitty/internal/crash"
+	"github.com/LFroesch/gitty/internal/forge"
+	"github.com/LFroesch/gitty/internal/git"
+	"github.com/LFroesch/gitty/internal/spell"
+	"github.com/LFroesch/gitty/suggest"
+)
+
+// Data loading commands
+
+func (m model) loadGitChanges() tea.Cmd {
+	return func() tea.Msg {
+		changes := m.repo.Changes()
+		return gitChangesMsg(changes)
+	}
+}
+
+func (m model) loadGitStatus() tea.Cmd {
+	return func() tea.Msg {
+		status := m.repo.Status()
+		return gitStatusMsg(status)
+	}
+}
+
+// withLoading shows a pane as loading until load's result is handled. The
+// started message is sequenced first so it cannot arrive after the result.
+func withLoading(pane string, load tea.Cmd) tea.Cmd {
+	return tea.Sequence(func() tea.Msg { return loadingMsg(pane) }, load)
+}
+
+// runJob runs cmd on the job queue under name. Write jobs (anything that
+// changes the repository) run one at a time in the order they were started;
+// reads run alongside them.
+func (m model) runJob(name string, write bool, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		var msg tea.Msg
+		m.jobs.Do(name, write, func() error {
+			// Another program's index.lock: ask rather than fail halfway
+			if write {
+				var lockErr *git.LockError
+				if err := git.WaitForIndexLock(m.repoPath, git.LockWait); errors.As(err, &lockErr) {
+					msg = indexLockMsg{lock: &lockErr.Lock, op: name, retry: m.runJob(name, write, cmd)}
+					return err
+				}
+			}
+			msg = cmd()
+			return jobError(msg)
+		})
+		return msg
+	}
+}
+
+// guard runs cmd, and every command of a batch or sequence it returns,
+// under reportPanic. Bubble Tea recovers panics in commands itself, so
+// without it a crashing loader would leave no report.
+func (m model) guard(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer m.reportPanic()
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = m.guard(c)
+			}
+			return guarded
+		}
+		// A sequence's message is unexported, but is a list of commands
+		if value := reflect.ValueOf(msg); value.Kind() == reflect.Slice && value.Type().Elem() == reflect.TypeOf(tea.Cmd(nil)) {
+			guarded := make([]tea.Cmd, value.Len())
+			for i := range guarded {
+				guarded[i] = m.guard(value.Index(i).Interface().(tea.Cmd))
+			}
+			return tea.Sequence(guarded...)()
+		}
+		return msg
+	}
+}
+
+// reportPanic writes a crash report for a panic under way, then lets it
+// carry on to Bubble Tea, which restores the terminal. Deferred directly,
+// as recover only works there.
+func (m model) reportPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	view := m.viewMode
+	if m.tab == "tools" {
+		view = m.toolMode
+	}
+	report := crash.Report{
+		Time:      time.Now(),
+		Repo:      m.repoPath,
+		Tab:       m.tab,
+		View:      view,
+		Operation: m.gitState.Operation,
+		Panic:     fmt.Sprint(r),
+		Stack:     string(debug.Stack()),
+	}
+	for _, entry := range m.statusLog {
+		report.Messages = append(report.Messages, entry.at.Format("15:04:05")+" "+entry.message)
+	}
+	for _, job := range m.jobs.Jobs() {
+		line := fmt.Sprintf("%s %s %s (%s)", job.Queued.Format("15:04:05"), job.Name, job.State, job.Elapsed().Round(time.Millisecond))
+		if job.Err != "" {
+			line += ": " + job.Err
+		}
+		report.Jobs = append(report.Jobs, line)
+	}
+	crash.Write(report)
+	panic(r)
+}
+
+// offerCrashRecovery picks up a crash report left by the last session in
+// this repository and offers, on the status line, to resume where it was
+func (m *model) offerCrashRecovery() {
+	report, ok := crash.TakePending(m.repoPath)
+	if !ok {
+		return
+	}
+	m.crashOffer = &report
+	where := "the " + report.Tab + " tab"
+	if op := git.GetOperationInProgress(m.repoPath); op != "" {
+		where = "the " + op + " still in progress"
+	}
+	m.statusMessage = fmt.Sprintf("gitty crashed last time (report: %s) - enter resumes %s", report.Path, where)
+}
+
+// resumeAfterCrash returns to what the crashed session was doing: an
+// operation still in progress opens in the conflicts view, where it can be
+// continued or aborted; otherwise the tab and tool it had open
+func (m model) resumeAfterCrash(report crash.Report) (tea.Model, tea.Cmd) {
+	if op := git.GetOperationInProgress(m.repoPath); op != "" {
+		m.statusMessage = "Resumed the " + op
+		return m, func() tea.Msg { return showConflictsMsg{} }
+	}
+	switch report.Tab {
+	case "commit", "branches", "prs":
+		m.tab = report.Tab
+	case "tools":
+		m.tab = "tools"
+		m.toolMode = report.View
+		if m.toolMode == "" {
+			m.toolMode = "menu"
+		}
+	}
+	m.statusMessage = "Resumed on the " + m.tab + " tab"
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+	return m, tea.Batch(m.loadBranches(), m.reloadTool())
+}
+
+// checkIndexLock looks for an index.lock after a write failed on one
+func (m model) checkIndexLock() tea.Cmd {
+	return func() tea.Msg {
+		return indexLockMsg{lock: git.GetIndexLock(m.repoPath)}
+	}
+}
+
+// lockPatience is how long "wait" waits for another program to finish
+const lockPatience = 30 * time.Second
+
+func (m model) waitForIndexLock() tea.Cmd {
+	return withLoading("index-lock", func() tea.Msg {
+		return lockWaitDoneMsg{err: git.WaitForIndexLock(m.repoPath, lockPatience)}
+	})
+}
+
+func (m model) removeIndexLock(lock git.IndexLock) tea.Cmd {
+	return func() tea.Msg {
+		return lockRemovedMsg{err: git.RemoveIndexLock(lock)}
+	}
+}
+
+// jobError reads a failure out of a job's result message
+func jobError(msg tea.Msg) error {
+	switch msg := msg.(type) {
+	case statusMsg:
+		if strings.Contains(msg.message, "failed") {
+			return errors.New(msg.message)
+		}
+	case commitPushDoneMsg:
+		if result, ok := msg.result.(commitSuccessMsg); ok && result.pushErr != "" {
+			return errors.New("push failed: " + result.pushErr)
+		}
+		return jobError(msg.result)
+	}
+	return nil
+}
+
+func (m model) loadBranches() tea.Cmd {
+	return withLoading("branches", m.runJob("Load branches", false, m.readBranches))
+}
+
+func (m model) readBranches() tea.Msg {
+	branches := m.repo.Branches()
+	remoteBranches := m.repo.RemoteBranches()
+	all := append(branches, remoteBranches...)
+
+	base := m.config.Branches.Compare
+	if base == "" {
+		base = git.GetDefaultBranch(m.repoPath)
+	}
+	git.SetBaseAheadBehind(m.repoPath, base, all)
+	return branchesMsg{branches: all, base: base}
+}
+
+// refreshAll reloads the state every tab shows - status, changes, branches,
+// recent commits and conflicts - as one job under one indicator, plus the
+// tool left open on the tools tab
+func (m model) refreshAll() tea.Cmd {
+	refresh := withLoading("refresh", m.runJob("Refresh", false, func() tea.Msg {
+		return refreshMsg{
+			m.loadGitStatus()(),
+			m.loadGitChanges()(),
+			m.readBranches(),
+			m.loadRecentCommits()(),
+			m.loadConflicts()(),
+			m.loadOperation()(),
+		}
+	}))
+	if m.tab == "tools" {
+		return tea.Batch(refresh, m.reloadTool())
+	}
+	return refresh
+}
+
+func (m model) loadRecentCommits() tea.Cmd {
+	return func() tea.Msg {
+		commits := m.repo.Log(3)
+		return recentCommitsMsg(commits)
+	}
+}
+
+func (m model) loadCommitHistory() tea.Cmd {
+	return withLoading("history", m.runJob("Load history", false, func() tea.Msg {
+		commits := m.repo.Log(50)
+		return commitsMsg(commits)
+	}))
+}
+
+// loadHistory loads the history tool's branch: the current one, or another
+// opened from the branches tab, limited to one contributor's commits when
+// opened from the contributors tool
+func (m model) loadHistory() tea.Cmd {
+	if m.historyRef == "" && m.historyAuthor == "" {
+		return m.loadCommitHistory()
+	}
+	ref, author := m.historyRef, m.historyAuthor
+	return withLoading("history", m.runJob("Load history", false, func() tea.Msg {
+		if author != "" {
+			return commitsMsg(git.GetAuthorLog(m.repoPath, ref, author, 50))
+		}
+		return commitsMsg(git.GetBranchLog(m.repoPath, ref, 50))
+	}))
+}
+
+// reloadTool refreshes the data of the tool left open on the tools tab, for
+// coming back to it from another tab
+func (m model) reloadTool() tea.Cmd {
+	switch m.toolMode {
+	case "history":
+		return m.loadHistory()
+	case "undo":
+		return m.loadCommitHistory()
+	case "log":
+		return m.loadLogCommits(m.logSearch)
+	case "stash":
+		return m.loadStashList()
+	case "tags":
+		return m.loadTags()
+	case "contributors":
+		return m.loadContributors()
+	}
+	return nil
+}
+
+func contributorEmail(c git.Contributor) string { return c.Email }
+
+// relocate returns where the item at cursor in before sits in after, found
+// by key so reloads keep the same thing selected. When it is gone the cursor
+// stays put, clamped to after.
+func relocate[T any](before, after []T, cursor int, key func(T) string) int {
+	if cursor < len(before) {
+		selected := key(before[cursor])
+		if i := slices.IndexFunc(after, func(item T) bool { return key(item) == selected }); i >= 0 {
+			return i
+		}
+	}
+	return max(0, min(cursor, len(after)-1))
+}
+
+// Keys identifying list items for relocate. Stashes renumber as they are
+// dropped, so their message stands in for them.
+func changeFile(change git.Change) string           { return change.File }
+func conflictPath(conflict git.ConflictFile) string { return conflict.Path }
+func branchName(branch git.Branch) string           { return branch.Name }
+func commitHash(commit git.Commit) string           { return commit.Hash }
+func stashMessage(stash git.Stash) string           { return stash.Message }
+func tagName(tag git.Tag) string                    { return tag.Name }
+
+func (m model) loadConflicts() tea.Cmd {
+	return func() tea.Msg {
+		files := m.repo.ConflictFiles()
+		var conflicts []git.ConflictFile
+		for _, f := range files {
+			conflicts = append(conflicts, git.ConflictFile{Path: f, IsResolved: false})
+		}
+		return conflictsMsg(conflicts)
+	}
+}
+
+func (m model) loadFileDiff(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		var file git.Change
+		for _, change := range m.changes {
+			if change.File == filePath {
+				file = change
+			}
+		}
+
+		staged := git.IsFileStaged(m.repoPath, filePath)
+		diff := git.GetFileDiff(m.repoPath, filePath, file.OrigPath, staged, m.diffOptions)
+		attr := git.GetDiffAttr(m.repoPath, filePath)
+		if git.IsBinaryDiff(diff) {
+			before, after := git.GetBinaryVersions(m.repoPath, filePath, file.OrigPath, staged)
+			diff = strings.TrimRight(diff, "\n") + "\n\n" + git.FormatBinarySummary(before, after, attr == "unset")
+		} else if attr != "" && attr != "unset" && diff != "" {
+			if textconv := git.GetTextconv(m.repoPath, attr); textconv != "" {
+				diff = strings.TrimRight(diff, "\n") + "\n\n" + fmt.Sprintf("Converted to text by the %q diff driver (%s)", attr, textconv)
+			}
+		}
+		if !git.IsBinaryDiff(diff) && !utf8.ValidString(diff) {
+			diff = strings.TrimRight(diff, "\n") + "\n\nSome lines are not UTF-8; they are shown decoded as Windows-1252"
+		}
+		// Mode and symlink changes show little or nothing as a text diff
+		if mc := file.ModeChange(staged); mc.Changed() || mc.Symlink() {
+			before, after := git.GetSymlinkTargets(m.repoPath, filePath, mc, staged)
+			if summary := git.FormatModeSummary(mc, before, after, diff); summary != "" {
+				diff = strings.TrimRight(diff, "\n") + "\n\n" + summary
+			}
+		}
+		return diffMsg(diff)
+	}
+}
+
+// pollRepo schedules the next cheap check of the repository state. Only a
+// changed stamp triggers a status reload.
+func (m model) pollRepo() tea.Cmd {
+	interval := time.Duration(m.config.Refresh.PollSeconds) * time.Second
+	if interval <= 0 {
+		return nil
+	}
+	gitDir, commonDir := m.gitDir, m.commonDir
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return repoStampMsg(git.StateStamp(gitDir, commonDir))
+	})
+}
+
+// tickJobs redraws the jobs screen, whose states change off the message loop
+func (m model) tickJobs() tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(time.Time) tea.Msg {
+		return jobsTickMsg{}
+	})
+}
+
+func (m model) loadOperation() tea.Cmd {
+	return func() tea.Msg {
+		msg := operationMsg{op: git.GetOperationInProgress(m.repoPath)}
+		if progress, ok := git.GetRebaseProgress(m.repoPath); ok {
+			msg.rebase = &progress
+		}
+		return msg
+	}
+}
+
+func (m model) continueOperation() tea.Cmd {
+	return func() tea.Msg {
+		op := git.GetOperationInProgress(m.repoPath)
+		before, _ := git.GetRebaseProgress(m.repoPath)
+		err := git.ContinueOperation(m.repoPath, op)
+
+		// A rebase that moved on may have stopped again on a later commit
+		message := "Continued " + op
+		if err != nil {
+			message = fmt.Sprintf("Continue %s failed: %v", op, err)
+		}
+		if after, ok := git.GetRebaseProgress(m.repoPath); ok && (err == nil || after.Step != before.Step) {
+			message = fmt.Sprintf("Rebase stopped at %s - resolve it, then %s to continue",
+				describeRebaseStop(after), m.keys.keyFor(ctxConflicts, "continue"))
+		}
+		if err != nil {
+			return tea.Batch(
+				m.loadConflicts(),
+				m.loadOperation(),
+				func() tea.Msg { return statusMsg{message: message} },
+			)()
+		}
+		return tea.Batch(
+			m.loadConflicts(),
+			m.loadOperation(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg { return statusMsg{message: message} },
+		)()
+	}
+}
+
+// describeRebaseStop names the step a rebase stopped on, e.g. "step 2 of 5
+// (pick 1a2b3c4 Fix parser)"
+func describeRebaseStop(progress git.RebaseProgress) string {
+	where := fmt.Sprintf("step %d of %d", progress.Step, progress.Total)
+	if progress.Current.Action != "" {
+		where += fmt.Sprintf(" (%s)", progress.Current)
+	}
+	return where
+}
+
+func (m model) abortOperation() tea.Cmd {
+	return func() tea.Msg {
+		op := git.GetOperationInProgress(m.repoPath)
+		if err := git.AbortOperation(m.repoPath, op); err != nil {
+			return statusMsg{message: fmt.Sprintf("Abort %s failed: %v", op, err)}
+		}
+		return tea.Batch(
+			m.loadConflicts(),
+			m.loadOperation(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg { return statusMsg{message: "Aborted " + op} },
+		)()
+	}
+}
+
+func (m model) loadConflictDoc(path string) tea.Cmd {
+	return func() tea.Msg {
+		doc, err := git.ReadConflict(m.repoPath, path)
+		return conflictDocMsg{doc: doc, err: err}
+	}
+}
+
+func (m model) writeResolution(path, content string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.WriteResolution(m.repoPath, path, content); err != nil {
+			return statusMsg{message: fmt.Sprintf("Resolve failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadConflicts(),
+			m.loadOperation(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg { return statusMsg{message: "Resolved and staged " + path} },
+		)()
+	}
+}
+
+func (m model) loadRebaseCommits() tea.Cmd {
+	return func() tea.Msg {
+		countStr := strings.TrimSpace(m.rebaseInput.Value())
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 || count > 50 {
+			return statusMsg{message: "Invalid count (1-50)"}
+		}
+
+		commits := git.GetCommitLog(m.repoPath, count)
+		var rebaseCommits []git.RebaseCommit
+		for _, c := range commits {
+			rebaseCommits = append(rebaseCommits, git.RebaseCommit{
+				Hash:    c.Hash,
+				Message: c.Message,
+				Action:  "pick",
+			})
+		}
+		return rebaseCommitsMsg(rebaseCommits)
+	}
+}
+
+// Staging operations
+
+func (m model) toggleStaging(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		isStaged := git.IsFileStaged(m.repoPath, filePath)
+
+		var gitCmd []string
+		var action string
+		if isStaged {
+			gitCmd = []string{"reset", "HEAD", filePath}
+			action = "unstaged"
+		} else {
+			gitCmd = []string{"add", filePath}
+			action = "staged"
+		}
+
+		output, err := git.Execute(m.repoPath, gitCmd...)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to %s file: %v - %s", action, err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("%s: %s", cases.Title(language.English).String(action), filePath)}
+			},
+		)()
+	}
+}
+
+func (m model) gitAddAll() tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "add", ".")
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Git add failed: %v - %s", err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Added all files to staging"}
+			},
+		)()
+	}
+}
+
+// bulkStageTargets picks the files a bulk stage key covers, like the classes
+// of `git add -i`: "m" modified tracked files, "u" untracked files and "A"
+// every unstaged change in the selected file's directory. what describes
+// them for prompts.
+func (m model) bulkStageTargets(key string) (paths []string, what string) {
+	dir := ""
+	if key == "A" && m.fileCursor < len(m.changes) {
+		// An untracked directory is listed as "dir/" and is its own directory
+		file := m.changes[m.fileCursor].File
+		if dir = strings.TrimSuffix(file, "/"); dir == file {
+			dir = path.Dir(file)
+		}
+	}
+	for _, change := range m.changes {
+		if len(change.Status) < 2 || change.Status[1] == ' ' {
+			continue
+		}
+		untracked := change.Status == "??"
+		conflicted := strings.Contains(change.Status, "U") || change.Status == "AA" || change.Status == "DD"
+		switch key {
+		case "m":
+			if !untracked && !conflicted {
+				paths = append(paths, change.File)
+			}
+		case "u":
+			if untracked {
+				paths = append(paths, change.File)
+			}
+		case "A":
+			if !conflicted && (dir == "." || strings.HasPrefix(change.File, dir+"/")) {
+				paths = append(paths, change.File)
+			}
+		}
+	}
+	switch key {
+	case "m":
+		what = "modified file(s)"
+	case "u":
+		what = "untracked file(s)"
+	case "A":
+		what = "file(s) under " + dir + "/"
+		if dir == "." {
+			what = "file(s) in the repository root and below"
+		}
+	}
+	return paths, what
+}
+
+// stagePaths stages a bulk selection of files
+func (m model) stagePaths(paths []string, what string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, append([]string{"add", "--"}, paths...)...)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Git add failed: %v - %s", err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Staged %d %s", len(paths), what)}
+			},
+		)()
+	}
+}
+
+func (m model) gitReset() tea.Cmd {
+	return func() tea.Msg {
+		status := m.repo.Status()
+		if status.StagedFiles == 0 {
+			return statusMsg{message: "No staged changes to reset"}
+		}
+
+		output, err := git.Execute(m.repoPath, "reset", "HEAD")
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Git reset failed: %v - %s", err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Reset %d staged file(s)", status.StagedFiles)}
+			},
+		)()
+	}
+}
+
+func (m model) gitResetLastCommit() tea.Cmd {
+	return func() tea.Msg {
+		// Mixed reset: undo last commit, keep changes in working directory (unstaged)
+		output, err := git.Execute(m.repoPath, "reset", "HEAD~1")
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Reset failed: %v - %s", err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg {
+				return statusMsg{message: "Reset last commit (changes kept in working directory)"}
+			},
+		)()
+	}
+}
+
+func (m model) discardChanges(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "checkout", "--", filePath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to discard changes: %v - %s", err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Discarded changes: %s", filePath)}
+			},
+		)()
+	}
+}
+
+// Commit operations
+
+// stageAllForCommit stages every change from the commit tab's empty state,
+// then refreshes the suggestions for what is now staged
+func (m model) stageAllForCommit() tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "add", ".")
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Git add failed: %v - %s", err, string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.generateCommitSuggestions(),
+			func() tea.Msg {
+				return statusMsg{message: "Staged all changes - pick a message and press enter"}
+			},
+		)()
+	}
+}
+
+func (m model) commitWithMessage(message string) tea.Cmd {
+	return m.runJob("Commit", true, func() tea.Msg {
+		return m.commit(message)
+	})
+}
+
+// commitAndPush commits, then pushes straight away; the summary reports the
+// pushed range or why the push failed
+func (m model) commitAndPush(message string) tea.Cmd {
+	return withLoading("commit-push", m.notifyWhenDone("Commit & push", func() tea.Msg {
+		msg := m.commit(message)
+		result, ok := msg.(commitSuccessMsg)
+		if !ok {
+			return commitPushDoneMsg{result: msg}
+		}
+		push := func() (git.PushResult, error) { return git.Push(m.repoPath) }
+		if m.needsPublish() {
+			push = func() (git.PushResult, error) { return git.Publish(m.repoPath, m.pushRemote(), m.gitState.Branch) }
+		}
+		if pushed, err := push(); err != nil {
+			result.pushErr = err.Error()
+		} else {
+			result.pushed = pushed.String()
+		}
+		return commitPushDoneMsg{result: result}
+	}))
+}
+
+// commitEmpty records a commit with no changes, e.g. to trigger CI
+func (m model) commitEmpty(message string) tea.Cmd {
+	return m.runJob("Empty commit", true, func() tea.Msg {
+		if !git.GetIdentity(m.repoPath).IsComplete() {
+			return statusMsg{message: "Cannot commit: set user.name and user.email first (Tools > Identity)"}
+		}
+		if err := git.CommitEmpty(m.repoPath, message); err != nil {
+			return statusMsg{message: fmt.Sprintf("Empty commit failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Empty commit %s on %s", git.GetCurrentCommitHash(m.repoPath), m.gitState.Branch)}
+			},
+		)()
+	})
+}
+
+func (m model) loadAmendMessage() tea.Cmd {
+	return func() tea.Msg {
+		message, err := git.GetLastCommitMessage(m.repoPath)
+		return amendMessageMsg{message: message, pushed: err == nil && git.IsHeadPushed(m.repoPath), err: err}
+	}
+}
+
+// amendMessage rewrites the last commit's message without touching its tree
+func (m model) amendMessage(message string, pushed bool) tea.Cmd {
+	return m.runJob("Amend message", true, func() tea.Msg {
+		if err := git.AmendMessage(m.repoPath, message); err != nil {
+			return statusMsg{message: fmt.Sprintf("Amend failed: %v", err)}
+		}
+		status := fmt.Sprintf("Reworded the last commit, now %s", git.GetCurrentCommitHash(m.repoPath))
+		if pushed {
+			status += " - it was pushed, so the branch needs a force push"
+		}
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg { return statusMsg{message: status} },
+		)()
+	})
+}
+
+// commit commits the staged changes (or the picked partial paths)
+func (m model) commit(message string) tea.Msg {
+	files := git.GetStagedFiles(m.repoPath)
+	if len(files) == 0 {
+		return statusMsg{message: "No staged changes to commit"}
+	}
+
+	if !git.GetIdentity(m.repoPath).IsComplete() {
+		return statusMsg{message: "Cannot commit: set user.name and user.email first (Tools > Identity)"}
+	}
+
+	if err := m.runPreCommitCheck(); err != nil {
+		return statusMsg{message: fmt.Sprintf("Pre-commit check failed: %v", err)}
+	}
+
+	// Partial commit: only the files picked on the commit tab
+	if len(m.partialPaths) > 0 {
+		output, _ := git.Execute(m.repoPath, append([]string{"diff", "--cached", "--"}, m.partialPaths...)...)
+		if err := git.CommitStagedPaths(m.repoPath, message, m.partialPaths, m.commitOverride); err != nil {
+			return statusMsg{message: fmt.Sprintf("Partial commit failed: %v", err)}
+		}
+		return commitSuccessMsg{
+			hash:     git.GetCurrentCommitHash(m.repoPath),
+			message:  message,
+			diff:     string(output),
+			files:    m.partialPaths,
+			override: m.commitOverride,
+		}
+	}
+
+	diff := git.GetStagedDiff(m.repoPath)
+	dirty := git.GetUnstagedFiles(m.repoPath)
+
+	output, err := git.Execute(m.repoPath, append([]string{"commit", "-m", message}, m.commitOverride.Args()...)...)
+	if err != nil {
+		if strings.Contains(string(output), "failed to sign") || strings.Contains(string(output), "gpg") {
+			return statusMsg{message: "Commit failed: could not sign the commit (Tools > Signing to diagnose)"}
+		}
+		if rewrote := m.restageHookRewrites(message, files, dirty); rewrote != nil {
+			return rewrote
+		}
+		return statusMsg{message: "Commit failed - check commit message format"}
+	}
+
+	hash := git.GetCurrentCommitHash(m.repoPath)
+
+	return commitSuccessMsg{
+		hash:     hash,
+		message:  message,
+		diff:     diff,
+		files:    files,
+		override: m.commitOverride,
+	}
+}
+
+// restageHookRewrites handles a commit stopped by a formatting hook (the
+// pre-commit framework's "files were modified by this hook", lint-staged,
+// ...): staged files that gained unstaged changes during the commit are
+// staged again so the commit can be retried as is. Files that had unstaged
+// edits before are left alone, since those edits would be staged with them.
+// It returns nil when the hooks changed nothing.
+func (m model) restageHookRewrites(message string, staged, dirtyBefore []string) tea.Msg {
+	var restaged, skipped []string
+	for _, file := range git.GetUnstagedFiles(m.repoPath) {
+		switch {
+		case !slices.Contains(staged, file):
+		case slices.Contains(dirtyBefore, file):
+			skipped = append(skipped, file)
+		default:
+			restaged = append(restaged, file)
+		}
+	}
+	if len(restaged) == 0 {
+		return nil
+	}
+	if output, err := git.Execute(m.repoPath, append([]string{"add", "--"}, restaged...)...); err != nil {
+		return statusMsg{message: fmt.Sprintf("Commit failed; restaging the files its hook reformatted failed: %v - %s", err, string(output))}
+	}
+	return hookRewroteMsg{message: message, restaged: restaged, skipped: skipped}
+}
+
+// runPreCommitCheck runs the [checks] pre_commit command, if configured,
+// and reports its last line of output when it fails
+func (m model) runPreCommitCheck() error {
+	check := m.config.Checks.PreCommit
+	if check == "" {
+		return nil
+	}
+	cmd := exec.Command("sh", "-c", check)
+	cmd.Dir = m.repoPath
+	cmd.Env = git.Environ(m.repoPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		return fmt.Errorf("%s: %s", check, lines[len(lines)-1])
+	}
+	return nil
+}
+
+func (m model) generateCommitSuggestions() tea.Cmd {
+	return withLoading("suggestions", m.runJob("Analyse changes", false, func() tea.Msg {
+		suggestions, breaking := suggest.Generate(suggestSource{m.repo}, suggest.Options{
+			Rules:       m.config.Suggest.Rules,
+			Classifiers: m.config.Suggest.Classifiers,
+			Owner:       m.workspace.Scope,
+			Max:         m.config.Suggest.Max,
+		})
+		return commitSuggestionsMsg{suggestions: suggestions, breaking: breaking}
+	}))
+}
+
+// suggestSource feeds suggestions from the repository: staged, unstaged and
+// untracked changes, as the commit tab shows them
+type suggestSource struct{ repo git.Repo }
+
+func (s suggestSource) Changes() []suggest.Change {
+	var changes []suggest.Change
+	for _, change := range s.repo.Changes() {
+		changes = append(changes, suggest.Change{File: change.File, Status: change.Status})
+	}
+	return changes
+}
+
+func (s suggestSource) StagedDiff() string { return s.repo.StagedDiff() }
+
+func (s suggestSource) Diff(files []string) string { return s.repo.Diff(files) }
+
+// stagedChanges are the entries of m.changes with something in the index
+func (m model) stagedChanges() []git.Change {
+	var staged []git.Change
+	for _, change := range m.changes {
+		if change.Status != "" && change.Status[0] != ' ' && change.Status[0] != '?' {
+			staged = append(staged, change)
+		}
+	}
+	return staged
+}
+
+func (m model) loadPartialFiles() tea.Cmd {
+	return func() tea.Msg {
+		var files []string
+		for _, change := range git.GetStagedChanges(m.repoPath) {
+			files = append(files, change.File)
+		}
+		return partialFilesMsg(files)
+	}
+}
+
+// partialSelection returns the picked files in list order
+func (m model) partialSelection() []string {
+	var paths []string
+	for _, file := range m.partialFiles {
+		if m.partialSelected[file] {
+			paths = append(paths, file)
+		}
+	}
+	return paths
+}
+
+func (m model) loadCommitGroups() tea.Cmd {
+	return func() tea.Msg {
+		var changes []suggest.Change
+		for _, change := range git.GetStagedChanges(m.repoPath) {
+			changes = append(changes, suggest.Change{File: change.File, Status: change.Status})
+		}
+		var groups []git.CommitGroup
+		for _, group := range suggest.PlanGroups(changes, m.config.Suggest.Rules, m.workspace.Scope) {
+			groups = append(groups, git.CommitGroup{Message: group.Message, Files: group.Files})
+		}
+		return commitGroupsMsg(groups)
+	}
+}
+
+func (m model) commitInGroups(groups []git.CommitGroup) tea.Cmd {
+	return m.runJob("Commit in groups", true, func() tea.Msg {
+		if !git.GetIdentity(m.repoPath).IsComplete() {
+			return statusMsg{message: "Cannot commit: set user.name and user.email first (Tools > Identity)"}
+		}
+
+		if err := m.runPreCommitCheck(); err != nil {
+			return statusMsg{message: fmt.Sprintf("Pre-commit check failed: %v", err)}
+		}
+
+		done, err := git.CommitInGroups(m.repoPath, groups)
+		if err != nil {
+			return tea.Batch(
+				m.loadGitChanges(),
+				m.loadGitStatus(),
+				m.loadRecentCommits(),
+				func() tea.Msg {
+					return statusMsg{message: fmt.Sprintf("Split commit stopped after %d of %d: %v", done, len(groups), err)}
+				},
+			)()
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg { return statusMsg{message: fmt.Sprintf("Created %d commits", done)} },
+		)()
+	})
+}
+
+// footerLine matches a git trailer or conventional-commit footer
+var footerLine = regexp.MustCompile(`^([A-Za-z-]+|BREAKING CHANGE): `)
+
+// branchNumber finds bare numbers in branch names (fix/123-crash) so a
+// pattern like #\d+ can match them as #123
+var branchNumber = regexp.MustCompile(`(^|[/_-])(\d+)`)
+
+// hasTicket reports whether the subject or the footer paragraph of message
+// contains a reference matching re
+func hasTicket(message string, re *regexp.Regexp) bool {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	subject, _, _ := strings.Cut(paragraphs[0], "\n")
+	if re.MatchString(subject) {
+		return true
+	}
+	return len(paragraphs) > 1 && re.MatchString(paragraphs[len(paragraphs)-1])
+}
+
+// ticketFromBranch takes a ticket reference from a branch name, e.g.
+// ABC-123 from feature/ABC-123-login
+func ticketFromBranch(branch string, re *regexp.Regexp) string {
+	if ticket := re.FindString(branch); ticket != "" {
+		return ticket
+	}
+	return re.FindString(branchNumber.ReplaceAllString(branch, "${1}#${2}"))
+}
+
+// withTicket adds a ticket reference as a footer, or to the subject when
+// insert is "subject"
+func withTicket(message, ticket, insert string) string {
+	if ticket == "" {
+		return message
+	}
+	if insert == "subject" {
+		subject, body, hasBody := strings.Cut(message, "\n")
+		subject += " (" + ticket + ")"
+		if hasBody {
+			return subject + "\n" + body
+		}
+		return subject
+	}
+
+	// Join an existing footer block (e.g. BREAKING CHANGE) rather than
+	// starting a paragraph after it
+	paragraphs := strings.Split(message, "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; len(paragraphs) > 1 && footerLine.MatchString(last) {
+		return message + "\nRefs: " + ticket
+	}
+	return message + "\n\nRefs: " + ticket
+}
+
+// withFooters appends footers to message, joining an existing footer block
+// (e.g. BREAKING CHANGE) or starting one after a blank line
+func withFooters(message string, footers []commitFooter) string {
+	if len(footers) == 0 {
+		return message
+	}
+	var lines []string
+	for _, footer := range footers {
+		lines = append(lines, footer.token+": "+footer.value)
+	}
+	paragraphs := strings.Split(message, "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; len(paragraphs) > 1 && footerLine.MatchString(last) {
+		return message + "\n" + strings.Join(lines, "\n")
+	}
+	return message + "\n\n" + strings.Join(lines, "\n")
+}
+
+// conventionalFooter matches the footers the builder writes, which only
+// count as footers in the last paragraph
+var conventionalFooter = regexp.MustCompile(`^(Refs|Closes|Reviewed-by|BREAKING CHANGE): `)
+
+// checkFooters refuses a message whose footers are not in a last paragraph
+// of their own, after a blank line
+func checkFooters(message string) error {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	for i, paragraph := range paragraphs {
+		for _, line := range strings.Split(paragraph, "\n") {
+			if !conventionalFooter.MatchString(line) {
+				continue
+			}
+			token, _, _ := strings.Cut(line, ":")
+			switch {
+			case i == 0:
+				return fmt.Errorf("%s footer needs a blank line between it and the subject", token)
+			case i < len(paragraphs)-1:
+				return fmt.Errorf("%s footer must be in the last paragraph of the message", token)
+			}
+		}
+	}
+	return nil
+}
+
+// ticketRule returns the configured reference pattern when commits on the
+// current branch need one, or nil
+func (m model) ticketRule() (*regexp.Regexp, error) {
+	tickets := m.config.Commit.Tickets
+	if !tickets.Required(m.gitState.Branch) {
+		return nil, nil
+	}
+	re, err := regexp.Compile(tickets.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit.tickets pattern: %v", err)
+	}
+	return re, nil
+}
+
+// Branch operations
+
+func (m model) switchBranch(branchName string) tea.Cmd {
+	return func() tea.Msg {
+		var localBranchName string
+
+		if strings.HasPrefix(branchName, "origin/") || strings.HasPrefix(branchName, "remotes/origin/") {
+			localBranchName = strings.TrimPrefix(branchName, "remotes/origin/")
+			localBranchName = strings.TrimPrefix(localBranchName, "origin/")
+
+			output, err := git.Execute(m.repoPath, "checkout", "-b", localBranchName, branchName)
+			if err != nil {
+				if strings.Contains(string(output), "already exists") {
+					_, err = git.Execute(m.repoPath, "checkout", localBranchName)
+				}
+				if err != nil {
+					return statusMsg{message: fmt.Sprintf("Failed to switch branch: %s", string(output))}
+				}
+			}
+		} else {
+			localBranchName = branchName
+			output, err := git.Execute(m.repoPath, "checkout", branchName)
+			if err != nil {
+				return statusMsg{message: fmt.Sprintf("Failed to switch branch: %s", string(output))}
+			}
+		}
+
+		return tea.Batch(
+			m.loadBranches(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Switched to branch '%s'", localBranchName)}
+			},
+		)()
+	}
+}
+
+func (m model) createBranch(branchName string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "checkout", "-b", branchName)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to create branch: %s", string(output))}
+		}
+
+		return tea.Batch(
+			m.loadBranches(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Created and switched to branch '%s'", branchName)}
+			},
+		)()
+	}
+}
+
+func (m model) deleteBranch(branchName string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "branch", "-d", branchName)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to delete branch: %s", string(output))}
+		}
+
+		return tea.Batch(
+			m.loadBranches(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Deleted branch '%s'", branchName)}
+			},
+		)()
+	}
+}
+
+func (m model) compareBranch(targetBranch string) tea.Cmd {
+	return withLoading("comparison", m.runJob("Compare branches", false, func() tea.Msg {
+		currentBranch := git.GetBranchName(m.repoPath)
+		comparison := git.GetBranchComparison(m.repoPath, currentBranch, targetBranch)
+		return comparisonMsg(comparison)
+	}))
+}
+
+// compareSinceTag compares HEAD with the latest tag it contains, showing
+// what the next release would ship
+func (m model) compareSinceTag() tea.Cmd {
+	return withLoading("comparison", m.runJob("Compare with last tag", false, func() tea.Msg {
+		tag, err := git.GetLatestTag(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Compare failed: %v", err)}
+		}
+		return comparisonMsg(git.GetBranchComparison(m.repoPath, git.GetBranchName(m.repoPath), tag))
+	}))
+}
+
+// compareUpstream compares the current branch with its upstream as of the
+// last fetch: ahead is what a push would send, behind what a pull would bring
+func (m model) compareUpstream() tea.Cmd {
+	return withLoading("comparison", m.runJob("Compare with upstream", false, func() tea.Msg {
+		upstream, err := git.GetUpstream(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Compare failed: %v", err)}
+		}
+		return comparisonMsg(git.GetBranchComparison(m.repoPath, git.GetBranchName(m.repoPath), upstream))
+	}))
+}
+
+// comparingUpstream reports whether the comparison on screen is of the
+// current branch with its own upstream
+func (m model) comparingUpstream() bool {
+	if m.branchComparison == nil {
+		return false
+	}
+	for _, branch := range m.branches {
+		if branch.IsCurrent {
+			return branch.Upstream != "" && branch.Upstream == m.branchComparison.TargetBranch
+		}
+	}
+	return false
+}
+
+// loadReleasePreview compares HEAD with the latest tag for the new tag form;
+// without a tag there is nothing to show
+func (m model) loadReleasePreview() tea.Cmd {
+	return func() tea.Msg {
+		tag, err := git.GetLatestTag(m.repoPath)
+		if err != nil {
+			return nil
+		}
+		return releasePreviewMsg(git.GetBranchComparison(m.repoPath, git.GetBranchName(m.repoPath), tag))
+	}
+}
+
+// Review mode
+
+// hunkHeader captures the old and new start lines of a diff hunk
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)`)
+
+func (m model) loadReviewDiff(file git.DiffFile) tea.Cmd {
+	target := m.branchComparison.TargetBranch
+	return withLoading("review", m.runJob("Review diff", false, func() tea.Msg {
+		diff := git.GetRangeFileDiff(m.repoPath, target+"...HEAD", file.Path, file.OldPath)
+		return reviewDiffMsg{file: file, diff: diff}
+	}))
+}
+
+// diffPositions maps each line of a unified diff to its line number in the
+// new file, or in the old file for removed lines. Headers get no position.
+func diffPositions(lines []string) []diffPos {
+	positions := make([]diffPos, len(lines))
+	oldLine, newLine := 0, 0
+	inHunk := false
+	for i, l := range lines {
+		if match := hunkHeader.FindStringSubmatch(l); match != nil {
+			oldLine, _ = strconv.Atoi(match[1])
+			newLine, _ = strconv.Atoi(match[2])
+			inHunk = true
+			continue
+		}
+		if !inHunk || l == "" {
+			continue
+		}
+		switch l[0] {
+		case '+':
+			positions[i] = diffPos{line: newLine, side: "new"}
+			newLine++
+		case '-':
+			positions[i] = diffPos{line: oldLine, side: "old"}
+			oldLine++
+		case ' ':
+			positions[i] = diffPos{line: newLine, side: "new"}
+			oldLine++
+			newLine++
+		}
+	}
+	return positions
+}
+
+// reviewCommentAt finds the comment on a diff position, or -1
+func (m model) reviewCommentAt(file string, pos diffPos) int {
+	if pos.side == "" {
+		return -1
+	}
+	for i, comment := range m.reviewComments {
+		if comment.File == file && comment.Line == pos.line && comment.Side == pos.side {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortedReviewComments orders comments by file, in the comparison's order,
+// then by line
+func (m model) sortedReviewComments() []reviewComment {
+	order := make(map[string]int)
+	for i, file := range m.branchComparison.DifferingFiles {
+		order[file.Path] = i
+	}
+	comments := slices.Clone(m.reviewComments)
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].File != comments[j].File {
+			return order[comments[i].File] < order[comments[j].File]
+		}
+		return comments[i].Line < comments[j].Line
+	})
+	return comments
+}
+
+// reviewMarkdown formats the review comments as a markdown summary
+func (m model) reviewMarkdown() string {
+	comparison := m.branchComparison
+	comments := m.sortedReviewComments()
+
+	files := make(map[string]bool)
+	for _, comment := range comments {
+		files[comment.File] = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Review: %s vs %s\n\n", comparison.SourceBranch, comparison.TargetBranch)
+	fmt.Fprintf(&b, "%d comment(s) on %d file(s).\n", len(comments), len(files))
+	file := ""
+	for _, comment := range comments {
+		if comment.File != file {
+			file = comment.File
+			fmt.Fprintf(&b, "\n## `%s`\n", file)
+		}
+		label := fmt.Sprintf("Line %d", comment.Line)
+		if comment.Side == "old" {
+			label += " (removed)"
+		}
+		fmt.Fprintf(&b, "\n**%s**\n\n```diff\n%s\n```\n\n%s\n", label, comment.Code, comment.Body)
+	}
+	return b.String()
+}
+
+// exportReview writes the review to a markdown file in the git directory,
+// where it can't be committed by accident
+func (m model) exportReview() tea.Cmd {
+	if err := m.writable(); err != nil {
+		return func() tea.Msg { return statusMsg{message: fmt.Sprintf("Export failed: %v", err)} }
+	}
+	name := "gitty-review-" + strings.NewReplacer("/", "-", " ", "-").Replace(m.branchComparison.SourceBranch) + ".md"
+	path := filepath.Join(m.gitDir, name)
+	markdown := m.reviewMarkdown()
+	return func() tea.Msg {
+		if err := os.WriteFile(path, []byte(markdown), 0o644); err != nil {
+			return statusMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+		return statusMsg{message: "Review exported to " + path}
+	}
+}
+
+// postReview posts the review on the branch's pull request with gh
+func (m model) postReview() tea.Cmd {
+	markdown := m.reviewMarkdown()
+	count := len(m.reviewComments)
+	return m.runJob("Post review", false, func() tea.Msg {
+		if err := git.PostPRReview(m.repoPath, markdown); err != nil {
+			return statusMsg{message: fmt.Sprintf("Posting review failed: %v", err)}
+		}
+		return statusMsg{message: fmt.Sprintf("Posted %d review comment(s) to the pull request", count)}
+	})
+}
+
+// comparisonReport is the exported form of a branch comparison
+type comparisonReport struct {
+	Source     string         `json:"source"`
+	Target     string         `json:"target"`
+	Ahead      []reportCommit `json:"ahead"`
+	Behind     []reportCommit `json:"behind"`
+	Files      []reportFile   `json:"files"`
+	Insertions int            `json:"insertions"`
+	Deletions  int            `json:"deletions"`
+}
+
+type reportCommit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+}
+
+type reportFile struct {
+	Status  string `json:"status"`
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// buildComparisonReport adds a diffstat to the comparison on screen
+func buildComparisonReport(repoPath string, comparison git.BranchComparison) comparisonReport {
+	report := comparisonReport{
+		Source: comparison.SourceBranch,
+		Target: comparison.TargetBranch,
+		Ahead:  []reportCommit{},
+		Behind: []reportCommit{},
+		Files:  []reportFile{},
+	}
+	for _, commit := range comparison.AheadCommits {
+		report.Ahead = append(report.Ahead, reportCommit{commit.Hash, commit.Message, commit.Author, commit.Date})
+	}
+	for _, commit := range comparison.BehindCommits {
+		report.Behind = append(report.Behind, reportCommit{commit.Hash, commit.Message, commit.Author, commit.Date})
+	}
+
+	stats := make(map[string]git.DiffStat)
+	for _, stat := range git.GetDiffStat(repoPath, comparison.TargetBranch+"...HEAD") {
+		stats[stat.Path] = stat
+	}
+	for _, file := range comparison.DifferingFiles {
+		stat := stats[file.Path]
+		report.Files = append(report.Files, reportFile{
+			Status:  file.Status,
+			Path:    file.Path,
+			OldPath: file.OldPath,
+			Added:   stat.Added,
+			Deleted: stat.Deleted,
+			Binary:  stat.Binary,
+		})
+		report.Insertions += stat.Added
+		report.Deletions += stat.Deleted
+	}
+	return report
+}
+
+// markdown formats the report for a pull request description or release notes
+func (r comparisonReport) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# `%s` compared with `%s`\n\n", r.Source, r.Target)
+	fmt.Fprintf(&b, "%d commit(s) ahead, %d behind; %d file(s) changed, +%d -%d\n",
+		len(r.Ahead), len(r.Behind), len(r.Files), r.Insertions, r.Deletions)
+
+	commits := func(title string, list []reportCommit) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, commit := range list {
+			fmt.Fprintf(&b, "- `%s` %s (%s, %s)\n", commit.Hash, commit.Subject, commit.Author, commit.Date)
+		}
+	}
+	commits(fmt.Sprintf("Commits ahead (on %s, not on %s)", r.Source, r.Target), r.Ahead)
+	commits(fmt.Sprintf("Commits behind (on %s, not on %s)", r.Target, r.Source), r.Behind)
+
+	if len(r.Files) > 0 {
+		b.WriteString("\n## Files\n\n| Status | File | + | - |\n|---|---|--:|--:|\n")
+		for _, file := range r.Files {
+			name := "`" + file.Path + "`"
+			if file.OldPath != "" {
+				name = "`" + file.OldPath + "` → " + name
+			}
+			added, deleted := strconv.Itoa(file.Added), strconv.Itoa(file.Deleted)
+			if file.Binary {
+				added, deleted = "bin", "bin"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", file.Status, strings.ReplaceAll(name, "|", "\\|"), added, deleted)
+		}
+	}
+	return b.String()
+}
+
+// exportComparison writes the comparison as markdown or JSON to the git
+// directory, next to exported reviews
+func (m model) exportComparison(format string) tea.Cmd {
+	if err := m.writable(); err != nil {
+		return func() tea.Msg { return statusMsg{message: fmt.Sprintf("Export failed: %v", err)} }
+	}
+	comparison := *m.branchComparison
+	name := "gitty-compare-" + strings.NewReplacer("/", "-", " ", "-").Replace(comparison.SourceBranch+"-"+comparison.TargetBranch) + "." + format
+	path := filepath.Join(m.gitDir, name)
+	return func() tea.Msg {
+		report := buildComparisonReport(m.repoPath, comparison)
+		content := []byte(report.markdown())
+		if format == "json" {
+			var err error
+			if content, err = json.MarshalIndent(report, "", "  "); err != nil {
+				return statusMsg{message: fmt.Sprintf("Export failed: %v", err)}
+			}
+			content = append(content, '\n')
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return statusMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+		return statusMsg{message: "Comparison exported to " + path}
+	}
+}
+
+// historyRecord is the exported form of a history commit
+type historyRecord struct {
+	Hash       string `json:"hash"`
+	Author     string `json:"author"`
+	Email      string `json:"email"`
+	Date       string `json:"date"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body,omitempty"`
+	Files      int    `json:"files"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+}
+
+// historyCSV formats records with a header row, one commit per row
+func historyCSV(records []historyRecord) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"hash", "author", "email", "date", "subject", "body", "files", "insertions", "deletions"})
+	for _, r := range records {
+		w.Write([]string{r.Hash, r.Author, r.Email, r.Date, r.Subject, r.Body,
+			strconv.Itoa(r.Files), strconv.Itoa(r.Insertions), strconv.Itoa(r.Deletions)})
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+// exportHistory writes the history on screen as CSV or JSON to the git
+// directory: the branch it shows, or only the marked range
+func (m model) exportHistory(format string) tea.Cmd {
+	if err := m.writable(); err != nil {
+		return func() tea.Msg { return statusMsg{message: fmt.Sprintf("Export failed: %v", err)} }
+	}
+	newest, oldest := 0, len(m.commits)-1
+	if m.historyMarked {
+		newest, oldest = m.historyRange()
+	}
+	hashes := make([]string, 0, oldest-newest+1)
+	for _, commit := range m.commits[newest : oldest+1] {
+		hashes = append(hashes, commit.Hash)
+	}
+	ref := m.historyRef
+	if ref == "" {
+		ref = m.gitState.Branch
+	}
+	if m.historyAuthor != "" {
+		ref += "-" + m.historyAuthor
+	}
+	name := "gitty-history-" + strings.NewReplacer("/", "-", " ", "-").Replace(ref) + "." + format
+	path := filepath.Join(m.gitDir, name)
+	return m.runJob("Export history", false, func() tea.Msg {
+		commits, err := git.GetCommitRecords(m.repoPath, hashes)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+		records := []historyRecord{}
+		for _, c := range commits {
+			records = append(records, historyRecord{c.Hash, c.Author, c.Email, c.Date.Format(time.RFC3339),
+				c.Subject, c.Body, c.Files, c.Insertions, c.Deletions})
+		}
+		var content []byte
+		if format == "json" {
+			content, err = json.MarshalIndent(records, "", "  ")
+			content = append(content, '\n')
+		} else {
+			content, err = historyCSV(records)
+		}
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return statusMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+		return statusMsg{message: fmt.Sprintf("Exported %d commit(s) to %s", len(records), path)}
+	})
+}
+
+// copyComparison puts the markdown report on the clipboard
+func (m model) copyComparison() tea.Cmd {
+	comparison := *m.branchComparison
+	return func() tea.Msg {
+		if err := copyToClipboard(buildComparisonReport(m.repoPath, comparison).markdown()); err != nil {
+			return statusMsg{message: fmt.Sprintf("Copy failed: %v", err)}
+		}
+		return statusMsg{message: "Comparison copied to the clipboard as markdown"}
+	}
+}
+
+// clipboardTools are tried in order; the first one installed wins
+var clipboardTools = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard hands text to the platform's clipboard tool
+func copyToClipboard(text string) error {
+	for _, tool := range clipboardTools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(tool[0], tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %s", tool[0], strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return errors.New("no clipboard tool found (install xclip, xsel or wl-clipboard)")
+}
+
+// Pull requests
+
+func (m model) loadPRs() tea.Cmd {
+	tool := m.forge
+	return withLoading("prs", m.runJob("List "+forge.Noun(tool)+"s", false, func() tea.Msg {
+		prs, err := forge.ListMine(tool, m.repoPath)
+		return prsMsg{prs: prs, err: err}
+	}))
+}
+
+// prNumberRe finds the number in "42", "#42" or a pull request URL
+var prNumberRe = regexp.MustCompile(`\d+`)
+
+// prNumber reads the pull request number from input, taking the last number
+// so URLs like .../pull/42/files work
+func prNumber(input string) (int, bool) {
+	matches := prNumberRe.FindAllString(input, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	number, err := strconv.Atoi(matches[len(matches)-1])
+	return number, err == nil && number > 0
+}
+
+// checkoutPR fetches a pull request and switches to it, through gh or glab
+// when installed (they name the branch after the author's) and plain git as
+// pr/<number> otherwise
+func (m model) checkoutPR(number int) tea.Cmd {
+	return m.runJob(fmt.Sprintf("Check out #%d", number), true, func() tea.Msg {
+		var branch string
+		var err error
+		if m.forge != "" {
+			if err = forge.Checkout(m.forge, m.repoPath, number); err == nil {
+				branch = git.GetBranchName(m.repoPath)
+			}
+		} else {
+			branch, err = git.CheckoutPullRequest(m.repoPath, number)
+		}
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Checkout of #%d failed: %v", number, err)}
+		}
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadGitChanges(),
+			m.loadBranches(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Checked out #%d as %s", number, branch)}
+			},
+		)()
+	})
+}
+
+func (m model) openPR(pr forge.PR) tea.Cmd {
+	return m.runJob(fmt.Sprintf("Open #%d", pr.Number), false, func() tea.Msg {
+		if err := forge.Open(m.forge, m.repoPath, pr.Number); err != nil {
+			return statusMsg{message: fmt.Sprintf("Opening #%d failed: %v", pr.Number, err)}
+		}
+		return statusMsg{message: fmt.Sprintf("Opened #%d in the browser", pr.Number)}
+	})
+}
+
+// Shell escape
+
+// runShell suspends the TUI to run command in the repo (or an interactive
+// shell when command is empty), then resumes with a full refresh
+func (m model) runShell(command string) tea.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	var cmd *exec.Cmd
+	if command == "" {
+		cmd = exec.Command(shell, "-c", `echo "gitty: type 'exit' to return"; exec "$0"`, shell)
+	} else {
+		// Keep the output on screen until the user is done reading it
+		script := command + `; printf '\n[exit %d] press enter to return to gitty' $?; read _`
+		cmd = exec.Command(shell, "-c", script)
+	}
+	cmd.Dir = m.repoPath
+	// Commands typed here should see the same git environment gitty uses
+	cmd.Env = git.Environ(m.repoPath)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		// A non-zero exit from the user's own command is not our failure
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = nil
+		}
+		return shellDoneMsg{err: err}
+	})
+}
+
+// Remote operations
+
+// notifyWhenDone runs cmd and, if it took longer than the configured
+// threshold, alerts the terminal so the user can switch away meanwhile
+func (m model) notifyWhenDone(name string, cmd tea.Cmd) tea.Cmd {
+	// Every operation slow enough to notify about changes the repository
+	cmd = m.runJob(name, true, cmd)
+	return func() tea.Msg {
+		start := time.Now()
+		msg := cmd()
+
+		notify := m.config.Notify
+		if notify.Mode == "off" || time.Since(start) < time.Duration(notify.AfterSeconds)*time.Second {
+			return msg
+		}
+
+		result := "finished"
+		if status, ok := msg.(statusMsg); ok && strings.Contains(status.message, "failed") {
+			result = "failed"
+		}
+		text := fmt.Sprintf("%s %s in %s", name, result, filepath.Base(m.repoPath))
+
+		// Written to stderr so it doesn't interleave with the renderer's stdout
+		switch notify.Mode {
+		case "osc":
+			// OSC 9 (iTerm2, Windows Terminal, kitty...) and OSC 777 (VTE, foot...)
+			fmt.Fprintf(os.Stderr, "\x1b]9;gitty: %s\x07\x1b]777;notify;gitty;%s\x07", text, text)
+		default:
+			fmt.Fprint(os.Stderr, "\a")
+		}
+		return msg
+	}
+}
+
+// needsPublish reports whether the current branch has no upstream yet, so
+// pushing it publishes it with push -u
+func (m model) needsPublish() bool {
+	return m.gitState.Upstream == "" && m.gitState.Branch != "HEAD" && !m.bare
+}
+
+// pushRemote is the remote new branches are published to
+func (m model) pushRemote() string {
+	if m.config.Branches.Remote == "" {
+		return "origin"
+	}
+	return m.config.Branches.Remote
+}
+
+// pushPrompt asks for the second press of key that pushes, saying so when
+// the push will publish a new branch
+func (m model) pushPrompt(key string) string {
+	if m.needsPublish() {
+		return fmt.Sprintf("%s has no upstream - press %s again to publish it to %s (push -u)", m.gitState.Branch, key, m.pushRemote())
+	}
+	return fmt.Sprintf("Press %s again to push to remote", key)
+}
+
+func (m model) pushChanges() tea.Cmd {
+	if m.needsPublish() {
+		return m.publishBranch()
+	}
+	return m.notifyWhenDone("Push", func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "push")
+		if err != nil {
+			if git.IsPushRejected(string(output)) {
+				return tea.Batch(
+					func() tea.Msg { return showDivergenceMsg{} },
+					func() tea.Msg { return statusMsg{message: "Push rejected: the upstream has commits you don't"} },
+				)()
+			}
+			return statusMsg{message: fmt.Sprintf("Push failed: %s", string(output))}
+		}
+
+		hash := git.GetCurrentCommitHash(m.repoPath)
+		return pushOutputMsg{output: string(output), commit: hash}
+	})
+}
+
+// publishBranch pushes a branch without an upstream to the push remote and
+// tracks it there, reloading the branches so the upstream shows at once
+func (m model) publishBranch() tea.Cmd {
+	remote, branch := m.pushRemote(), m.gitState.Branch
+	return m.notifyWhenDone("Publish", func() tea.Msg {
+		result, err := git.Publish(m.repoPath, remote, branch)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Publish failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadBranches(),
+			m.loadUpstreamCommits(),
+			func() tea.Msg { return statusMsg{message: result.String()} },
+		)()
+	})
+}
+
+// pushBranchPrompt asks for the second P that pushes branch from the
+// branches tab
+func (m model) pushBranchPrompt(branch git.Branch) string {
+	switch {
+	case branch.IsCurrent:
+		return m.pushPrompt("P")
+	case branch.Upstream == "":
+		return fmt.Sprintf("%s has no upstream - press P again to publish it to %s (push -u)", branch.Name, m.pushRemote())
+	}
+	return fmt.Sprintf("Press P again to push %s to %s", branch.Name, branch.Upstream)
+}
+
+// pushBranch pushes a branch that isn't checked out to its upstream, or
+// publishes it when it has none
+func (m model) pushBranch(branch git.Branch) tea.Cmd {
+	remote := m.pushRemote()
+	return m.notifyWhenDone("Push", func() tea.Msg {
+		var result git.PushResult
+		var err error
+		if branch.Upstream == "" {
+			result, err = git.Publish(m.repoPath, remote, branch.Name)
+		} else {
+			result, err = git.PushBranch(m.repoPath, branch.Name)
+		}
+		if err != nil {
+			if git.IsPushRejected(err.Error()) {
+				return statusMsg{message: fmt.Sprintf("Push rejected: %s has commits %s doesn't - press L to fast-forward it, or check it out to merge if both have moved", branch.Upstream, branch.Name)}
+			}
+			return statusMsg{message: fmt.Sprintf("Push failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadBranches(),
+			func() tea.Msg { return statusMsg{message: result.String()} },
+		)()
+	})
+}
+
+// fastForwardBranch moves a branch that isn't checked out up to its
+// upstream as of a fresh fetch, refusing if it has commits of its own
+func (m model) fastForwardBranch(branch string) tea.Cmd {
+	return m.notifyWhenDone("Fast-forward", func() tea.Msg {
+		moved, err := git.FastForwardBranch(m.repoPath, branch)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Fast-forward failed: %v", err)}
+		}
+		message := fmt.Sprintf("%s already up to date with its upstream", branch)
+		if moved > 0 {
+			message = fmt.Sprintf("Fast-forwarded %s by %d commit(s)", branch, moved)
+		}
+		return tea.Batch(
+			m.loadBranches(),
+			m.loadGitStatus(),
+			func() tea.Msg { return statusMsg{message: message} },
+		)()
+	})
+}
+
+// behindBranches lists the local branches other than the current one that
+// are strictly behind their upstream as of the last fetch
+func (m model) behindBranches() []string {
+	var names []string
+	for _, branch := range m.branches {
+		if !branch.IsRemote && !branch.IsCurrent && !branch.Gone && branch.Ahead == 0 && branch.Behind > 0 {
+			names = append(names, branch.Name)
+		}
+	}
+	return names
+}
+
+// fastForwardBehind fast-forwards every branch behindBranches lists, so base
+// branches stay fresh without checking them out
+func (m model) fastForwardBehind() tea.Cmd {
+	names := m.behindBranches()
+	if len(names) == 0 {
+		return func() tea.Msg {
+			return statusMsg{message: "No other branch is strictly behind its upstream - press f to fetch first"}
+		}
+	}
+	return m.notifyWhenDone("Fast-forward", func() tea.Msg {
+		var moved, failed []string
+		for _, name := range names {
+			count, err := git.FastForwardBranch(m.repoPath, name)
+			if err != nil {
+				failed = append(failed, name)
+				continue
+			}
+			moved = append(moved, fmt.Sprintf("%s +%d", name, count))
+		}
+		message := "Fast-forwarded " + strings.Join(moved, ", ")
+		if len(failed) > 0 {
+			message = fmt.Sprintf("%s; failed: %s", message, strings.Join(failed, ", "))
+			if len(moved) == 0 {
+				message = "Fast-forward failed: " + strings.Join(failed, ", ")
+			}
+		}
+		return tea.Batch(
+			m.loadBranches(),
+			func() tea.Msg { return statusMsg{message: message} },
+		)()
+	})
+}
+
+func (m model) pullChanges() tea.Cmd {
+	return m.notifyWhenDone("Pull", func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "pull")
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Pull failed: %s", string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadBranches(),
+			m.loadUpstreamCommits(),
+			func() tea.Msg {
+				return statusMsg{message: "Pull successful"}
+			},
+		)()
+	})
+}
+
+func (m model) fetchChanges() tea.Cmd {
+	return m.notifyWhenDone("Fetch", func() tea.Msg {
+		results, err := git.FetchAll(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Fetch failed: %v", err)}
+		}
+
+		var failed []string
+		changed := 0
+		for _, result := range results {
+			if result.Err != "" {
+				failed = append(failed, result.Remote)
+			}
+			if result.Changed() {
+				changed++
+			}
+		}
+		message := fmt.Sprintf("Fetched %d remote(s), %d with changes", len(results), changed)
+		if len(failed) > 0 {
+			message = fmt.Sprintf("Fetch failed for %s (%d of %d remotes)", strings.Join(failed, ", "), len(failed), len(results))
+		}
+
+		// Branches are reloaded too so every tracking branch's ahead/behind is current
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadBranches(),
+			m.loadUpstreamCommits(),
+			func() tea.Msg { return fetchResultMsg{results: results} },
+			func() tea.Msg { return statusMsg{message: message} },
+		)()
+	})
+}
+
+// loadUpstreamCommits lists the outgoing (@{u}..HEAD) and incoming
+// (HEAD..@{u}) commits as of the last fetch
+func (m model) loadUpstreamCommits() tea.Cmd {
+	return func() tea.Msg {
+		div, err := git.GetDivergence(m.repoPath)
+		return upstreamCommitsMsg{div: div, err: err}
+	}
+}
+
+// Undo operations
+
+func (m model) undoToCommit(hash, mode string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "reset", "--"+mode, hash)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Undo failed: %s", string(output))}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadCommitHistory(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Reset (%s) to commit %s", mode, hash)}
+			},
+		)()
+	}
+}
+
+// parseAgo reads durations like "30m", "2h", "1h30m", "1d" or "3 days"
+func parseAgo(text string) (time.Duration, error) {
+	text = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "ago")))
+	if d, err := time.ParseDuration(text); err == nil {
+		return d, nil
+	}
+
+	units := []struct {
+		names []string
+		unit  time.Duration
+	}{
+		{[]string{"d", "day", "days"}, 24 * time.Hour},
+		{[]string{"h", "hr", "hrs", "hour", "hours"}, time.Hour},
+		{[]string{"m", "min", "mins", "minute", "minutes"}, time.Minute},
+	}
+	for _, u := range units {
+		for _, name := range u.names {
+			if number, ok := strings.CutSuffix(text, name); ok {
+				n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+				if err == nil && n > 0 {
+					return time.Duration(n * float64(u.unit)), nil
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("could not read %q - try 30m, 2h or 1d", text)
+}
+
+func (m model) loadTimeRestore(ago time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		target, err := git.FindReflogAt(m.repoPath, time.Now().Add(-ago))
+		if err != nil {
+			return timeRestoreMsg{err: err}
+		}
+		return timeRestoreMsg{restore: git.PreviewTimeRestore(m.repoPath, target)}
+	}
+}
+
+func (m model) restoreToPoint(target git.ReflogPoint) tea.Cmd {
+	return func() tea.Msg {
+		backup, err := git.RestoreToPoint(m.repoPath, target)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Restore failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadCommitHistory(),
+			m.loadRecentCommits(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Restored to %s - previous state saved on %s", target.Hash[:7], backup)}
+			},
+		)()
+	}
+}
+
+// Signing setup
+
+func (m model) loadSigning() tea.Cmd {
+	return func() tea.Msg {
+		return signingMsg{keys: git.DetectSigningKeys(), config: git.GetSigningConfig(m.repoPath)}
+	}
+}
+
+// loadSigningConfig reads just the config, without looking for keys, for
+// the commit tab header
+func (m model) loadSigningConfig() tea.Cmd {
+	return func() tea.Msg {
+		return signingConfigMsg(git.GetSigningConfig(m.repoPath))
+	}
+}
+
+// configureSigning applies the key, then immediately test-signs so a broken
+// setup is reported here rather than on the next commit
+func (m model) configureSigning(key git.SigningKey) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.ConfigureSigning(m.repoPath, m.configScope, key); err != nil {
+			return statusMsg{message: fmt.Sprintf("Signing setup failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadSigning(),
+			m.testSigning(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Signing with %s key (%s config)", key.Format, m.configScope)}
+			},
+		)()
+	}
+}
+
+func (m model) testSigning() tea.Cmd {
+	return func() tea.Msg {
+		return signingTestMsg{err: git.TestSigning(m.repoPath)}
+	}
+}
+
+func (m model) disableSigning() tea.Cmd {
+	return func() tea.Msg {
+		if err := git.SetConfig(m.repoPath, m.configScope, "commit.gpgsign", "false"); err != nil {
+			return statusMsg{message: fmt.Sprintf("Disable signing failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadSigning(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Commit signing turned off (%s config)", m.configScope)}
+			},
+		)()
+	}
+}
+
+// enableSigning turns commit.gpgsign back on for the key already configured
+func (m model) enableSigning() tea.Cmd {
+	return func() tea.Msg {
+		if err := git.SetConfig(m.repoPath, m.configScope, "commit.gpgsign", "true"); err != nil {
+			return statusMsg{message: fmt.Sprintf("Enable signing failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadSigningConfig(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Commit signing turned on (%s config)", m.configScope)}
+			},
+		)()
+	}
+}
+
+// Lost commit recovery
+
+func (m model) loadLostCommits() tea.Cmd {
+	return func() tea.Msg {
+		commits, err := git.FindLostCommits(m.repoPath)
+		return lostCommitsMsg{commits: commits, err: err}
+	}
+}
+
+func (m model) loadLostDiff(hash string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.Execute(m.repoPath, "show", "--stat", "--patch", hash)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Preview failed: %s", strings.TrimSpace(string(output)))}
+		}
+		return lostDiffMsg(output)
+	}
+}
+
+func (m model) recoverToBranch(lost git.LostCommit) tea.Cmd {
+	return func() tea.Msg {
+		name := "recovered/" + lost.Hash
+		if err := git.RecoverToBranch(m.repoPath, name, lost.FullHash); err != nil {
+			return statusMsg{message: fmt.Sprintf("Recover failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadBranches(),
+			m.loadLostCommits(),
+			func() tea.Msg { return statusMsg{message: fmt.Sprintf("Created branch %s", name)} },
+		)()
+	}
+}
+
+func (m model) cherryPickLost(lost git.LostCommit) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.CherryPick(m.repoPath, lost.FullHash); err != nil {
+			return statusMsg{message: fmt.Sprintf("Cherry-pick failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			m.loadLostCommits(),
+			func() tea.Msg { return statusMsg{message: fmt.Sprintf("Cherry-picked %s", lost.Hash)} },
+		)()
+	}
+}
+
+func (m model) loadPushUndo() tea.Cmd {
+	return func() tea.Msg {
+		undo, err := git.GetLastPush(m.repoPath)
+		return pushUndoMsg{undo: undo, err: err}
+	}
+}
+
+func (m model) revertLastPush(undo git.PushUndo) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.RevertPush(m.repoPath, undo); err != nil {
+			return statusMsg{message: fmt.Sprintf("Revert failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			m.loadPushUndo(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Created %d revert commit(s) - push to publish them", len(undo.Commits))}
+			},
+		)()
+	}
+}
+
+// Diverged branch assistant
+
+// openDivergence switches to the assistant for a branch that is both ahead
+// of and behind its upstream
+func (m model) openDivergence() (model, tea.Cmd) {
+	m.tab = "tools"
+	m.toolMode = "diverged"
+	m.confirmAction = ""
+	m.divergence, m.divergenceErr = nil, ""
+	return m, m.loadDivergence()
+}
+
+// loadDivergence fetches first so the preview shows the upstream as it is
+// now; offline it falls back to the last fetched state
+func (m model) loadDivergence() tea.Cmd {
+	return withLoading("divergence", m.runJob("Fetch and compare with upstream", true, func() tea.Msg {
+		git.Execute(m.repoPath, "fetch", "--quiet")
+		div, err := git.GetDivergence(m.repoPath)
+		return divergenceMsg{div: div, err: err}
+	}))
+}
+
+// afterIntegrate reports a rebase or merge of the upstream: on conflicts it
+// hands over to the conflicts view, where the operation can be continued
+func (m model) afterIntegrate(name string, err error) tea.Msg {
+	if err != nil {
+		if git.GetOperationInProgress(m.repoPath) == "" {
+			return statusMsg{message: fmt.Sprintf("%s failed: %v", name, err)}
+		}
+		message := fmt.Sprintf("%s stopped on conflicts - resolve them, then %s to continue",
+			name, m.keys.keyFor(ctxConflicts, "continue"))
+		if progress, ok := git.GetRebaseProgress(m.repoPath); ok {
+			message = fmt.Sprintf("%s stopped at %s - resolve it, then %s to continue",
+				name, describeRebaseStop(progress), m.keys.keyFor(ctxConflicts, "continue"))
+		}
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg { return showConflictsMsg{} },
+			func() tea.Msg { return statusMsg{message: message} },
+		)()
+	}
+
+	return tea.Batch(
+		m.loadGitChanges(),
+		m.loadGitStatus(),
+		m.loadRecentCommits(),
+		m.loadDivergence(),
+		func() tea.Msg { return statusMsg{message: name + " done - push when ready"} },
+	)()
+}
+
+func (m model) rebaseOntoUpstream() tea.Cmd {
+	return m.runJob("Rebase upstream", true, func() tea.Msg {
+		return m.afterIntegrate("Rebase", git.RebaseOntoUpstream(m.repoPath))
+	})
+}
+
+func (m model) mergeUpstream() tea.Cmd {
+	return m.runJob("Merge upstream", true, func() tea.Msg {
+		return m.afterIntegrate("Merge", git.MergeUpstream(m.repoPath))
+	})
+}
+
+func (m model) forcePushDiverged(div git.Divergence) tea.Cmd {
+	return m.notifyWhenDone("Force-push", func() tea.Msg {
+		if err := git.ForcePushWithLease(m.repoPath, div.RemoteAt); err != nil {
+			return statusMsg{message: fmt.Sprintf("Force-push failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadDivergence(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("%s now matches %s", div.Upstream, div.Branch)}
+			},
+		)()
+	})
+}
+
+func (m model) forcePushPrevious(undo git.PushUndo) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.ForcePushPrevious(m.repoPath, undo); err != nil {
+			return statusMsg{message: fmt.Sprintf("Force-push failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitStatus(),
+			m.loadPushUndo(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("%s reset to %s", undo.Tracking(), undo.From[:7])}
+			},
+		)()
+	}
+}
+
+// Rebase operations
+
+func (m model) executeRebase() tea.Cmd {
+	return m.notifyWhenDone("Rebase", func() tea.Msg {
+		if len(m.rebaseCommits) == 0 {
+			return statusMsg{message: "No commits to rebase"}
+		}
+
+		err := git.ExecuteRebase(m.repoPath, m.rebaseCommits)
+		if err != nil {
+			// Stopped part way, on a conflict or an edit: hand over to the
+			// conflicts view, which shows the steps left
+			return m.afterIntegrate("Rebase", err)
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadCommitHistory(),
+			func() tea.Msg {
+				return statusMsg{message: "Rebase completed successfully"}
+			},
+		)()
+	})
+}
+
+// Stash operations
+
+func (m model) loadStashList() tea.Cmd {
+	return func() tea.Msg {
+		stashes := git.GetStashList(m.repoPath)
+		return stashListMsg(stashes)
+	}
+}
+
+func (m model) loadStashDiff(index int) tea.Cmd {
+	return func() tea.Msg {
+		diff := git.StashShow(m.repoPath, index)
+		return stashDiffMsg(diff)
+	}
+}
+
+func (m model) loadStashFiles(index int) tea.Cmd {
+	return func() tea.Msg {
+		return stashFilesMsg(git.GetStashFiles(m.repoPath, index))
+	}
+}
+
+// stashFileSelection lists the picked stash files, in stash order
+func (m model) stashFileSelection() []git.DiffFile {
+	var files []git.DiffFile
+	for _, file := range m.stashFiles {
+		if m.stashFileSelected[file.Path] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// stashCheckoutFiles applies only files from a stash, leaving it in the list
+func (m model) stashCheckoutFiles(index int, files []git.DiffFile) tea.Cmd {
+	var paths, deleted []string
+	for _, file := range files {
+		if file.Status == "D" {
+			deleted = append(deleted, file.Path)
+		} else {
+			paths = append(paths, file.Path)
+		}
+	}
+	return m.runJob("Apply stash files", true, func() tea.Msg {
+		if err := git.StashCheckoutFiles(m.repoPath, index, paths, deleted); err != nil {
+			return statusMsg{message: fmt.Sprintf("Stash apply failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Applied %d file(s) from stash@{%d} (kept in stash list)", len(files), index)}
+			},
+		)()
+	})
+}
+
+// stashBranch turns a stash into a branch started where the stash was made
+func (m model) stashBranch(index int, branch string) tea.Cmd {
+	return m.runJob("Stash to branch", true, func() tea.Msg {
+		if err := git.StashBranch(m.repoPath, index, branch); err != nil {
+			return statusMsg{message: fmt.Sprintf("Stash branch failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadStashList(),
+			m.loadBranches(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Created branch '%s' from stash@{%d}", branch, index)}
+			},
+		)()
+	})
+}
+
+func (m model) stashPush(message string) tea.Cmd {
+	return func() tea.Msg {
+		err := git.StashPush(m.repoPath, message)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Stash failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadStashList(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Changes stashed"}
+			},
+		)()
+	}
+}
+
+func (m model) stashPop(index int) tea.Cmd {
+	return func() tea.Msg {
+		err := git.StashPop(m.repoPath, index)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Stash pop failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadStashList(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Stash popped"}
+			},
+		)()
+	}
+}
+
+func (m model) stashApply(index int) tea.Cmd {
+	return func() tea.Msg {
+		err := git.StashApply(m.repoPath, index)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Stash apply failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Stash applied (kept in stash list)"}
+			},
+		)()
+	}
+}
+
+func (m model) stashDrop(index int) tea.Cmd {
+	return func() tea.Msg {
+		err := git.StashDrop(m.repoPath, index)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Stash drop failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadStashList(),
+			func() tea.Msg {
+				return statusMsg{message: "Stash dropped"}
+			},
+		)()
+	}
+}
+
+// Snapshot operations
+
+// scheduleSnapshot queues the next background snapshot of uncommitted work;
+// it is off unless [snapshots] interval_minutes is set
+func (m model) scheduleSnapshot() tea.Cmd {
+	interval := time.Duration(m.config.Snapshot.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return snapshotTickMsg{}
+	})
+}
+
+// takeSnapshot records the dirty worktree under refs/gitty/snapshots. The
+// timer's snapshots stay quiet; only one asked for reports back.
+func (m model) takeSnapshot(manual bool) tea.Cmd {
+	snapshot := func() tea.Msg {
+		_, created, err := git.CreateSnapshot(m.repoPath, m.config.Snapshot.Keep)
+		if !manual {
+			if created {
+				return m.loadSnapshots()()
+			}
+			return nil
+		}
+		switch {
+		case err != nil:
+			return statusMsg{message: fmt.Sprintf("Snapshot failed: %v", err)}
+		case !created:
+			return statusMsg{message: "Nothing new to snapshot"}
+		}
+		return tea.Batch(
+			m.loadSnapshots(),
+			func() tea.Msg {
+				return statusMsg{message: "Snapshot saved"}
+			},
+		)()
+	}
+	if !manual {
+		return snapshot
+	}
+	return m.runJob("Snapshot", false, snapshot)
+}
+
+func (m model) loadContributors() tea.Cmd {
+	return withLoading("contributors", m.runJob("Load contributors", false, func() tea.Msg {
+		contributors, err := git.GetContributors(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Loading contributors failed: %v", err)}
+		}
+		return contributorsMsg(contributors)
+	}))
+}
+
+func (m model) loadSnapshots() tea.Cmd {
+	return func() tea.Msg {
+		return snapshotsMsg(git.GetSnapshots(m.repoPath))
+	}
+}
+
+func (m model) loadSnapshotDiff(snap git.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		return snapshotDiffMsg(git.SnapshotDiff(m.repoPath, snap))
+	}
+}
+
+// restoreSnapshot applies a snapshot's changes on top of the worktree; the
+// snapshot itself is kept
+func (m model) restoreSnapshot(snap git.Snapshot) tea.Cmd {
+	return m.runJob("Restore snapshot", true, func() tea.Msg {
+		if err := git.RestoreSnapshot(m.repoPath, snap); err != nil {
+			return statusMsg{message: fmt.Sprintf("Snapshot restore failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Snapshot from " + snap.Time.Format("Jan 2 15:04") + " restored"}
+			},
+		)()
+	})
+}
+
+func (m model) deleteSnapshot(snap git.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.DeleteSnapshot(m.repoPath, snap); err != nil {
+			return statusMsg{message: fmt.Sprintf("Snapshot delete failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadSnapshots(),
+			func() tea.Msg {
+				return statusMsg{message: "Snapshot deleted"}
+			},
+		)()
+	}
+}
+
+// .git/info/exclude operations
+
+func (m model) loadExcludeLines() tea.Cmd {
+	return func() tea.Msg {
+		lines, err := git.GetExcludeLines(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Reading info/exclude failed: %v", err)}
+		}
+		return excludeLinesMsg(lines)
+	}
+}
+
+// saveExclude appends pattern to .git/info/exclude, or replaces line edit
+// when it is not -1
+func (m model) saveExclude(edit int, pattern string) tea.Cmd {
+	lines := slices.Clone(m.excludeLines)
+	return func() tea.Msg {
+		var err error
+		if edit >= 0 && edit < len(lines) {
+			lines[edit] = pattern
+			err = git.WriteExcludeLines(m.repoPath, lines)
+		} else {
+			err = git.AddExclude(m.repoPath, pattern)
+		}
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Exclude failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadExcludeLines(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Ignoring %s locally", pattern)}
+			},
+		)()
+	}
+}
+
+func (m model) deleteExcludeLine(i int) tea.Cmd {
+	lines := slices.Delete(slices.Clone(m.excludeLines), i, i+1)
+	return func() tea.Msg {
+		if err := git.WriteExcludeLines(m.repoPath, lines); err != nil {
+			return statusMsg{message: fmt.Sprintf("Exclude delete failed: %v", err)}
+		}
+		return tea.Batch(
+			m.loadExcludeLines(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Exclude line deleted"}
+			},
+		)()
+	}
+}
+
+// Tag operations
+
+func (m model) loadTags() tea.Cmd {
+	return func() tea.Msg {
+		tags := git.GetTags(m.repoPath)
+		return tagListMsg(tags)
+	}
+}
+
+// verifyTag checks the signature of a tag and of the commit it points to
+func (m model) verifyTag(tag git.Tag) tea.Cmd {
+	return m.runJob("Verify "+tag.Name, false, func() tea.Msg {
+		var sigs tagSignature
+		if tag.IsAnnotated {
+			sig := git.VerifyTag(m.repoPath, tag.Name)
+			sigs.tag = &sig
+		}
+		sigs.commit = git.VerifyCommit(m.repoPath, tag.Commit)
+		return tagVerifiedMsg{name: tag.Name, sigs: sigs}
+	})
+}
+
+// verifyCommits checks the signatures of the given commits
+func (m model) verifyCommits(hashes []string) tea.Cmd {
+	return m.runJob(fmt.Sprintf("Verify %d commit(s)", len(hashes)), false, func() tea.Msg {
+		sigs := make(commitsVerifiedMsg, len(hashes))
+		for _, hash := range hashes {
+			sigs[hash] = git.VerifyCommit(m.repoPath, hash)
+		}
+		return sigs
+	})
+}
+
+func (m model) createTag(name, message string, annotated bool) tea.Cmd {
+	return func() tea.Msg {
+		err := git.CreateTag(m.repoPath, name, message, annotated)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Create tag failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadTags(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Created tag '%s'", name)}
+			},
+		)()
+	}
+}
+
+func (m model) deleteTag(name string) tea.Cmd {
+	return func() tea.Msg {
+		err := git.DeleteTag(m.repoPath, name)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Delete tag failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadTags(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Deleted tag '%s'", name)}
+			},
+		)()
+	}
+}
+
+func (m model) pushTag(name string) tea.Cmd {
+	return func() tea.Msg {
+		err := git.PushTag(m.repoPath, name)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Push tag failed: %v", err)}
+		}
+
+		return statusMsg{message: fmt.Sprintf("Pushed tag '%s' to remote", name)}
+	}
+}
+
+func (m model) pushAllTags() tea.Cmd {
+	return func() tea.Msg {
+		err := git.PushAllTags(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Push tags failed: %v", err)}
+		}
+
+		return statusMsg{message: "Pushed all tags to remote"}
+	}
+}
+
+// Hook operations
+
+func (m model) installConventionalCommitsHook() tea.Cmd {
+	return func() tea.Msg {
+		err := git.InstallCommitMsgHook(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Install failed: %v", err)}
+		}
+
+		return tea.Batch(
+			func() tea.Msg { return hookStatusMsg(true) },
+			func() tea.Msg { return statusMsg{message: "Installed conventional commits hook"} },
+		)()
+	}
+}
+
+func (m model) installNoLargeFilesHook() tea.Cmd {
+	return func() tea.Msg {
+		err := git.InstallNoLargeFilesHook(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Install failed: %v", err)}
+		}
+
+		return tea.Batch(
+			func() tea.Msg { return preCommitHookMsg(true) },
+			func() tea.Msg { return statusMsg{message: "Installed no-large-files hook (blocks files >5MB)"} },
+		)()
+	}
+}
+
+func (m model) installDetectSecretsHook() tea.Cmd {
+	return func() tea.Msg {
+		err := git.InstallDetectSecretsHook(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Install failed: %v", err)}
+		}
+
+		return tea.Batch(
+			func() tea.Msg { return preCommitHookMsg(true) },
+			func() tea.Msg { return statusMsg{message: "Installed detect-secrets hook"} },
+		)()
+	}
+}
+
+func (m model) removeSelectedHook() tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		var hookName string
+
+		switch m.hookCursor {
+		case 0:
+			err = git.RemoveCommitMsgHook(m.repoPath)
+			hookName = "conventional commits"
+		case 1, 2:
+			err = git.RemovePreCommitHook(m.repoPath)
+			hookName = "pre-commit"
+		}
+
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Remove failed: %v", err)}
+		}
+
+		return tea.Batch(
+			func() tea.Msg {
+				if m.hookCursor == 0 {
+					return hookStatusMsg(false)
+				}
+				return preCommitHookMsg(false)
+			},
+			func() tea.Msg { return statusMsg{message: fmt.Sprintf("Removed %s hook", hookName)} },
+		)()
+	}
+}
+
+// Log viewer operations
+
+func (m model) loadLogCommits(search string) tea.Cmd {
+	return withLoading("log", m.runJob("Load log", false, func() tea.Msg {
+		commits := git.GetCommitLog2(m.repoPath, 50, search)
+		return logCommitsMsg(commits)
+	}))
+}
+
+func (m model) loadLogDetail(hash string) tea.Cmd {
+	return func() tea.Msg {
+		detail := git.GetCommitDetail(m.repoPath, hash)
+		diff := git.GetCommitDiff(m.repoPath, hash)
+		return tea.Batch(
+			func() tea.Msg { return logDetailMsg(detail) },
+			func() tea.Msg { return logDiffMsg(diff) },
+		)()
+	}
+}
+
+func (m model) loadLogFileDiff(hash, path string) tea.Cmd {
+	return func() tea.Msg {
+		return logFileDiffMsg{path: path, diff: git.GetCommitFileDiff(m.repoPath, hash, path)}
+	}
+}
+
+// fileTreeRow is a line of a commit's file tree: a directory, with the
+// counts of everything under it, or a file
+type fileTreeRow struct {
+	path    string // the directory's or file's path
+	name    string // as shown: a file's name, or one or more directories
+	depth   int
+	dir     bool
+	added   int
+	deleted int
+	binary  bool
+}
+
+// fileTreeNode is a directory or file while the tree is being built
+type fileTreeNode struct {
+	name     string
+	path     string
+	children map[string]*fileTreeNode
+	stat     *git.DiffStat // nil for directories
+}
+
+// fileTreeRows lays out stats as a tree, directories before files, leaving
+// out what collapsed directories hold. A directory holding nothing but
+// another directory shares its row, e.g. "internal/ui".
+func fileTreeRows(stats []git.DiffStat, collapsed map[string]bool) []fileTreeRow {
+	root := &fileTreeNode{children: make(map[string]*fileTreeNode)}
+	for i := range stats {
+		node := root
+		parts := strings.Split(stats[i].Path, "/")
+		for j, part := range parts {
+			child, ok := node.children[part]
+			if !ok {
+				child = &fileTreeNode{name: part, path: strings.Join(parts[:j+1], "/"), children: make(map[string]*fileTreeNode)}
+				node.children[part] = child
+			}
+			node = child
+		}
+		node.stat = &stats[i]
+	}
+
+	var rows []fileTreeRow
+	var walk func(node *fileTreeNode, depth int)
+	walk = func(node *fileTreeNode, depth int) {
+		children := slices.Collect(maps.Values(node.children))
+		slices.SortFunc(children, func(a, b *fileTreeNode) int {
+			if (a.stat == nil) != (b.stat == nil) {
+				if a.stat == nil {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a.name, b.name)
+		})
+		for _, child := range children {
+			if child.stat != nil {
+				rows = append(rows, fileTreeRow{path: child.path, name: child.name, depth: depth,
+					added: child.stat.Added, deleted: child.stat.Deleted, binary: child.stat.Binary})
+				continue
+			}
+			name := child.name
+			for len(child.children) == 1 {
+				only := slices.Collect(maps.Values(child.children))[0]
+				if only.stat != nil {
+					break
+				}
+				name += "/" + only.name
+				child = only
+			}
+			row := fileTreeRow{path: child.path, name: name, depth: depth, dir: true}
+			row.added, row.deleted, row.binary = child.counts()
+			rows = append(rows, row)
+			if !collapsed[child.path] {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(root, 0)
+	return rows
+}
+
+// counts sums the line counts of every file under the node
+func (n *fileTreeNode) counts() (added, deleted int, binary bool) {
+	if n.stat != nil {
+		return n.stat.Added, n.stat.Deleted, n.stat.Binary
+	}
+	for _, child := range n.children {
+		a, d, b := child.counts()
+		added, deleted, binary = added+a, deleted+d, binary || b
+	}
+	return added, deleted, binary
+}
+
+// Blame operations
+
+func (m model) loadBlame(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		lines := git.GetBlame(m.repoPath, filePath)
+		return blameMsg(lines)
+	}
+}
+
+// Cherry-pick and Revert operations
+
+func (m model) cherryPickCommit(hash string) tea.Cmd {
+	return func() tea.Msg {
+		err := git.CherryPick(m.repoPath, hash)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Cherry-pick failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Cherry-picked %s", hash)}
+			},
+		)()
+	}
+}
+
+// cherryPickRange applies a range of another branch's commits onto HEAD
+func (m model) cherryPickRange(oldest, newest git.Commit, count int) tea.Cmd {
+	return m.runJob("Cherry-pick range", true, func() tea.Msg {
+		err := git.CherryPickRange(m.repoPath, oldest.Hash, newest.Hash)
+		status := fmt.Sprintf("Cherry-picked %d commit(s), %s..%s", count, oldest.Hash, newest.Hash)
+		if err != nil {
+			status = fmt.Sprintf("Cherry-pick failed: %v", err)
+			if git.GetOperationInProgress(m.repoPath) == "cherry-pick" {
+				status = fmt.Sprintf("Cherry-pick stopped: %v - resolve, then continue or abort", err)
+			}
+		}
+		return tea.Batch(
+			m.loadConflicts(),
+			m.loadOperation(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg { return statusMsg{message: status} },
+		)()
+	})
+}
+
+// revertRange reverts a range of the current branch's commits, newest first
+func (m model) revertRange(oldest, newest git.Commit, count int) tea.Cmd {
+	return m.runJob("Revert range", true, func() tea.Msg {
+		err := git.RevertRange(m.repoPath, oldest.Hash, newest.Hash)
+		status := fmt.Sprintf("Reverted %d commit(s), %s..%s", count, oldest.Hash, newest.Hash)
+		if err != nil {
+			status = fmt.Sprintf("Revert failed: %v", err)
+			if git.GetOperationInProgress(m.repoPath) == "revert" {
+				status = fmt.Sprintf("Revert stopped: %v - resolve, then continue or abort", err)
+			}
+		}
+		return tea.Batch(
+			m.loadConflicts(),
+			m.loadOperation(),
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			m.loadHistory(),
+			func() tea.Msg { return statusMsg{message: status} },
+		)()
+	})
+}
+
+func (m model) revertCommit(hash string) tea.Cmd {
+	return func() tea.Msg {
+		err := git.RevertCommit(m.repoPath, hash)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Revert failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Reverted %s", hash)}
+			},
+		)()
+	}
+}
+
+// Clean operations
+
+type cleanFilesMsg []string
+
+func (m model) loadCleanFiles() tea.Cmd {
+	return func() tea.Msg {
+		files, err := git.CleanDryRun(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Clean check failed: %v", err)}
+		}
+		return cleanFilesMsg(files)
+	}
+}
+
+func (m model) executeClean() tea.Cmd {
+	return func() tea.Msg {
+		err := git.CleanForce(m.repoPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Clean failed: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			func() tea.Msg {
+				return statusMsg{message: "Cleaned untracked files"}
+			},
+		)()
+	}
+}
+
+// Clone/Init operations
+
+func (m model) cloneRepo(url string) tea.Cmd {
+	return func() tea.Msg {
+		// Clone to current directory with repo name
+		parts := strings.Split(url, "/")
+		repoName := strings.TrimSuffix(parts[len(parts)-1], ".git")
+		output, err := git.Clone(url, repoName)
+
+		// Get absolute path to the cloned repo
+		cwd, _ := os.Getwd()
+		newPath := filepath.Join(cwd, repoName)
+
+		return cloneResultMsg{output: output, err: err, newPath: newPath}
+	}
+}
+
+func (m model) initRepo(path string) tea.Cmd {
+	return func() tea.Msg {
+		if git.ReadOnly() {
+			return statusMsg{message: fmt.Sprintf("Init failed: %v", git.ErrReadOnly)}
+		}
+		// Use current directory if path is empty
+		targetPath := path
+		if targetPath == "" {
+			targetPath = "."
+		}
+
+		// Make absolute
+		absPath, err := filepath.Abs(targetPath)
+		if err != nil {
+			return statusMsg{message: fmt.Sprintf("Invalid path: %v", err)}
+		}
+
+		// Create directory if it doesn't exist
+		if err := os.MkdirAll(absPath, 0755); err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to create directory: %v", err)}
+		}
+
+		// Initialize git repo
+		if err := git.Init(absPath); err != nil {
+			return statusMsg{message: fmt.Sprintf("Init failed: %v", err)}
+		}
+
+		// Switch to the new repo
+		return repoSwitchMsg(absPath)
+	}
+}
+
+// Identity operations
+
+func (m model) loadIdentity() tea.Cmd {
+	return func() tea.Msg {
+		return identityMsg(git.GetIdentity(m.repoPath))
+	}
+}
+
+func (m model) loadKnownIdentities() tea.Cmd {
+	return func() tea.Msg {
+		return knownIdentitiesMsg(git.GetKnownIdentities(m.repoPath))
+	}
+}
+
+func (m model) setIdentity(id git.Identity) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.SetLocalIdentity(m.repoPath, id); err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to set identity: %v", err)}
+		}
+
+		return tea.Batch(
+			m.loadIdentity(),
+			m.loadKnownIdentities(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Commits in this repo will be authored as %s", id)}
+			},
+		)()
+	}
+}
+
+// Config editor operations
+
+type configRow struct {
+	key      string
+	value    string
+	scope    string
+	desc     string
+	options  []string
+	editable bool
+}
+
+// configRows lists the editable keys (with their effective value) followed by
+// every other config entry
+func (m model) configRows() []configRow {
+	var rows []configRow
+	editable := make(map[string]bool)
+
+	for _, k := range git.EditableConfigKeys() {
+		editable[strings.ToLower(k.Key)] = true
+		row := configRow{key: k.Key, desc: k.Desc, options: k.Options, editable: true}
+		for _, e := range m.configEntries {
+			if strings.EqualFold(e.Key, k.Key) {
+				row.value, row.scope = e.Value, e.Scope
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	for _, e := range m.configEntries {
+		if editable[strings.ToLower(e.Key)] {
+			continue
+		}
+		rows = append(rows, configRow{key: e.Key, value: e.Value, scope: e.Scope})
+	}
+	return rows
+}
+
+func (m model) loadConfigEntries() tea.Cmd {
+	return func() tea.Msg {
+		return configEntriesMsg(git.GetConfigEntries(m.repoPath))
+	}
+}
+
+func (m model) setConfigValue(scope, key, value string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.ValidateConfigValue(key, value); err != nil {
+			return statusMsg{message: err.Error()}
+		}
+		if err := git.SetConfig(m.repoPath, scope, key, value); err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to set %s: %v", key, err)}
+		}
+
+		return tea.Batch(
+			m.loadConfigEntries(),
+			m.loadIdentity(),
+			m.loadSigningConfig(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Set %s = %s (%s)", key, value, scope)}
+			},
+		)()
+	}
+}
+
+func (m model) unsetConfigValue(scope, key string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.UnsetConfig(m.repoPath, scope, key); err != nil {
+			return statusMsg{message: fmt.Sprintf("Failed to unset %s in %s config: %v", key, scope, err)}
+		}
+
+		return tea.Batch(
+			m.loadConfigEntries(),
+			m.loadIdentity(),
+			m.loadSigningConfig(),
+			func() tea.Msg {
+				return statusMsg{message: fmt.Sprintf("Unset %s (%s)", key, scope)}
+			},
+		)()
+	}
+}
+
+// Alias operations
+
+func (m model) loadAliases() tea.Cmd {
+	return func() tea.Msg {
+		return aliasesMsg(git.GetAliases(m.repoPath))
+	}
+}
+
+func (m model) runAlias(name string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := git.RunAlias(m.repoPath, name)
+		return aliasOutputMsg{name: name, output: output, err: err}
+	}
+}
+
+func (m model) loadCommitScopes() tea.Cmd {
+	return func() tea.Msg {
+		return commitScopesMsg(git.GetRecentScopes(m.repoPath, 200))
+	}
+}
+
+// scopeOptions lists the scope picker entries: "(none)", then scopes from
+// config, then the packages of the current changes, then scopes
+// seen in history
+func (m model) scopeOptions() []string {
+	options := []string{"(none)"}
+	seen := make(map[string]bool)
+	scopes := append([]string{}, m.config.Commit.Scopes...)
+	for _, change := range m.changes {
+		if scope, ok := m.workspace.Scope(change.File); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	for _, scope := range append(scopes, m.commitScopes...) {
+		if scope != "" && !seen[scope] {
+			seen[scope] = true
+			options = append(options, scope)
+		}
+	}
+	return options
+}
+
+// applyCommitPrefix replaces any conventional prefix on message with
+// type(scope):, keeping the description
+func applyCommitPrefix(message, commitType, scope string) string {
+	description := suggest.ConventionalPrefix.ReplaceAllString(strings.TrimSpace(message), "")
+	prefix := commitType
+	if scope != "" {
+		prefix += "(" + scope + ")"
+	}
+	return prefix + ": " + description
+}
+
+// loadSpellChecker builds the commit message spell checker, treating words
+// from the staged files and diff as known identifiers
+func (m model) loadSpellChecker() tea.Cmd {
+	return func() tea.Msg {
+		checker := spell.ForRepo(m.repoPath, git.GetCommonDir(m.repoPath))
+		checker.AddIdentifiers(strings.Join(git.GetStagedFiles(m.repoPath), "\n"))
+		checker.AddIdentifiers(git.GetStagedDiff(m.repoPath))
+		return spellCheckerMsg{checker: checker}
+	}
+}
+
+// checkSpelling returns suspect words in the commit message, or nil when
+// spell-check is off or not loaded yet
+func (m model) checkSpelling(text string) []spell.Issue {
+	if m.spellOff || m.speller == nil {
+		return nil
+	}
+	return m.speller.Check(text)
+}
+
+func (m model) addToDictionary(word string) tea.Cmd {
+	if err := m.writable(); err != nil {
+		return func() tea.Msg { return statusMsg{message: fmt.Sprintf("Add to dictionary failed: %v", err)} }
+	}
+	return func() tea.Msg {
+		path := spell.RepoDictionary(git.GetCommonDir(m.repoPath))
+		if err := spell.AddToDictionary(path, word); err != nil {
+			return statusMsg{message: fmt.Sprintf("Add to dictionary failed: %v", err)}
+		}
+		return statusMsg{message: fmt.Sprintf("Added '%s' to the repository's dictionary", word)}
+	}
+}
@@ -0,0 +1,638 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// keyBinding is one action available in a key context. The context's key
+// handler switches on handle; the keymap translates whatever key the user
+// bound to the action into it, so handlers never see remapped keys.
+type keyBinding struct {
+	action string             // name used in config remaps, e.g. "stage"
+	keys   []string           // keys that trigger the action
+	label  string             // footer label when it differs from keys[0], e.g. "j/k"
+	help   string             // description for the footer and help listings
+	hidden bool               // left out of the footer (e.g. the second half of "j/k")
+	when   func(m model) bool // footer visibility; nil means always shown
+	writes bool               // changes the repository: disabled by --read-only
+
+	handle   string   // key the context's handler switches on
+	defaults []string // keys before remapping
+}
+
+// keyMap holds the bindings of every key context
+type keyMap map[string][]keyBinding
+
+// Key contexts, one per input mode
+const (
+	ctxGlobal        = "global"
+	ctxShell         = "shell"
+	ctxIndexLock     = "index-lock" // prompt about another program's index.lock
+	ctxTour          = "tour"       // the first-launch walkthrough
+	ctxFiles         = "files"
+	ctxDiff          = "diff"
+	ctxBlame         = "blame"
+	ctxConflicts     = "conflicts"
+	ctxResolve       = "resolve"
+	ctxCommit        = "commit"
+	ctxNothingStaged = "nothing-staged" // commit tab before anything is staged
+	ctxSummary       = "summary"
+	ctxBreaking      = "breaking"
+	ctxOverride      = "override" // setting the next commit's author and date
+	ctxMessage       = "message"  // writing an empty commit's or an amend's message
+	ctxFooter        = "footer"   // adding Refs/Closes/... footers to the next commit
+	ctxPartial       = "partial"
+	ctxStagedFiles   = "staged-files" // browsing the commit tab's staged files
+	ctxSplit         = "split"
+	ctxPicker        = "picker"
+	ctxBranches      = "branches"
+	ctxPRNumber      = "pr-number" // typing a pull request to check out
+	ctxCompare       = "compare"
+	ctxReview        = "review"         // annotating a compared file's diff
+	ctxReviewComment = "review-comment" // typing a review comment
+	ctxPRs           = "prs"
+	ctxTools         = "tools"
+	ctxStash         = "stash"
+	ctxStashFiles    = "stash-files"  // picking files to apply from a stash
+	ctxStashBranch   = "stash-branch" // naming the branch for a stash
+	ctxDanger        = "danger"       // typing a word to confirm a dangerous action
+	ctxSnapshots     = "snapshots"
+	ctxExclude       = "exclude"
+	ctxContributors  = "contributors"
+	ctxExcludeInput  = "exclude-input" // typing a .git/info/exclude pattern
+	ctxTags          = "tags"
+	ctxHistory       = "history"
+	ctxLogDetail     = "log-detail" // a commit opened from the log
+	ctxHooks         = "hooks"
+	ctxTool          = "tool" // any other tool screen
+)
+
+func hasSpellIssues(m model) bool    { return len(m.spellIssues) > 0 }
+func hasHookRetry(m model) bool      { return m.hookRetry != "" }
+func hasConflictOp(m model) bool     { return m.conflictOp != "" }
+func hasConflicts(m model) bool      { return m.gitState.Conflicts > 0 }
+func hasReviewComments(m model) bool { return len(m.reviewComments) > 0 }
+func historyOnBranch(m model) bool   { return m.historyRef == "" && m.historyAuthor == "" }
+func historyOffBranch(m model) bool  { return m.historyRef != "" && m.historyAuthor == "" }
+func historyOnRef(m model) bool      { return len(m.historyBranches()) > 0 }
+
+// quickCommitBindings commit with suggestion 1-9 in one key. Digits alone
+// switch tabs, so they take alt.
+func quickCommitBindings() []keyBinding {
+	bindings := make([]keyBinding, 9)
+	for i := range bindings {
+		n := i + 1
+		bindings[i] = keyBinding{
+			action: fmt.Sprintf("commit-%d", n),
+			keys:   []string{fmt.Sprintf("alt+%d", n)},
+			help:   fmt.Sprintf("commit suggestion %d", n),
+			hidden: n > 1,
+			writes: true,
+		}
+	}
+	bindings[0].label, bindings[0].help = "alt+1-9", "commit #N"
+	return bindings
+}
+
+// Shared list navigation
+var (
+	navDown = keyBinding{action: "down", keys: []string{"j", "down"}, label: "j/k", help: "nav"}
+	navUp   = keyBinding{action: "up", keys: []string{"k", "up"}, help: "up", hidden: true}
+)
+
+func scroll(b keyBinding) keyBinding {
+	if b.action == "down" {
+		b.help = "scroll"
+	}
+	return b
+}
+
+func defaultKeyMap() keyMap {
+	km := keyMap{
+		ctxGlobal: {
+			{action: "quit", keys: []string{"ctrl+c", "q"}, help: "quit", hidden: true},
+			{action: "workspace", keys: []string{"1"}, help: "workspace tab", hidden: true},
+			{action: "commit", keys: []string{"2"}, help: "commit tab", hidden: true},
+			{action: "branches", keys: []string{"3"}, help: "branches tab", hidden: true},
+			{action: "tools", keys: []string{"4"}, help: "tools tab", hidden: true},
+			{action: "prs", keys: []string{"5"}, help: "PRs tab", hidden: true},
+			{action: "shell", keys: []string{"ctrl+z"}, help: "shell", writes: true},
+			{action: "sync", keys: []string{"ctrl+q"}, help: "push/pull"},
+			{action: "refresh", keys: []string{"ctrl+r"}, help: "refresh"},
+			{action: "tour", keys: []string{"f1"}, help: "tour", hidden: true},
+			{action: "debug", keys: []string{"f12"}, help: "debug overlay", hidden: true},
+		},
+		ctxShell: {
+			{action: "run", keys: []string{"enter"}, help: "run"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxTour: {
+			{action: "next", keys: []string{"enter", "right", "l"}, label: "enter/→", help: "next"},
+			{action: "back", keys: []string{"left", "h"}, label: "←", help: "back"},
+			{action: "skip", keys: []string{"esc"}, help: "skip tour"},
+		},
+		ctxIndexLock: {
+			{action: "wait", keys: []string{"w"}, help: "wait"},
+			{action: "retry", keys: []string{"r"}, help: "retry"},
+			{action: "remove", keys: []string{"d"}, help: "remove lock", writes: true},
+			{action: "back", keys: []string{"esc"}, help: "dismiss"},
+		},
+		ctxFiles: {
+			navDown, navUp,
+			{action: "stage", keys: []string{" ", "space"}, label: "space", help: "stage", writes: true},
+			{action: "stage-all", keys: []string{"a"}, help: "all", writes: true},
+			{action: "unstage-all", keys: []string{"r"}, help: "unstage all", hidden: true, writes: true},
+			{action: "stage-tracked", keys: []string{"m"}, label: "m/u/A", help: "stage modified/untracked/dir", writes: true},
+			{action: "stage-untracked", keys: []string{"u"}, help: "stage untracked", hidden: true, writes: true},
+			{action: "stage-dir", keys: []string{"A"}, help: "stage directory", hidden: true, writes: true},
+			{action: "reset-commit", keys: []string{"R"}, help: "reset commit", writes: true},
+			{action: "diff", keys: []string{"enter"}, help: "diff"},
+			{action: "blame", keys: []string{"b"}, help: "blame"},
+			{action: "discard", keys: []string{"d"}, help: "discard", writes: true},
+			{action: "whitespace", keys: []string{"W"}, label: "W/B/+/-", help: "diff options"},
+			{action: "blank-lines", keys: []string{"B"}, help: "ignore blank lines", hidden: true},
+			{action: "more-context", keys: []string{"+", "="}, help: "more context", hidden: true},
+			{action: "less-context", keys: []string{"-"}, help: "less context", hidden: true},
+			{action: "preview", keys: []string{"p"}, help: "toggle preview", hidden: true},
+			{action: "preview-up", keys: []string{"w"}, help: "scroll preview up", hidden: true},
+			{action: "preview-down", keys: []string{"s"}, help: "scroll preview down", hidden: true},
+			{action: "conflicts", keys: []string{"c"}, help: "conflicts", when: hasConflicts},
+			{action: "exclude", keys: []string{"i"}, help: "ignore locally", hidden: true, writes: true},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel", hidden: true},
+		},
+		ctxDiff: {
+			{action: "back", keys: []string{"esc"}, help: "back"},
+			scroll(navDown), navUp,
+			{action: "next-hunk", keys: []string{"n"}, label: "n/p", help: "next/prev hunk"},
+			{action: "prev-hunk", keys: []string{"p"}, help: "previous hunk", hidden: true},
+			{action: "next-file", keys: []string{"]"}, label: "]/[", help: "next/prev file"},
+			{action: "prev-file", keys: []string{"["}, help: "previous file", hidden: true},
+			{action: "whitespace", keys: []string{"W"}, help: "whitespace"},
+			{action: "blank-lines", keys: []string{"B"}, help: "blank lines"},
+			{action: "more-context", keys: []string{"+", "="}, label: "+/-", help: "context"},
+			{action: "less-context", keys: []string{"-"}, help: "less context", hidden: true},
+		},
+		ctxBlame: {
+			{action: "back", keys: []string{"esc"}, help: "back"},
+			scroll(navDown), navUp,
+		},
+		ctxConflicts: {
+			{action: "back", keys: []string{"esc"}, help: "back"},
+			navDown, navUp,
+			{action: "diff", keys: []string{"enter"}, help: "diff"},
+			{action: "edit", keys: []string{"e"}, help: "edit result", writes: true},
+			{action: "continue", keys: []string{"C"}, help: "continue", when: hasConflictOp, writes: true},
+			{action: "abort", keys: []string{"A"}, help: "abort", when: hasConflictOp, writes: true},
+			{action: "files", keys: []string{"c"}, help: "files"},
+		},
+		ctxResolve: {
+			{action: "next-block", keys: []string{"tab"}, label: "tab/shift+tab", help: "block"},
+			{action: "prev-block", keys: []string{"shift+tab"}, help: "previous block", hidden: true},
+			{action: "ours", keys: []string{"ctrl+o"}, help: "ours"},
+			{action: "theirs", keys: []string{"ctrl+r"}, help: "theirs"},
+			{action: "base", keys: []string{"ctrl+y"}, help: "base"},
+			{action: "both", keys: []string{"ctrl+g"}, help: "both"},
+			{action: "save", keys: []string{"ctrl+s"}, help: "write & stage"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxCommit: append([]keyBinding{
+			{action: "select-up", keys: []string{"up"}, label: "↑/↓", help: "select"},
+			{action: "select-down", keys: []string{"down"}, help: "select next", hidden: true},
+			{action: "commit", keys: []string{"enter"}, help: "commit", writes: true},
+			{action: "commit-push", keys: []string{"alt+enter", "ctrl+y"}, label: "alt+enter", help: "commit & push", writes: true},
+			{action: "retry", keys: []string{"alt+r"}, help: "retry commit", when: hasHookRetry, writes: true},
+			{action: "why", keys: []string{"ctrl+l"}, help: "why"},
+			{action: "identity", keys: []string{"alt+a"}, help: "change identity", hidden: true},
+			{action: "signing", keys: []string{"alt+n"}, help: "toggle signing", hidden: true, writes: true},
+			{action: "override", keys: []string{"alt+o"}, help: "author/date", hidden: true},
+			{action: "footers", keys: []string{"alt+f"}, help: "footers", hidden: true},
+			{action: "empty", keys: []string{"alt+e"}, help: "empty commit", hidden: true, writes: true},
+			{action: "amend-message", keys: []string{"alt+m"}, help: "amend message", hidden: true, writes: true},
+			{action: "custom", keys: []string{"tab"}, help: "custom"},
+			{action: "type-scope", keys: []string{"ctrl+t"}, help: "type/scope"},
+			{action: "breaking", keys: []string{"ctrl+x"}, help: "breaking"},
+			{action: "split", keys: []string{"ctrl+o"}, help: "split"},
+			{action: "pick-files", keys: []string{"ctrl+p"}, help: "pick files"},
+			{action: "staged-files", keys: []string{"ctrl+f"}, help: "staged files"},
+			{action: "clear", keys: []string{"esc"}, help: "clear"},
+			{action: "spell-check", keys: []string{"ctrl+s"}, help: "spell-check"},
+			{action: "spell-fix", keys: []string{"ctrl+r"}, help: "fix", when: hasSpellIssues},
+			{action: "add-word", keys: []string{"ctrl+g"}, help: "add word", when: hasSpellIssues, writes: true},
+		}, quickCommitBindings()...),
+		ctxNothingStaged: {
+			{action: "stage-all", keys: []string{"a", "enter"}, label: "a/enter", help: "stage all & commit", writes: true},
+			{action: "empty", keys: []string{"e"}, help: "empty commit", writes: true},
+			{action: "amend-message", keys: []string{"m"}, help: "amend message", writes: true},
+			{action: "back", keys: []string{"esc"}, help: "back to workspace"},
+		},
+		ctxSummary: {
+			{action: "push", keys: []string{"p"}, help: "push", writes: true},
+			{action: "continue", keys: []string{"c"}, help: "continue"},
+			scroll(navDown), navUp,
+		},
+		ctxBreaking: {
+			{action: "save", keys: []string{"enter"}, help: "save footer"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxMessage: {
+			{action: "confirm", keys: []string{"enter"}, help: "confirm"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxFooter: {
+			{action: "next", keys: []string{"tab"}, help: "footer type"},
+			{action: "add", keys: []string{"enter"}, help: "add"},
+			{action: "remove", keys: []string{"alt+x"}, help: "remove last"},
+			{action: "done", keys: []string{"esc"}, help: "done"},
+		},
+		ctxOverride: {
+			{action: "next", keys: []string{"tab", "shift+tab"}, label: "tab", help: "author/date"},
+			{action: "apply", keys: []string{"enter"}, help: "apply"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxPartial: {
+			navDown, navUp,
+			{action: "toggle", keys: []string{" ", "space"}, label: "space", help: "toggle"},
+			{action: "all", keys: []string{"a"}, help: "all"},
+			{action: "done", keys: []string{"enter"}, help: "done"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxStagedFiles: {
+			navDown, navUp,
+			{action: "jump", keys: []string{"enter"}, help: "adjust in workspace"},
+			{action: "cancel", keys: []string{"esc"}, help: "back"},
+		},
+		ctxSplit: {
+			navDown, navUp,
+			{action: "commit-all", keys: []string{"enter"}, help: "commit all", writes: true},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxPicker: {
+			navDown, navUp,
+			{action: "select", keys: []string{"enter"}, help: "select"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxBranches: {
+			navDown, navUp,
+			{action: "checkout", keys: []string{"enter"}, help: "checkout", writes: true},
+			{action: "new", keys: []string{"n"}, help: "new", writes: true},
+			{action: "delete", keys: []string{"d"}, help: "delete", writes: true},
+			{action: "compare", keys: []string{"c"}, help: "compare"},
+			{action: "compare-default", keys: []string{"C"}, help: "vs default"},
+			{action: "compare-tag", keys: []string{"T"}, help: "since last tag"},
+			{action: "compare-upstream", keys: []string{"u"}, help: "vs upstream"},
+			{action: "push-branch", keys: []string{"P"}, help: "push branch", writes: true},
+			{action: "fast-forward", keys: []string{"L"}, help: "fast-forward", writes: true},
+			{action: "history", keys: []string{"h"}, help: "history"},
+			{action: "checkout-pr", keys: []string{"#"}, help: "check out PR", writes: true},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel", hidden: true},
+		},
+		ctxPRNumber: {
+			{action: "checkout", keys: []string{"enter"}, help: "check out"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxCompare: {
+			navDown, navUp,
+			{action: "review", keys: []string{"enter"}, help: "review file"},
+			{action: "export", keys: []string{"e"}, help: "export review", when: hasReviewComments, writes: true},
+			{action: "post", keys: []string{"P"}, help: "post via gh", when: hasReviewComments, writes: true},
+			{action: "export-report", keys: []string{"x"}, label: "x/X", help: "export md/json", writes: true},
+			{action: "export-json", keys: []string{"X"}, help: "export json", hidden: true, writes: true},
+			{action: "copy-report", keys: []string{"C"}, help: "copy report"},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxReview: {
+			{action: "back", keys: []string{"esc"}, help: "back"},
+			navDown, navUp,
+			{action: "comment", keys: []string{"c", "enter"}, help: "comment"},
+			{action: "delete", keys: []string{"d"}, help: "delete comment"},
+			{action: "next-comment", keys: []string{"n"}, label: "n/p", help: "next/prev comment"},
+			{action: "prev-comment", keys: []string{"p"}, help: "previous comment", hidden: true},
+			{action: "next-file", keys: []string{"]"}, label: "]/[", help: "next/prev file"},
+			{action: "prev-file", keys: []string{"["}, help: "previous file", hidden: true},
+		},
+		ctxReviewComment: {
+			{action: "save", keys: []string{"enter"}, help: "save comment"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxPRs: {
+			navDown, navUp,
+			{action: "checkout", keys: []string{"enter"}, help: "check out", writes: true},
+			{action: "open", keys: []string{"o"}, help: "open in browser"},
+			{action: "refresh", keys: []string{"r"}, help: "refresh"},
+		},
+		ctxTools: {
+			navDown, navUp,
+			{action: "select", keys: []string{"enter"}, help: "select"},
+			{action: "log", keys: []string{"o"}, help: "log", hidden: true},
+			{action: "stash", keys: []string{"s"}, help: "stash", hidden: true},
+			{action: "tags", keys: []string{"t"}, help: "tags", hidden: true},
+			{action: "history", keys: []string{"h"}, help: "history", hidden: true},
+			{action: "undo", keys: []string{"u"}, help: "undo", hidden: true},
+			{action: "rebase", keys: []string{"r"}, help: "rebase", hidden: true},
+			{action: "push", keys: []string{"p"}, help: "push", hidden: true, writes: true},
+			{action: "fetch", keys: []string{"f"}, help: "fetch", hidden: true},
+			{action: "pull", keys: []string{"l"}, help: "pull", hidden: true, writes: true},
+			{action: "hooks", keys: []string{"g"}, help: "hooks", hidden: true},
+			{action: "clean", keys: []string{"x"}, help: "clean", hidden: true},
+			{action: "clone", keys: []string{"c"}, help: "clone", hidden: true},
+			{action: "init", keys: []string{"i"}, help: "init", hidden: true},
+			{action: "identity", keys: []string{"a"}, help: "identity", hidden: true},
+			{action: "config", keys: []string{"e"}, help: "config", hidden: true},
+			{action: "aliases", keys: []string{"w"}, help: "aliases", hidden: true},
+			{action: "recover", keys: []string{"v"}, help: "recover", hidden: true},
+			{action: "signing", keys: []string{"n"}, help: "signing", hidden: true},
+			{action: "jobs", keys: []string{"b"}, help: "jobs", hidden: true},
+			{action: "snapshots", keys: []string{"z"}, help: "snapshots", hidden: true},
+			{action: "exclude", keys: []string{"I"}, help: "local excludes", hidden: true},
+			{action: "messages", keys: []string{"m"}, help: "messages", hidden: true},
+			{action: "contributors", keys: []string{"A"}, help: "contributors", hidden: true},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxStash: {
+			navDown, navUp,
+			{action: "stash", keys: []string{"s"}, help: "stash", writes: true},
+			{action: "pop", keys: []string{"p", "enter"}, help: "pop", writes: true},
+			{action: "apply", keys: []string{"a"}, help: "apply", writes: true},
+			{action: "drop", keys: []string{"d"}, help: "drop", hidden: true, writes: true},
+			{action: "files", keys: []string{"f"}, help: "apply files", writes: true},
+			{action: "branch", keys: []string{"b"}, help: "to branch", writes: true},
+			{action: "preview-down", keys: []string{"J"}, label: "J/K", help: "scroll diff"},
+			{action: "preview-up", keys: []string{"K"}, help: "scroll diff up", hidden: true},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxStashFiles: {
+			navDown, navUp,
+			{action: "toggle", keys: []string{" ", "space"}, label: "space", help: "toggle"},
+			{action: "all", keys: []string{"a"}, help: "all"},
+			{action: "apply", keys: []string{"enter"}, help: "apply selected", writes: true},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxStashBranch: {
+			{action: "create", keys: []string{"enter"}, help: "create branch"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxDanger: {
+			{action: "confirm", keys: []string{"enter"}, help: "confirm"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxSnapshots: {
+			navDown, navUp,
+			{action: "restore", keys: []string{"enter"}, help: "restore", writes: true},
+			{action: "snapshot", keys: []string{"s"}, help: "snapshot now", writes: true},
+			{action: "delete", keys: []string{"d"}, help: "delete", hidden: true, writes: true},
+			{action: "preview-down", keys: []string{"J"}, label: "J/K", help: "scroll diff"},
+			{action: "preview-up", keys: []string{"K"}, help: "scroll diff up", hidden: true},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxContributors: {
+			navDown, navUp,
+			{action: "history", keys: []string{"enter"}, help: "their commits"},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxExclude: {
+			navDown, navUp,
+			{action: "new", keys: []string{"n"}, help: "add pattern", writes: true},
+			{action: "edit", keys: []string{"e", "enter"}, help: "edit", writes: true},
+			{action: "delete", keys: []string{"d"}, help: "delete", writes: true},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxExcludeInput: {
+			{action: "save", keys: []string{"enter"}, help: "save"},
+			{action: "cancel", keys: []string{"esc"}, help: "cancel"},
+		},
+		ctxLogDetail: {
+			navDown, navUp,
+			{action: "open", keys: []string{"enter"}, help: "file diff/fold"},
+			{action: "preview-down", keys: []string{"J"}, label: "J/K", help: "scroll diff"},
+			{action: "preview-up", keys: []string{"K"}, help: "scroll diff up", hidden: true},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxTags: {
+			navDown, navUp,
+			{action: "new", keys: []string{"n"}, help: "new", writes: true},
+			{action: "delete", keys: []string{"d"}, help: "delete", writes: true},
+			{action: "push", keys: []string{"p"}, help: "push", writes: true},
+			{action: "push-all", keys: []string{"P"}, help: "push all", hidden: true, writes: true},
+			{action: "verify", keys: []string{"v"}, help: "verify"},
+			{action: "compare", keys: []string{"c"}, help: "changes since"},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxHistory: {
+			navDown, navUp,
+			{action: "mark", keys: []string{" ", "space"}, label: "space", help: "mark range"},
+			{action: "cherry-pick", keys: []string{"c"}, help: "cherry-pick", when: historyOffBranch, writes: true},
+			{action: "revert", keys: []string{"R"}, help: "revert", when: historyOnBranch, writes: true},
+			{action: "rebase", keys: []string{"r"}, help: "rebase", when: historyOnBranch, writes: true},
+			{action: "verify", keys: []string{"v"}, help: "verify"},
+			{action: "verify-all", keys: []string{"V"}, help: "verify all"},
+			{action: "branch", keys: []string{"b"}, help: "go to branch", when: historyOnRef},
+			{action: "export-csv", keys: []string{"x"}, label: "x/X", help: "export csv/json", writes: true},
+			{action: "export-json", keys: []string{"X"}, help: "export json", hidden: true, writes: true},
+			{action: "dates", keys: []string{"d"}, help: "dates"},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxHooks: {
+			navDown, navUp,
+			{action: "install", keys: []string{"enter"}, help: "install", writes: true},
+			{action: "remove", keys: []string{"r"}, help: "remove", writes: true},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+		ctxTool: {
+			navDown, navUp,
+			{action: "select", keys: []string{"enter"}, help: "select"},
+			{action: "back", keys: []string{"esc"}, help: "back"},
+		},
+	}
+
+	for ctx, bindings := range km {
+		for i := range bindings {
+			b := &bindings[i]
+			b.handle = b.keys[0]
+			b.keys = slices.Clone(b.keys)
+			b.defaults = slices.Clone(b.keys)
+		}
+		km[ctx] = bindings
+	}
+	return km
+}
+
+// remap replaces the keys of actions, as configured under [keys.<context>]
+func (km keyMap) remap(overrides map[string]map[string][]string) error {
+	contexts := make([]string, 0, len(overrides))
+	for ctx := range overrides {
+		contexts = append(contexts, ctx)
+	}
+	sort.Strings(contexts)
+
+	for _, ctx := range contexts {
+		bindings, ok := km[ctx]
+		if !ok {
+			return fmt.Errorf("unknown key context %q", ctx)
+		}
+		for action, keys := range overrides[ctx] {
+			i := slices.IndexFunc(bindings, func(b keyBinding) bool { return b.action == action })
+			if i < 0 {
+				return fmt.Errorf("unknown action %q in [keys.%s]", action, ctx)
+			}
+			if len(keys) == 0 {
+				return fmt.Errorf("no keys for %s.%s", ctx, action)
+			}
+			bindings[i].keys = slices.Clone(keys)
+			bindings[i].label = ""
+		}
+	}
+	return nil
+}
+
+// resolve translates a pressed key into the key the context's handler
+// expects. Default keys taken away by a remap resolve to "".
+func (km keyMap) resolve(ctx, key string) string {
+	displaced := false
+	for _, b := range km[ctx] {
+		if slices.Contains(b.keys, key) {
+			return b.handle
+		}
+		if slices.Contains(b.defaults, key) {
+			displaced = true
+		}
+	}
+	if displaced {
+		return ""
+	}
+	return key
+}
+
+// claims reports whether key triggers a binding of ctx that applies to m,
+// one shown in the footer
+func (km keyMap) claims(ctx, key string, m model) bool {
+	for _, b := range km[ctx] {
+		if slices.Contains(b.keys, key) {
+			return b.when == nil || b.when(m)
+		}
+	}
+	return false
+}
+
+// writes reports whether the action behind handle changes the repository
+func (km keyMap) writes(ctx, handle string) bool {
+	for _, b := range km[ctx] {
+		if b.handle == handle {
+			return b.writes
+		}
+	}
+	return false
+}
+
+// keyFor returns the first key bound to an action, for labels in the UI
+func (km keyMap) keyFor(ctx, action string) string {
+	for _, b := range km[ctx] {
+		if b.action == action {
+			return b.keys[0]
+		}
+	}
+	return ""
+}
+
+// keyContext names the key context for the current mode
+func (m model) keyContext() string {
+	if m.lockPrompt != nil {
+		return ctxIndexLock
+	}
+	if m.touring {
+		return ctxTour
+	}
+	if m.reviewInput.Focused() {
+		return ctxReviewComment
+	}
+	if m.prInput.Focused() {
+		return ctxPRNumber
+	}
+	if m.stashBranchInput.Focused() {
+		return ctxStashBranch
+	}
+	if m.danger != nil {
+		return ctxDanger
+	}
+	if m.messageInput.Focused() {
+		return ctxMessage
+	}
+	if m.excludeInput.Focused() {
+		return ctxExcludeInput
+	}
+	switch m.tab {
+	case "workspace":
+		switch m.viewMode {
+		case "diff":
+			return ctxDiff
+		case "blame":
+			return ctxBlame
+		case "conflicts":
+			return ctxConflicts
+		case "resolve":
+			return ctxResolve
+		}
+		return ctxFiles
+	case "commit":
+		switch {
+		case m.commitSummary != nil:
+			return ctxSummary
+		case m.gitState.StagedFiles == 0:
+			return ctxNothingStaged
+		case m.splitGroups != nil:
+			return ctxSplit
+		case m.partialFiles != nil:
+			return ctxPartial
+		case m.stagedBrowsing:
+			return ctxStagedFiles
+		case m.commitPicker != "":
+			return ctxPicker
+		case m.breakingInput.Focused():
+			return ctxBreaking
+		case m.overrideOpen:
+			return ctxOverride
+		case m.footerOpen:
+			return ctxFooter
+		}
+		return ctxCommit
+	case "branches":
+		switch {
+		case m.review != nil:
+			return ctxReview
+		case m.branchComparison != nil:
+			return ctxCompare
+		}
+		return ctxBranches
+	case "prs":
+		return ctxPRs
+	case "tools":
+		switch m.toolMode {
+		case "menu":
+			return ctxTools
+		case "stash":
+			if m.stashFiles != nil {
+				return ctxStashFiles
+			}
+			return ctxStash
+		case "snapshots":
+			return ctxSnapshots
+		case "exclude":
+			return ctxExclude
+		case "contributors":
+			return ctxContributors
+		case "tags":
+			return ctxTags
+		case "log":
+			if m.logDetail != nil {
+				return ctxLogDetail
+			}
+		case "history":
+			return ctxHistory
+		case "hooks":
+			return ctxHooks
+		}
+		return ctxTool
+	}
+	return ctxGlobal
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/gitty/internal/crash"
+	"github.com/LFroesch/gitty/internal/git"
+	"github.com/LFroesch/gitty/internal/git/gittest"
+	"github.com/LFroesch/gitty/internal/jobs"
+)
+
+// The refresh loaders read through model.repo, so they and the updates
+// they feed run against a fake repository
+func TestLoadersReadRepo(t *testing.T) {
+	repo := gittest.Repo{
+		Files: []git.Change{{File: "main.go", Status: " M"}, {File: "notes.txt", Status: "??"}},
+		State: git.Status{Branch: "topic", Ahead: 2, Operation: "merge"},
+		Commits: []git.Commit{
+			{Hash: "c3", Message: "third"}, {Hash: "c2", Message: "second"},
+			{Hash: "c1", Message: "first"}, {Hash: "c0", Message: "root"},
+		},
+		Conflicts: []string{"main.go"},
+	}
+	m := model{repo: repo, loading: make(map[string]bool)}
+
+	update := func(cmd func() tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(cmd())
+		m = next.(model)
+	}
+	update(m.loadGitStatus())
+	update(m.loadGitChanges())
+	update(m.loadRecentCommits())
+	update(m.loadConflicts())
+
+	if m.gitState.Branch != "topic" || m.gitState.Ahead != 2 || m.gitState.Operation != "merge" {
+		t.Errorf("gitState = %+v, want the fake's status", m.gitState)
+	}
+	if !slices.Equal(m.changes, repo.Files) {
+		t.Errorf("changes = %+v, want %+v", m.changes, repo.Files)
+	}
+	if len(m.recentCommits) != 3 || m.recentCommits[0].Hash != "c3" {
+		t.Errorf("recentCommits = %+v, want the 3 newest", m.recentCommits)
+	}
+	if len(m.conflicts) != 1 || m.conflicts[0].Path != "main.go" {
+		t.Errorf("conflicts = %+v, want main.go", m.conflicts)
+	}
+}
+
+// panickyRepo fails the status loader the way a bug in it would
+type panickyRepo struct{ gittest.Repo }
+
+func (panickyRepo) Status() git.Status { panic("status exploded") }
+
+// Commands returned by Update run on Bubble Tea's goroutines, which
+// recover panics without a report unless the command is guarded
+func TestLoaderPanicLeavesReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := model{
+		repoPath:   "/work/repo",
+		repo:       panickyRepo{},
+		jobs:       jobs.New(),
+		loading:    make(map[string]bool),
+		lockPrompt: &indexLockPrompt{},
+	}
+	_, cmd := m.Update(lockRemovedMsg{})
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok || len(batch) == 0 {
+		t.Fatalf("Update returned %T, want a batch with the status loader first", msg)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "status exploded" {
+				t.Errorf("recovered %v, want the loader's panic", r)
+			}
+		}()
+		batch[0]()
+	}()
+
+	report, ok := crash.TakePending("/work/repo")
+	if !ok {
+		t.Fatal("no pending crash report after the loader panicked")
+	}
+	if report.Panic != "status exploded" {
+		t.Errorf("report.Panic = %q, want the loader's panic", report.Panic)
+	}
+}
@@ -0,0 +1,1072 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/LFroesch/gitty/internal/config"
+	"github.com/LFroesch/gitty/internal/crash"
+	"github.com/LFroesch/gitty/internal/forge"
+	"github.com/LFroesch/gitty/internal/git"
+	"github.com/LFroesch/gitty/internal/jobs"
+	"github.com/LFroesch/gitty/internal/spell"
+	"github.com/LFroesch/gitty/internal/workspace"
+	"github.com/LFroesch/gitty/suggest"
+)
+
+// Constants
+const uiOverhead = 9 // Header (1) + status (1) + borders (4) + padding (3)
+
+// compactOverhead is uiOverhead for the compact layout: header (2), a
+// one-line status bar (1) and the panel's borders (2)
+const compactOverhead = 5
+
+// Terminal sizes. Below compactWidth x compactHeight gitty switches to the
+// compact layout; below minWidth x minHeight it only says the terminal is
+// too small.
+const (
+	compactWidth  = 80
+	compactHeight = 24
+	minWidth      = 40
+	minHeight     = 10
+)
+
+// statusLogSize is how many status messages the messages tool keeps
+const statusLogSize = 50
+
+// debugTrailSize is how many handled messages the debug overlay keeps
+const debugTrailSize = 100
+
+// Message types for tea.Msg
+
+type statusMsg struct{ message string }
+type gitChangesMsg []git.Change
+type commitSuggestionsMsg struct {
+	suggestions []suggest.Suggestion
+	breaking    []string // likely breaking changes in the staged diff
+}
+type gitStatusMsg git.Status
+type branchesMsg struct {
+	branches []git.Branch
+	base     string
+}
+type commitsMsg []git.Commit
+type recentCommitsMsg []git.Commit
+type diffMsg string
+type conflictsMsg []git.ConflictFile
+type comparisonMsg git.BranchComparison
+type rebaseCommitsMsg []git.RebaseCommit
+type prsMsg struct {
+	prs []forge.PR
+	err error
+}
+type reviewDiffMsg struct {
+	file git.DiffFile
+	diff string
+}
+
+// reviewDiff is a compared file opened for annotation
+type reviewDiff struct {
+	file      git.DiffFile
+	lines     []string
+	positions []diffPos
+	cursor    int
+}
+
+// diffPos is where a diff line sits in the file; side is "" for headers
+type diffPos struct {
+	line int
+	side string // "new", or "old" for removed lines
+}
+
+// reviewComment is a note on one line of a compared file
+type reviewComment struct {
+	File string
+	Line int    // line number on Side
+	Side string // "new", or "old" for removed lines
+	Code string // the diff line, quoted in the export
+	Body string
+}
+type pushOutputMsg struct {
+	output string
+	commit string
+}
+type commitSuccessMsg struct {
+	hash     string
+	message  string
+	diff     string
+	files    []string
+	pushed   string // push summary when the commit was pushed right away
+	pushErr  string
+	override git.CommitOverride
+}
+
+// commitFooter is one "Token: value" footer of a commit message
+type commitFooter struct {
+	token string
+	value string
+}
+
+// footerTokens are the footers the builder offers, with a placeholder each
+var footerTokens = []struct{ token, placeholder string }{
+	{"Refs", "ABC-123"},
+	{"Closes", "#42"},
+	{"Reviewed-by", "Name <email>"},
+	{"BREAKING CHANGE", "what breaks, and how to migrate"},
+}
+
+// hookRewroteMsg reports a commit its hooks stopped after reformatting staged
+// files: the ones restaged, and those left alone because they also had
+// unstaged edits of the user's
+type hookRewroteMsg struct {
+	message  string
+	restaged []string
+	skipped  []string
+}
+
+// commitPushDoneMsg wraps the result of commit-and-push so its spinner stops
+// whether the commit succeeded or not
+type commitPushDoneMsg struct{ result tea.Msg }
+type stashListMsg []git.Stash
+type tagListMsg []git.Tag
+type releasePreviewMsg git.BranchComparison
+type hookStatusMsg bool
+type preCommitHookMsg bool
+type stashDiffMsg string
+type stashFilesMsg []git.DiffFile
+type logCommitsMsg []git.Commit
+type logDetailMsg git.CommitDetail
+type logDiffMsg string
+type logFileDiffMsg struct {
+	path string
+	diff string
+}
+type blameMsg []git.BlameLine
+type cloneResultMsg struct {
+	output  string
+	err     error
+	newPath string
+}
+type repoSwitchMsg string
+type identityMsg git.Identity
+type knownIdentitiesMsg []git.Identity
+type configEntriesMsg []git.ConfigEntry
+type aliasesMsg []git.Alias
+type commitScopesMsg []string
+type commitGroupsMsg []git.CommitGroup
+type partialFilesMsg []string
+type timeRestoreMsg struct {
+	restore git.TimeRestore
+	err     error
+}
+type lostCommitsMsg struct {
+	commits []git.LostCommit
+	err     error
+}
+type lostDiffMsg string
+type amendMessageMsg struct {
+	message string
+	pushed  bool
+	err     error
+}
+type signingMsg struct {
+	keys   []git.SigningKey
+	config git.SigningConfig
+}
+type signingTestMsg struct{ err error }
+type signingConfigMsg git.SigningConfig
+type shellDoneMsg struct{ err error }
+type operationMsg struct {
+	op     string
+	rebase *git.RebaseProgress // nil unless a rebase is in progress
+}
+type repoStampMsg string
+type loadingMsg string    // a pane's load has started
+type refreshMsg []tea.Msg // everything refreshAll read, handled in order
+type conflictDocMsg struct {
+	doc *git.ConflictDoc
+	err error
+}
+type pushUndoMsg struct {
+	undo git.PushUndo
+	err  error
+}
+type divergenceMsg struct {
+	div git.Divergence
+	err error
+}
+type fetchResultMsg struct{ results []git.FetchResult }
+type upstreamCommitsMsg struct {
+	div git.Divergence
+	err error
+}
+type showDivergenceMsg struct{}
+type jobsTickMsg struct{}
+type snapshotTickMsg struct{}
+type snapshotsMsg []git.Snapshot
+type contributorsMsg []git.Contributor
+type snapshotDiffMsg string
+type excludeLinesMsg []string
+type keysFadeMsg struct{}
+
+// statusExpiredMsg clears the status line if it still shows message
+// statusEntry is a status message kept for the messages tool
+type statusEntry struct {
+	at      time.Time
+	message string
+}
+
+// debugEntry is a message Update handled in debug mode: what it was, how
+// long it took and the key context before and after
+type debugEntry struct {
+	at       time.Time
+	msg      string
+	took     time.Duration
+	from, to string
+}
+
+type statusExpiredMsg struct {
+	message string
+	at      time.Time
+}
+type indexLockMsg struct {
+	lock  *git.IndexLock
+	op    string
+	retry tea.Cmd
+}
+type lockWaitDoneMsg struct{ err error }
+type tagVerifiedMsg struct {
+	name string
+	sigs tagSignature
+}
+type commitsVerifiedMsg map[string]git.Signature
+type lockRemovedMsg struct{ err error }
+type showConflictsMsg struct{}
+type spellCheckerMsg struct{ checker *spell.Checker }
+type aliasOutputMsg struct {
+	name   string
+	output string
+	err    error
+}
+
+// Model
+
+type model struct {
+	// State management
+	tab         string // "workspace", "commit", "branches", "tools"
+	toolMode    string // when tab="tools": "menu", "undo", "rebase", "history", "remote", "stash", "tags", "hooks"
+	toolSubmenu string // "local", "remote", "history", "advanced", "hooks"
+	viewMode    string // workspace sub-states: "files", "diff", "conflicts", "resolve"
+
+	// Data
+	changes          []git.Change
+	suggestions      []suggest.Suggestion
+	gitState         git.Status
+	branches         []git.Branch
+	defaultBranch    string
+	commits          []git.Commit
+	conflicts        []git.ConflictFile
+	conflictOp       string // operation that produced the conflicts: "merge", "rebase", ...
+	rebaseProgress   *git.RebaseProgress
+	branchComparison *git.BranchComparison
+	rebaseCommits    []git.RebaseCommit
+
+	// Review mode: comments on the files of a branch comparison (the file
+	// cursor is compareCursor)
+	review         *reviewDiff
+	reviewComments []reviewComment
+	reviewRange    string // comparison the comments belong to
+	reviewInput    textinput.Model
+
+	// UI content
+	diffContent   string
+	pushOutput    string
+	recentCommits []git.Commit
+	commitSummary *commitSuccessMsg
+
+	// List navigation (replaces tables)
+	fileCursor     int
+	fileOffset     int
+	branchCursor   int
+	branchOffset   int
+	toolCursor     int
+	historyCursor  int
+	historyOffset  int
+	historyMarked  bool   // a range is being marked, from historyMark to the cursor
+	historyMark    int    // where the marked range starts
+	historyRef     string // branch the history shows, "" for the current one
+	historyAuthor  string // email of the contributor the history is limited to
+	absoluteDates  bool   // commit lists show local times instead of "2 days ago"
+	conflictCursor int
+	compareCursor  int
+	rebaseCursor   int
+	undoCursor     int
+	undoOffset     int
+
+	// Inputs
+	commitInput textinput.Model
+	branchInput textinput.Model
+	prInput     textinput.Model // pull request number to check out
+	rebaseInput textinput.Model
+
+	// Loading indicators: panes whose load is in flight
+	loading map[string]bool
+	spinner spinner.Model
+
+	// Background git operations, shared by every copy of the model
+	jobs *jobs.Queue
+
+	// Prompt shown while another program's index.lock blocks a write
+	lockPrompt *indexLockPrompt
+
+	// Typed confirmation of a dangerous action, nil when none is pending
+	danger      *dangerPrompt
+	dangerInput textinput.Model
+
+	// First-launch tour, shown over the tab it describes
+	touring  bool
+	tourStep int
+
+	// Started with --read-only: actions that change the repository are off
+	readOnly bool
+
+	// Presenter mode: recent keys shown over the panel, statuses kept longer
+	presenter     bool
+	presenterKeys []string
+	presenterAt   time.Time // last key press
+
+	// Status messages shown so far, oldest first, for the messages tool
+	statusLog []statusEntry
+
+	// Started with --debug: every message handled is logged, and the last
+	// ones can be shown over the panel
+	debug        bool
+	debugTrail   []debugEntry
+	debugOverlay bool
+
+	// The last session in this repository crashed; enter resumes it
+	crashOffer *crash.Report
+
+	// UI state
+	width              int
+	height             int
+	statusMessage      string
+	statusExpiry       time.Time
+	showDiffPreview    bool
+	diffOptions        git.DiffOptions // whitespace/context flags for diffs
+	selectedSuggestion int
+	showExplanation    bool // expand the selected suggestion's evidence
+	scrollOffset       int
+
+	// Stash
+	stashes          []git.Stash
+	stashCursor      int
+	stashOffset      int
+	stashDiff        string // preview of the selected stash
+	stashDiffOffset  int
+	stashBranchInput textinput.Model
+
+	// Stash file picker (apply only some files), nil when closed
+	stashFiles        []git.DiffFile
+	stashFileSelected map[string]bool
+	stashFileCursor   int
+
+	// WIP snapshots (refs/gitty/snapshots)
+	snapshots          []git.Snapshot
+	snapshotCursor     int
+	snapshotOffset     int
+	snapshotDiff       string // preview of the selected snapshot
+	snapshotDiffOffset int
+
+	// Authors of the current branch (shortlog), most commits first
+	contributors      []git.Contributor
+	contributorCursor int
+	contributorOffset int
+
+	// .git/info/exclude, line by line
+	excludeLines  []string
+	excludeCursor int
+	excludeOffset int
+	excludeEdit   int // line being edited in excludeInput, -1 to append
+	excludeInput  textinput.Model
+
+	// Tags
+	tags      []git.Tag
+	tagCursor int
+	tagOffset int
+	tagInput  textinput.Model
+
+	// What a new tag would release: HEAD compared with the latest tag
+	releasePreview *git.BranchComparison
+
+	// Signature checks run on demand, by tag name and commit hash
+	tagSigs    map[string]tagSignature
+	commitSigs map[string]git.Signature
+
+	// Hooks
+	commitMsgHookInstalled bool
+	preCommitHookInstalled bool
+	hookCursor             int
+
+	// Clean
+	cleanFiles  []string
+	cleanCursor int
+
+	// Log viewer
+	logCommits     []git.Commit
+	logCursor      int
+	logOffset      int
+	logSearch      string
+	logSearchInput textinput.Model
+	logDetail      *git.CommitDetail
+	logDiff        string
+	logFileCursor  int // row of the detail's file tree
+	logFileOffset  int
+	logCollapsed   map[string]bool // folded directories of the file tree
+	logFile        string          // file whose diff the detail shows, "" for all
+	logFileDiff    string
+
+	// Blame
+	blameLines  []git.BlameLine
+	blameCursor int
+	blameOffset int
+	blameFile   string
+
+	// Clone/Init
+	cloneInput textinput.Model
+	initInput  textinput.Model
+
+	// Identity
+	identity       git.Identity
+	identities     []git.Identity
+	identityCursor int
+	identityInput  textinput.Model
+
+	// Config editor
+	configEntries []git.ConfigEntry
+	configCursor  int
+	configOffset  int
+	configScope   string // layer edits are written to: "local" or "global"
+	configEditKey string
+	configInput   textinput.Model
+
+	// Breaking changes
+	breakingChanges []string // detected in the staged diff
+	breakingNote    string   // BREAKING CHANGE footer for the next commit
+	breakingInput   textinput.Model
+
+	// Ticket reference accepted for the next commit (see commit.tickets)
+	ticket string
+
+	// Empty commit or message-only amend, written in the footer
+	messageMode  string // "empty" or "amend"
+	messageInput textinput.Model
+	amendBody    string // rest of HEAD's message, kept when the subject changes
+	amendPushed  bool
+
+	// Message of a commit a formatting hook stopped, retried with alt+r
+	hookRetry string
+
+	// Footers for the next commit, built with alt+f. A BREAKING CHANGE
+	// footer goes to breakingNote so the subject gets its ! marker.
+	footers     []commitFooter
+	footerOpen  bool
+	footerToken int // index into footerTokens
+	footerInput textinput.Model
+
+	// Author/date override for the next commit
+	commitOverride git.CommitOverride
+	overrideOpen   bool
+	authorInput    textinput.Model
+	dateInput      textinput.Model
+
+	// Split commit (staged changes committed in groups)
+	splitGroups []git.CommitGroup
+	splitCursor int
+
+	// Partial commit (subset of staged files)
+	partialFiles    []string // staged files shown in the picker, nil when closed
+	partialSelected map[string]bool
+	partialCursor   int
+	partialPaths    []string // files the next commit is limited to
+
+	// Staged file list on the commit tab
+	stagedBrowsing bool // the list has the keyboard
+	stagedCursor   int
+
+	// Commit type/scope picker
+	commitPicker string // "", "type", "scope"
+	pickerCursor int
+	pickedType   string
+	commitScopes []string // scopes seen in recent history
+	workspace    workspace.Workspace
+	codeOwners   *git.CodeOwners // nil without a CODEOWNERS file
+
+	// PRs tab, shown when gh or glab is installed
+	forge    string // "gh", "glab" or ""
+	prs      []forge.PR
+	prsErr   error
+	prCursor int
+
+	// Spell-check (commit message)
+	speller     *spell.Checker
+	spellOff    bool
+	spellIssues []spell.Issue
+
+	// Undo (reset)
+	undoMode  string // "soft", "mixed" or "hard"
+	undoInput textinput.Model
+
+	// Time-based undo
+	undoTimeInput textinput.Model
+	timeRestore   *git.TimeRestore
+
+	// Undo last push
+	pushUndo    *git.PushUndo
+	pushUndoErr string
+
+	// Per-remote results of the last fetch
+	fetchResults []git.FetchResult
+
+	// Commits a push or pull would transfer, as of the last fetch
+	upstream    *git.Divergence
+	upstreamErr string
+
+	// Diverged branch assistant
+	divergence    *git.Divergence
+	divergenceErr string
+
+	// Lost commit recovery
+	lostCommits []git.LostCommit
+	lostCursor  int
+	lostOffset  int
+	lostDiff    string // preview of the selected commit, "" when showing the list
+	lostStatus  string // scan state/error shown instead of the list
+
+	// Signing setup
+	signingKeys   []git.SigningKey
+	signingConfig git.SigningConfig
+	signingCursor int
+	signingResult string // outcome of the last test signature
+	signingOK     bool
+
+	// Aliases
+	aliases     []git.Alias
+	aliasCursor int
+	aliasOutput string
+
+	// Shell escape
+	shellInput textinput.Model
+
+	// Conflict editor
+	resolveDoc   *git.ConflictDoc
+	resolveBlock int
+	resolutions  []string // composed result per conflict block
+	resolveInput textarea.Model
+
+	// System
+	config           config.Config
+	keys             keyMap
+	gitDir           string
+	commonDir        string // shared git dir; differs from gitDir in linked worktrees
+	bare             bool   // no working tree: only branches and tools are available
+	pollStamp        string // last StateStamp seen by the polling refresher
+	repoPath         string
+	repo             git.Repo // repoPath, for the refresh loaders
+	launchDir        string   // where gitty was started; paths display relative to it
+	lastCommit       string
+	lastStatusUpdate time.Time
+	confirmAction    string
+}
+
+// Styles
+
+var (
+	// Header bar style
+	headerStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Padding(0, 1)
+
+	// Main panel border
+	borderStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62"))
+
+	// Panel header (inside bordered panel)
+	panelHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("99")).
+				BorderBottom(true).
+				BorderStyle(lipgloss.NormalBorder()).
+				BorderForeground(lipgloss.Color("240")).
+				MarginBottom(1)
+
+	// List content padding
+	listStyle = lipgloss.NewStyle().
+			Padding(0, 1)
+
+	// Status bar
+	statusBarStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Padding(0, 1)
+
+	// Title
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("99")).
+			Background(lipgloss.Color("236"))
+
+	// Tab styles
+	tabStyle = lipgloss.NewStyle().
+			Padding(0, 2).
+			Foreground(lipgloss.Color("245")).
+			Background(lipgloss.Color("236"))
+
+	activeTabStyle = lipgloss.NewStyle().
+			Padding(0, 2).
+			Foreground(lipgloss.Color("212")).
+			Background(lipgloss.Color("236")).
+			Bold(true).
+			Underline(true)
+
+	// Counts on a tab header; each badge sets its own palette colour
+	tabBadgeStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Bold(true)
+
+	// Item styles
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("255")).
+			Bold(true)
+
+	normalStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252"))
+
+	// Suggestion styles
+	suggestionStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39")).
+			MarginLeft(2)
+
+	selectedSuggestionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("46")).
+				Bold(true).
+				MarginLeft(2)
+
+	// Status styles
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Background(lipgloss.Color("236"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	successStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("46")).
+			Bold(true)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Bold(true)
+
+	// Scroll indicators
+	scrollIndicatorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("245")).
+				Italic(true)
+
+	// Diff colors
+	diffAddStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("82"))
+
+	diffRemoveStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196"))
+
+	diffHeaderStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("75")).
+			Bold(true)
+
+	diffHunkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("141"))
+
+	// Icon styles
+	iconStagedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("82")).
+			Bold(true)
+
+	iconUnstagedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214"))
+
+	iconUntrackedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("245"))
+
+	iconDeletedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196"))
+
+	iconConflictStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196")).
+				Bold(true)
+
+	// Branch styles
+	branchCurrentStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("82")).
+				Bold(true)
+
+	branchRemoteStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("75"))
+
+	branchAheadStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("82")).
+				Background(lipgloss.Color("236"))
+
+	branchBehindStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214")).
+				Background(lipgloss.Color("236"))
+
+	// Pane border style
+	paneBorderStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240"))
+
+	// Section header
+	sectionHeaderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("212")).
+				Bold(true)
+
+	// Keybind styles (scout-style)
+	keyBindStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("99")).
+			Background(lipgloss.Color("236")).
+			Bold(true).
+			Inline(true)
+
+	keyDescStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("255")).
+			Background(lipgloss.Color("236")).
+			Inline(true)
+)
+
+// palette holds the colors that carry meaning: good (added, staged, ahead),
+// bad (removed, deleted, errors) and warn (unstaged, behind)
+type palette struct {
+	good, bad, warn lipgloss.Color
+}
+
+var palettes = map[string]palette{
+	"default": {good: "82", bad: "196", warn: "214"},
+	// Blue/orange stay distinct for red-green colour blindness
+	"colorblind": {good: "33", bad: "208", warn: "226"},
+}
+
+// colors is the active palette; applyPalette switches it
+var colors = palettes["default"]
+
+// applyPalette recolours every style that signals good/bad/warn
+func applyPalette(name string) error {
+	if name == "" {
+		name = "default"
+	}
+	p, ok := palettes[name]
+	if !ok {
+		return fmt.Errorf("unknown palette %q (want default or colorblind)", name)
+	}
+	colors = p
+
+	selectedSuggestionStyle = selectedSuggestionStyle.Foreground(p.good)
+	errorStyle = errorStyle.Foreground(p.bad)
+	successStyle = successStyle.Foreground(p.good)
+	diffAddStyle = diffAddStyle.Foreground(p.good)
+	diffRemoveStyle = diffRemoveStyle.Foreground(p.bad)
+	iconStagedStyle = iconStagedStyle.Foreground(p.good)
+	iconUnstagedStyle = iconUnstagedStyle.Foreground(p.warn)
+	iconDeletedStyle = iconDeletedStyle.Foreground(p.bad)
+	iconConflictStyle = iconConflictStyle.Foreground(p.bad)
+	branchCurrentStyle = branchCurrentStyle.Foreground(p.good)
+	branchAheadStyle = branchAheadStyle.Foreground(p.good)
+	branchBehindStyle = branchBehindStyle.Foreground(p.warn)
+	return nil
+}
+
+// tagSignature holds the checks of a tag and the commit it points to; tag is
+// nil for lightweight tags, which cannot be signed
+type tagSignature struct {
+	tag    *git.Signature
+	commit git.Signature
+}
+
+// dangerPrompt asks for word to be typed before a dangerous action goes
+// ahead (danger_confirm = "typed")
+type dangerPrompt struct {
+	word    string // branch name or "discard"
+	what    string // the action, e.g. "delete branch 'topic'"
+	confirm string // confirmAction of the key's second press
+	key     string // handle key that asked, pressed again once confirmed
+}
+
+// indexLockPrompt offers to wait for, retry past or remove an index.lock
+type indexLockPrompt struct {
+	lock    git.IndexLock
+	op      string  // blocked operation, "" if unknown
+	retry   tea.Cmd // reruns the blocked operation, nil if unknown
+	waiting bool
+}
+
+// Initialization
+
+// Options are the command-line switches for the TUI
+type Options struct {
+	// Presenter shows pressed keys and keeps status messages up longer, for
+	// recording demos and pairing
+	Presenter bool
+	// ReadOnly disables everything that changes the repository, leaving
+	// browsing
+	ReadOnly bool
+	// Debug records every message handled, with timings, for the debug
+	// overlay and the debug log
+	Debug bool
+}
+
+// Run starts the TUI on the repository containing the working directory
+func Run(opts Options) error {
+	// Loads the config, which also picks the git executable to use
+	m := initialModel()
+	m.presenter = opts.Presenter
+	m.readOnly = opts.ReadOnly
+	m.debug = opts.Debug
+	// The tour waits for a session of one's own: it would get in the way of
+	// a demo, and read-only sessions don't record that it was seen
+	m.touring = m.touring && !m.presenter && !m.readOnly
+	git.SetReadOnly(opts.ReadOnly)
+
+	if err := git.CheckBinary(); err != nil {
+		return err
+	}
+	if !git.IsRepo(m.repoPath) {
+		return fmt.Errorf("Not a git repository")
+	}
+	m.offerCrashRecovery()
+
+	// Bubble Tea restores the terminal after a panic; the report was written
+	// on the way out
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if errors.Is(err, tea.ErrProgramPanic) && crash.LastPath() != "" {
+		return fmt.Errorf("gitty crashed - the report is in %s", crash.LastPath())
+	}
+	return err
+}
+
+func initialModel() model {
+	cfg, err := config.Load()
+	var statusMessage string
+	if err != nil {
+		statusMessage = fmt.Sprintf("Config error: %v", err)
+	}
+	git.Configure(cfg.Git.Binary, cfg.Git.Env, cfg.Git.RepoEnv())
+
+	// Operate from the top of the working tree wherever gitty was launched,
+	// but keep the launch directory for showing paths relative to it
+	launchDir, err := os.Getwd()
+	if err != nil {
+		launchDir = "."
+	}
+	if resolved, err := filepath.EvalSymlinks(launchDir); err == nil {
+		launchDir = resolved
+	}
+	repoPath := launchDir
+	if topLevel, err := git.GetTopLevel(launchDir); err == nil {
+		repoPath = topLevel
+	}
+	// Team settings from the repo's .gitty.toml override the user's
+	if cfg, err = cfg.WithRepo(repoPath); err != nil {
+		statusMessage = fmt.Sprintf("Config error: %v", err)
+	}
+
+	commitInput := textinput.New()
+	commitInput.Placeholder = "Or type your custom commit message..."
+	commitInput.CharLimit = 200
+
+	branchInput := textinput.New()
+	branchInput.Placeholder = "Branch name..."
+	branchInput.CharLimit = 100
+
+	stashBranchInput := textinput.New()
+	stashBranchInput.Placeholder = "New branch name..."
+	stashBranchInput.CharLimit = 100
+
+	prInput := textinput.New()
+	prInput.Placeholder = "Pull request number or URL..."
+	prInput.CharLimit = 200
+
+	rebaseInput := textinput.New()
+	rebaseInput.Placeholder = "Number of commits to rebase..."
+	rebaseInput.CharLimit = 3
+
+	tagInput := textinput.New()
+	tagInput.Placeholder = "Tag name (e.g. v1.0.0)..."
+	tagInput.CharLimit = 50
+
+	excludeInput := textinput.New()
+	excludeInput.Placeholder = "Pattern (e.g. /notes.txt or *.local)..."
+	excludeInput.CharLimit = 200
+
+	logSearchInput := textinput.New()
+	logSearchInput.Placeholder = "Search commits..."
+	logSearchInput.CharLimit = 100
+
+	cloneInput := textinput.New()
+	cloneInput.Placeholder = "Repository URL (https://... or git@...)..."
+	cloneInput.CharLimit = 200
+
+	initInput := textinput.New()
+	initInput.Placeholder = "Directory path..."
+	initInput.CharLimit = 200
+
+	identityInput := textinput.New()
+	identityInput.Placeholder = "Name <email@example.com>"
+	identityInput.CharLimit = 200
+
+	undoInput := textinput.New()
+	undoInput.Placeholder = "Number of commits to undo..."
+	undoInput.CharLimit = 3
+
+	shellInput := textinput.New()
+	shellInput.Placeholder = "Command to run (empty for an interactive shell)"
+	shellInput.CharLimit = 300
+
+	loadSpinner := spinner.New()
+	loadSpinner.Spinner = spinner.Dot
+	loadSpinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("105"))
+
+	resolveInput := textarea.New()
+	resolveInput.Placeholder = "Resolved content for this block (empty drops it)"
+	resolveInput.ShowLineNumbers = false
+	resolveInput.CharLimit = 0
+
+	undoTimeInput := textinput.New()
+	undoTimeInput.Placeholder = "How long ago? (e.g. 30m, 2h, 1d)"
+	undoTimeInput.CharLimit = 20
+
+	messageInput := textinput.New()
+	messageInput.Prompt = ""
+	messageInput.CharLimit = 200
+
+	authorInput := textinput.New()
+	authorInput.Placeholder = "Name <email> (empty: your identity)"
+	authorInput.CharLimit = 200
+
+	dateInput := textinput.New()
+	dateInput.Placeholder = "2024-03-01 09:30 (empty: now)"
+	dateInput.CharLimit = 40
+
+	footerInput := textinput.New()
+	footerInput.Prompt = ""
+	footerInput.CharLimit = 300
+
+	breakingInput := textinput.New()
+	breakingInput.Placeholder = "Describe the breaking change..."
+	breakingInput.CharLimit = 300
+
+	reviewInput := textinput.New()
+	reviewInput.Placeholder = "Comment on this line..."
+	reviewInput.CharLimit = 500
+
+	configInput := textinput.New()
+	configInput.Placeholder = "Value..."
+	configInput.CharLimit = 200
+
+	dangerInput := textinput.New()
+	dangerInput.Prompt = ""
+	dangerInput.CharLimit = 200
+
+	if err := applyPalette(cfg.UI.Palette); err != nil {
+		statusMessage = fmt.Sprintf("Config error: %v", err)
+	}
+	switch cfg.UI.DangerConfirm {
+	case "", "key", "typed":
+	default:
+		statusMessage = fmt.Sprintf("Config error: unknown danger_confirm %q (want key or typed)", cfg.UI.DangerConfirm)
+	}
+	switch cfg.UI.Dates {
+	case "", "relative", "absolute":
+	default:
+		statusMessage = fmt.Sprintf("Config error: unknown dates %q (want relative or absolute)", cfg.UI.Dates)
+	}
+
+	// A bare repository has no working tree, so start on the branches tab
+	bare := git.IsBareRepo(repoPath)
+	tab := "workspace"
+	if bare {
+		tab = "branches"
+	}
+
+	keys := defaultKeyMap()
+	if err := keys.remap(cfg.Keys); err != nil {
+		keys = defaultKeyMap()
+		statusMessage = fmt.Sprintf("Config error: %v", err)
+	}
+
+	return model{
+		config:                 cfg,
+		keys:                   keys,
+		touring:                !cfg.UI.TourDone,
+		absoluteDates:          cfg.UI.Dates == "absolute",
+		gitDir:                 git.GetGitDir(repoPath),
+		commonDir:              git.GetCommonDir(repoPath),
+		workspace:              workspace.Detect(repoPath),
+		codeOwners:             git.GetCodeOwners(repoPath),
+		forge:                  forge.Detect(git.GetRemoteURLs(repoPath)),
+		bare:                   bare,
+		statusMessage:          statusMessage,
+		tab:                    tab,
+		toolMode:               "menu",
+		toolSubmenu:            "",
+		viewMode:               "files",
+		repoPath:               repoPath,
+		repo:                   git.Local(repoPath),
+		launchDir:              launchDir,
+		commitInput:            commitInput,
+		branchInput:            branchInput,
+		prInput:                prInput,
+		stashBranchInput:       stashBranchInput,
+		rebaseInput:            rebaseInput,
+		tagInput:               tagInput,
+		excludeInput:           excludeInput,
+		logSearchInput:         logSearchInput,
+		cloneInput:             cloneInput,
+		initInput:              initInput,
+		identityInput:          identityInput,
+		configInput:            configInput,
+		dangerInput:            dangerInput,
+		breakingInput:          breakingInput,
+		messageInput:           messageInput,
+		authorInput:            authorInput,
+		footerInput:            footerInput,
+		dateInput:              dateInput,
+		reviewInput:            reviewInput,
+		undoInput:              undoInput,
+		undoTimeInput:          undoTimeInput,
+		shellInput:             shellInput,
+		resolveInput:           resolveInput,
+		loading:                make(map[string]bool),
+		jobs:                   jobs.New(),
+		tagSigs:                make(map[string]tagSignature),
+		commitSigs:             make(map[string]git.Signature),
+		spinner:                loadSpinner,
+		undoMode:               "soft",
+		configScope:            "local",
+		showDiffPreview:        true,
+		diffOptions:            git.DiffOptions{Context: -1},
+		selectedSuggestion:     0,
+		commitMsgHookInstalled: git.IsCommitMsgHookInstalled(repoPath),
+		preCommitHookInstalled: git.IsPreCommitHookInstalled(repoPath),
+	}
+}
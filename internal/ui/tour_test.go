@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"os"
+	"testing"
+
+	"github.com/LFroesch/gitty/internal/config"
+	"github.com/LFroesch/gitty/internal/git/gittest"
+)
+
+func TestEndTourLeavesConfigInReadOnlyAndPresenter(t *testing.T) {
+	for _, tt := range []struct {
+		name                string
+		readOnly, presenter bool
+		saved               bool
+	}{
+		{"normal", false, false, true},
+		{"read-only", true, false, false},
+		{"presenter", false, true, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			path, err := config.Path()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := model{repo: gittest.Repo{}, touring: true, readOnly: tt.readOnly, presenter: tt.presenter}
+			next, _ := m.endTour("Tour skipped")
+			if next.(model).touring {
+				t.Error("still touring")
+			}
+			if _, err := os.Stat(path); (err == nil) != tt.saved {
+				t.Errorf("config written: %v, want %v", err == nil, tt.saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,4622 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/gitty/internal/config"
+	"github.com/LFroesch/gitty/internal/forge"
+	"github.com/LFroesch/gitty/internal/git"
+	"github.com/LFroesch/gitty/internal/logger"
+	"github.com/LFroesch/gitty/internal/spell"
+	"github.com/LFroesch/gitty/internal/workspace"
+	"github.com/LFroesch/gitty/suggest"
+)
+
+func (m model) Init() tea.Cmd {
+	if m.bare {
+		return m.guard(tea.Batch(m.loadGitStatus(), m.loadBranches(), m.pollRepo(), m.scheduleSnapshot()))
+	}
+	return m.guard(tea.Batch(
+		m.loadGitChanges(),
+		m.loadGitStatus(),
+		m.loadRecentCommits(),
+		m.loadIdentity(),
+		m.loadSigningConfig(),
+		m.pollRepo(),
+		m.scheduleSnapshot(),
+	))
+}
+
+// Presenter mode timings
+const (
+	presenterSlowdown  = 3               // status messages stay up 3x longer
+	presenterKeyLinger = 2 * time.Second // pressed keys fade after this idle time
+)
+
+// Update runs update, then schedules clearing a status message given an
+// expiry and, in presenter mode, records the key pressed. The commands it
+// returns report panics like Update itself.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer m.reportPanic()
+	start := time.Now()
+	result, cmd := m.update(msg)
+	next, ok := result.(model)
+	if !ok {
+		return result, m.guard(cmd)
+	}
+	cmds := []tea.Cmd{cmd}
+
+	if next.debug {
+		next.traceUpdate(msg, m.keyContext(), time.Since(start))
+	}
+
+	if key, ok := msg.(tea.KeyMsg); ok && next.presenter {
+		next.showKey(key.String())
+		cmds = append(cmds, tea.Tick(presenterKeyLinger, func(time.Time) tea.Msg { return keysFadeMsg{} }))
+	}
+
+	// Keep outcomes for the messages tool, but not confirmation prompts
+	if next.statusMessage != "" && next.confirmAction == "" &&
+		(next.statusMessage != m.statusMessage || !next.statusExpiry.Equal(m.statusExpiry)) {
+		next.logStatus(next.statusMessage)
+	}
+
+	if !next.statusExpiry.IsZero() && !next.statusExpiry.Equal(m.statusExpiry) {
+		if next.presenter {
+			next.statusExpiry = time.Now().Add(time.Until(next.statusExpiry) * presenterSlowdown)
+		}
+		expired := statusExpiredMsg{message: next.statusMessage, at: next.statusExpiry}
+		cmds = append(cmds, tea.Tick(time.Until(next.statusExpiry), func(time.Time) tea.Msg { return expired }))
+	}
+	return next, next.guard(tea.Batch(cmds...))
+}
+
+// logStatus records a status message, dropping the oldest past statusLogSize
+func (m *model) logStatus(message string) {
+	m.statusLog = append(m.statusLog, statusEntry{at: time.Now(), message: message})
+	if len(m.statusLog) > statusLogSize {
+		m.statusLog = slices.Clone(m.statusLog[len(m.statusLog)-statusLogSize:])
+	}
+}
+
+// traceUpdate logs a handled message in debug mode and keeps it for the
+// debug overlay. Spinner frames are left out, as they would drown the rest.
+func (m *model) traceUpdate(msg tea.Msg, from string, took time.Duration) {
+	if _, ok := msg.(spinner.TickMsg); ok {
+		return
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", msg), "ui.")
+	if key, ok := msg.(tea.KeyMsg); ok {
+		name += " " + key.String()
+	}
+	to := m.keyContext()
+	logger.Debug("update", "msg", name, "took", took, "from", from, "to", to, "tab", m.tab)
+
+	m.debugTrail = append(m.debugTrail, debugEntry{at: time.Now(), msg: name, took: took, from: from, to: to})
+	if len(m.debugTrail) > debugTrailSize {
+		m.debugTrail = slices.Clone(m.debugTrail[len(m.debugTrail)-debugTrailSize:])
+	}
+}
+
+// showKey adds a key press to the presenter overlay, counting repeats
+func (m *model) showKey(key string) {
+	if key == " " {
+		key = "space"
+	}
+	m.presenterAt = time.Now()
+	if n := len(m.presenterKeys); n > 0 {
+		last, count := m.presenterKeys[n-1], 1
+		if base, times, ok := strings.Cut(last, " ×"); ok {
+			last = base
+			count, _ = strconv.Atoi(times)
+		}
+		if last == key {
+			m.presenterKeys[n-1] = fmt.Sprintf("%s ×%d", key, count+1)
+			return
+		}
+	}
+	m.presenterKeys = append(m.presenterKeys, key)
+	if len(m.presenterKeys) > 6 {
+		m.presenterKeys = m.presenterKeys[1:]
+	}
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKeyPress(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.sizeResolveInput()
+		m.sizeCommitInput()
+		return m, nil
+
+	case statusExpiredMsg:
+		if m.statusMessage == msg.message && m.statusExpiry.Equal(msg.at) {
+			m.statusMessage = ""
+			m.statusExpiry = time.Time{}
+		}
+		return m, nil
+
+	case keysFadeMsg:
+		if time.Since(m.presenterAt) >= presenterKeyLinger {
+			m.presenterKeys = nil
+		}
+		return m, nil
+
+	case statusMsg:
+		m.statusMessage = msg.message
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		// A write outside the job queue ran into another program's lock
+		if strings.Contains(msg.message, "index.lock") && m.lockPrompt == nil {
+			return m, m.checkIndexLock()
+		}
+		return m, nil
+
+	case indexLockMsg:
+		if msg.lock == nil {
+			m.lockPrompt = nil
+			m.statusMessage = "index.lock is gone - try again"
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		m.lockPrompt = &indexLockPrompt{lock: *msg.lock, op: msg.op, retry: msg.retry}
+		m.confirmAction = ""
+		return m, nil
+
+	case lockWaitDoneMsg:
+		delete(m.loading, "index-lock")
+		if m.lockPrompt == nil {
+			return m, nil
+		}
+		var lockErr *git.LockError
+		if errors.As(msg.err, &lockErr) {
+			prompt := *m.lockPrompt
+			prompt.lock, prompt.waiting = lockErr.Lock, false
+			m.lockPrompt = &prompt
+			m.statusMessage = fmt.Sprintf("Still locked after %s", lockPatience)
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		retry := m.lockPrompt.retry
+		m.lockPrompt = nil
+		m.statusMessage = "index.lock released"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return m, retry
+
+	case tagVerifiedMsg:
+		m.tagSigs[msg.name] = msg.sigs
+		return m, nil
+
+	case commitsVerifiedMsg:
+		for hash, sig := range msg {
+			m.commitSigs[hash] = sig
+		}
+		if len(msg) == 1 {
+			for hash, sig := range msg {
+				m.statusMessage = fmt.Sprintf("%s: %s", hash, describeSignature(sig))
+				m.statusExpiry = time.Now().Add(5 * time.Second)
+			}
+		}
+		return m, nil
+
+	case lockRemovedMsg:
+		if m.lockPrompt == nil {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Removing index.lock failed: %v", msg.err)
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+			m.lockPrompt = nil
+			return m, m.checkIndexLock()
+		}
+		retry := m.lockPrompt.retry
+		m.lockPrompt = nil
+		m.statusMessage = "Removed stale index.lock"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return m, tea.Batch(m.loadGitStatus(), retry)
+
+	case gitChangesMsg:
+		// Stay on the same file as it moves through the list
+		m.fileCursor = relocate(m.changes, msg, m.fileCursor, changeFile)
+		m.changes = msg
+		for _, change := range m.changes {
+			if strings.HasSuffix(change.File, "CODEOWNERS") {
+				m.codeOwners = git.GetCodeOwners(m.repoPath)
+				break
+			}
+		}
+		m.adjustFileScroll()
+		// Generate commit suggestions
+		cmds = append(cmds, m.generateCommitSuggestions())
+		// Load diff for selected file
+		if len(m.changes) > 0 && m.fileCursor < len(m.changes) {
+			cmds = append(cmds, m.loadFileDiff(m.changes[m.fileCursor].File))
+		}
+		return m, tea.Batch(cmds...)
+
+	case gitStatusMsg:
+		m.gitState = git.Status(msg)
+		return m, nil
+
+	case branchesMsg:
+		delete(m.loading, "branches")
+		m.branchCursor = relocate(m.branches, msg.branches, m.branchCursor, branchName)
+		m.branches = msg.branches
+		m.defaultBranch = msg.base
+		m.adjustBranchScroll()
+		return m, nil
+
+	case commitsMsg:
+		delete(m.loading, "history")
+		// The history keeps its selection and marked range by hash; undo
+		// keeps its count of commits to undo
+		if m.historyMarked {
+			m.historyMark = relocate(m.commits, msg, m.historyMark, commitHash)
+		}
+		m.historyCursor = relocate(m.commits, msg, m.historyCursor, commitHash)
+		m.undoCursor = max(0, min(m.undoCursor, len(msg)-1))
+		m.commits = msg
+		m.adjustHistoryScroll()
+		m.adjustUndoScroll()
+		return m, nil
+
+	case recentCommitsMsg:
+		m.recentCommits = msg
+		return m, nil
+
+	case diffMsg:
+		m.diffContent = string(msg)
+		return m, nil
+
+	case conflictsMsg:
+		m.conflictCursor = relocate(m.conflicts, msg, m.conflictCursor, conflictPath)
+		m.conflicts = msg
+		return m, nil
+
+	case refreshMsg:
+		delete(m.loading, "refresh")
+		var next tea.Model = m
+		var cmds []tea.Cmd
+		for _, loaded := range msg {
+			var cmd tea.Cmd
+			next, cmd = next.Update(loaded)
+			cmds = append(cmds, cmd)
+		}
+		cmds = append(cmds, func() tea.Msg { return statusMsg{message: "Refreshed"} })
+		return next, tea.Batch(cmds...)
+
+	case loadingMsg:
+		idle := len(m.loading) == 0
+		m.loading[string(msg)] = true
+		if idle {
+			return m, m.spinner.Tick
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		// Stop ticking once nothing is loading
+		if len(m.loading) == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case jobsTickMsg:
+		// Redraw the job list while it is on screen
+		if m.tab == "tools" && m.toolMode == "jobs" {
+			return m, m.tickJobs()
+		}
+		return m, nil
+
+	case snapshotTickMsg:
+		// Bare repositories have no worktree to protect, and read-only mode
+		// must not write snapshot refs
+		if m.bare || m.readOnly {
+			return m, m.scheduleSnapshot()
+		}
+		return m, tea.Batch(m.scheduleSnapshot(), m.takeSnapshot(false))
+
+	case repoStampMsg:
+		changed := m.pollStamp != "" && string(msg) != m.pollStamp
+		m.pollStamp = string(msg)
+		cmds = append(cmds, m.pollRepo())
+		if changed {
+			cmds = append(cmds, m.loadGitChanges(), m.loadGitStatus())
+			if m.tab == "branches" {
+				cmds = append(cmds, m.loadBranches())
+			}
+		}
+		return m, tea.Batch(cmds...)
+
+	case operationMsg:
+		m.conflictOp = msg.op
+		m.rebaseProgress = msg.rebase
+		if m.rebaseProgress != nil && m.toolMode == "rebase" {
+			// A stopped rebase has to be finished before another starts
+			m.rebaseInput.Blur()
+		}
+		return m, nil
+
+	case releasePreviewMsg:
+		comparison := git.BranchComparison(msg)
+		m.releasePreview = &comparison
+		return m, nil
+
+	case comparisonMsg:
+		delete(m.loading, "comparison")
+		comparison := git.BranchComparison(msg)
+		m.branchComparison = &comparison
+		m.compareCursor = 0
+		m.review = nil
+		// Comments survive leaving and re-opening the same comparison
+		if r := comparison.TargetBranch + "..." + comparison.SourceBranch; r != m.reviewRange {
+			m.reviewRange = r
+			m.reviewComments = nil
+		}
+		return m, nil
+
+	case prsMsg:
+		delete(m.loading, "prs")
+		m.prs, m.prsErr = msg.prs, msg.err
+		if m.prCursor >= len(m.prs) {
+			m.prCursor = max(0, len(m.prs)-1)
+		}
+		return m, nil
+
+	case reviewDiffMsg:
+		delete(m.loading, "review")
+		if m.branchComparison == nil {
+			return m, nil
+		}
+		lines := strings.Split(strings.TrimRight(msg.diff, "\n"), "\n")
+		m.review = &reviewDiff{file: msg.file, lines: lines, positions: diffPositions(lines)}
+		// Start on the first line that can take a comment
+		for i, pos := range m.review.positions {
+			if pos.side != "" {
+				m.review.cursor = i
+				break
+			}
+		}
+		return m, nil
+
+	case rebaseCommitsMsg:
+		m.rebaseCommits = msg
+		return m, nil
+
+	case fetchResultMsg:
+		m.fetchResults = msg.results
+		m.pushOutput = ""
+		return m, nil
+
+	case pushOutputMsg:
+		m.pushOutput = msg.output
+		m.lastCommit = msg.commit
+		return m, m.loadUpstreamCommits()
+
+	case upstreamCommitsMsg:
+		if msg.err != nil {
+			m.upstream, m.upstreamErr = nil, msg.err.Error()
+		} else {
+			m.upstream, m.upstreamErr = &msg.div, ""
+		}
+		return m, nil
+
+	case commitPushDoneMsg:
+		delete(m.loading, "commit-push")
+		return m.Update(msg.result)
+
+	case hookRewroteMsg:
+		m.hookRetry = msg.message
+		m.statusMessage = fmt.Sprintf("A commit hook reformatted %d file(s); restaged them - %s commits again",
+			len(msg.restaged), m.keys.keyFor(ctxCommit, "retry"))
+		if len(msg.skipped) > 0 {
+			m.statusMessage += fmt.Sprintf(" (not restaged, they have unstaged edits: %s)", strings.Join(msg.skipped, ", "))
+		}
+		m.statusExpiry = time.Now().Add(10 * time.Second)
+		return m, tea.Batch(m.loadGitChanges(), m.loadGitStatus())
+
+	case commitSuccessMsg:
+		m.commitSummary = &msg
+		m.hookRetry = ""
+		m.breakingNote = ""
+		m.ticket = ""
+		m.footers = nil
+		m.partialPaths = nil
+		m.scrollOffset = 0
+		if !msg.override.IsZero() {
+			// The override is for one commit only
+			m.commitOverride = git.CommitOverride{}
+			m.statusMessage = fmt.Sprintf("Committed %s %s (author/date override)", msg.hash, msg.override)
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+		}
+		cmds = append(cmds, m.loadGitChanges(), m.loadGitStatus())
+		if msg.pushed != "" {
+			// A published branch shows its new upstream
+			cmds = append(cmds, m.loadBranches())
+		}
+		return m, tea.Batch(cmds...)
+
+	case commitSuggestionsMsg:
+		delete(m.loading, "suggestions")
+		m.suggestions = msg.suggestions
+		m.breakingChanges = msg.breaking
+		return m, nil
+
+	case partialFilesMsg:
+		if len(msg) == 0 {
+			m.statusMessage = "No staged changes"
+			return m, nil
+		}
+		m.partialFiles = msg
+		m.partialCursor = 0
+		selected := make(map[string]bool)
+		for _, file := range m.partialPaths {
+			selected[file] = true
+		}
+		m.partialSelected = selected
+		m.commitInput.Blur()
+		return m, nil
+
+	case timeRestoreMsg:
+		if msg.err != nil {
+			m.timeRestore = nil
+			m.statusMessage = fmt.Sprintf("Time restore: %v", msg.err)
+			m.undoTimeInput.Focus()
+			return m, nil
+		}
+		m.timeRestore = &msg.restore
+		return m, nil
+
+	case signingMsg:
+		m.signingKeys = msg.keys
+		m.signingConfig = msg.config
+		if m.signingCursor >= len(m.signingKeys) {
+			m.signingCursor = max(0, len(m.signingKeys)-1)
+		}
+		return m, nil
+
+	case signingConfigMsg:
+		m.signingConfig = git.SigningConfig(msg)
+		return m, nil
+
+	case signingTestMsg:
+		if msg.err != nil {
+			m.signingOK = false
+			m.signingResult = msg.err.Error()
+		} else {
+			m.signingOK = true
+			m.signingResult = "Test signature succeeded - commits will be signed"
+		}
+		return m, nil
+
+	case lostCommitsMsg:
+		m.lostCommits = msg.commits
+		switch {
+		case msg.err != nil:
+			m.lostStatus = "Scan failed: " + msg.err.Error()
+		case len(msg.commits) == 0:
+			m.lostStatus = "No lost commits found - nothing to recover"
+		default:
+			m.lostStatus = ""
+		}
+		if m.lostCursor >= len(m.lostCommits) {
+			m.lostCursor = max(0, len(m.lostCommits)-1)
+		}
+		return m, nil
+
+	case amendMessageMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Amend failed: %v", msg.err)
+			return m, nil
+		}
+		subject, body, _ := strings.Cut(msg.message, "\n")
+		m.messageMode = "amend"
+		m.amendBody = body
+		m.amendPushed = msg.pushed
+		m.commitInput.Blur()
+		m.messageInput.SetValue(subject)
+		m.messageInput.CursorEnd()
+		m.messageInput.Focus()
+		return m, textinput.Blink
+
+	case lostDiffMsg:
+		m.lostDiff = string(msg)
+		m.scrollOffset = 0
+		return m, nil
+
+	case conflictDocMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Cannot edit conflict: %v", msg.err)
+			return m, nil
+		}
+		m.resolveDoc = msg.doc
+		m.resolveBlock = 0
+		m.resolutions = make([]string, len(msg.doc.Blocks))
+		for i, block := range msg.doc.Blocks {
+			m.resolutions[i] = strings.Join(block.Ours, "\n")
+		}
+		m.viewMode = "resolve"
+		m.sizeResolveInput()
+		m.resolveInput.SetValue(m.resolutions[0])
+		return m, m.resolveInput.Focus()
+
+	case shellDoneMsg:
+		status := "Back from shell"
+		if msg.err != nil {
+			status = fmt.Sprintf("Shell exited: %v", msg.err)
+		}
+		return m, tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadBranches(),
+			m.loadRecentCommits(),
+			m.loadIdentity(),
+			m.loadSigningConfig(),
+			func() tea.Msg { return statusMsg{message: status} },
+		)
+
+	case pushUndoMsg:
+		if msg.err != nil {
+			m.pushUndo = nil
+			m.pushUndoErr = msg.err.Error()
+		} else {
+			m.pushUndo = &msg.undo
+			m.pushUndoErr = ""
+		}
+		return m, nil
+
+	case divergenceMsg:
+		delete(m.loading, "divergence")
+		if msg.err != nil {
+			m.divergence = nil
+			m.divergenceErr = msg.err.Error()
+		} else {
+			m.divergence = &msg.div
+			m.divergenceErr = ""
+		}
+		return m, nil
+
+	case showDivergenceMsg:
+		return m.openDivergence()
+
+	case showConflictsMsg:
+		m.tab = "workspace"
+		m.viewMode = "conflicts"
+		m.conflictCursor = 0
+		return m, tea.Batch(m.loadConflicts(), m.loadOperation())
+
+	case commitGroupsMsg:
+		if len(msg) == 0 {
+			m.statusMessage = "No staged changes to split"
+			return m, nil
+		}
+		m.splitGroups = msg
+		m.splitCursor = 0
+		m.commitInput.Blur()
+		return m, nil
+
+	case commitScopesMsg:
+		m.commitScopes = msg
+		return m, nil
+
+	case spellCheckerMsg:
+		m.speller = msg.checker
+		m.spellIssues = m.checkSpelling(m.commitInput.Value())
+		return m, nil
+
+	case stashListMsg:
+		m.stashCursor = relocate(m.stashes, msg, m.stashCursor, stashMessage)
+		m.stashes = msg
+		m.adjustStashScroll()
+		m.stashDiff = ""
+		if m.toolMode == "stash" && len(m.stashes) > 0 {
+			return m, m.loadStashDiff(m.stashCursor)
+		}
+		return m, nil
+
+	case stashFilesMsg:
+		if len(msg) == 0 {
+			m.statusMessage = "No tracked files in this stash"
+			return m, nil
+		}
+		m.stashFiles = msg
+		m.stashFileSelected = make(map[string]bool)
+		m.stashFileCursor = 0
+		return m, nil
+
+	case tagListMsg:
+		m.tagCursor = relocate(m.tags, msg, m.tagCursor, tagName)
+		m.tags = msg
+		m.adjustTagScroll()
+		return m, nil
+
+	case hookStatusMsg:
+		m.commitMsgHookInstalled = bool(msg)
+		return m, nil
+
+	case preCommitHookMsg:
+		m.preCommitHookInstalled = bool(msg)
+		return m, nil
+
+	case stashDiffMsg:
+		m.stashDiff = string(msg)
+		m.stashDiffOffset = 0
+		return m, nil
+
+	case contributorsMsg:
+		m.contributorCursor = relocate(m.contributors, msg, m.contributorCursor, contributorEmail)
+		m.contributors = msg
+		m.adjustContributorScroll()
+		return m, nil
+
+	case snapshotsMsg:
+		m.snapshots = msg
+		if m.snapshotCursor >= len(m.snapshots) {
+			m.snapshotCursor = max(0, len(m.snapshots)-1)
+		}
+		m.adjustSnapshotScroll()
+		m.snapshotDiff = ""
+		if m.toolMode == "snapshots" && len(m.snapshots) > 0 {
+			return m, m.loadSnapshotDiff(m.snapshots[m.snapshotCursor])
+		}
+		return m, nil
+
+	case excludeLinesMsg:
+		m.excludeLines = msg
+		if m.excludeCursor >= len(m.excludeLines) {
+			m.excludeCursor = max(0, len(m.excludeLines)-1)
+		}
+		m.adjustExcludeScroll()
+		return m, nil
+
+	case snapshotDiffMsg:
+		m.snapshotDiff = string(msg)
+		m.snapshotDiffOffset = 0
+		return m, nil
+
+	case logCommitsMsg:
+		delete(m.loading, "log")
+		m.logCursor = relocate(m.logCommits, msg, m.logCursor, commitHash)
+		m.logCommits = msg
+		m.adjustLogScroll()
+		return m, nil
+
+	case logDetailMsg:
+		detail := git.CommitDetail(msg)
+		m.logDetail = &detail
+		m.logFileCursor, m.logFileOffset = 0, 0
+		m.logCollapsed = make(map[string]bool)
+		m.logFile, m.logFileDiff = "", ""
+		m.scrollOffset = 0
+		return m, nil
+
+	case logFileDiffMsg:
+		if msg.path == m.logFile {
+			m.logFileDiff = msg.diff
+		}
+		return m, nil
+
+	case logDiffMsg:
+		m.logDiff = string(msg)
+		return m, nil
+
+	case blameMsg:
+		m.blameLines = msg
+		m.blameCursor = 0
+		m.blameOffset = 0
+		return m, nil
+
+	case cloneResultMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return statusMsg{message: "Clone failed: " + msg.output} }
+		}
+		// Switch to the cloned repo
+		return m, func() tea.Msg { return repoSwitchMsg(msg.newPath) }
+
+	case cleanFilesMsg:
+		m.cleanFiles = msg
+		m.cleanCursor = 0
+		return m, nil
+
+	case identityMsg:
+		m.identity = git.Identity(msg)
+		if !m.identity.IsComplete() {
+			m.statusMessage = "⚠ user.name/user.email not set - commits will fail (Tools > Identity)"
+		}
+		return m, nil
+
+	case configEntriesMsg:
+		m.configEntries = msg
+		if rows := m.configRows(); m.configCursor >= len(rows) {
+			m.configCursor = max(0, len(rows)-1)
+		}
+		return m, nil
+
+	case aliasesMsg:
+		m.aliases = msg
+		if m.aliasCursor >= len(m.aliases) {
+			m.aliasCursor = max(0, len(m.aliases)-1)
+		}
+		return m, nil
+
+	case aliasOutputMsg:
+		m.aliasOutput = msg.output
+		status := fmt.Sprintf("git %s finished", msg.name)
+		if msg.err != nil {
+			status = fmt.Sprintf("git %s failed: %v", msg.name, msg.err)
+		}
+		// Aliases can do anything, so refresh what is on screen
+		return m, tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			func() tea.Msg { return statusMsg{message: status} },
+		)
+
+	case knownIdentitiesMsg:
+		m.identities = msg
+		if m.identityCursor >= len(m.identities) {
+			m.identityCursor = max(0, len(m.identities)-1)
+		}
+		return m, nil
+
+	case repoSwitchMsg:
+		newPath := string(msg)
+		m.repoPath = newPath
+		m.repo = git.Local(newPath)
+		status := "Switched to " + newPath
+		// Drop the previous repo's .gitty.toml overrides
+		cfg, _ := config.Load()
+		cfg, err := cfg.WithRepo(newPath)
+		if err != nil {
+			status += fmt.Sprintf(" (config error: %v)", err)
+		}
+		m.config = cfg
+		m.gitDir = git.GetGitDir(newPath)
+		m.commonDir = git.GetCommonDir(newPath)
+		m.bare = git.IsBareRepo(newPath)
+		m.workspace = workspace.Detect(newPath)
+		m.codeOwners = git.GetCodeOwners(newPath)
+		m.pollStamp = ""
+		m.tab = "workspace"
+		if m.bare {
+			m.tab = "branches"
+		}
+		m.toolMode = "menu"
+		// Reset all cursors and state
+		m.changes, m.fileCursor, m.fileOffset = nil, 0, 0
+		m.branches, m.branchCursor, m.branchOffset = nil, 0, 0
+		m.commitSummary, m.hookRetry = nil, ""
+		m.footers = nil
+		m.diffContent = ""
+		m.tagSigs = make(map[string]tagSignature)
+		m.commitSigs = make(map[string]git.Signature)
+		m.branchComparison, m.review = nil, nil
+		m.reviewComments, m.reviewRange = nil, ""
+		m.forge = forge.Detect(git.GetRemoteURLs(newPath))
+		m.prs, m.prsErr, m.prCursor = nil, nil, 0
+		m.snapshots, m.snapshotCursor, m.snapshotOffset = nil, 0, 0
+		m.contributors, m.contributorCursor, m.contributorOffset = nil, 0, 0
+		m.historyAuthor = ""
+		m.excludeLines, m.excludeCursor, m.excludeOffset = nil, 0, 0
+		m.commitMsgHookInstalled = git.IsCommitMsgHookInstalled(newPath)
+		m.preCommitHookInstalled = git.IsPreCommitHookInstalled(newPath)
+		// Reload everything
+		return m, tea.Batch(
+			m.loadGitChanges(),
+			m.loadGitStatus(),
+			m.loadRecentCommits(),
+			m.loadIdentity(),
+			m.loadSigningConfig(),
+			func() tea.Msg { return statusMsg{message: status} },
+		)
+	}
+
+	// Update text inputs
+	if m.commitInput.Focused() {
+		var cmd tea.Cmd
+		m.commitInput, cmd = m.commitInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.branchInput.Focused() {
+		var cmd tea.Cmd
+		m.branchInput, cmd = m.branchInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.rebaseInput.Focused() {
+		var cmd tea.Cmd
+		m.rebaseInput, cmd = m.rebaseInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.logSearchInput.Focused() {
+		var cmd tea.Cmd
+		m.logSearchInput, cmd = m.logSearchInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.cloneInput.Focused() {
+		var cmd tea.Cmd
+		m.cloneInput, cmd = m.cloneInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.initInput.Focused() {
+		var cmd tea.Cmd
+		m.initInput, cmd = m.initInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.identityInput.Focused() {
+		var cmd tea.Cmd
+		m.identityInput, cmd = m.identityInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.configInput.Focused() {
+		var cmd tea.Cmd
+		m.configInput, cmd = m.configInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.undoInput.Focused() {
+		var cmd tea.Cmd
+		m.undoInput, cmd = m.undoInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.shellInput.Focused() {
+		var cmd tea.Cmd
+		m.shellInput, cmd = m.shellInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.undoTimeInput.Focused() {
+		var cmd tea.Cmd
+		m.undoTimeInput, cmd = m.undoTimeInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.breakingInput.Focused() {
+		var cmd tea.Cmd
+		m.breakingInput, cmd = m.breakingInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.resolveInput.Focused() {
+		var cmd tea.Cmd
+		m.resolveInput, cmd = m.resolveInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	// Shell command prompt takes every key while open
+	if m.shellInput.Focused() {
+		switch m.keys.resolve(ctxShell, key) {
+		case "enter":
+			command := strings.TrimSpace(m.shellInput.Value())
+			m.shellInput.SetValue("")
+			m.shellInput.Blur()
+			return m, m.runShell(command)
+		case "esc":
+			m.shellInput.SetValue("")
+			m.shellInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.shellInput, cmd = m.shellInput.Update(msg)
+		return m, cmd
+	}
+
+	// Conflict editor scratch buffer takes every key while open
+	if m.resolveInput.Focused() {
+		return m.handleResolveKey(msg)
+	}
+
+	// Review comment input takes every key while open
+	if m.reviewInput.Focused() {
+		return m.handleReviewCommentKey(msg)
+	}
+
+	// Stash branch name input takes every key while open
+	if m.stashBranchInput.Focused() {
+		return m.handleStashBranchKey(msg)
+	}
+
+	// Exclude pattern input takes every key while open
+	if m.excludeInput.Focused() {
+		return m.handleExcludeInputKey(msg)
+	}
+
+	// So do the next commit's author and date, digits included
+	if m.overrideOpen {
+		return m.handleOverrideKey(msg)
+	}
+
+	// So do the footers, "Closes #42" included
+	if m.footerOpen {
+		return m.handleFooterKey(msg)
+	}
+
+	// So does the message of an empty commit or an amend
+	if m.messageInput.Focused() {
+		return m.handleMessageKey(msg)
+	}
+
+	// So does the typed confirmation of a dangerous action
+	if m.danger != nil {
+		return m.handleDangerKey(msg)
+	}
+
+	// Pull request number input takes every key while open, digits included
+	if m.prInput.Focused() {
+		return m.handlePRNumberKey(msg)
+	}
+
+	// The index.lock prompt takes every key while open
+	if m.lockPrompt != nil {
+		return m.handleLockPromptKey(key)
+	}
+
+	// So does the tour, so nothing runs behind it
+	if m.touring {
+		return m.handleTourKey(key)
+	}
+
+	// After a crash the first key answers the offer to resume; any other
+	// key declines it and does what it always does
+	if m.crashOffer != nil {
+		offer := *m.crashOffer
+		m.crashOffer = nil
+		m.statusMessage = ""
+		if key == "enter" {
+			return m.resumeAfterCrash(offer)
+		}
+	}
+
+	// Global keys
+	global := m.keys.resolve(ctxGlobal, key)
+	if m.readOnly && m.keys.writes(ctxGlobal, global) {
+		return m.refuseWrite()
+	}
+	if m.bare && (global == "1" || global == "2") {
+		m.statusMessage = "Bare repository: no working tree to show"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return m, nil
+	}
+	switch global {
+	case "ctrl+z":
+		m.shellInput.Focus()
+		return m, textinput.Blink
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "ctrl+q":
+		return m.openRemote()
+	case "ctrl+r":
+		// Views with a ctrl+r of their own keep it while it applies
+		if !m.keys.claims(m.keyContext(), key, m) {
+			return m, m.refreshAll()
+		}
+	case "f12":
+		if !m.debug {
+			m.statusMessage = "Start gitty with --debug to trace what it does"
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		m.debugOverlay = !m.debugOverlay
+		return m, nil
+	case "f1":
+		m.touring = true
+		m.showTourStep(0)
+		return m, nil
+	case "1":
+		m.tab = "workspace"
+		m.viewMode = "files"
+		m.commitSummary = nil
+		return m, tea.Batch(m.loadGitChanges(), m.loadGitStatus())
+	case "2":
+		m.tab = "commit"
+		m.stagedBrowsing = false
+		m.commitInput.Focus()
+		return m, tea.Batch(m.loadGitChanges(), m.loadGitStatus(), m.generateCommitSuggestions(), m.loadSpellChecker(), m.loadSigningConfig())
+	case "3":
+		m.tab = "branches"
+		return m, m.loadBranches()
+	case "4":
+		// Coming back finds the tool as it was left; from the tools tab
+		// itself 4 goes to the menu
+		if m.tab == "tools" {
+			m.toolMode = "menu"
+			return m, nil
+		}
+		m.tab = "tools"
+		return m, m.reloadTool()
+	case "5":
+		if m.forge == "" {
+			m.statusMessage = "Install gh or glab to list your pull requests"
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		m.tab = "prs"
+		return m, m.loadPRs()
+	}
+
+	// Tab-specific keys, translated through the keymap so remapped keys
+	// reach the handlers as the action's default key
+	key = m.keys.resolve(m.keyContext(), key)
+	if m.readOnly && m.keys.writes(m.keyContext(), key) {
+		return m.refuseWrite()
+	}
+	return m.handleTabKey(key, msg)
+}
+
+func (m model) handleTabKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.tab {
+	case "workspace":
+		return m.handleWorkspaceKey(key)
+	case "commit":
+		return m.handleCommitKey(key, msg)
+	case "branches":
+		return m.handleBranchesKey(key, msg)
+	case "tools":
+		return m.handleToolsKey(key, msg)
+	case "prs":
+		return m.handlePRsKey(key)
+	}
+
+	return m, nil
+}
+
+// typedConfirm reports whether dangerous actions need a word typed rather
+// than a second key press (danger_confirm = "typed")
+func (m model) typedConfirm() bool {
+	return m.config.UI.DangerConfirm == "typed"
+}
+
+// askTyped opens the typed confirmation in place of the status line's
+// "press again"; once word is typed, key is handled again as if pressed a
+// second time with confirmAction set to confirm
+func (m model) askTyped(word, what, confirm, key string) (tea.Model, tea.Cmd) {
+	m.confirmAction = ""
+	m.danger = &dangerPrompt{word: word, what: what, confirm: confirm, key: key}
+	m.dangerInput.SetValue("")
+	m.dangerInput.Focus()
+	return m, textinput.Blink
+}
+
+// handleDangerKey reads the word that confirms a dangerous action
+func (m model) handleDangerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt := *m.danger
+	switch m.keys.resolve(ctxDanger, msg.String()) {
+	case "enter":
+		typed := strings.TrimSpace(m.dangerInput.Value())
+		m.danger = nil
+		m.dangerInput.Blur()
+		if typed != prompt.word {
+			m.statusMessage = fmt.Sprintf("'%s' does not match '%s' - nothing done", typed, prompt.word)
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		// No input is focused at any of the asking keys, so the key
+		// message itself is not needed
+		m.confirmAction = prompt.confirm
+		return m.handleTabKey(prompt.key, tea.KeyMsg{})
+	case "esc":
+		m.danger = nil
+		m.dangerInput.Blur()
+		m.statusMessage = "Cancelled"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.dangerInput, cmd = m.dangerInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) handleWorkspaceKey(key string) (tea.Model, tea.Cmd) {
+	if m.viewMode == "diff" || m.viewMode == "files" {
+		if m.setDiffOption(key) {
+			m.statusMessage = "Diff: " + diffOptionsDesc(m.diffOptions)
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			if m.fileCursor < len(m.changes) {
+				return m, m.loadFileDiff(m.changes[m.fileCursor].File)
+			}
+			return m, nil
+		}
+	}
+
+	if m.viewMode == "diff" {
+		switch key {
+		case "esc":
+			m.viewMode = "files"
+			return m, nil
+		case "j", "down":
+			m.scrollOffset++
+			return m, nil
+		case "k", "up":
+			if m.scrollOffset > 0 {
+				m.scrollOffset--
+			}
+			return m, nil
+		case "n":
+			for _, start := range diffHunkStarts(m.diffContent) {
+				if start > m.scrollOffset {
+					m.scrollOffset = start
+					break
+				}
+			}
+			return m, nil
+		case "p":
+			hunks := diffHunkStarts(m.diffContent)
+			for i := len(hunks) - 1; i >= 0; i-- {
+				if hunks[i] < m.scrollOffset {
+					m.scrollOffset = hunks[i]
+					break
+				}
+			}
+			return m, nil
+		case "]":
+			if m.fileCursor < len(m.changes)-1 {
+				m.fileCursor++
+				m.scrollOffset = 0
+				m.adjustFileScroll()
+				return m, m.loadFileDiff(m.changes[m.fileCursor].File)
+			}
+			return m, nil
+		case "[":
+			if m.fileCursor > 0 && m.fileCursor < len(m.changes) {
+				m.fileCursor--
+				m.scrollOffset = 0
+				m.adjustFileScroll()
+				return m, m.loadFileDiff(m.changes[m.fileCursor].File)
+			}
+			return m, nil
+		}
+		return m, nil
+	}
+
+	if m.viewMode == "blame" {
+		switch key {
+		case "esc":
+			m.viewMode = "files"
+			m.blameLines = nil
+			return m, nil
+		case "j", "down":
+			if m.blameCursor < len(m.blameLines)-1 {
+				m.blameCursor++
+				m.adjustBlameScroll()
+			}
+			return m, nil
+		case "k", "up":
+			if m.blameCursor > 0 {
+				m.blameCursor--
+				m.adjustBlameScroll()
+			}
+			return m, nil
+		}
+		return m, nil
+	}
+
+	if m.viewMode == "conflicts" {
+		switch key {
+		case "esc", "c":
+			m.viewMode = "files"
+			m.conflicts = nil
+			return m, nil
+		case "j", "down":
+			if m.conflictCursor < len(m.conflicts)-1 {
+				m.conflictCursor++
+			}
+			return m, nil
+		case "k", "up":
+			if m.conflictCursor > 0 {
+				m.conflictCursor--
+			}
+			return m, nil
+		case "C":
+			if m.conflictOp == "" {
+				m.statusMessage = "No merge, rebase, cherry-pick or revert in progress"
+				return m, nil
+			}
+			if len(m.conflicts) > 0 {
+				m.statusMessage = fmt.Sprintf("%d conflicted files left - resolve them before continuing", len(m.conflicts))
+				return m, nil
+			}
+			return m, m.continueOperation()
+		case "A":
+			if m.conflictOp == "" {
+				return m, nil
+			}
+			if m.confirmAction != "abort-op" {
+				m.confirmAction = "abort-op"
+				m.statusMessage = fmt.Sprintf("Press 'A' again to abort the %s", m.conflictOp)
+				return m, nil
+			}
+			m.confirmAction = ""
+			return m, m.abortOperation()
+		case "e":
+			// Compose the merge result block by block
+			if m.conflictCursor < len(m.conflicts) {
+				return m, m.loadConflictDoc(m.conflicts[m.conflictCursor].Path)
+			}
+			return m, nil
+		case "enter":
+			// Open conflict file in diff view
+			if m.conflictCursor < len(m.conflicts) {
+				path := m.conflicts[m.conflictCursor].Path
+				// Keep ]/[ in the diff view relative to this file
+				for i, change := range m.changes {
+					if change.File == path {
+						m.fileCursor = i
+					}
+				}
+				m.viewMode = "diff"
+				m.scrollOffset = 0
+				return m, m.loadFileDiff(path)
+			}
+			return m, nil
+		}
+		return m, nil
+	}
+
+	switch key {
+	case "j", "down":
+		if m.fileCursor < len(m.changes)-1 {
+			m.fileCursor++
+			m.scrollOffset = 0
+			m.adjustFileScroll()
+			if m.fileCursor < len(m.changes) {
+				return m, m.loadFileDiff(m.changes[m.fileCursor].File)
+			}
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.fileCursor > 0 {
+			m.fileCursor--
+			m.scrollOffset = 0
+			m.adjustFileScroll()
+			if m.fileCursor < len(m.changes) {
+				return m, m.loadFileDiff(m.changes[m.fileCursor].File)
+			}
+		}
+		return m, nil
+
+	case " ", "space":
+		if m.fileCursor < len(m.changes) {
+			change := m.changes[m.fileCursor]
+			// A mode change is easy to stage by accident, e.g. after a
+			// checkout on a filesystem without executable bits
+			mc := change.ModeChange(false)
+			if mc.Changed() && change.Status[0] == ' ' && m.confirmAction != "stage-mode:"+change.File {
+				key := m.keys.keyFor(ctxFiles, "stage")
+				if key == " " {
+					key = "space"
+				}
+				m.confirmAction = "stage-mode:" + change.File
+				m.statusMessage = fmt.Sprintf("%s: mode change %s - press %s again to stage it",
+					m.displayPath(change.File), mc, key)
+				return m, nil
+			}
+			m.confirmAction = ""
+			return m, m.toggleStaging(change.File)
+		}
+		return m, nil
+
+	case "a":
+		return m, m.gitAddAll()
+
+	case "r":
+		staged := 0
+		for _, change := range m.changes {
+			if change.Status != "" && change.Status[0] != ' ' && change.Status[0] != '?' {
+				staged++
+			}
+		}
+		if staged == 0 {
+			m.statusMessage = "No staged changes to reset"
+			return m, nil
+		}
+		if m.confirmAction != "unstage-all" {
+			m.confirmAction = "unstage-all"
+			m.statusMessage = fmt.Sprintf("Unstage %d file(s)? Press '%s' again", staged, m.keys.keyFor(ctxFiles, "unstage-all"))
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.gitReset()
+
+	case "m", "u", "A":
+		paths, what := m.bulkStageTargets(key)
+		if len(paths) == 0 {
+			m.statusMessage = "No " + strings.Replace(what, "file(s)", "files", 1) + " to stage"
+			return m, nil
+		}
+		if m.confirmAction != "stage-"+key {
+			m.confirmAction = "stage-" + key
+			m.statusMessage = fmt.Sprintf("Stage %d %s? Press '%s' again", len(paths), what, m.keys.keyFor(ctxFiles, bulkStageActions[key]))
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.stagePaths(paths, what)
+
+	case "enter":
+		m.viewMode = "diff"
+		m.scrollOffset = 0
+		return m, nil
+
+	case "b":
+		// Blame selected file
+		if m.fileCursor < len(m.changes) {
+			file := m.changes[m.fileCursor].File
+			m.blameFile = file
+			m.viewMode = "blame"
+			return m, m.loadBlame(file)
+		}
+		return m, nil
+
+	case "d":
+		if m.fileCursor < len(m.changes) {
+			if m.confirmAction != "discard" && m.typedConfirm() {
+				return m.askTyped("discard", "discard changes to "+m.changes[m.fileCursor].File, "discard", key)
+			}
+			if m.confirmAction == "" {
+				m.confirmAction = "discard"
+				m.statusMessage = "Press 'd' again to confirm discard"
+				return m, nil
+			} else if m.confirmAction == "discard" {
+				m.confirmAction = ""
+				return m, m.discardChanges(m.changes[m.fileCursor].File)
+			}
+		}
+		return m, nil
+
+	case "esc":
+		m.confirmAction = ""
+		m.statusMessage = ""
+		return m, nil
+
+	case "p":
+		m.showDiffPreview = !m.showDiffPreview
+		return m, nil
+
+	case "w":
+		if m.scrollOffset > 0 {
+			m.scrollOffset--
+		}
+		return m, nil
+
+	case "s":
+		m.scrollOffset++
+		return m, nil
+
+	case "c":
+		// Enter conflicts view
+		m.viewMode = "conflicts"
+		return m, tea.Batch(m.loadConflicts(), m.loadOperation())
+
+	case "i":
+		// Ignore the selected untracked file without touching .gitignore
+		if m.fileCursor < len(m.changes) {
+			change := m.changes[m.fileCursor]
+			if change.Status != "??" {
+				m.statusMessage = "Only untracked files can be ignored; tracked files stay tracked"
+				return m, nil
+			}
+			m.tab = "tools"
+			return m.openExclude(git.ExcludePattern(change.File))
+		}
+		return m, nil
+
+	case "R":
+		// Reset last commit (mixed - keeps changes unstaged)
+		if m.confirmAction == "" {
+			m.confirmAction = "reset-commit"
+			m.statusMessage = "Press 'R' again to reset last commit (changes kept)"
+			return m, nil
+		} else if m.confirmAction == "reset-commit" {
+			m.confirmAction = ""
+			return m, m.gitResetLastCommit()
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// bulkStageActions names the keymap action behind each bulk stage key
+var bulkStageActions = map[string]string{
+	"m": "stage-tracked",
+	"u": "stage-untracked",
+	"A": "stage-dir",
+}
+
+func (m model) handleCommitKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// If viewing commit summary
+	if m.commitSummary != nil {
+		switch key {
+		case "p":
+			return m, m.pushChanges()
+		case "c":
+			m.commitSummary = nil
+			return m, tea.Batch(m.loadGitChanges(), m.loadGitStatus())
+		case "j", "down":
+			m.scrollOffset++
+			return m, nil
+		case "k", "up":
+			if m.scrollOffset > 0 {
+				m.scrollOffset--
+			}
+			return m, nil
+		}
+		return m, nil
+	}
+
+	// Nothing staged yet: offer to stage everything and carry on
+	if m.gitState.StagedFiles == 0 {
+		switch key {
+		case "a":
+			if len(m.changes) == 0 {
+				return m, nil
+			}
+			return m, m.stageAllForCommit()
+		case "e":
+			return m.openEmptyCommit()
+		case "m":
+			return m, m.loadAmendMessage()
+		case "esc":
+			m.commitInput.Blur()
+			m.tab = "workspace"
+			m.viewMode = "files"
+			return m, tea.Batch(m.loadGitChanges(), m.loadGitStatus())
+		}
+		return m, nil
+	}
+
+	// If reviewing a split commit plan
+	if m.splitGroups != nil {
+		return m.handleSplitKey(key)
+	}
+
+	// If picking files for a partial commit
+	if m.partialFiles != nil {
+		return m.handlePartialKey(key)
+	}
+
+	// If browsing the staged files
+	if m.stagedBrowsing {
+		return m.handleStagedFilesKey(key)
+	}
+
+	// If picking a type or scope
+	if m.commitPicker != "" {
+		return m.handleCommitPickerKey(key)
+	}
+
+	// If describing a breaking change
+	if m.breakingInput.Focused() {
+		switch key {
+		case "enter":
+			m.breakingNote = strings.TrimSpace(m.breakingInput.Value())
+			m.breakingInput.Blur()
+			if m.breakingNote != "" {
+				m.commitInput.SetValue(suggest.MarkBreaking(m.commitInput.Value()))
+				m.commitInput.CursorEnd()
+			}
+			m.commitInput.Focus()
+			return m, nil
+		case "esc":
+			m.breakingInput.Blur()
+			m.commitInput.Focus()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.breakingInput, cmd = m.breakingInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "ctrl+o":
+		return m, m.loadCommitGroups()
+
+	case "ctrl+p":
+		return m, m.loadPartialFiles()
+
+	case "ctrl+f":
+		m.stagedBrowsing = true
+		m.stagedCursor = min(m.stagedCursor, max(0, len(m.stagedChanges())-1))
+		m.commitInput.Blur()
+		return m, nil
+
+	case "ctrl+t":
+		m.commitPicker = "type"
+		m.pickerCursor = 0
+		m.commitInput.Blur()
+		return m, m.loadCommitScopes()
+
+	case "ctrl+l":
+		m.showExplanation = !m.showExplanation
+		return m, nil
+
+	case "alt+a":
+		// Change identity in Tools, then come back with 2
+		m.commitInput.Blur()
+		m.tab = "tools"
+		m.toolMode = "identity"
+		return m, m.loadKnownIdentities()
+
+	case "alt+r":
+		if m.hookRetry == "" {
+			return m, nil
+		}
+		message := m.hookRetry
+		m.hookRetry = ""
+		return m, m.commitWithMessage(message)
+
+	case "alt+f":
+		m.footerOpen = true
+		m.commitInput.Blur()
+		m.footerInput.SetValue("")
+		m.footerInput.Placeholder = footerTokens[m.footerToken].placeholder
+		m.footerInput.Focus()
+		return m, textinput.Blink
+
+	case "alt+e":
+		return m.openEmptyCommit()
+
+	case "alt+m":
+		return m, m.loadAmendMessage()
+
+	case "alt+o":
+		m.overrideOpen = true
+		m.commitInput.Blur()
+		m.authorInput.SetValue("")
+		if m.commitOverride.Author.IsComplete() {
+			m.authorInput.SetValue(m.commitOverride.Author.String())
+		}
+		m.dateInput.SetValue("")
+		if !m.commitOverride.Date.IsZero() {
+			m.dateInput.SetValue(m.commitOverride.Date.Format("2006-01-02 15:04:05"))
+		}
+		m.authorInput.CursorEnd()
+		m.authorInput.Focus()
+		return m, textinput.Blink
+
+	case "alt+n":
+		if m.signingConfig.SignCommits {
+			return m, m.disableSigning()
+		}
+		if m.signingConfig.Key == "" {
+			// Nothing to sign with yet: pick a key in Tools
+			m.commitInput.Blur()
+			m.tab = "tools"
+			m.toolMode = "signing"
+			m.signingResult = ""
+			m.statusMessage = "Pick a signing key first"
+			return m, m.loadSigning()
+		}
+		return m, m.enableSigning()
+
+	case "ctrl+x":
+		m.commitInput.Blur()
+		if m.breakingNote != "" {
+			m.breakingInput.SetValue(m.breakingNote)
+		} else {
+			m.breakingInput.SetValue(strings.Join(m.breakingChanges, "; "))
+		}
+		m.breakingInput.CursorEnd()
+		m.breakingInput.Focus()
+		return m, nil
+
+	case "enter", "alt+enter":
+		if m.loading["commit-push"] {
+			return m, nil
+		}
+		message := strings.TrimSpace(m.commitInput.Value())
+		if message != "" {
+			message = suggest.WithBreakingFooter(message, m.breakingNote)
+		} else if m.selectedSuggestion > 0 && m.selectedSuggestion <= len(m.suggestions) {
+			message = m.suggestionMessage(m.selectedSuggestion)
+		}
+		if message == "" {
+			return m, nil
+		}
+		return m.submitCommit(message, key, key == "alt+enter")
+
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		// Commit with suggestion N straight away, whatever is typed
+		if m.loading["commit-push"] {
+			return m, nil
+		}
+		n := int(key[len(key)-1] - '0')
+		if n > len(m.suggestions) {
+			m.statusMessage = fmt.Sprintf("No suggestion %d", n)
+			return m, nil
+		}
+		m.selectedSuggestion = n
+		return m.submitCommit(m.suggestionMessage(n), m.keys.keyFor(ctxCommit, fmt.Sprintf("commit-%d", n)), false)
+
+	case "esc":
+		m.commitInput.SetValue("")
+		m.commitInput.Blur()
+		m.selectedSuggestion = 0
+		m.spellIssues = nil
+		m.breakingNote = ""
+		m.ticket = ""
+		m.footers = nil
+		m.partialPaths = nil
+		return m, nil
+
+	case "ctrl+s":
+		m.spellOff = !m.spellOff
+		m.spellIssues = m.checkSpelling(m.commitInput.Value())
+		if m.spellOff {
+			m.statusMessage = "Spell-check off"
+		} else {
+			m.statusMessage = "Spell-check on"
+		}
+		return m, nil
+
+	case "ctrl+r":
+		if len(m.spellIssues) > 0 {
+			m.commitInput.SetValue(spell.ApplyFirst(m.commitInput.Value(), m.spellIssues))
+			m.spellIssues = m.checkSpelling(m.commitInput.Value())
+		}
+		return m, nil
+
+	case "ctrl+g":
+		if len(m.spellIssues) > 0 {
+			word := m.spellIssues[0].Word
+			m.speller.Add(word)
+			m.spellIssues = m.checkSpelling(m.commitInput.Value())
+			return m, m.addToDictionary(word)
+		}
+		return m, nil
+
+	case "up":
+		if m.selectedSuggestion > 0 {
+			m.selectedSuggestion--
+		}
+		return m, nil
+
+	case "down":
+		if m.selectedSuggestion < len(m.suggestions) {
+			m.selectedSuggestion++
+		}
+		return m, nil
+
+	case "tab":
+		if !m.commitInput.Focused() {
+			m.commitInput.Focus()
+		}
+		return m, nil
+	}
+
+	// Pass to text input
+	var cmd tea.Cmd
+	m.commitInput, cmd = m.commitInput.Update(msg)
+	m.spellIssues = m.checkSpelling(m.commitInput.Value())
+	return m, cmd
+}
+
+// suggestionMessage is suggestion n (1-based) as it would be committed,
+// with any breaking change footer
+func (m model) suggestionMessage(n int) string {
+	suggestion := m.suggestions[n-1]
+	note := m.breakingNote
+	if note == "" {
+		note = suggestion.Breaking
+	}
+	return suggest.WithBreakingFooter(suggestion.Message, note)
+}
+
+// submitCommit runs the ticket and protected branch checks, then commits
+// (and pushes). key is what the user pressed, for "press again" prompts.
+func (m model) submitCommit(message, key string, push bool) (tea.Model, tea.Cmd) {
+	message = withFooters(message, m.footers)
+	if err := checkFooters(message); err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+	tickets := m.config.Commit.Tickets
+	re, err := m.ticketRule()
+	if err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+	if re != nil && !hasTicket(message, re) {
+		if m.ticket == "" {
+			ticket := ticketFromBranch(m.gitState.Branch, re)
+			if ticket == "" {
+				m.statusMessage = fmt.Sprintf("Commits on '%s' need a ticket reference matching %s", m.gitState.Branch, tickets.Pattern)
+				return m, nil
+			}
+			if m.confirmAction != "commit-ticket" {
+				m.confirmAction = "commit-ticket"
+				m.statusMessage = fmt.Sprintf("No ticket reference - press %s again to add %s from the branch name", key, ticket)
+				return m, nil
+			}
+			m.confirmAction = ""
+			m.ticket = ticket
+		}
+		message = withTicket(message, m.ticket, tickets.Insert)
+	}
+	if m.config.Branches.IsProtected(m.gitState.Branch) && m.confirmAction != "commit-protected" {
+		m.confirmAction = "commit-protected"
+		m.statusMessage = fmt.Sprintf("'%s' is protected - press %s again to commit on it anyway", m.gitState.Branch, key)
+		return m, nil
+	}
+	m.confirmAction = ""
+	if push {
+		return m, m.commitAndPush(message)
+	}
+	return m, m.commitWithMessage(message)
+}
+
+// handleOverrideKey edits the author and date used for the next commit.
+// Both are checked on enter; an empty field keeps git's own.
+func (m model) handleOverrideKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.resolve(ctxOverride, msg.String()) {
+	case "tab":
+		if m.authorInput.Focused() {
+			m.authorInput.Blur()
+			m.dateInput.Focus()
+		} else {
+			m.dateInput.Blur()
+			m.authorInput.Focus()
+		}
+		return m, textinput.Blink
+	case "enter":
+		var override git.CommitOverride
+		if value := strings.TrimSpace(m.authorInput.Value()); value != "" {
+			author, err := git.ParseIdentity(value)
+			if err != nil {
+				m.statusMessage = "Author: " + err.Error()
+				return m, nil
+			}
+			override.Author = author
+		}
+		if value := strings.TrimSpace(m.dateInput.Value()); value != "" {
+			date, err := git.ParseCommitDate(value)
+			if err != nil {
+				m.statusMessage = "Date: " + err.Error()
+				return m, nil
+			}
+			override.Date = date
+		}
+		m.commitOverride = override
+		if override.IsZero() {
+			m.statusMessage = "Author/date override cleared"
+		} else {
+			m.statusMessage = "Next commit " + override.String()
+		}
+		return m.closeOverride(), nil
+	case "esc":
+		return m.closeOverride(), nil
+	}
+	var cmd tea.Cmd
+	if m.authorInput.Focused() {
+		m.authorInput, cmd = m.authorInput.Update(msg)
+	} else {
+		m.dateInput, cmd = m.dateInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m model) openEmptyCommit() (tea.Model, tea.Cmd) {
+	m.messageMode = "empty"
+	m.commitInput.Blur()
+	m.messageInput.SetValue("")
+	m.messageInput.Placeholder = "ci: trigger build"
+	m.messageInput.Focus()
+	return m, textinput.Blink
+}
+
+// handleMessageKey reads the message of an empty commit or a message-only
+// amend; enter asks for confirmation, a second enter goes ahead
+func (m model) handleMessageKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	confirm := "message-" + m.messageMode
+	switch m.keys.resolve(ctxMessage, msg.String()) {
+	case "enter":
+		message := strings.TrimSpace(m.messageInput.Value())
+		if message == "" {
+			return m, nil
+		}
+		if m.confirmAction != confirm {
+			m.confirmAction = confirm
+			if m.messageMode == "empty" {
+				m.statusMessage = fmt.Sprintf("Press enter again to commit no changes to %s", m.gitState.Branch)
+			} else if m.amendPushed {
+				m.statusMessage = "Press enter again to reword the last commit - it is pushed, so this needs a force push!"
+			} else {
+				m.statusMessage = "Press enter again to reword the last commit (its changes stay as they are)"
+			}
+			return m, nil
+		}
+		m.confirmAction = ""
+		mode := m.messageMode
+		m = m.closeMessage()
+		if mode == "empty" {
+			return m, m.commitEmpty(message)
+		}
+		if m.amendBody != "" {
+			message += "\n" + m.amendBody
+		}
+		return m, m.amendMessage(message, m.amendPushed)
+	case "esc":
+		m.confirmAction = ""
+		return m.closeMessage(), nil
+	}
+	// Editing after the first enter asks again
+	if m.confirmAction == confirm {
+		m.confirmAction = ""
+		m.statusMessage = ""
+	}
+	var cmd tea.Cmd
+	m.messageInput, cmd = m.messageInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) closeMessage() model {
+	m.messageMode = ""
+	m.messageInput.Blur()
+	if m.tab == "commit" && m.gitState.StagedFiles > 0 {
+		m.commitInput.Focus()
+	}
+	return m
+}
+
+// handleFooterKey builds the next commit's footers: tab picks the kind,
+// enter adds one, and the input stays open for more
+func (m model) handleFooterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.resolve(ctxFooter, msg.String()) {
+	case "tab":
+		m.footerToken = (m.footerToken + 1) % len(footerTokens)
+		m.footerInput.Placeholder = footerTokens[m.footerToken].placeholder
+		return m, nil
+	case "enter":
+		token := footerTokens[m.footerToken].token
+		value := strings.TrimSpace(m.footerInput.Value())
+		if value == "" {
+			return m.closeFooters(), nil
+		}
+		switch token {
+		case "Reviewed-by":
+			reviewer, err := git.ParseIdentity(value)
+			if err != nil {
+				m.statusMessage = "Reviewed-by: " + err.Error()
+				return m, nil
+			}
+			value = reviewer.String()
+		case "BREAKING CHANGE":
+			m.breakingNote = value
+			m.footerInput.SetValue("")
+			m.statusMessage = "Marked as a breaking change"
+			return m, nil
+		}
+		m.footers = append(m.footers, commitFooter{token: token, value: value})
+		m.footerInput.SetValue("")
+		m.statusMessage = fmt.Sprintf("Added %s: %s", token, value)
+		return m, nil
+	case "alt+x":
+		if n := len(m.footers); n > 0 {
+			m.statusMessage = fmt.Sprintf("Removed %s: %s", m.footers[n-1].token, m.footers[n-1].value)
+			m.footers = m.footers[:n-1]
+		} else if m.breakingNote != "" {
+			m.breakingNote = ""
+			m.statusMessage = "No longer a breaking change"
+		}
+		return m, nil
+	case "esc":
+		return m.closeFooters(), nil
+	}
+	var cmd tea.Cmd
+	m.footerInput, cmd = m.footerInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) closeFooters() model {
+	m.footerOpen = false
+	m.footerInput.Blur()
+	m.commitInput.Focus()
+	return m
+}
+
+func (m model) closeOverride() model {
+	m.overrideOpen = false
+	m.authorInput.Blur()
+	m.dateInput.Blur()
+	m.commitInput.Focus()
+	return m
+}
+
+// handleStagedFilesKey moves through the commit tab's staged files; enter
+// opens the workspace on the selected one to adjust its staging
+func (m model) handleStagedFilesKey(key string) (tea.Model, tea.Cmd) {
+	staged := m.stagedChanges()
+	switch key {
+	case "esc":
+		m.stagedBrowsing = false
+		m.commitInput.Focus()
+		return m, nil
+	case "j", "down":
+		if m.stagedCursor < len(staged)-1 {
+			m.stagedCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.stagedCursor > 0 {
+			m.stagedCursor--
+		}
+		return m, nil
+	case "enter":
+		if m.stagedCursor >= len(staged) {
+			return m, nil
+		}
+		file := staged[m.stagedCursor].File
+		for i, change := range m.changes {
+			if change.File == file {
+				m.fileCursor = i
+			}
+		}
+		m.stagedBrowsing = false
+		m.tab = "workspace"
+		m.viewMode = "files"
+		m.adjustFileScroll()
+		return m, m.loadFileDiff(file)
+	}
+	return m, nil
+}
+
+func (m model) handlePartialKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "esc":
+		m.partialFiles = nil
+		m.commitInput.Focus()
+		return m, nil
+	case "j", "down":
+		if m.partialCursor < len(m.partialFiles)-1 {
+			m.partialCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.partialCursor > 0 {
+			m.partialCursor--
+		}
+		return m, nil
+	case " ", "space":
+		file := m.partialFiles[m.partialCursor]
+		m.partialSelected[file] = !m.partialSelected[file]
+		return m, nil
+	case "a":
+		all := len(m.partialSelection()) < len(m.partialFiles)
+		for _, file := range m.partialFiles {
+			m.partialSelected[file] = all
+		}
+		return m, nil
+	case "enter":
+		paths := m.partialSelection()
+		// Picking everything is an ordinary commit
+		if len(paths) == len(m.partialFiles) {
+			paths = nil
+		}
+		m.partialPaths = paths
+		m.partialFiles = nil
+		m.commitInput.Focus()
+		if len(paths) > 0 {
+			m.statusMessage = fmt.Sprintf("Next commit limited to %d file(s)", len(paths))
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m model) handleSplitKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "esc":
+		m.splitGroups = nil
+		m.confirmAction = ""
+		m.statusMessage = ""
+		m.commitInput.Focus()
+		return m, nil
+	case "j", "down":
+		if m.splitCursor < len(m.splitGroups)-1 {
+			m.splitCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.splitCursor > 0 {
+			m.splitCursor--
+		}
+		return m, nil
+	case "enter":
+		re, err := m.ticketRule()
+		if err != nil {
+			m.statusMessage = err.Error()
+			return m, nil
+		}
+		ticket := ""
+		if re != nil {
+			if ticket = ticketFromBranch(m.gitState.Branch, re); ticket == "" {
+				m.statusMessage = fmt.Sprintf("Commits on '%s' need a ticket reference matching %s", m.gitState.Branch, m.config.Commit.Tickets.Pattern)
+				return m, nil
+			}
+		}
+		if m.confirmAction == "split" {
+			groups := m.splitGroups
+			for i := range groups {
+				if re != nil && !hasTicket(groups[i].Message, re) {
+					groups[i].Message = withTicket(groups[i].Message, ticket, m.config.Commit.Tickets.Insert)
+				}
+			}
+			m.confirmAction = ""
+			m.splitGroups = nil
+			m.commitInput.Focus()
+			return m, m.commitInGroups(groups)
+		}
+		m.confirmAction = "split"
+		m.statusMessage = fmt.Sprintf("Press enter again to create %d commits", len(m.splitGroups))
+		if ticket != "" {
+			m.statusMessage += fmt.Sprintf(" referencing %s", ticket)
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m model) handleCommitPickerKey(key string) (tea.Model, tea.Cmd) {
+	options := m.config.Commit.Types
+	if m.commitPicker == "scope" {
+		options = m.scopeOptions()
+	}
+
+	switch key {
+	case "esc":
+		m.commitPicker = ""
+		m.commitInput.Focus()
+		return m, nil
+	case "j", "down":
+		if m.pickerCursor < len(options)-1 {
+			m.pickerCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.pickerCursor > 0 {
+			m.pickerCursor--
+		}
+		return m, nil
+	case "enter":
+		if m.pickerCursor >= len(options) {
+			return m, nil
+		}
+		if m.commitPicker == "type" {
+			m.pickedType = options[m.pickerCursor]
+			m.commitPicker = "scope"
+			m.pickerCursor = 0
+			return m, nil
+		}
+		scope := ""
+		if m.pickerCursor > 0 {
+			scope = options[m.pickerCursor]
+		}
+		m.commitInput.SetValue(applyCommitPrefix(m.commitInput.Value(), m.pickedType, scope))
+		m.commitInput.CursorEnd()
+		m.commitInput.Focus()
+		m.commitPicker = ""
+		m.selectedSuggestion = 0
+		m.spellIssues = m.checkSpelling(m.commitInput.Value())
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m model) handleBranchesKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// If comparing branches
+	if m.branchComparison != nil {
+		if m.review != nil {
+			return m.handleReviewKey(key)
+		}
+		files := m.branchComparison.DifferingFiles
+		switch key {
+		case "esc":
+			m.branchComparison = nil
+			return m, nil
+		case "j", "down":
+			if m.compareCursor < len(files)-1 {
+				m.compareCursor++
+			}
+		case "k", "up":
+			if m.compareCursor > 0 {
+				m.compareCursor--
+			}
+		case "enter":
+			if m.compareCursor < len(files) {
+				return m, m.loadReviewDiff(files[m.compareCursor])
+			}
+		case "e":
+			if len(m.reviewComments) > 0 {
+				return m, m.exportReview()
+			}
+		case "x":
+			return m, m.exportComparison("md")
+		case "X":
+			return m, m.exportComparison("json")
+		case "C":
+			return m, m.copyComparison()
+		case "P":
+			if len(m.reviewComments) == 0 {
+				return m, nil
+			}
+			if !git.HasGH() {
+				m.statusMessage = "gh is not installed - export the review with " + m.keys.keyFor(ctxCompare, "export") + " instead"
+				m.statusExpiry = time.Now().Add(5 * time.Second)
+				return m, nil
+			}
+			if m.confirmAction != "post-review" {
+				m.confirmAction = "post-review"
+				m.statusMessage = fmt.Sprintf("Press %s again to post %d comment(s) to this branch's pull request", m.keys.keyFor(ctxCompare, "post"), len(m.reviewComments))
+				return m, nil
+			}
+			m.confirmAction = ""
+			return m, m.postReview()
+		}
+		return m, nil
+	}
+
+	// If creating new branch
+	if m.branchInput.Focused() {
+		switch key {
+		case "enter":
+			branchName := strings.TrimSpace(m.branchInput.Value())
+			if branchName != "" {
+				m.branchInput.SetValue("")
+				m.branchInput.Blur()
+				return m, m.createBranch(branchName)
+			}
+			return m, nil
+		case "esc":
+			m.branchInput.SetValue("")
+			m.branchInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.branchInput, cmd = m.branchInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "j", "down":
+		if m.branchCursor < len(m.branches)-1 {
+			m.branchCursor++
+			m.adjustBranchScroll()
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.branchCursor > 0 {
+			m.branchCursor--
+			m.adjustBranchScroll()
+		}
+		return m, nil
+
+	case "enter":
+		if m.branchCursor < len(m.branches) {
+			return m, m.switchBranch(m.branches[m.branchCursor].Name)
+		}
+		return m, nil
+
+	case "n":
+		m.branchInput.Focus()
+		return m, textinput.Blink
+
+	case "h":
+		if m.branchCursor < len(m.branches) {
+			return m.openHistory(m.branches[m.branchCursor].Name)
+		}
+		return m, nil
+
+	case "d":
+		if m.branchCursor < len(m.branches) {
+			branch := m.branches[m.branchCursor]
+			if m.config.Branches.IsProtected(branch.Name) {
+				m.statusMessage = fmt.Sprintf("'%s' is protected (see %s)", branch.Name, config.RepoFile)
+				return m, nil
+			}
+			if !branch.IsCurrent {
+				if m.confirmAction != "delete-branch" && m.typedConfirm() {
+					return m.askTyped(branch.Name, fmt.Sprintf("delete branch '%s'", branch.Name), "delete-branch", key)
+				}
+				if m.confirmAction == "" {
+					m.confirmAction = "delete-branch"
+					m.statusMessage = fmt.Sprintf("Press 'd' to confirm delete '%s'", branch.Name)
+					return m, nil
+				} else if m.confirmAction == "delete-branch" {
+					m.confirmAction = ""
+					return m, m.deleteBranch(branch.Name)
+				}
+			}
+		}
+		return m, nil
+
+	case "c":
+		if m.branchCursor < len(m.branches) {
+			return m, m.compareBranch(m.branches[m.branchCursor].Name)
+		}
+		return m, nil
+
+	case "C":
+		// Compare against the default branch
+		if m.defaultBranch == "" {
+			m.statusMessage = "Could not detect default branch"
+			return m, nil
+		}
+		return m, m.compareBranch(m.defaultBranch)
+
+	case "T":
+		return m, m.compareSinceTag()
+
+	case "u":
+		return m, m.compareUpstream()
+
+	case "P":
+		if m.branchCursor >= len(m.branches) || m.branches[m.branchCursor].IsRemote {
+			return m, nil
+		}
+		branch := m.branches[m.branchCursor]
+		if m.confirmAction != "push-branch" {
+			m.confirmAction = "push-branch"
+			m.statusMessage = m.pushBranchPrompt(branch)
+			return m, nil
+		}
+		m.confirmAction = ""
+		if branch.IsCurrent {
+			return m, m.pushChanges()
+		}
+		return m, m.pushBranch(branch)
+
+	case "L":
+		if m.branchCursor >= len(m.branches) || m.branches[m.branchCursor].IsRemote {
+			return m, nil
+		}
+		branch := m.branches[m.branchCursor]
+		switch {
+		case branch.IsCurrent:
+			return m, m.pullChanges()
+		case branch.Ahead > 0:
+			m.statusMessage = fmt.Sprintf("%s is %d ahead of %s - only a branch strictly behind can be fast-forwarded", branch.Name, branch.Ahead, branch.Upstream)
+			return m, nil
+		}
+		return m, m.fastForwardBranch(branch.Name)
+
+	case "#":
+		m.prInput.Focus()
+		return m, textinput.Blink
+
+	case "esc":
+		m.confirmAction = ""
+		m.statusMessage = ""
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m model) handleToolsKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle rebase input
+	if m.toolMode == "rebase" && m.rebaseInput.Focused() {
+		switch key {
+		case "enter":
+			m.rebaseInput.Blur()
+			return m, m.loadRebaseCommits()
+		case "esc":
+			m.rebaseInput.Blur()
+			m.toolMode = "menu"
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.rebaseInput, cmd = m.rebaseInput.Update(msg)
+		return m, cmd
+	}
+
+	// Handle custom identity input
+	if m.toolMode == "identity" && m.identityInput.Focused() {
+		return m.handleIdentityKey(key, msg)
+	}
+
+	// Handle config value input
+	if m.toolMode == "config" && m.configInput.Focused() {
+		return m.handleConfigKey(key, msg)
+	}
+
+	// Handle undo count input
+	if m.toolMode == "undo" && m.undoInput.Focused() {
+		return m.handleUndoKey(key, msg)
+	}
+
+	// Handle lost commit diff preview (esc returns to the list)
+	if m.toolMode == "recover" && m.lostDiff != "" {
+		return m.handleRecoverKey(key)
+	}
+
+	// Handle an opened log commit (esc steps back to its whole diff, then
+	// to the log)
+	if m.toolMode == "log" && m.logDetail != nil {
+		return m.handleLogDetailKey(key)
+	}
+
+	// Handle time-based undo input
+	if m.toolMode == "undotime" && m.undoTimeInput.Focused() {
+		return m.handleTimeRestoreKey(key, msg)
+	}
+
+	// Handle a marked history range (esc clears just the range)
+	if m.toolMode == "history" && m.historyMarked && key == "esc" {
+		m.historyMarked = false
+		m.confirmAction = ""
+		return m, nil
+	}
+
+	// Handle stash file picker (esc closes just the picker)
+	if m.toolMode == "stash" && m.stashFiles != nil {
+		return m.handleStashFilesKey(key)
+	}
+
+	// Handle one contributor's history (esc returns to the contributors)
+	if m.toolMode == "history" && m.historyAuthor != "" && key == "esc" {
+		m.toolMode = "contributors"
+		m.historyAuthor = ""
+		m.commits = nil
+		return m, nil
+	}
+
+	// Back to menu
+	if key == "esc" {
+		if m.toolMode != "menu" {
+			m.toolMode = "menu"
+			m.pushOutput = ""
+			return m, nil
+		}
+		return m, nil
+	}
+
+	// Tool mode specific
+	switch m.toolMode {
+	case "menu":
+		return m.handleToolsMenuKey(key)
+	case "undo":
+		return m.handleUndoKey(key, msg)
+	case "undopush":
+		return m.handlePushUndoKey(key)
+	case "diverged":
+		return m.handleDivergedKey(key)
+	case "undotime":
+		return m.handleTimeRestoreKey(key, msg)
+	case "rebase":
+		return m.handleRebaseKey(key)
+	case "history":
+		return m.handleHistoryKey(key)
+	case "remote":
+		return m.handleRemoteKey(key)
+	case "stash":
+		return m.handleStashKey(key, msg)
+	case "tags":
+		return m.handleTagsKey(key, msg)
+	case "hooks":
+		return m.handleHooksKey(key)
+	case "log":
+		return m.handleLogKey(key, msg)
+	case "clone":
+		return m.handleCloneKey(key, msg)
+	case "init":
+		return m.handleInitKey(key, msg)
+	case "clean":
+		return m.handleCleanKey(key)
+	case "identity":
+		return m.handleIdentityKey(key, msg)
+	case "config":
+		return m.handleConfigKey(key, msg)
+	case "aliases":
+		return m.handleAliasKey(key)
+	case "recover":
+		return m.handleRecoverKey(key)
+	case "signing":
+		return m.handleSigningKey(key)
+	case "jobs":
+		return m, nil
+	case "messages":
+		// m toggles the list like it opened it
+		if key == "m" {
+			m.toolMode = "menu"
+		}
+		return m, nil
+	case "snapshots":
+		return m.handleSnapshotsKey(key)
+	case "contributors":
+		return m.handleContributorsKey(key)
+	case "exclude":
+		return m.handleExcludeKey(key)
+	}
+
+	return m, nil
+}
+
+func (m model) handleToolsMenuKey(key string) (tea.Model, tea.Cmd) {
+	// Main tools menu (categories)
+	maxCursor := 21 // 22 items: 0-21
+
+	switch key {
+	case "j", "down":
+		if m.toolCursor < maxCursor {
+			m.toolCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.toolCursor > 0 {
+			m.toolCursor--
+		}
+		return m, nil
+	case "enter":
+		return m.selectToolMenuItem()
+	// Quick keys
+	case "s":
+		m.toolMode = "stash"
+		return m, m.loadStashList()
+	case "t":
+		m.toolMode = "tags"
+		return m, m.loadTags()
+	case "h":
+		return m.openHistory("")
+	case "u":
+		m.toolMode = "undo"
+		m.undoCursor, m.undoOffset = 0, 0
+		m.commits = nil // may hold another branch's history
+		return m, m.loadCommitHistory()
+	case "r":
+		m.toolMode = "rebase"
+		m.rebaseInput.Focus()
+		return m, tea.Batch(textinput.Blink, m.loadOperation())
+	case "p":
+		if m.confirmAction == "" {
+			m.confirmAction = "push"
+			m.statusMessage = m.pushPrompt("p")
+			return m, nil
+		} else if m.confirmAction == "push" {
+			m.confirmAction = ""
+			return m, m.pushChanges()
+		}
+		return m, nil
+	case "f":
+		return m.openFetch()
+	case "l":
+		if m.confirmAction == "" {
+			m.confirmAction = "pull"
+			m.statusMessage = "Press l again to pull from remote"
+			return m, nil
+		} else if m.confirmAction == "pull" {
+			m.confirmAction = ""
+			return m, m.pullChanges()
+		}
+		return m, nil
+	case "g":
+		m.toolMode = "hooks"
+		return m, nil
+	case "o":
+		m.toolMode = "log"
+		return m, m.loadLogCommits("")
+	case "c":
+		m.toolMode = "clone"
+		m.cloneInput.Focus()
+		return m, textinput.Blink
+	case "i":
+		m.toolMode = "init"
+		m.initInput.Focus()
+		return m, textinput.Blink
+	case "x":
+		m.toolMode = "clean"
+		return m, m.loadCleanFiles()
+	case "a":
+		m.toolMode = "identity"
+		return m, m.loadKnownIdentities()
+	case "e":
+		m.toolMode = "config"
+		return m, m.loadConfigEntries()
+	case "w":
+		m.toolMode = "aliases"
+		m.aliasOutput = ""
+		return m, m.loadAliases()
+	case "v":
+		return m.openRecoverTool()
+	case "n":
+		m.toolMode = "signing"
+		m.signingResult = ""
+		return m, m.loadSigning()
+	case "b":
+		m.toolMode = "jobs"
+		return m, m.tickJobs()
+	case "z":
+		m.toolMode = "snapshots"
+		return m, m.loadSnapshots()
+	case "I":
+		return m.openExclude("")
+	case "m":
+		m.toolMode = "messages"
+		return m, nil
+	case "A":
+		return m.openContributors()
+	}
+	return m, nil
+}
+
+func (m model) selectToolMenuItem() (tea.Model, tea.Cmd) {
+	switch m.toolCursor {
+	case 0: // Log
+		m.toolMode = "log"
+		return m, m.loadLogCommits("")
+	case 1: // Stash
+		m.toolMode = "stash"
+		return m, m.loadStashList()
+	case 2: // Tags
+		m.toolMode = "tags"
+		return m, m.loadTags()
+	case 3: // History
+		return m.openHistory("")
+	case 4: // Undo
+		m.toolMode = "undo"
+		m.undoCursor, m.undoOffset = 0, 0
+		m.commits = nil // may hold another branch's history
+		return m, m.loadCommitHistory()
+	case 5: // Rebase
+		m.toolMode = "rebase"
+		m.rebaseInput.Focus()
+		return m, tea.Batch(textinput.Blink, m.loadOperation())
+	case 6: // Push
+		if m.confirmAction == "" {
+			m.confirmAction = "push"
+			m.statusMessage = m.pushPrompt("enter")
+			return m, nil
+		} else if m.confirmAction == "push" {
+			m.confirmAction = ""
+			return m, m.pushChanges()
+		}
+		return m, nil
+	case 7: // Fetch/Pull
+		// Fetch is safe, no confirm needed
+		return m.openFetch()
+	case 8: // Hooks
+		m.toolMode = "hooks"
+		return m, nil
+	case 9: // Clean
+		m.toolMode = "clean"
+		return m, m.loadCleanFiles()
+	case 10: // Clone
+		m.toolMode = "clone"
+		m.cloneInput.Focus()
+		return m, textinput.Blink
+	case 11: // Init
+		m.toolMode = "init"
+		m.initInput.Focus()
+		return m, textinput.Blink
+	case 12: // Identity
+		m.toolMode = "identity"
+		return m, m.loadKnownIdentities()
+	case 13: // Config
+		m.toolMode = "config"
+		return m, m.loadConfigEntries()
+	case 14: // Aliases
+		m.toolMode = "aliases"
+		m.aliasOutput = ""
+		return m, m.loadAliases()
+	case 15: // Recover
+		return m.openRecoverTool()
+	case 16: // Signing
+		m.toolMode = "signing"
+		m.signingResult = ""
+		return m, m.loadSigning()
+	case 17: // Jobs
+		m.toolMode = "jobs"
+		return m, m.tickJobs()
+	case 18: // Snapshots
+		m.toolMode = "snapshots"
+		return m, m.loadSnapshots()
+	case 19: // Exclude
+		return m.openExclude("")
+	case 20: // Messages
+		m.toolMode = "messages"
+		return m, nil
+	case 21: // Contributors
+		return m.openContributors()
+	}
+	return m, nil
+}
+
+// undoModeDesc explains what each reset mode does with the undone changes
+var undoModeDesc = map[string]string{
+	"soft":  "changes kept staged",
+	"mixed": "changes kept unstaged",
+	"hard":  "changes DISCARDED, including uncommitted work",
+}
+
+func (m model) handleUndoKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Number of commits input
+	if m.undoInput.Focused() {
+		switch key {
+		case "enter":
+			m.undoInput.Blur()
+			n, err := strconv.Atoi(strings.TrimSpace(m.undoInput.Value()))
+			m.undoInput.SetValue("")
+			if err != nil || n < 1 {
+				m.statusMessage = "Enter a number of commits (1 or more)"
+				return m, nil
+			}
+			if n >= len(m.commits) {
+				m.statusMessage = fmt.Sprintf("Can undo at most %d commits from here", len(m.commits)-1)
+				return m, nil
+			}
+			m.undoCursor = n
+			m.adjustUndoScroll()
+			m.confirmAction = ""
+			return m, nil
+		case "esc":
+			m.undoInput.Blur()
+			m.undoInput.SetValue("")
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.undoInput, cmd = m.undoInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "j", "down":
+		if m.undoCursor < len(m.commits)-1 {
+			m.undoCursor++
+			m.adjustUndoScroll()
+		}
+		m.confirmAction = ""
+		return m, nil
+	case "k", "up":
+		if m.undoCursor > 0 {
+			m.undoCursor--
+			m.adjustUndoScroll()
+		}
+		m.confirmAction = ""
+		return m, nil
+	case "n":
+		m.undoInput.Focus()
+		return m, textinput.Blink
+	case "d":
+		m.absoluteDates = !m.absoluteDates
+		return m, nil
+	case "m":
+		switch m.undoMode {
+		case "soft":
+			m.undoMode = "mixed"
+		case "mixed":
+			m.undoMode = "hard"
+		default:
+			m.undoMode = "soft"
+		}
+		m.confirmAction = ""
+		return m, nil
+	case "enter":
+		if m.undoCursor == 0 {
+			m.statusMessage = "Select the commit to go back to (commits above it are undone)"
+			return m, nil
+		}
+		if m.undoCursor < len(m.commits) {
+			if m.confirmAction != "undo" && m.undoMode == "hard" && m.typedConfirm() {
+				return m.askTyped("discard", fmt.Sprintf("hard reset, undoing %d commit(s) and discarding changes", m.undoCursor), "undo", key)
+			}
+			if m.confirmAction != "undo" {
+				m.confirmAction = "undo"
+				m.statusMessage = fmt.Sprintf("Press enter again to undo %d commit(s) with --%s (%s)",
+					m.undoCursor, m.undoMode, undoModeDesc[m.undoMode])
+				return m, nil
+			}
+			m.confirmAction = ""
+			hash := m.commits[m.undoCursor].Hash
+			m.undoCursor, m.undoOffset = 0, 0
+			return m, m.undoToCommit(hash, m.undoMode)
+		}
+		return m, nil
+	case "t":
+		m.toolMode = "undotime"
+		m.timeRestore = nil
+		m.confirmAction = ""
+		m.undoTimeInput.SetValue("")
+		m.undoTimeInput.Focus()
+		return m, textinput.Blink
+	case "P":
+		m.toolMode = "undopush"
+		m.pushUndo = nil
+		m.pushUndoErr = ""
+		m.confirmAction = ""
+		return m, m.loadPushUndo()
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleTimeRestoreKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.undoTimeInput.Focused() {
+		switch key {
+		case "enter":
+			ago, err := parseAgo(m.undoTimeInput.Value())
+			if err != nil {
+				m.statusMessage = err.Error()
+				return m, nil
+			}
+			m.undoTimeInput.Blur()
+			return m, m.loadTimeRestore(ago)
+		case "esc":
+			m.undoTimeInput.Blur()
+			m.toolMode = "undo"
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.undoTimeInput, cmd = m.undoTimeInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "t":
+		m.timeRestore = nil
+		m.confirmAction = ""
+		m.undoTimeInput.Focus()
+		return m, textinput.Blink
+	case "enter":
+		if m.timeRestore == nil {
+			return m, nil
+		}
+		if m.confirmAction != "timerestore" {
+			m.confirmAction = "timerestore"
+			m.statusMessage = fmt.Sprintf("Press enter again to reset to %s (current state kept on a backup branch)", m.timeRestore.Target.Hash[:7])
+			return m, nil
+		}
+		m.confirmAction = ""
+		target := m.timeRestore.Target
+		m.timeRestore = nil
+		m.toolMode = "undo"
+		return m, m.restoreToPoint(target)
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleSigningKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.signingCursor < len(m.signingKeys)-1 {
+			m.signingCursor++
+		}
+	case "k", "up":
+		if m.signingCursor > 0 {
+			m.signingCursor--
+		}
+	case "enter":
+		if m.signingCursor < len(m.signingKeys) {
+			return m, m.configureSigning(m.signingKeys[m.signingCursor])
+		}
+	case "t":
+		m.signingResult = "Signing test object..."
+		m.signingOK = false
+		return m, m.testSigning()
+	case "d":
+		return m, m.disableSigning()
+	case "g":
+		if m.configScope == "local" {
+			m.configScope = "global"
+		} else {
+			m.configScope = "local"
+		}
+	case "r":
+		return m, m.loadSigning()
+	}
+	return m, nil
+}
+
+func (m model) openRecoverTool() (tea.Model, tea.Cmd) {
+	m.toolMode = "recover"
+	m.lostCommits = nil
+	m.lostCursor, m.lostOffset = 0, 0
+	m.lostDiff = ""
+	m.lostStatus = "Scanning for lost commits..."
+	return m, m.loadLostCommits()
+}
+
+func (m model) handleRecoverKey(key string) (tea.Model, tea.Cmd) {
+	// Diff preview
+	if m.lostDiff != "" {
+		switch key {
+		case "esc":
+			m.lostDiff = ""
+			m.scrollOffset = 0
+		case "j", "down":
+			m.scrollOffset++
+		case "k", "up":
+			if m.scrollOffset > 0 {
+				m.scrollOffset--
+			}
+		}
+		return m, nil
+	}
+
+	if len(m.lostCommits) == 0 {
+		if key == "r" {
+			return m.openRecoverTool()
+		}
+		return m, nil
+	}
+	lost := m.lostCommits[m.lostCursor]
+
+	switch key {
+	case "j", "down":
+		if m.lostCursor < len(m.lostCommits)-1 {
+			m.lostCursor++
+			m.adjustLostScroll()
+		}
+	case "k", "up":
+		if m.lostCursor > 0 {
+			m.lostCursor--
+			m.adjustLostScroll()
+		}
+	case "enter":
+		return m, m.loadLostDiff(lost.FullHash)
+	case "b":
+		return m, m.recoverToBranch(lost)
+	case "c":
+		if m.confirmAction != "recoverpick" {
+			m.confirmAction = "recoverpick"
+			m.statusMessage = fmt.Sprintf("Press c again to cherry-pick %s onto %s", lost.Hash, m.gitState.Branch)
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.cherryPickLost(lost)
+	case "r":
+		return m.openRecoverTool()
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handlePushUndoKey(key string) (tea.Model, tea.Cmd) {
+	if m.pushUndo == nil {
+		return m, nil
+	}
+
+	switch key {
+	case "r":
+		if m.confirmAction != "revertpush" {
+			m.confirmAction = "revertpush"
+			m.statusMessage = fmt.Sprintf("Press r again to create %d revert commit(s)", len(m.pushUndo.Commits))
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.revertLastPush(*m.pushUndo)
+	case "f":
+		if m.config.Branches.IsProtected(m.pushUndo.RemoteBranch) {
+			m.statusMessage = fmt.Sprintf("'%s' is protected: revert the push instead", m.pushUndo.RemoteBranch)
+			return m, nil
+		}
+		if m.confirmAction != "forcepush" && m.typedConfirm() {
+			return m.askTyped(m.pushUndo.RemoteBranch, fmt.Sprintf("force-push %s back to %s", m.pushUndo.Tracking(), m.pushUndo.From[:7]), "forcepush", key)
+		}
+		if m.confirmAction != "forcepush" {
+			m.confirmAction = "forcepush"
+			m.statusMessage = fmt.Sprintf("Press f again to force-push %s back to %s (rewrites remote history!)", m.pushUndo.Tracking(), m.pushUndo.From[:7])
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.forcePushPrevious(*m.pushUndo)
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+// refuseWrite explains why a key that changes the repository did nothing
+// writable fails in read-only mode, for files gitty writes itself rather
+// than through git: exports and the spelling dictionary
+func (m model) writable() error {
+	if m.readOnly {
+		return git.ErrReadOnly
+	}
+	return nil
+}
+
+func (m model) refuseWrite() (tea.Model, tea.Cmd) {
+	m.confirmAction = ""
+	m.statusMessage = "Read-only mode: this would change the repository"
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+	return m, nil
+}
+
+func (m model) handleTourKey(key string) (tea.Model, tea.Cmd) {
+	steps := m.tourSteps()
+	switch m.keys.resolve(ctxTour, key) {
+	case "enter":
+		if m.tourStep < len(steps)-1 {
+			m.showTourStep(m.tourStep + 1)
+			return m, nil
+		}
+		return m.endTour("Tour finished")
+	case "left":
+		if m.tourStep > 0 {
+			m.showTourStep(m.tourStep - 1)
+		}
+		return m, nil
+	case "esc":
+		return m.endTour(fmt.Sprintf("Tour skipped - press %s to take it later", m.keys.keyFor(ctxGlobal, "tour")))
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// showTourStep turns to page i of the tour and the tab it describes; a bare
+// repository has no workspace or commit tab to show
+func (m *model) showTourStep(i int) {
+	m.tourStep = i
+	tab := m.tourSteps()[i].tab
+	if m.bare && (tab == "workspace" || tab == "commit") {
+		tab = "branches"
+	}
+	m.tab = tab
+}
+
+// endTour closes the tour on the workspace and records in the config that
+// it has been seen, so it only shows on the first launch. Read-only and
+// presenter sessions leave the config alone.
+func (m model) endTour(status string) (tea.Model, tea.Cmd) {
+	m.touring, m.tourStep = false, 0
+	m.tab = "workspace"
+	if m.bare {
+		m.tab = "branches"
+	}
+	m.viewMode = "files"
+	if !m.config.UI.TourDone && !m.readOnly && !m.presenter {
+		m.config.UI.TourDone = true
+		if err := config.SaveTourDone(); err != nil {
+			status = fmt.Sprintf("Saving tour_done failed: %v", err)
+		}
+	}
+	m.statusMessage = status
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+	return m, tea.Batch(m.loadGitChanges(), m.loadGitStatus())
+}
+
+func (m model) handleLockPromptKey(key string) (tea.Model, tea.Cmd) {
+	prompt := *m.lockPrompt
+	if prompt.waiting {
+		if m.keys.resolve(ctxIndexLock, key) == "esc" {
+			m.lockPrompt = nil
+		}
+		return m, nil
+	}
+
+	switch m.keys.resolve(ctxIndexLock, key) {
+	case "w":
+		m.confirmAction = ""
+		prompt.waiting = true
+		m.lockPrompt = &prompt
+		return m, m.waitForIndexLock()
+	case "r":
+		m.confirmAction = ""
+		m.lockPrompt = nil
+		if prompt.retry != nil {
+			return m, prompt.retry
+		}
+		return m, m.checkIndexLock()
+	case "d":
+		if !prompt.lock.Stale() {
+			m.statusMessage = fmt.Sprintf("%s (pid %d) still holds the lock - wait for it instead", prompt.lock.Owner, prompt.lock.PID)
+			if !prompt.lock.OwnerKnown {
+				m.statusMessage = "The lock is recent and its owner cannot be checked - wait for it instead"
+			}
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if m.confirmAction != "remove-lock" {
+			m.confirmAction = "remove-lock"
+			m.statusMessage = "Press d again to delete index.lock"
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.removeIndexLock(prompt.lock)
+	case "esc":
+		m.confirmAction = ""
+		m.lockPrompt = nil
+		return m, nil
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleDivergedKey(key string) (tea.Model, tea.Cmd) {
+	if key == "g" {
+		m.confirmAction = ""
+		return m, m.loadDivergence()
+	}
+	div := m.divergence
+	if div == nil || !div.Diverged() {
+		return m, nil
+	}
+
+	switch key {
+	case "r":
+		if m.confirmAction != "rebase-upstream" {
+			m.confirmAction = "rebase-upstream"
+			m.statusMessage = fmt.Sprintf("Press r again to replay %d commit(s) onto %s", len(div.Local), div.Upstream)
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.rebaseOntoUpstream()
+	case "m":
+		if m.confirmAction != "merge-upstream" {
+			m.confirmAction = "merge-upstream"
+			m.statusMessage = fmt.Sprintf("Press m again to merge %s into %s", div.Upstream, div.Branch)
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.mergeUpstream()
+	case "f":
+		if m.config.Branches.IsProtected(div.Upstream) {
+			m.statusMessage = fmt.Sprintf("'%s' is protected: rebase or merge instead", div.Upstream)
+			return m, nil
+		}
+		if m.confirmAction != "force-diverged" && m.typedConfirm() {
+			return m.askTyped(div.Branch, fmt.Sprintf("force-push %s, dropping %d commit(s) from %s", div.Branch, len(div.Remote), div.Upstream), "force-diverged", key)
+		}
+		if m.confirmAction != "force-diverged" {
+			m.confirmAction = "force-diverged"
+			m.statusMessage = fmt.Sprintf("Press f again to force-push, dropping %d commit(s) from %s!", len(div.Remote), div.Upstream)
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.forcePushDiverged(*div)
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleRebaseKey(key string) (tea.Model, tea.Cmd) {
+	if m.rebaseProgress != nil {
+		if key == "enter" {
+			return m, func() tea.Msg { return showConflictsMsg{} }
+		}
+		return m, nil
+	}
+	if len(m.rebaseCommits) == 0 {
+		return m, nil
+	}
+
+	switch key {
+	case "j", "down":
+		if m.rebaseCursor < len(m.rebaseCommits)-1 {
+			m.rebaseCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.rebaseCursor > 0 {
+			m.rebaseCursor--
+		}
+		return m, nil
+	case "p":
+		m.rebaseCommits[m.rebaseCursor].Action = "pick"
+		return m, nil
+	case "s":
+		m.rebaseCommits[m.rebaseCursor].Action = "squash"
+		return m, nil
+	case "r":
+		m.rebaseCommits[m.rebaseCursor].Action = "reword"
+		return m, nil
+	case "d":
+		m.rebaseCommits[m.rebaseCursor].Action = "drop"
+		return m, nil
+	case "f":
+		m.rebaseCommits[m.rebaseCursor].Action = "fixup"
+		return m, nil
+	case "enter":
+		if m.confirmAction == "" {
+			m.confirmAction = "rebase"
+			m.statusMessage = "Press enter again to execute rebase (rewrites history!)"
+			return m, nil
+		} else if m.confirmAction == "rebase" {
+			m.confirmAction = ""
+			return m, m.executeRebase()
+		}
+		return m, nil
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleHistoryKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.historyCursor < len(m.commits)-1 {
+			m.historyCursor++
+			m.adjustHistoryScroll()
+		}
+		m.confirmAction = ""
+		return m, nil
+	case "k", "up":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+			m.adjustHistoryScroll()
+		}
+		m.confirmAction = ""
+		return m, nil
+	case "v":
+		if m.historyCursor < len(m.commits) {
+			return m, m.verifyCommits([]string{m.commits[m.historyCursor].Hash})
+		}
+		return m, nil
+	case "V":
+		hashes := make([]string, len(m.commits))
+		for i, commit := range m.commits {
+			hashes[i] = commit.Hash
+		}
+		m.statusMessage = fmt.Sprintf("Verifying %d commit(s)...", len(hashes))
+		return m, m.verifyCommits(hashes)
+	case " ":
+		// Start marking a range at the cursor; moving extends it
+		m.historyMarked = !m.historyMarked
+		m.historyMark = m.historyCursor
+		m.confirmAction = ""
+		return m, nil
+	case "c", "R", "r":
+		if len(m.commits) == 0 {
+			return m, nil
+		}
+		if m.historyAuthor != "" {
+			// One author's commits are not a contiguous range
+			m.statusMessage = "Cherry-pick, revert and rebase work on the whole history, not one author's commits"
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		return m.historyRangeAction(key)
+	case "b":
+		return m.jumpToHistoryBranch()
+	case "d":
+		m.absoluteDates = !m.absoluteDates
+		return m, nil
+	case "x", "X":
+		if len(m.commits) == 0 {
+			return m, nil
+		}
+		format := "csv"
+		if key == "X" {
+			format = "json"
+		}
+		return m, m.exportHistory(format)
+	}
+	return m, nil
+}
+
+// historyBranches returns the branches pointing at the selected history
+// commit that the branches tab lists
+func (m model) historyBranches() []string {
+	if m.historyCursor >= len(m.commits) {
+		return nil
+	}
+	var names []string
+	for _, name := range m.commits[m.historyCursor].Branches {
+		if slices.ContainsFunc(m.branches, func(b git.Branch) bool { return b.Name == name }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jumpToHistoryBranch moves the branches tab cursor to a branch pointing at
+// the selected history commit, preferring a local one
+func (m model) jumpToHistoryBranch() (tea.Model, tea.Cmd) {
+	names := m.historyBranches()
+	if len(names) == 0 {
+		return m, nil
+	}
+	target := slices.IndexFunc(m.branches, func(b git.Branch) bool { return slices.Contains(names, b.Name) })
+	m.tab = "branches"
+	m.branchComparison = nil
+	m.branchCursor = target
+	m.adjustBranchScroll()
+	if len(names) > 1 {
+		m.statusMessage = fmt.Sprintf("Also pointing here: %s", strings.Join(slices.DeleteFunc(names, func(name string) bool {
+			return name == m.branches[target].Name
+		}), ", "))
+		m.statusExpiry = time.Now().Add(5 * time.Second)
+	}
+	return m, nil
+}
+
+// historyRange returns the marked range as indexes into the history, newest
+// (smallest) first; with nothing marked it is just the cursor's commit
+func (m model) historyRange() (newest, oldest int) {
+	if !m.historyMarked {
+		return m.historyCursor, m.historyCursor
+	}
+	return min(m.historyMark, m.historyCursor), max(m.historyMark, m.historyCursor)
+}
+
+// historyRangeAction cherry-picks (c), reverts (R) or rebases (r) the
+// marked range. Cherry-picks come from another branch's history; reverts
+// and rebases work on the current branch.
+func (m model) historyRangeAction(key string) (tea.Model, tea.Cmd) {
+	newest, oldest := m.historyRange()
+	count := oldest - newest + 1
+	span := m.commits[oldest].Hash
+	if count > 1 {
+		span = fmt.Sprintf("%d commits, %s..%s", count, m.commits[oldest].Hash, m.commits[newest].Hash)
+	}
+
+	switch key {
+	case "c":
+		if m.historyRef == "" {
+			m.statusMessage = fmt.Sprintf("These commits are already on %s - open another branch's history with %s on the branches tab",
+				m.gitState.Branch, m.keys.keyFor(ctxBranches, "history"))
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if m.confirmAction != "history-cherry-pick" {
+			m.confirmAction = "history-cherry-pick"
+			m.statusMessage = fmt.Sprintf("Press c again to cherry-pick %s onto %s", span, m.gitState.Branch)
+			return m, nil
+		}
+		m.confirmAction = ""
+		m.historyMarked = false
+		return m, m.cherryPickRange(m.commits[oldest], m.commits[newest], count)
+
+	case "R":
+		if m.historyRef != "" {
+			return m, nil
+		}
+		if m.confirmAction != "history-revert" {
+			m.confirmAction = "history-revert"
+			m.statusMessage = fmt.Sprintf("Press R again to revert %s, newest first", span)
+			return m, nil
+		}
+		m.confirmAction = ""
+		m.historyMarked = false
+		return m, m.revertRange(m.commits[oldest], m.commits[newest], count)
+
+	case "r":
+		if m.historyRef != "" {
+			return m, nil
+		}
+		// The rebase starts below the range; newer commits come along as picks
+		var commits []git.RebaseCommit
+		for _, commit := range m.commits[:oldest+1] {
+			commits = append(commits, git.RebaseCommit{Hash: commit.Hash, Message: commit.Message, Action: "pick"})
+		}
+		m.rebaseCommits = commits
+		m.rebaseCursor = newest
+		m.rebaseInput.Blur()
+		m.toolMode = "rebase"
+		m.historyMarked = false
+		m.confirmAction = ""
+		if newest > 0 {
+			m.statusMessage = fmt.Sprintf("Rebasing %s; the %d newer commit(s) above it stay as picks", span, newest)
+		} else {
+			m.statusMessage = "Rebasing " + span
+		}
+		m.statusExpiry = time.Now().Add(5 * time.Second)
+		return m, nil
+	}
+	return m, nil
+}
+
+// openHistory shows the history tool for branch, "" being the current one
+func (m model) openHistory(branch string) (tea.Model, tea.Cmd) {
+	if branch == m.gitState.Branch {
+		branch = ""
+	}
+	m.tab = "tools"
+	m.toolMode = "history"
+	m.historyRef = branch
+	m.historyAuthor = ""
+	m.historyMarked = false
+	m.historyCursor, m.historyOffset = 0, 0
+	m.commits = nil
+	return m, m.loadHistory()
+}
+
+// openContributors lists the current branch's authors
+func (m model) openContributors() (tea.Model, tea.Cmd) {
+	m.toolMode = "contributors"
+	return m, m.loadContributors()
+}
+
+// handleContributorsKey browses the contributors; enter opens the history
+// limited to the selected one's commits
+func (m model) handleContributorsKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.contributorCursor < len(m.contributors)-1 {
+			m.contributorCursor++
+			m.adjustContributorScroll()
+		}
+		return m, nil
+	case "k", "up":
+		if m.contributorCursor > 0 {
+			m.contributorCursor--
+			m.adjustContributorScroll()
+		}
+		return m, nil
+	case "enter":
+		if m.contributorCursor >= len(m.contributors) {
+			return m, nil
+		}
+		m.toolMode = "history"
+		m.historyRef = ""
+		m.historyAuthor = m.contributors[m.contributorCursor].Email
+		m.historyMarked = false
+		m.historyCursor, m.historyOffset = 0, 0
+		m.commits = nil
+		return m, m.loadHistory()
+	}
+	return m, nil
+}
+
+// openRemote jumps from the status bar's ahead/behind counts to the remote
+// tool with pull (when behind) or push (when ahead) preselected, so a single
+// press of that key runs it
+func (m model) openRemote() (tea.Model, tea.Cmd) {
+	m.tab = "tools"
+	m.toolMode = "remote"
+	m.pushOutput = ""
+	m.confirmAction = ""
+	switch {
+	case m.gitState.UpstreamGone:
+		m.statusMessage = fmt.Sprintf("%s no longer exists on the remote - set another upstream to push or pull", m.gitState.Upstream)
+	case m.needsPublish():
+		m.confirmAction = "push"
+		m.statusMessage = fmt.Sprintf("%s has no upstream yet - press p to publish it to %s (push -u)", m.gitState.Branch, m.pushRemote())
+	case m.gitState.Ahead > 0 && m.gitState.Behind > 0:
+		return m.openDivergence()
+	case m.gitState.Behind > 0:
+		m.confirmAction = "pull"
+		m.statusMessage = fmt.Sprintf("%d behind upstream - press l to pull", m.gitState.Behind)
+	case m.gitState.Ahead > 0:
+		m.confirmAction = "push"
+		m.statusMessage = fmt.Sprintf("%d ahead of upstream - press p to push", m.gitState.Ahead)
+	default:
+		m.statusMessage = "In sync with upstream - press f to fetch"
+	}
+	m.statusExpiry = time.Now().Add(5 * time.Second)
+	return m, tea.Batch(m.loadGitStatus(), m.loadBranches(), m.loadUpstreamCommits())
+}
+
+// openFetch fetches every remote from the tools menu and shows the remote
+// tool, where the per-remote results appear
+func (m model) openFetch() (tea.Model, tea.Cmd) {
+	m.toolMode = "remote"
+	m.pushOutput = ""
+	m.confirmAction = ""
+	return m, m.fetchChanges()
+}
+
+func (m model) handleRemoteKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "p":
+		if m.confirmAction != "push" {
+			m.confirmAction = "push"
+			m.statusMessage = m.pushPrompt("p")
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.pushChanges()
+	case "f":
+		return m, m.fetchChanges()
+	case "F":
+		return m, m.fastForwardBehind()
+	case "l":
+		if m.confirmAction != "pull" {
+			m.confirmAction = "pull"
+			m.statusMessage = "Press l again to pull from remote"
+			return m, nil
+		}
+		m.confirmAction = ""
+		return m, m.pullChanges()
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleStashKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key {
+	case "f":
+		// Pick files to apply
+		if m.stashCursor < len(m.stashes) {
+			return m, m.loadStashFiles(m.stashCursor)
+		}
+		return m, nil
+	case "b":
+		// Turn the stash into a branch
+		if m.stashCursor < len(m.stashes) {
+			m.stashBranchInput.SetValue("")
+			m.stashBranchInput.Focus()
+			return m, textinput.Blink
+		}
+		return m, nil
+	case "J":
+		if m.stashDiffOffset < strings.Count(m.stashDiff, "\n")-1 {
+			m.stashDiffOffset++
+		}
+		return m, nil
+	case "K":
+		if m.stashDiffOffset > 0 {
+			m.stashDiffOffset--
+		}
+		return m, nil
+	case "j", "down":
+		if m.stashCursor < len(m.stashes)-1 {
+			m.stashCursor++
+			m.adjustStashScroll()
+			// Load stash diff preview
+			if m.stashCursor < len(m.stashes) {
+				return m, m.loadStashDiff(m.stashCursor)
+			}
+		}
+		return m, nil
+	case "k", "up":
+		if m.stashCursor > 0 {
+			m.stashCursor--
+			m.adjustStashScroll()
+			if m.stashCursor < len(m.stashes) {
+				return m, m.loadStashDiff(m.stashCursor)
+			}
+		}
+		return m, nil
+	case "s":
+		// Create new stash
+		return m, m.stashPush("")
+	case "p", "enter":
+		// Pop stash (removes from stash list)
+		if m.stashCursor < len(m.stashes) {
+			if m.confirmAction == "" {
+				m.confirmAction = "pop-stash"
+				m.statusMessage = "Press p again to pop stash (removes from stash list)"
+				return m, nil
+			} else if m.confirmAction == "pop-stash" {
+				m.confirmAction = ""
+				return m, m.stashPop(m.stashCursor)
+			}
+		}
+		return m, nil
+	case "a":
+		// Apply stash (without removing)
+		if m.stashCursor < len(m.stashes) {
+			return m, m.stashApply(m.stashCursor)
+		}
+		return m, nil
+	case "d":
+		// Drop stash
+		if m.stashCursor < len(m.stashes) {
+			if m.confirmAction == "" {
+				m.confirmAction = "drop-stash"
+				m.statusMessage = "Press 'd' to confirm drop stash"
+				return m, nil
+			} else if m.confirmAction == "drop-stash" {
+				m.confirmAction = ""
+				return m, m.stashDrop(m.stashCursor)
+			}
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleSnapshotsKey browses WIP snapshots and restores one
+func (m model) handleSnapshotsKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.snapshotCursor < len(m.snapshots)-1 {
+			m.snapshotCursor++
+			m.adjustSnapshotScroll()
+			return m, m.loadSnapshotDiff(m.snapshots[m.snapshotCursor])
+		}
+	case "k", "up":
+		if m.snapshotCursor > 0 {
+			m.snapshotCursor--
+			m.adjustSnapshotScroll()
+			return m, m.loadSnapshotDiff(m.snapshots[m.snapshotCursor])
+		}
+	case "J":
+		if m.snapshotDiffOffset < strings.Count(m.snapshotDiff, "\n")-1 {
+			m.snapshotDiffOffset++
+		}
+	case "K":
+		if m.snapshotDiffOffset > 0 {
+			m.snapshotDiffOffset--
+		}
+	case "s":
+		return m, m.takeSnapshot(true)
+	case "enter":
+		if m.snapshotCursor < len(m.snapshots) {
+			if m.confirmAction != "restore-snapshot" {
+				m.confirmAction = "restore-snapshot"
+				m.statusMessage = "Press enter again to apply this snapshot to the worktree"
+				return m, nil
+			}
+			m.confirmAction = ""
+			return m, m.restoreSnapshot(m.snapshots[m.snapshotCursor])
+		}
+	case "d":
+		if m.snapshotCursor < len(m.snapshots) {
+			if m.confirmAction != "delete-snapshot" {
+				m.confirmAction = "delete-snapshot"
+				m.statusMessage = "Press 'd' to confirm delete snapshot"
+				return m, nil
+			}
+			m.confirmAction = ""
+			return m, m.deleteSnapshot(m.snapshots[m.snapshotCursor])
+		}
+	}
+	return m, nil
+}
+
+// openExclude shows .git/info/exclude; a non-empty pattern opens the input
+// to add it, so it can be widened (say to *.log) before saving
+func (m model) openExclude(pattern string) (tea.Model, tea.Cmd) {
+	m.toolMode = "exclude"
+	if pattern == "" {
+		return m, m.loadExcludeLines()
+	}
+	m.excludeEdit = -1
+	m.excludeInput.SetValue(pattern)
+	m.excludeInput.CursorEnd()
+	m.excludeInput.Focus()
+	return m, tea.Batch(m.loadExcludeLines(), textinput.Blink)
+}
+
+func (m model) handleExcludeKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.excludeCursor < len(m.excludeLines)-1 {
+			m.excludeCursor++
+			m.adjustExcludeScroll()
+		}
+	case "k", "up":
+		if m.excludeCursor > 0 {
+			m.excludeCursor--
+			m.adjustExcludeScroll()
+		}
+	case "n":
+		m.excludeEdit = -1
+		m.excludeInput.SetValue("")
+		m.excludeInput.Focus()
+		return m, textinput.Blink
+	case "e":
+		if m.excludeCursor < len(m.excludeLines) {
+			m.excludeEdit = m.excludeCursor
+			m.excludeInput.SetValue(m.excludeLines[m.excludeCursor])
+			m.excludeInput.CursorEnd()
+			m.excludeInput.Focus()
+			return m, textinput.Blink
+		}
+	case "d":
+		if m.excludeCursor < len(m.excludeLines) {
+			if m.confirmAction != "delete-exclude" {
+				m.confirmAction = "delete-exclude"
+				m.statusMessage = fmt.Sprintf("Press 'd' to confirm delete '%s'", m.excludeLines[m.excludeCursor])
+				return m, nil
+			}
+			m.confirmAction = ""
+			return m, m.deleteExcludeLine(m.excludeCursor)
+		}
+	}
+	return m, nil
+}
+
+func (m model) handleExcludeInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.resolve(ctxExcludeInput, msg.String()) {
+	case "enter":
+		// Only leading space is trimmed: "\ " at the end is an escaped space
+		pattern := strings.TrimLeft(m.excludeInput.Value(), " ")
+		if strings.TrimSpace(pattern) == "" {
+			return m, nil
+		}
+		m.excludeInput.SetValue("")
+		m.excludeInput.Blur()
+		return m, m.saveExclude(m.excludeEdit, pattern)
+	case "esc":
+		m.excludeInput.SetValue("")
+		m.excludeInput.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.excludeInput, cmd = m.excludeInput.Update(msg)
+	return m, cmd
+}
+
+// handleStashFilesKey picks files to apply from the selected stash
+func (m model) handleStashFilesKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.stashFileCursor < len(m.stashFiles)-1 {
+			m.stashFileCursor++
+		}
+	case "k", "up":
+		if m.stashFileCursor > 0 {
+			m.stashFileCursor--
+		}
+	case " ":
+		path := m.stashFiles[m.stashFileCursor].Path
+		m.stashFileSelected[path] = !m.stashFileSelected[path]
+	case "a":
+		all := len(m.stashFileSelection()) < len(m.stashFiles)
+		for _, file := range m.stashFiles {
+			m.stashFileSelected[file.Path] = all
+		}
+	case "enter":
+		files := m.stashFileSelection()
+		if len(files) == 0 {
+			m.statusMessage = "Select files with space first"
+			return m, nil
+		}
+		m.stashFiles = nil
+		return m, m.stashCheckoutFiles(m.stashCursor, files)
+	case "esc":
+		m.stashFiles = nil
+	}
+	return m, nil
+}
+
+// handleStashBranchKey reads the name of the branch to make from a stash
+func (m model) handleStashBranchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.resolve(ctxStashBranch, msg.String()) {
+	case "enter":
+		name := strings.TrimSpace(m.stashBranchInput.Value())
+		if name == "" || m.stashCursor >= len(m.stashes) {
+			return m, nil
+		}
+		m.stashBranchInput.SetValue("")
+		m.stashBranchInput.Blur()
+		return m, m.stashBranch(m.stashCursor, name)
+	case "esc":
+		m.stashBranchInput.SetValue("")
+		m.stashBranchInput.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.stashBranchInput, cmd = m.stashBranchInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) handleTagsKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// If creating new tag
+	if m.tagInput.Focused() {
+		switch key {
+		case "enter":
+			tagName := strings.TrimSpace(m.tagInput.Value())
+			if tagName != "" {
+				m.tagInput.SetValue("")
+				m.tagInput.Blur()
+				return m, m.createTag(tagName, "", false)
+			}
+			return m, nil
+		case "esc":
+			m.tagInput.SetValue("")
+			m.tagInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.tagInput, cmd = m.tagInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "j", "down":
+		if m.tagCursor < len(m.tags)-1 {
+			m.tagCursor++
+			m.adjustTagScroll()
+		}
+		return m, nil
+	case "k", "up":
+		if m.tagCursor > 0 {
+			m.tagCursor--
+			m.adjustTagScroll()
+		}
+		return m, nil
+	case "n":
+		// Create new tag
+		m.tagInput.Focus()
+		m.releasePreview = nil
+		return m, tea.Batch(textinput.Blink, m.loadReleasePreview())
+	case "c":
+		// What has happened since the selected tag
+		if m.tagCursor < len(m.tags) {
+			m.tab = "branches"
+			return m, m.compareBranch(m.tags[m.tagCursor].Name)
+		}
+		return m, nil
+	case "d":
+		// Delete tag
+		if m.tagCursor < len(m.tags) {
+			tag := m.tags[m.tagCursor]
+			if m.confirmAction == "" {
+				m.confirmAction = "delete-tag"
+				m.statusMessage = fmt.Sprintf("Press 'd' to confirm delete tag '%s'", tag.Name)
+				return m, nil
+			} else if m.confirmAction == "delete-tag" {
+				m.confirmAction = ""
+				return m, m.deleteTag(tag.Name)
+			}
+		}
+		return m, nil
+	case "v":
+		if m.tagCursor < len(m.tags) {
+			return m, m.verifyTag(m.tags[m.tagCursor])
+		}
+		return m, nil
+	case "p":
+		// Push tag to remote
+		if m.tagCursor < len(m.tags) {
+			return m, m.pushTag(m.tags[m.tagCursor].Name)
+		}
+		return m, nil
+	case "P":
+		// Push all tags
+		return m, m.pushAllTags()
+	}
+	return m, nil
+}
+
+func (m model) handleHooksKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.hookCursor < 2 {
+			m.hookCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.hookCursor > 0 {
+			m.hookCursor--
+		}
+		return m, nil
+	case "1":
+		// Install conventional commits hook
+		return m, m.installConventionalCommitsHook()
+	case "2":
+		// Install no-large-files hook
+		return m, m.installNoLargeFilesHook()
+	case "3":
+		// Install detect-secrets hook
+		return m, m.installDetectSecretsHook()
+	case "r":
+		// Remove selected hook
+		return m, m.removeSelectedHook()
+	case "enter":
+		// Install selected hook
+		switch m.hookCursor {
+		case 0:
+			return m, m.installConventionalCommitsHook()
+		case 1:
+			return m, m.installNoLargeFilesHook()
+		case 2:
+			return m, m.installDetectSecretsHook()
+		}
+	}
+	return m, nil
+}
+
+func (m model) handleLogKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// If searching
+	if m.logSearchInput.Focused() {
+		switch key {
+		case "enter":
+			search := strings.TrimSpace(m.logSearchInput.Value())
+			m.logSearchInput.Blur()
+			m.logSearch = search
+			return m, m.loadLogCommits(search)
+		case "esc":
+			m.logSearchInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.logSearchInput, cmd = m.logSearchInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "j", "down":
+		if m.logCursor < len(m.logCommits)-1 {
+			m.logCursor++
+			m.adjustLogScroll()
+		}
+		return m, nil
+	case "k", "up":
+		if m.logCursor > 0 {
+			m.logCursor--
+			m.adjustLogScroll()
+		}
+		return m, nil
+	case "enter":
+		if m.logCursor < len(m.logCommits) {
+			return m, m.loadLogDetail(m.logCommits[m.logCursor].Hash)
+		}
+		return m, nil
+	case "/":
+		m.logSearchInput.Focus()
+		return m, textinput.Blink
+	case "d":
+		m.absoluteDates = !m.absoluteDates
+		return m, nil
+	case "c":
+		// Cherry-pick selected commit
+		if m.logCursor < len(m.logCommits) {
+			return m, m.cherryPickCommit(m.logCommits[m.logCursor].Hash)
+		}
+		return m, nil
+	case "R":
+		// Revert selected commit (capital R to avoid conflict)
+		if m.logCursor < len(m.logCommits) {
+			if m.confirmAction == "" {
+				m.confirmAction = "revert"
+				m.statusMessage = fmt.Sprintf("Press R again to confirm revert %s", m.logCommits[m.logCursor].Hash)
+				return m, nil
+			} else if m.confirmAction == "revert" {
+				m.confirmAction = ""
+				return m, m.revertCommit(m.logCommits[m.logCursor].Hash)
+			}
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleLogDetailKey walks the file tree of the opened commit: enter folds
+// a directory or shows only a file's diff, esc goes back to the whole diff
+// and then to the log
+func (m model) handleLogDetailKey(key string) (tea.Model, tea.Cmd) {
+	rows := fileTreeRows(m.logDetail.Files, m.logCollapsed)
+	switch key {
+	case "esc":
+		if m.logFile != "" {
+			m.logFile, m.logFileDiff = "", ""
+			m.scrollOffset = 0
+			return m, nil
+		}
+		m.logDetail = nil
+		m.logDiff = ""
+		return m, nil
+	case "j", "down":
+		if m.logFileCursor < len(rows)-1 {
+			m.logFileCursor++
+			m.adjustLogFileScroll()
+		}
+		return m, nil
+	case "k", "up":
+		if m.logFileCursor > 0 {
+			m.logFileCursor--
+			m.adjustLogFileScroll()
+		}
+		return m, nil
+	case "J":
+		m.scrollOffset++
+		return m, nil
+	case "K":
+		if m.scrollOffset > 0 {
+			m.scrollOffset--
+		}
+		return m, nil
+	case "enter":
+		if m.logFileCursor >= len(rows) {
+			return m, nil
+		}
+		row := rows[m.logFileCursor]
+		if row.dir {
+			m.logCollapsed[row.path] = !m.logCollapsed[row.path]
+			return m, nil
+		}
+		m.scrollOffset = 0
+		if m.logFile == row.path {
+			m.logFile, m.logFileDiff = "", ""
+			return m, nil
+		}
+		m.logFile, m.logFileDiff = row.path, ""
+		return m, m.loadLogFileDiff(m.logDetail.Hash, row.path)
+	}
+	return m, nil
+}
+
+func (m model) handleCleanKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.cleanCursor < len(m.cleanFiles)-1 {
+			m.cleanCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.cleanCursor > 0 {
+			m.cleanCursor--
+		}
+		return m, nil
+	case "d", "enter":
+		// Execute clean
+		if len(m.cleanFiles) > 0 {
+			if m.confirmAction != "clean" && m.typedConfirm() {
+				return m.askTyped("discard", fmt.Sprintf("deleting %d untracked file(s)", len(m.cleanFiles)), "clean", key)
+			}
+			if m.confirmAction == "" {
+				m.confirmAction = "clean"
+				m.statusMessage = "Press d again to confirm deleting untracked files"
+				return m, nil
+			} else if m.confirmAction == "clean" {
+				m.confirmAction = ""
+				return m, m.executeClean()
+			}
+		}
+		return m, nil
+	case "r":
+		// Refresh the list
+		return m, m.loadCleanFiles()
+	}
+	return m, nil
+}
+
+func (m model) handleCloneKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.cloneInput.Focused() {
+		switch key {
+		case "enter":
+			url := strings.TrimSpace(m.cloneInput.Value())
+			if url != "" {
+				m.cloneInput.SetValue("")
+				m.cloneInput.Blur()
+				m.toolMode = "menu"
+				return m, m.cloneRepo(url)
+			}
+			return m, nil
+		case "esc":
+			m.cloneInput.SetValue("")
+			m.cloneInput.Blur()
+			m.toolMode = "menu"
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.cloneInput, cmd = m.cloneInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) handleInitKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.initInput.Focused() {
+		switch key {
+		case "enter":
+			path := strings.TrimSpace(m.initInput.Value())
+			if path != "" {
+				m.initInput.SetValue("")
+				m.initInput.Blur()
+				m.toolMode = "menu"
+				return m, m.initRepo(path)
+			}
+			return m, nil
+		case "esc":
+			m.initInput.SetValue("")
+			m.initInput.Blur()
+			m.toolMode = "menu"
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.initInput, cmd = m.initInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) handleIdentityKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.identityInput.Focused() {
+		switch key {
+		case "enter":
+			id, err := git.ParseIdentity(strings.TrimSpace(m.identityInput.Value()))
+			if err != nil {
+				m.statusMessage = err.Error()
+				return m, nil
+			}
+			m.identityInput.SetValue("")
+			m.identityInput.Blur()
+			return m, m.setIdentity(id)
+		case "esc":
+			m.identityInput.SetValue("")
+			m.identityInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.identityInput, cmd = m.identityInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "j", "down":
+		if m.identityCursor < len(m.identities)-1 {
+			m.identityCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.identityCursor > 0 {
+			m.identityCursor--
+		}
+		return m, nil
+	case "enter":
+		if m.identityCursor < len(m.identities) {
+			return m, m.setIdentity(m.identities[m.identityCursor])
+		}
+		return m, nil
+	case "n":
+		m.identityInput.Focus()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+func (m model) handleAliasKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.aliasCursor < len(m.aliases)-1 {
+			m.aliasCursor++
+			m.aliasOutput = ""
+		}
+		return m, nil
+	case "k", "up":
+		if m.aliasCursor > 0 {
+			m.aliasCursor--
+			m.aliasOutput = ""
+		}
+		return m, nil
+	case "enter":
+		// Show the expansion first, run on second press
+		if m.aliasCursor < len(m.aliases) {
+			alias := m.aliases[m.aliasCursor]
+			if m.confirmAction == "" {
+				m.confirmAction = "run-alias"
+				m.statusMessage = fmt.Sprintf("Press enter again to run: git %s → %s", alias.Name, alias.Expansion)
+				return m, nil
+			} else if m.confirmAction == "run-alias" {
+				m.confirmAction = ""
+				m.statusMessage = fmt.Sprintf("Running git %s...", alias.Name)
+				return m, m.runAlias(alias.Name)
+			}
+		}
+		return m, nil
+	case "r":
+		return m, m.loadAliases()
+	}
+	m.confirmAction = ""
+	return m, nil
+}
+
+func (m model) handleConfigKey(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.configInput.Focused() {
+		switch key {
+		case "enter":
+			value := strings.TrimSpace(m.configInput.Value())
+			editKey := m.configEditKey
+			m.configInput.SetValue("")
+			m.configInput.Blur()
+			m.configEditKey = ""
+			return m, m.setConfigValue(m.configScope, editKey, value)
+		case "esc":
+			m.configInput.SetValue("")
+			m.configInput.Blur()
+			m.configEditKey = ""
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.configInput, cmd = m.configInput.Update(msg)
+		return m, cmd
+	}
+
+	rows := m.configRows()
+	switch key {
+	case "j", "down":
+		if m.configCursor < len(rows)-1 {
+			m.configCursor++
+			m.adjustConfigScroll()
+		}
+		return m, nil
+	case "k", "up":
+		if m.configCursor > 0 {
+			m.configCursor--
+			m.adjustConfigScroll()
+		}
+		return m, nil
+	case "enter":
+		if m.configCursor < len(rows) && rows[m.configCursor].editable {
+			row := rows[m.configCursor]
+			m.configEditKey = row.key
+			m.configInput.SetValue(row.value)
+			m.configInput.CursorEnd()
+			m.configInput.Focus()
+			return m, textinput.Blink
+		}
+		m.statusMessage = "Only the common settings at the top can be edited here"
+		return m, nil
+	case " ", "space":
+		// Cycle through allowed values
+		if m.configCursor < len(rows) && len(rows[m.configCursor].options) > 0 {
+			row := rows[m.configCursor]
+			next := row.options[0]
+			for i, opt := range row.options {
+				if opt == row.value {
+					next = row.options[(i+1)%len(row.options)]
+				}
+			}
+			return m, m.setConfigValue(m.configScope, row.key, next)
+		}
+		return m, nil
+	case "g":
+		if m.configScope == "local" {
+			m.configScope = "global"
+		} else {
+			m.configScope = "local"
+		}
+		return m, nil
+	case "u":
+		if m.configCursor < len(rows) && rows[m.configCursor].editable {
+			return m, m.unsetConfigValue(m.configScope, rows[m.configCursor].key)
+		}
+		return m, nil
+	case "r":
+		return m, m.loadConfigEntries()
+	}
+	return m, nil
+}
+
+// Scroll adjustment helpers
+
+// handleResolveKey drives the conflict editor: the scratch buffer holds the
+// result for the current block and is saved when switching blocks
+func (m model) handleResolveKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	doc := m.resolveDoc
+	block := doc.Blocks[m.resolveBlock]
+
+	key := m.keys.resolve(ctxResolve, msg.String())
+	switch key {
+	case "esc":
+		m.resolveInput.Blur()
+		m.resolveDoc = nil
+		m.resolutions = nil
+		m.viewMode = "conflicts"
+		m.statusMessage = "Conflict edit cancelled"
+		return m, nil
+	case "tab", "shift+tab":
+		m.resolutions[m.resolveBlock] = m.resolveInput.Value()
+		if key == "tab" && m.resolveBlock < len(doc.Blocks)-1 {
+			m.resolveBlock++
+		} else if key == "shift+tab" && m.resolveBlock > 0 {
+			m.resolveBlock--
+		}
+		m.resolveInput.SetValue(m.resolutions[m.resolveBlock])
+		return m, nil
+	case "ctrl+o":
+		m.resolveInput.SetValue(strings.Join(block.Ours, "\n"))
+		return m, nil
+	case "ctrl+r":
+		m.resolveInput.SetValue(strings.Join(block.Theirs, "\n"))
+		return m, nil
+	case "ctrl+y":
+		if block.HasBase {
+			m.resolveInput.SetValue(strings.Join(block.Base, "\n"))
+		}
+		return m, nil
+	case "ctrl+g":
+		m.resolveInput.SetValue(strings.Join(append(append([]string{}, block.Ours...), block.Theirs...), "\n"))
+		return m, nil
+	case "ctrl+s":
+		m.resolutions[m.resolveBlock] = m.resolveInput.Value()
+		content := doc.Resolve(m.resolutions)
+		m.resolveInput.Blur()
+		m.resolveDoc = nil
+		m.resolutions = nil
+		m.viewMode = "conflicts"
+		return m, m.writeResolution(doc.Path, content)
+	}
+
+	var cmd tea.Cmd
+	m.resolveInput, cmd = m.resolveInput.Update(msg)
+	return m, cmd
+}
+
+// sizeResolveInput fits the scratch buffer to the lower third of the panel
+func (m *model) sizeResolveInput() {
+	m.resolveInput.SetWidth(max(10, m.width-8))
+	m.resolveInput.SetHeight(max(3, m.contentHeight()/3))
+}
+
+// sizeCommitInput fits the custom message to the panel, less the prompt
+// and the cursor; unsized, it shows one character
+func (m *model) sizeCommitInput() {
+	m.commitInput.Width = max(10, m.width-9)
+}
+
+// setDiffOption applies a diff option key (W, B, +, -) and reports whether
+// the key was one
+func (m *model) setDiffOption(key string) bool {
+	switch key {
+	case "W":
+		m.diffOptions.IgnoreWhitespace = !m.diffOptions.IgnoreWhitespace
+	case "B":
+		m.diffOptions.IgnoreBlankLines = !m.diffOptions.IgnoreBlankLines
+	case "+", "=":
+		if m.diffOptions.Context < 0 {
+			m.diffOptions.Context = 3
+		}
+		m.diffOptions.Context++
+	case "-":
+		if m.diffOptions.Context < 0 {
+			m.diffOptions.Context = 3
+		}
+		if m.diffOptions.Context > 0 {
+			m.diffOptions.Context--
+		}
+	default:
+		return false
+	}
+	m.scrollOffset = 0
+	return true
+}
+
+// diffOptionsDesc summarises the active diff options
+func diffOptionsDesc(opts git.DiffOptions) string {
+	var parts []string
+	if opts.IgnoreWhitespace {
+		parts = append(parts, "ignore whitespace")
+	}
+	if opts.IgnoreBlankLines {
+		parts = append(parts, "ignore blank lines")
+	}
+	if opts.Context >= 0 {
+		parts = append(parts, fmt.Sprintf("%d context lines", opts.Context))
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m *model) adjustFileScroll() {
+	visibleItems := m.contentHeight() - 7
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.fileCursor < m.fileOffset {
+		m.fileOffset = m.fileCursor
+	}
+	if m.fileCursor >= m.fileOffset+visibleItems {
+		m.fileOffset = m.fileCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustBranchScroll() {
+	visibleItems := m.contentHeight() - 4
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.branchCursor < m.branchOffset {
+		m.branchOffset = m.branchCursor
+	}
+	if m.branchCursor >= m.branchOffset+visibleItems {
+		m.branchOffset = m.branchCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustUndoScroll() {
+	visibleItems := m.contentHeight() - 6
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.undoCursor < m.undoOffset {
+		m.undoOffset = m.undoCursor
+	}
+	if m.undoCursor >= m.undoOffset+visibleItems {
+		m.undoOffset = m.undoCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustHistoryScroll() {
+	visibleItems := m.contentHeight() - 4
+	// Less the branch and marked range lines above the list
+	if m.historyRef != "" {
+		visibleItems--
+	}
+	if m.historyMarked {
+		visibleItems--
+	}
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.historyCursor < m.historyOffset {
+		m.historyOffset = m.historyCursor
+	}
+	if m.historyCursor >= m.historyOffset+visibleItems {
+		m.historyOffset = m.historyCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustStashScroll() {
+	visibleItems := m.stashListRows(m.contentHeight())
+
+	if m.stashCursor < m.stashOffset {
+		m.stashOffset = m.stashCursor
+	}
+	if m.stashCursor >= m.stashOffset+visibleItems {
+		m.stashOffset = m.stashCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustSnapshotScroll() {
+	visibleItems := m.stashListRows(m.contentHeight())
+
+	if m.snapshotCursor < m.snapshotOffset {
+		m.snapshotOffset = m.snapshotCursor
+	}
+	if m.snapshotCursor >= m.snapshotOffset+visibleItems {
+		m.snapshotOffset = m.snapshotCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustContributorScroll() {
+	visibleItems := m.excludeListRows(m.contentHeight())
+
+	if m.contributorCursor < m.contributorOffset {
+		m.contributorOffset = m.contributorCursor
+	}
+	if m.contributorCursor >= m.contributorOffset+visibleItems {
+		m.contributorOffset = m.contributorCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustExcludeScroll() {
+	visibleItems := m.excludeListRows(m.contentHeight())
+
+	if m.excludeCursor < m.excludeOffset {
+		m.excludeOffset = m.excludeCursor
+	}
+	if m.excludeCursor >= m.excludeOffset+visibleItems {
+		m.excludeOffset = m.excludeCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustTagScroll() {
+	visibleItems := m.contentHeight() - 4
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.tagCursor < m.tagOffset {
+		m.tagOffset = m.tagCursor
+	}
+	if m.tagCursor >= m.tagOffset+visibleItems {
+		m.tagOffset = m.tagCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustLogFileScroll() {
+	visibleItems := m.logTreeRows(m.contentHeight())
+
+	if m.logFileCursor < m.logFileOffset {
+		m.logFileOffset = m.logFileCursor
+	}
+	if m.logFileCursor >= m.logFileOffset+visibleItems {
+		m.logFileOffset = m.logFileCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustLogScroll() {
+	visibleItems := m.contentHeight() - 4
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.logCursor < m.logOffset {
+		m.logOffset = m.logCursor
+	}
+	if m.logCursor >= m.logOffset+visibleItems {
+		m.logOffset = m.logCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustLostScroll() {
+	visibleItems := m.contentHeight() - 6
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.lostCursor < m.lostOffset {
+		m.lostOffset = m.lostCursor
+	}
+	if m.lostCursor >= m.lostOffset+visibleItems {
+		m.lostOffset = m.lostCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustConfigScroll() {
+	visibleItems := m.contentHeight() - 6
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.configCursor < m.configOffset {
+		m.configOffset = m.configCursor
+	}
+	if m.configCursor >= m.configOffset+visibleItems {
+		m.configOffset = m.configCursor - visibleItems + 1
+	}
+}
+
+func (m *model) adjustBlameScroll() {
+	visibleItems := m.contentHeight() - 4
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.blameCursor < m.blameOffset {
+		m.blameOffset = m.blameCursor
+	}
+	if m.blameCursor >= m.blameOffset+visibleItems {
+		m.blameOffset = m.blameCursor - visibleItems + 1
+	}
+}
+
+// handleReviewKey moves through a compared file's diff and manages the
+// comments on its lines
+func (m model) handleReviewKey(key string) (tea.Model, tea.Cmd) {
+	review := *m.review
+	files := m.branchComparison.DifferingFiles
+	pos := review.positions[review.cursor]
+
+	switch key {
+	case "esc":
+		m.review = nil
+		return m, nil
+	case "j", "down":
+		if review.cursor < len(review.lines)-1 {
+			review.cursor++
+		}
+	case "k", "up":
+		if review.cursor > 0 {
+			review.cursor--
+		}
+	case "c", "enter":
+		if pos.side == "" {
+			m.statusMessage = "Move to a changed or context line to comment"
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		m.reviewInput.SetValue("")
+		if i := m.reviewCommentAt(review.file.Path, pos); i >= 0 {
+			m.reviewInput.SetValue(m.reviewComments[i].Body)
+		}
+		m.reviewInput.CursorEnd()
+		m.reviewInput.Focus()
+		return m, textinput.Blink
+	case "d":
+		if i := m.reviewCommentAt(review.file.Path, pos); i >= 0 {
+			m.reviewComments = slices.Delete(slices.Clone(m.reviewComments), i, i+1)
+			m.statusMessage = "Comment deleted"
+		}
+	case "n", "p":
+		step := 1
+		if key == "p" {
+			step = -1
+		}
+		for i := review.cursor + step; i >= 0 && i < len(review.lines); i += step {
+			if m.reviewCommentAt(review.file.Path, review.positions[i]) >= 0 {
+				review.cursor = i
+				break
+			}
+		}
+	case "]", "[":
+		next := m.compareCursor + 1
+		if key == "[" {
+			next = m.compareCursor - 1
+		}
+		if next >= 0 && next < len(files) {
+			m.compareCursor = next
+			return m, m.loadReviewDiff(files[next])
+		}
+	}
+	m.review = &review
+	return m, nil
+}
+
+// handleReviewCommentKey edits the comment on the review cursor's line
+func (m model) handleReviewCommentKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.resolve(ctxReviewComment, msg.String()) {
+	case "enter":
+		body := strings.TrimSpace(m.reviewInput.Value())
+		m.reviewInput.SetValue("")
+		m.reviewInput.Blur()
+		if m.review == nil {
+			return m, nil
+		}
+		pos := m.review.positions[m.review.cursor]
+		comments := slices.Clone(m.reviewComments)
+		i := m.reviewCommentAt(m.review.file.Path, pos)
+		switch {
+		case body == "" && i >= 0:
+			comments = slices.Delete(comments, i, i+1)
+		case body == "":
+		case i >= 0:
+			comments[i].Body = body
+		default:
+			comments = append(comments, reviewComment{
+				File: m.review.file.Path,
+				Line: pos.line,
+				Side: pos.side,
+				Code: m.review.lines[m.review.cursor],
+				Body: body,
+			})
+		}
+		m.reviewComments = comments
+		return m, nil
+	case "esc":
+		m.reviewInput.SetValue("")
+		m.reviewInput.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.reviewInput, cmd = m.reviewInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) handlePRsKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "j", "down":
+		if m.prCursor < len(m.prs)-1 {
+			m.prCursor++
+		}
+	case "k", "up":
+		if m.prCursor > 0 {
+			m.prCursor--
+		}
+	case "enter":
+		if m.prCursor < len(m.prs) {
+			return m, m.checkoutPR(m.prs[m.prCursor].Number)
+		}
+	case "o":
+		if m.prCursor < len(m.prs) {
+			return m, m.openPR(m.prs[m.prCursor])
+		}
+	case "r":
+		return m, m.loadPRs()
+	}
+	return m, nil
+}
+
+// handlePRNumberKey reads a pull request number (or URL) to check out
+func (m model) handlePRNumberKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keys.resolve(ctxPRNumber, msg.String()) {
+	case "enter":
+		number, ok := prNumber(m.prInput.Value())
+		if !ok {
+			m.statusMessage = "Enter a pull request number, e.g. 42 or #42"
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return m, nil
+		}
+		m.prInput.SetValue("")
+		m.prInput.Blur()
+		return m, m.checkoutPR(number)
+	case "esc":
+		m.prInput.SetValue("")
+		m.prInput.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.prInput, cmd = m.prInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,4209 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/LFroesch/gitty/internal/forge"
+	"github.com/LFroesch/gitty/internal/git"
+	"github.com/LFroesch/gitty/internal/jobs"
+	"github.com/LFroesch/gitty/suggest"
+)
+
+// View is the main render function
+func (m model) View() string {
+	defer m.reportPanic()
+	if m.width == 0 || m.height == 0 {
+		return "Loading..."
+	}
+	if m.width < minWidth || m.height < minHeight {
+		return m.renderTooSmall()
+	}
+
+	// 3-section layout
+	header := m.renderTopBar()
+	content := m.renderMainPanel()
+	footer := m.renderStatusBar()
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, content, footer)
+}
+
+// renderTooSmall replaces everything when the terminal is below the
+// minimum, rather than drawing panels over each other
+func (m model) renderTooSmall() string {
+	message := lipgloss.JoinVertical(lipgloss.Center,
+		warningStyle.Render("Terminal too small"),
+		helpStyle.Render(fmt.Sprintf("%dx%d, needs %dx%d", m.width, m.height, minWidth, minHeight)),
+		helpStyle.Render(m.keys.keyFor(ctxGlobal, "quit")+" quits"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().MaxWidth(m.width).Render(message))
+}
+
+// compact is the layout for small terminals: no diff preview beside the
+// files, only the active tab named and one line of footer
+func (m model) compact() bool {
+	return m.width < compactWidth || m.height < compactHeight
+}
+
+// contentHeight is the height inside the main panel
+func (m model) contentHeight() int {
+	if m.compact() {
+		return m.height - compactOverhead
+	}
+	return m.height - uiOverhead
+}
+
+// Top header bar (full-width, bg 235)
+func (m model) renderTopBar() string {
+	title := titleStyle.Render("Gitty")
+	repoName := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("208")).
+		Background(lipgloss.Color("236")).
+		Render(fmt.Sprintf(" %s%s", filepath.Join(filepath.Base(m.repoPath), m.launchSubdir()), m.repoKind()))
+
+	// Git status info
+	statusInfo := m.renderGitStatusInfo()
+
+	// Tabs, without their badges when those don't fit
+	tabs := m.renderTabs(true)
+	if lipgloss.Width(tabs) > m.width-2 {
+		tabs = m.renderTabs(false)
+	}
+
+	spacer := lipgloss.NewStyle().Background(lipgloss.Color("236")).Render("  ")
+	leftPart := lipgloss.JoinHorizontal(lipgloss.Top, title, repoName, spacer, statusInfo)
+
+	// Fill both rows to full width with background
+	rowStyle := lipgloss.NewStyle().Background(lipgloss.Color("236")).Width(m.width - 2)
+	if m.compact() {
+		// Cut rather than wrap: every row counts
+		rowStyle = rowStyle.MaxHeight(1)
+	}
+	leftPartStyled := rowStyle.Render(leftPart)
+	tabsStyled := rowStyle.Render(tabs)
+
+	return headerStyle.Width(m.width).Render(
+		lipgloss.JoinVertical(lipgloss.Left, leftPartStyled, tabsStyled),
+	)
+}
+
+func (m model) renderGitStatusInfo() string {
+	branchIcon := "🌿 "
+	parts := []string{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Background(lipgloss.Color("236")).Bold(true).Render(branchIcon + m.gitState.Branch),
+	}
+
+	if m.gitState.StagedFiles > 0 {
+		parts = append(parts, iconStagedStyle.Render(fmt.Sprintf("✓ %d", m.gitState.StagedFiles)))
+	}
+	if m.gitState.UnstagedFiles > 0 {
+		parts = append(parts, iconUnstagedStyle.Render(fmt.Sprintf("● %d", m.gitState.UnstagedFiles)))
+	}
+	if m.gitState.Ahead > 0 {
+		parts = append(parts, branchAheadStyle.Render(fmt.Sprintf("↑ %d", m.gitState.Ahead)))
+	}
+	if m.gitState.Behind > 0 {
+		parts = append(parts, branchBehindStyle.Render(fmt.Sprintf("↓ %d", m.gitState.Behind)))
+	}
+	// Without an upstream there is nothing to be in sync with
+	switch {
+	case m.gitState.UpstreamGone:
+		parts = append(parts, warningStyle.Background(lipgloss.Color("236")).Render(m.gitState.Upstream+" gone"))
+	case m.gitState.Upstream == "" && m.gitState.Branch != "HEAD" && !m.bare:
+		parts = append(parts, helpStyle.Render("no upstream"))
+	}
+	if m.gitState.Stashes > 0 {
+		parts = append(parts, helpStyle.Render(fmt.Sprintf("≡ %d", m.gitState.Stashes)))
+	}
+	if op := m.gitState.Operation; op != "" {
+		parts = append(parts, warningStyle.Background(lipgloss.Color("236")).Render(strings.ToUpper(op)+" IN PROGRESS"))
+	}
+	if m.readOnly {
+		parts = append(parts, warningStyle.Background(lipgloss.Color("236")).Render("READ-ONLY"))
+	}
+	if !m.gitState.LastFetch.IsZero() {
+		parts = append(parts, helpStyle.Render("fetched "+formatAgo(since(m.gitState.LastFetch))))
+	}
+	if running, queued := m.jobs.Counts(); running+queued > 0 {
+		label := fmt.Sprintf("⏳ %d running", running)
+		if queued > 0 {
+			label += fmt.Sprintf(", %d queued", queued)
+		}
+		parts = append(parts, helpStyle.Render(label))
+	}
+
+	styledSpace := lipgloss.NewStyle().Background(lipgloss.Color("236")).Render("  ")
+	return strings.Join(parts, styledSpace)
+}
+
+func (m model) renderTabs(badges bool) string {
+	// Count badges show pending work without switching tabs
+	status := m.gitState
+	badge := func(color lipgloss.Color, format string, n int) string {
+		if n == 0 || !badges {
+			return ""
+		}
+		return tabBadgeStyle.Foreground(color).Render(fmt.Sprintf(format, n))
+	}
+	branches := m.renderTab("3", "Branches", m.tab == "branches",
+		badge(colors.good, "↑%d", status.Ahead), badge(colors.warn, "↓%d", status.Behind))
+	tools := "Tools"
+	if status.Operation != "" && badges {
+		tools += " " + tabBadgeStyle.Foreground(colors.warn).Render("● "+status.Operation)
+	}
+
+	if m.bare {
+		// Nothing to stage or commit without a working tree
+		return lipgloss.JoinHorizontal(lipgloss.Top, branches, m.renderTab("4", tools, m.tab == "tools"))
+	}
+
+	tab1 := m.renderTab("1", "Workspace", m.tab == "workspace",
+		badge(colors.bad, "⚠%d", status.Conflicts), badge(colors.warn, "●%d", status.UnstagedFiles), badge(colors.good, "✓%d", status.StagedFiles))
+	tab2 := m.renderTab("2", "Commit", m.tab == "commit", badge(colors.good, "✓%d", status.StagedFiles))
+	tab4 := m.renderTab("4", tools, m.tab == "tools")
+	if m.forge != "" {
+		tab5 := m.renderTab("5", m.prLabel(), m.tab == "prs")
+		return lipgloss.JoinHorizontal(lipgloss.Top, tab1, tab2, branches, tab4, tab5)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, tab1, tab2, branches, tab4)
+}
+
+// renderTab renders a tab header, followed by any non-empty badges. The
+// compact layout names only the active tab.
+func (m model) renderTab(key, label string, active bool, badges ...string) string {
+	style := tabStyle
+	if active {
+		style = activeTabStyle
+	}
+	if m.compact() && !active {
+		label = fmt.Sprintf("[%s]", key)
+	} else {
+		label = fmt.Sprintf("[%s] %s", key, label)
+	}
+	for _, badge := range badges {
+		if badge != "" {
+			label += " " + badge
+		}
+	}
+	return style.Render(label)
+}
+
+// Main panel (bordered)
+func (m model) renderMainPanel() string {
+	panelWidth := m.width - 2
+	contentHeight := m.contentHeight()
+
+	if contentHeight < 3 {
+		contentHeight = 3
+	}
+
+	var content string
+
+	switch m.tab {
+	case "workspace":
+		_, content = m.renderWorkspaceContent(panelWidth-4, contentHeight)
+	case "commit":
+		_, content = m.renderCommitContent(panelWidth-4, contentHeight)
+	case "branches":
+		_, content = m.renderBranchesContent(panelWidth-4, contentHeight)
+	case "tools":
+		_, content = m.renderToolsContent(panelWidth-4, contentHeight)
+	case "prs":
+		content = m.renderPRsContent(panelWidth-4, contentHeight)
+	}
+
+	// The index.lock prompt sits over whatever the tab shows
+	if m.lockPrompt != nil {
+		content = lipgloss.Place(panelWidth-4, contentHeight, lipgloss.Center, lipgloss.Center,
+			m.renderLockPrompt(panelWidth-4))
+	}
+
+	// The tour takes the bottom of the panel, leaving the tab it describes
+	// visible above
+	if m.touring {
+		tour := m.renderTour(panelWidth - 4)
+		keep := max(0, contentHeight-lipgloss.Height(tour))
+		lines := strings.Split(content, "\n")
+		if len(lines) > keep {
+			lines = lines[:keep]
+		}
+		for len(lines) < keep {
+			lines = append(lines, "")
+		}
+		content = strings.Join(append(lines, tour), "\n")
+	}
+
+	// The debug overlay takes the bottom half of the panel, like the tour
+	if m.debugOverlay {
+		trail := m.renderDebugTrail(panelWidth-4, contentHeight/2)
+		keep := max(0, contentHeight-lipgloss.Height(trail))
+		lines := strings.Split(content, "\n")
+		if len(lines) > keep {
+			lines = lines[:keep]
+		}
+		for len(lines) < keep {
+			lines = append(lines, "")
+		}
+		content = strings.Join(append(lines, trail), "\n")
+	}
+
+	// Presenter mode shows the last keys pressed in the bottom-right corner
+	if m.presenter && len(m.presenterKeys) > 0 {
+		var keys []string
+		for _, key := range m.presenterKeys {
+			keys = append(keys, keyBindStyle.Render(" "+key+" "))
+		}
+		badge := strings.Join(keys, " ")
+		lines := strings.Split(content, "\n")
+		for len(lines) < contentHeight {
+			lines = append(lines, "")
+		}
+		lines[contentHeight-1] = strings.Repeat(" ", max(0, panelWidth-4-lipgloss.Width(badge))) + badge
+		content = strings.Join(lines, "\n")
+	}
+
+	// Content taller than the panel, once long lines wrap, would push the
+	// footer off screen
+	panelContent := listStyle.Width(panelWidth).Render(content)
+	if lines := strings.Split(panelContent, "\n"); len(lines) > contentHeight {
+		panelContent = strings.Join(lines[:contentHeight], "\n")
+	}
+
+	return borderStyle.Width(panelWidth).Height(contentHeight).Render(panelContent)
+}
+
+// tourStep is one page of the first-launch tour
+type tourStep struct {
+	tab   string // shown behind the page
+	title string
+	lines []string
+}
+
+// tourSteps walks through the tabs, then a commit from staging to push.
+// Keys come from the keymap so remapped keys show as remapped.
+func (m model) tourSteps() []tourStep {
+	key := func(ctx, action string) string {
+		if key := m.keys.keyFor(ctx, action); key != " " {
+			return key
+		}
+		return "space"
+	}
+	k := func(ctx, action string) string { return keyBindStyle.Render(key(ctx, action)) }
+	step := func(n int, what, ctx, action, before, after string) string {
+		return fmt.Sprintf("%s %-8s %s %-16s %s %s", helpStyle.Render(fmt.Sprintf("%d.", n)), what,
+			keyBindStyle.Render(fmt.Sprintf("%-6s", key(ctx, action))), before, helpStyle.Render("→"), successStyle.Render(after))
+	}
+	return []tourStep{
+		{"workspace", "Welcome to gitty", []string{
+			"A short tour of the four tabs and of a commit from start to finish.",
+			"Nothing in the tour touches your repository.",
+		}},
+		{"workspace", "1 · Workspace", []string{
+			"Every changed file, with a diff preview beside it.",
+			k(ctxFiles, "stage") + " stages the selected file, " + k(ctxFiles, "stage-all") + " stages everything, " +
+				k(ctxFiles, "diff") + " opens the full diff and " + k(ctxFiles, "discard") + " discards changes.",
+		}},
+		{"commit", "2 · Commit", []string{
+			"Commit messages suggested from what you staged, numbered by confidence.",
+			k(ctxCommit, "select-up") + "/" + k(ctxCommit, "select-down") + " picks one and " + k(ctxCommit, "commit") +
+				" commits it, " + k(ctxCommit, "custom") + " switches to your own message, and alt+1…9 commits a suggestion in one key.",
+		}},
+		{"branches", "3 · Branches", []string{
+			"Local and remote branches with ahead/behind counts.",
+			k(ctxBranches, "checkout") + " switches, " + k(ctxBranches, "new") + " creates, " + k(ctxBranches, "compare") +
+				" compares and " + k(ctxBranches, "delete") + " deletes, asking before anything destructive.",
+		}},
+		{"tools", "4 · Tools", []string{
+			"Log, stash, tags, undo, rebase, hooks, config and more, one key each.",
+			k(ctxGlobal, "sync") + " pushes or pulls from any tab and " + k(ctxGlobal, "shell") + " runs a shell command.",
+		}},
+		{"workspace", "A commit, start to finish", []string{
+			step(1, "Stage", ctxFiles, "stage", "M src/auth.go", "✓ M src/auth.go"),
+			step(2, "Suggest", ctxGlobal, "commit", "commit tab", "[1] fix(auth): handle expired tokens"),
+			step(3, "Commit", ctxCommit, "commit", "[1] selected", "✓ a1b2c3d fix(auth): handle expired tokens"),
+			step(4, "Push", ctxSummary, "push", "↑1 ahead", "pushed to origin/main"),
+		}},
+		{"workspace", "That's it", []string{
+			"Every key can be remapped under [keys] in ~/.config/gitty/config.toml.",
+			"Press " + k(ctxGlobal, "tour") + " to take this tour again.",
+		}},
+	}
+}
+
+// renderDebugTrail lists the last messages handled, newest at the bottom,
+// with the key context each moved from and to
+func (m model) renderDebugTrail(width, height int) string {
+	lines := []string{sectionHeaderStyle.Render("Debug") + helpStyle.Render(fmt.Sprintf("  last %d messages - %s hides", debugTrailSize, m.keys.keyFor(ctxGlobal, "debug")))}
+	room := max(1, height-1)
+	trail := m.debugTrail
+	if len(trail) > room {
+		trail = trail[len(trail)-room:]
+	}
+	for _, entry := range trail {
+		context := helpStyle.Render(entry.from)
+		if entry.to != entry.from {
+			context = warningStyle.Render(entry.from + " → " + entry.to)
+		}
+		line := fmt.Sprintf("%s %8s %s %s", helpStyle.Render(entry.at.Format("15:04:05.000")),
+			entry.took.Round(time.Microsecond), entry.msg, context)
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderTour(width int) string {
+	steps := m.tourSteps()
+	step := steps[min(m.tourStep, len(steps)-1)]
+	// The footer shows the tour's keys
+	lines := []string{sectionHeaderStyle.Render(step.title) + helpStyle.Render(fmt.Sprintf("  (%d/%d)", m.tourStep+1, len(steps))), ""}
+	lines = append(lines, step.lines...)
+
+	return paneBorderStyle.BorderForeground(lipgloss.Color("212")).Width(width-2).Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+func (m model) renderLockPrompt(width int) string {
+	prompt := m.lockPrompt
+	lock := prompt.lock
+
+	var lines []string
+	lines = append(lines, warningStyle.Render("⚠ The repository is locked"))
+	lines = append(lines, "")
+	path := lock.Path
+	if rel, err := filepath.Rel(m.repoPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	lines = append(lines, fmt.Sprintf("%s exists since %s (%s)",
+		path, lock.Since.Format("15:04:05"), formatAgo(since(lock.Since))))
+
+	switch {
+	case lock.PID != 0:
+		lines = append(lines, fmt.Sprintf("Held by %s (pid %d) - probably still working.", lock.Owner, lock.PID))
+	case lock.Stale():
+		lines = append(lines, "No running program holds it: it looks stale, left by a crashed editor or git.")
+	default:
+		lines = append(lines, "Its owner cannot be checked on this system; it may still be in use.")
+	}
+	if prompt.op != "" {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("Blocked: %s (retried after waiting or removing)", prompt.op)))
+	}
+
+	lines = append(lines, "")
+	if prompt.waiting {
+		lines = append(lines, m.spinner.View()+fmt.Sprintf(" Waiting up to %s for the lock to go away...", lockPatience))
+	} else {
+		k := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxIndexLock, action)) }
+		lines = append(lines, k("wait")+normalStyle.Render(fmt.Sprintf(" wait up to %s", lockPatience)))
+		lines = append(lines, k("retry")+normalStyle.Render(" retry now"))
+		if lock.Stale() {
+			lines = append(lines, k("remove")+normalStyle.Render(" remove the stale lock (asks to confirm)"))
+		}
+		lines = append(lines, k("back")+normalStyle.Render(" dismiss"))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.warn).
+		Padding(1, 2).
+		MaxWidth(width)
+	return box.Render(strings.Join(lines, "\n"))
+}
+
+// Bottom status bar (full-width, bg 235)
+func (m model) renderStatusBar() string {
+	// Keybind hints come from the keymap: purple keys, white descriptions
+	ctx := m.keyContext()
+	helpText := m.keyHints(ctx)
+	if ctx == ctxFiles {
+		helpText += keyDescStyle.Render(" | ") + m.keyHints(ctxGlobal)
+	}
+
+	// Status message
+	var statusText string
+	if m.statusMessage != "" {
+		statusText = m.statusMessage
+	}
+	if m.loading["refresh"] {
+		statusText = m.spinner.View() + " Refreshing status, changes, branches and conflicts..."
+	}
+
+	// Shell prompt replaces the status line while open
+	if m.shellInput.Focused() {
+		statusText = "$ " + m.shellInput.View()
+		helpText = m.keyHints(ctxShell)
+	}
+
+	// So does the message of an empty commit or an amend
+	if m.messageInput.Focused() {
+		label := "Empty commit message: "
+		if m.messageMode == "amend" {
+			label = "New subject for the last commit: "
+		}
+		statusText = label + m.messageInput.View()
+		if m.confirmAction == "message-"+m.messageMode && m.statusMessage != "" {
+			statusText = m.statusMessage
+		}
+		helpText = m.keyHints(ctxMessage)
+	}
+
+	// So does the typed confirmation of a dangerous action
+	if m.danger != nil {
+		statusText = fmt.Sprintf("Type '%s' to %s: ", m.danger.word, m.danger.what) + m.dangerInput.View()
+		helpText = m.keyHints(ctxDanger)
+	}
+
+	// Layout: status on left, help on right
+	leftSide := lipgloss.NewStyle().Inline(true).Background(lipgloss.Color("236")).Render(statusText)
+	rightSide := helpText
+
+	availableWidth := m.width - 4
+	if m.compact() {
+		// One line: the status as far as it fits, then whichever hints fit
+		// after it
+		leftSide = lipgloss.NewStyle().Inline(true).MaxWidth(availableWidth).Background(lipgloss.Color("236")).Render(statusText)
+		rightSide = fitHints(helpText, availableWidth-lipgloss.Width(leftSide)-1)
+	}
+	padding := availableWidth - lipgloss.Width(leftSide) - lipgloss.Width(rightSide)
+	if padding < 1 {
+		padding = 1
+	}
+
+	styledPadding := lipgloss.NewStyle().Background(lipgloss.Color("236")).Render(strings.Repeat(" ", padding))
+	content := leftSide + styledPadding + rightSide
+
+	return statusBarStyle.Width(m.width).Render(content)
+}
+
+// fitHints keeps the leading footer hints that fit in width
+func fitHints(hints string, width int) string {
+	separator := keyDescStyle.Render(" | ")
+	var kept []string
+	for _, hint := range strings.Split(hints, separator) {
+		if lipgloss.Width(strings.Join(append(kept, hint), separator)) > width {
+			break
+		}
+		kept = append(kept, hint)
+	}
+	return strings.Join(kept, separator)
+}
+
+// keyHints renders the footer hints (scout-style) for a key context from the keymap
+func (m model) keyHints(ctx string) string {
+	var hints []string
+	for _, b := range m.keys[ctx] {
+		if b.hidden || (b.when != nil && !b.when(m)) || (b.writes && m.readOnly) {
+			continue
+		}
+		label := b.label
+		if label == "" {
+			label = b.keys[0]
+		}
+		hints = append(hints, keyBindStyle.Render(label)+keyDescStyle.Render(": "+b.help))
+	}
+	return strings.Join(hints, keyDescStyle.Render(" | "))
+}
+
+// renderLoading shows a spinner for a pane whose load is in flight
+func (m model) renderLoading(text string) string {
+	return m.spinner.View() + " " + helpStyle.Render(text)
+}
+
+// Workspace tab content
+func (m model) renderWorkspaceContent(width, height int) (string, string) {
+	if m.viewMode == "diff" {
+		return "", m.renderDiff(width, height)
+	}
+
+	if m.viewMode == "blame" {
+		return "", m.renderBlame(width, height)
+	}
+
+	if m.viewMode == "conflicts" {
+		return "", m.renderConflictsList(width, height)
+	}
+
+	if m.viewMode == "resolve" && m.resolveDoc != nil {
+		return "", m.renderConflictEditor(width, height)
+	}
+
+	// Files view - split pane layout (scout style)
+	if len(m.changes) == 0 {
+		return "", m.renderEmptyWorkspace(width, height)
+	}
+
+	// Too narrow or short for two panes: the files alone, enter shows a diff
+	if m.compact() {
+		return "", lipgloss.NewStyle().Height(height).Render(m.renderFilePane(width, height))
+	}
+
+	// Split pane: files on left, diff preview on right
+	// Each pane is a self-contained bordered panel
+	// Use full available height
+	panelHeight := height
+	leftWidth := width / 2
+	rightWidth := width - leftWidth
+
+	leftPane := m.renderFilePane(leftWidth, panelHeight)
+	rightPane := m.renderDiffPane(rightWidth, panelHeight)
+
+	// Force both panels to exact same height
+	leftStyled := lipgloss.NewStyle().Height(panelHeight).Render(leftPane)
+	rightStyled := lipgloss.NewStyle().Height(panelHeight).Render(rightPane)
+
+	content := lipgloss.JoinHorizontal(lipgloss.Top, leftStyled, rightStyled)
+
+	return "", content
+}
+
+func (m model) renderEmptyWorkspace(width, height int) string {
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(2, 4).
+		Width(width - 4)
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		sectionHeaderStyle.Render("✨ Working directory clean"),
+		"",
+		helpStyle.Render("No uncommitted changes"),
+		"",
+		normalStyle.Render("• Make changes to files to see them here"),
+		normalStyle.Render("• Use "+lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Render("git add")+" to stage files"),
+	)
+
+	return box.Render(content)
+}
+
+// renderDiffPane renders the diff preview as a bordered panel (scout style)
+func (m model) renderDiffPane(width, height int) string {
+	// Calculate available content height (height minus header and borders ~4 lines)
+	contentHeight := height - 4
+	if contentHeight < 1 {
+		contentHeight = 1
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Width(width - 4)
+
+	var headerText string
+	var content string
+
+	if m.diffContent == "" {
+		headerText = "👁 Preview"
+		content = helpStyle.Render("Select a file to preview changes")
+	} else {
+		lines := strings.Split(m.diffContent, "\n")
+		maxLines := contentHeight
+		if maxLines < 1 {
+			maxLines = 1
+		}
+
+		// Check scroll indicators
+		hasTop := m.scrollOffset > 0
+		hasBottom := m.scrollOffset+maxLines < len(lines)
+
+		if hasTop {
+			maxLines--
+		}
+		if hasBottom {
+			maxLines--
+		}
+
+		// Scroll info
+		scrollInfo := ""
+		if len(lines) > maxLines {
+			scrollInfo = helpStyle.Render(fmt.Sprintf("[%d/%d]", m.scrollOffset+1, len(lines)))
+		}
+		headerText = ("👁 Preview ") + scrollInfo
+		if flags := m.diffOptions.Args(); len(flags) > 0 {
+			headerText += " " + helpStyle.Render(strings.Join(flags, " "))
+		}
+
+		// Apply scroll
+		startIdx := m.scrollOffset
+		if startIdx > len(lines) {
+			startIdx = 0
+		}
+		endIdx := startIdx + maxLines
+		if endIdx > len(lines) {
+			endIdx = len(lines)
+		}
+
+		var items []string
+		if hasTop {
+			items = append(items, scrollIndicatorStyle.Render("▲"))
+		}
+
+		for i := startIdx; i < endIdx; i++ {
+			// Truncate long lines
+			line := lines[i]
+			maxLineWidth := width - 6
+			if len(line) > maxLineWidth {
+				line = line[:maxLineWidth-3] + "..."
+			}
+			items = append(items, colorizeDiffLine(line))
+		}
+
+		if hasBottom {
+			items = append(items, scrollIndicatorStyle.Render("▼"))
+		}
+
+		content = strings.Join(items, "\n")
+	}
+
+	header := headerStyle.Render(headerText)
+	listContent := lipgloss.NewStyle().Padding(0, 1).Render(content)
+
+	// Combine header and content with border - use height-2 for border box
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Width(width - 2).
+		Height(height - 2)
+
+	combined := header + "\n" + listContent
+	return borderStyle.Render(combined)
+}
+
+// renderFilePane renders the file list as a bordered panel (scout style)
+func (m model) renderFilePane(width, height int) string {
+	// Calculate available content height (height minus header and borders ~4 lines)
+	contentHeight := height - 4
+	if contentHeight < 1 {
+		contentHeight = 1
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Width(width - 4)
+
+	header := headerStyle.Render(fmt.Sprintf("📄 Files"))
+
+	// Calculate scroll - use most of content height for items
+	maxItems := contentHeight
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTopIndicator := m.fileOffset > 0
+	hasBottomIndicator := m.fileOffset+maxItems < len(m.changes)
+
+	if hasTopIndicator {
+		maxItems--
+	}
+	if hasBottomIndicator {
+		maxItems--
+	}
+
+	var items []string
+
+	if hasTopIndicator {
+		items = append(items, scrollIndicatorStyle.Render("▲ more above"))
+	}
+
+	endIdx := m.fileOffset + maxItems
+	if endIdx > len(m.changes) {
+		endIdx = len(m.changes)
+	}
+
+	for i := m.fileOffset; i < endIdx; i++ {
+		change := m.changes[i]
+		iconChar, iconColor := getStatusIconParts(change.Status)
+		note := modeNote(change)
+		if note != "" {
+			note = " (" + note + ")"
+		}
+		// Long paths lose the middle of their directories, not the file name
+		room := width - 6 - lipgloss.Width(iconChar) - 1 - lipgloss.Width(note)
+		name := fitPath(m.displayPath(change.File), room)
+		if change.OrigPath != "" {
+			name = fitRename(m.displayPath(change.OrigPath), m.displayPath(change.File), room)
+		}
+		name += note
+
+		// Owners go after the name when there is room for them
+		owners := strings.Join(m.codeOwners.Owners(change.File), " ")
+		if room := width - 6 - lipgloss.Width(name) - 4; owners != "" && room >= 8 {
+			if lipgloss.Width(owners) > room {
+				owners = headCells(owners, room-1) + "…"
+			}
+			owners = "  " + owners
+		} else {
+			owners = ""
+		}
+
+		if i == m.fileCursor {
+			selBg := lipgloss.Color("236")
+
+			iconPart := lipgloss.NewStyle().Foreground(iconColor).Background(selBg).Bold(true).Render(iconChar)
+			textPart := lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(selBg).Bold(true).Render(" " + name)
+			ownerPart := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(selBg).Render(owners)
+
+			line := iconPart + textPart + ownerPart
+			items = append(items, lipgloss.NewStyle().Width(width-6).Background(selBg).Render(line))
+		} else {
+			icon := getStatusIcon(change.Status)
+			line := fmt.Sprintf("%s %s", icon, name)
+			items = append(items, normalStyle.Render(line)+helpStyle.Render(owners))
+		}
+	}
+
+	if hasBottomIndicator {
+		items = append(items, scrollIndicatorStyle.Render("▼ more below"))
+	}
+
+	listContent := lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(items, "\n"))
+
+	// Combine header and list with border - use height-2 for border box
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Width(width - 2).
+		Height(height - 2)
+
+	combined := header + "\n" + listContent
+	return borderStyle.Render(combined)
+}
+
+func (m model) renderDiff(width, height int) string {
+	if m.diffContent == "" {
+		return helpStyle.Render("No diff to display")
+	}
+
+	lines := strings.Split(m.diffContent, "\n")
+
+	// Apply scroll (one line goes to the breadcrumb)
+	maxLines := height - 3
+	if maxLines < 1 {
+		maxLines = 1
+	}
+
+	hasTop := m.scrollOffset > 0
+	hasBottom := m.scrollOffset+maxLines < len(lines)
+
+	result := []string{m.renderDiffBreadcrumb(lines, width)}
+
+	if hasTop {
+		result = append(result, scrollIndicatorStyle.Render("scroll up for more..."))
+		maxLines--
+	}
+
+	endIdx := m.scrollOffset + maxLines
+	if endIdx > len(lines) {
+		endIdx = len(lines)
+	}
+
+	for i := m.scrollOffset; i < endIdx; i++ {
+		result = append(result, colorizeDiffLine(lines[i]))
+	}
+
+	if hasBottom {
+		result = append(result, scrollIndicatorStyle.Render("scroll down for more..."))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// renderConflictEditor shows ours and theirs for the current conflict block
+// side by side, with the scratch buffer for the result below
+func (m model) renderConflictEditor(width, height int) string {
+	doc := m.resolveDoc
+	block := doc.Blocks[m.resolveBlock]
+
+	title := sectionHeaderStyle.Render(fmt.Sprintf("Resolve %s - block %d/%d", m.displayPath(doc.Path), m.resolveBlock+1, len(doc.Blocks)))
+
+	editorHeight := m.resolveInput.Height()
+	paneHeight := height - editorHeight - 6
+	if paneHeight < 3 {
+		paneHeight = 3
+	}
+	paneWidth := width / 2
+	if block.HasBase {
+		paneWidth = width / 3
+	}
+
+	pane := func(label string, lines []string, style lipgloss.Style) string {
+		shown := []string{style.Bold(true).Render(label)}
+		maxLineWidth := paneWidth - 4
+		for i, line := range lines {
+			if i == paneHeight-2 {
+				shown = append(shown, scrollIndicatorStyle.Render(fmt.Sprintf("  ... %d more lines", len(lines)-i)))
+				break
+			}
+			if maxLineWidth > 3 && len(line) > maxLineWidth {
+				line = line[:maxLineWidth-3] + "..."
+			}
+			shown = append(shown, style.Render(line))
+		}
+		return lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240")).
+			Width(paneWidth - 2).
+			Height(paneHeight).
+			Render(strings.Join(shown, "\n"))
+	}
+
+	panes := []string{pane("ours: "+block.OursLabel, block.Ours, diffRemoveStyle)}
+	if block.HasBase {
+		panes = append(panes, pane("base: "+block.BaseLabel, block.Base, helpStyle))
+	}
+	panes = append(panes, pane("theirs: "+block.TheirsLabel, block.Theirs, diffAddStyle))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		lipgloss.JoinHorizontal(lipgloss.Top, panes...),
+		helpStyle.Render("Result:"),
+		m.resolveInput.View(),
+	)
+}
+
+func (m model) renderConflictsList(width, height int) string {
+	var lines []string
+	switch {
+	case m.rebaseProgress != nil:
+		lines = append(lines, m.renderRebaseProgress(width, height/2)...)
+		lines = append(lines, "")
+	case m.conflictOp != "":
+		lines = append(lines, sectionHeaderStyle.Render(strings.ToUpper(m.conflictOp[:1])+m.conflictOp[1:]+" in progress"), "")
+	}
+
+	if len(m.conflicts) == 0 {
+		if m.conflictOp != "" {
+			lines = append(lines, helpStyle.Render("All conflicts resolved - press C to continue the "+m.conflictOp))
+			return strings.Join(lines, "\n")
+		}
+		return helpStyle.Render("No conflicts found")
+	}
+
+	for i, conflict := range m.conflicts {
+		icon := "!"
+		if conflict.IsResolved {
+			icon = "ok"
+		}
+		line := fmt.Sprintf("%s %s", icon, fitPath(m.displayPath(conflict.Path), width-5-len(icon)))
+
+		if i == m.conflictCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Commit tab content
+func (m model) renderCommitContent(width, height int) (string, string) {
+	if m.commitSummary != nil {
+		return "", m.renderCommitSummary(width, height)
+	}
+
+	if m.gitState.StagedFiles == 0 {
+		return "", m.renderNothingStaged(width, height)
+	}
+
+	var sections []string
+
+	sections = append(sections, m.renderCommitTarget(), "")
+
+	// Recent commits and the staged files; the compact layout keeps the room
+	// for suggestions, and they give way below when the custom message
+	// wouldn't fit
+	var recent, staged []string
+	if len(m.recentCommits) > 0 && !m.compact() {
+		recent = append(recent, helpStyle.Render("Recent:"))
+		for _, commit := range m.recentCommits {
+			recent = append(recent, fmt.Sprintf("  %s %s",
+				lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(commit.Hash),
+				commit.Message))
+		}
+		recent = append(recent, "")
+	}
+
+	// Partial commit file picker
+	if m.partialFiles != nil {
+		return "", m.renderPartialPicker(width, height)
+	}
+
+	if files := m.renderStagedFiles(width); files != "" && !m.compact() {
+		staged = []string{files, ""}
+	}
+
+	// Split commit plan
+	if m.splitGroups != nil {
+		return "", m.renderSplitPlan(width, height)
+	}
+
+	// Type/scope picker
+	if m.commitPicker != "" {
+		sections = append(slices.Concat(sections, recent, staged), m.renderCommitPicker(height))
+		return "", strings.Join(sections, "\n")
+	}
+
+	var body []string
+
+	if m.loading["commit-push"] {
+		body = append(body, m.renderLoading("Committing and pushing..."), "")
+	}
+
+	// Suggestions
+	if len(m.suggestions) == 0 && m.loading["suggestions"] {
+		body = append(body, m.renderLoading("Analyzing changes..."), "")
+	}
+	if len(m.suggestions) > 0 {
+		quick := m.keys.keyFor(ctxCommit, "commit-1")
+		if quick == "alt+1" {
+			quick = "alt+1-9"
+		}
+		title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86")).Render(
+			fmt.Sprintf("Suggestions (↑/↓ to select, enter to commit, %s for #N):", quick))
+		if m.loading["suggestions"] {
+			title += " " + m.spinner.View()
+		}
+		body = append(body, title)
+		for i, suggestion := range m.suggestions {
+			style := suggestionStyle
+			indicator := "  "
+			if m.selectedSuggestion == i+1 {
+				style = selectedSuggestionStyle
+				indicator = "> "
+			}
+			number := "   "
+			if i < 9 {
+				number = fmt.Sprintf("%d. ", i+1)
+			}
+			body = append(body, style.Render(fmt.Sprintf("%s%s%s", indicator, number, suggestion.Message)))
+			if m.showExplanation && m.selectedSuggestion == i+1 {
+				body = append(body, renderSuggestionEvidence(suggestion))
+			}
+		}
+		body = append(body, "")
+	}
+
+	// Custom input
+	if len(m.partialPaths) > 0 {
+		body = append(body, warningStyle.Render(fmt.Sprintf("Committing %d of %d staged files: ", len(m.partialPaths), m.gitState.StagedFiles))+
+			helpStyle.Render(strings.Join(m.partialPaths, ", ")))
+	}
+	body = append(body, lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86")).Render("Custom message:"))
+	body = append(body, m.commitInput.View())
+
+	// The panel wraps long lines, so measure the way it renders
+	fits := func(parts ...[]string) bool {
+		return lipgloss.Height(listStyle.Width(width+4).Render(strings.Join(slices.Concat(parts...), "\n"))) <= height
+	}
+	if allRecent := recent; !fits(sections, recent, staged, body) {
+		recent = nil
+		if !fits(sections, staged, body) && !m.stagedBrowsing {
+			staged = nil
+			if fits(sections, allRecent, body) {
+				recent = allRecent
+			}
+		}
+	}
+	sections = slices.Concat(sections, recent, staged, body)
+
+	// Breaking change
+	if m.breakingInput.Focused() {
+		sections = append(sections, "", warningStyle.Render("BREAKING CHANGE:"), m.breakingInput.View())
+	} else if m.breakingNote != "" {
+		sections = append(sections, "", warningStyle.Render("BREAKING CHANGE: ")+normalStyle.Render(m.breakingNote))
+	} else if len(m.breakingChanges) > 0 {
+		sections = append(sections, "", warningStyle.Render("⚠ Possible breaking change: ")+
+			helpStyle.Render(strings.Join(m.breakingChanges, ", ")+" (ctrl+x to mark)"))
+	}
+
+	if m.ticket != "" {
+		sections = append(sections, "", helpStyle.Render("Ticket: ")+normalStyle.Render(m.ticket))
+	}
+
+	// Footers
+	if m.footerOpen || len(m.footers) > 0 {
+		lines := []string{""}
+		for _, footer := range m.footers {
+			lines = append(lines, helpStyle.Render(footer.token+": ")+normalStyle.Render(footer.value))
+		}
+		if m.footerOpen {
+			lines = append(lines, warningStyle.Render(footerTokens[m.footerToken].token+": ")+m.footerInput.View())
+		} else {
+			lines = append(lines, helpStyle.Render(fmt.Sprintf("(%s to change footers)", m.keys.keyFor(ctxCommit, "footers"))))
+		}
+		sections = append(sections, lines...)
+	}
+
+	// Author/date override
+	if m.overrideOpen {
+		sections = append(sections, "", warningStyle.Render("Override for the next commit:"),
+			helpStyle.Render("Author ")+m.authorInput.View(),
+			helpStyle.Render("Date   ")+m.dateInput.View())
+	} else if !m.commitOverride.IsZero() {
+		sections = append(sections, "", warningStyle.Render("Override: ")+normalStyle.Render(m.commitOverride.String())+
+			helpStyle.Render(fmt.Sprintf(" (%s to change)", m.keys.keyFor(ctxCommit, "override"))))
+	}
+
+	if owners := m.renderStagedOwners(width); owners != "" {
+		sections = append(sections, "", owners)
+	}
+
+	// Spell-check
+	if len(m.spellIssues) > 0 {
+		sections = append(sections, "", m.renderSpellIssues(width))
+	}
+
+	return "", strings.Join(sections, "\n")
+}
+
+// renderStagedFiles lists what the commit will contain: a few files while
+// typing, a scrolling list with a cursor while browsing it
+func (m model) renderStagedFiles(width int) string {
+	staged := m.stagedChanges()
+	if len(staged) == 0 {
+		return ""
+	}
+
+	title := fmt.Sprintf("Staged (%d):", len(staged))
+	if !m.stagedBrowsing {
+		title += helpStyle.Render(fmt.Sprintf(" %s to browse", m.keys.keyFor(ctxCommit, "staged-files")))
+	}
+	lines := []string{helpStyle.Render(title)}
+
+	visible := 5
+	if m.stagedBrowsing {
+		visible = 10
+	}
+	offset := 0
+	if m.stagedBrowsing && m.stagedCursor >= visible {
+		offset = m.stagedCursor - visible + 1
+	}
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	end := min(len(staged), offset+visible)
+	for i := offset; i < end; i++ {
+		change := staged[i]
+		line := fmt.Sprintf("%s %s", getStatusIcon(change.Status[:1]+" "), fitPath(m.displayPath(change.File), width-12))
+		if change.Status[1] != ' ' {
+			// Partly staged: the worktree has more
+			line += iconUnstagedStyle.Render(" ●")
+		}
+		if m.stagedBrowsing && i == m.stagedCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render("> "+line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if end < len(staged) {
+		lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  … %d more", len(staged)-end)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderStagedOwners summarises who owns the staged files, i.e. who will be
+// asked to review them
+func (m model) renderStagedOwners(width int) string {
+	if m.codeOwners == nil {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	unowned := 0
+	for _, change := range m.stagedChanges() {
+		owners := m.codeOwners.Owners(change.File)
+		if len(owners) == 0 {
+			unowned++
+		}
+		for _, owner := range owners {
+			if counts[owner] == 0 {
+				order = append(order, owner)
+			}
+			counts[owner]++
+		}
+	}
+	if len(order) == 0 && unowned == 0 {
+		return ""
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+
+	var parts []string
+	for _, owner := range order {
+		parts = append(parts, fmt.Sprintf("%s (%d)", normalStyle.Render(owner), counts[owner]))
+	}
+	if unowned > 0 {
+		parts = append(parts, warningStyle.Render(fmt.Sprintf("%d unowned", unowned)))
+	}
+	return lipgloss.NewStyle().Width(width - 4).Render(
+		helpStyle.Render("Reviewers ("+m.codeOwners.Path+"): ") + strings.Join(parts, helpStyle.Render(", ")))
+}
+
+// renderSuggestionEvidence explains why a suggestion was made
+// renderNothingStaged lists the unstaged changes and offers to stage them
+// all, so modify -> commit doesn't need a trip through the workspace
+func (m model) renderNothingStaged(width, height int) string {
+	key := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxNothingStaged, action)) }
+	other := key("empty") + helpStyle.Render(" empty commit  ") + key("amend-message") + helpStyle.Render(" amend last message")
+	if m.readOnly {
+		other = ""
+	}
+	if len(m.changes) == 0 {
+		return helpStyle.Render("Nothing to commit - the working tree is clean.") + "\n\n" + other
+	}
+
+	lines := []string{
+		sectionHeaderStyle.Render("Nothing staged yet"),
+		helpStyle.Render(strings.Repeat("─", width-6)),
+	}
+	maxItems := max(1, height-6)
+	for i, change := range m.changes {
+		if i == maxItems {
+			lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  … %d more", len(m.changes)-maxItems)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf(" %s %s", getStatusIcon(change.Status), m.displayPath(change.File)))
+	}
+
+	lines = append(lines, "",
+		normalStyle.Render("Stage all and continue to commit? ")+key("stage-all")+helpStyle.Render(" yes  ")+
+			key("back")+helpStyle.Render(" back to workspace"),
+		"", other)
+	return strings.Join(lines, "\n")
+}
+
+func renderSuggestionEvidence(suggestion suggest.Suggestion) string {
+	info := suggestion.Info
+	row := func(label, value string) string {
+		return "      " + helpStyle.Render(fmt.Sprintf("%-11s", label)) + normalStyle.Render(value)
+	}
+	orNone := func(values []string) string {
+		if len(values) == 0 {
+			return "none"
+		}
+		return strings.Join(values, ", ")
+	}
+
+	files := info.Files
+	if len(files) > 5 {
+		files = append(append([]string{}, files[:5]...), fmt.Sprintf("+%d more", len(info.Files)-5))
+	}
+
+	lines := []string{
+		row("Type:", suggestion.Type+" ← "+info.Context),
+		row("Files:", orNone(files)),
+		row("Lines:", diffAddStyle.Render(fmt.Sprintf("+%d", info.Added))+" "+diffRemoveStyle.Render(fmt.Sprintf("-%d", info.Removed))),
+		row("Functions:", orNone(info.Functions)),
+		row("Keywords:", orNone(info.Keywords)),
+	}
+	if suggestion.Breaking != "" {
+		lines = append(lines, row("Breaking:", suggestion.Breaking))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPartialPicker lists staged files with checkboxes for a partial commit
+func (m model) renderPartialPicker(width, height int) string {
+	lines := []string{
+		sectionHeaderStyle.Render(fmt.Sprintf("Commit only selected files (%d/%d)", len(m.partialSelection()), len(m.partialFiles))),
+		helpStyle.Render(strings.Repeat("─", width-6)),
+	}
+
+	visible := max(1, height-len(lines)-2)
+	offset := 0
+	if m.partialCursor >= visible {
+		offset = m.partialCursor - visible + 1
+	}
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	end := min(len(m.partialFiles), offset+visible)
+	for i := offset; i < end; i++ {
+		file := m.partialFiles[i]
+		check := "[ ]"
+		if m.partialSelected[file] {
+			check = iconStagedStyle.Render("[✓]")
+		}
+		line := fmt.Sprintf("%s %s", check, file)
+		if i == m.partialCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+	if end < len(m.partialFiles) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSplitPlan lists the proposed commits with the files each one takes
+func (m model) renderSplitPlan(width, height int) string {
+	lines := []string{
+		sectionHeaderStyle.Render(fmt.Sprintf("Split into %d commits", len(m.splitGroups))),
+		helpStyle.Render(strings.Repeat("─", width-6)),
+	}
+
+	var body []string
+	cursorLine := 0
+	for i, group := range m.splitGroups {
+		if i == m.splitCursor {
+			cursorLine = len(body)
+			body = append(body, selectedStyle.Width(width-4).Render(fmt.Sprintf("> %d. %s", i+1, group.Message)))
+		} else {
+			body = append(body, normalStyle.Render(fmt.Sprintf("  %d. %s", i+1, group.Message)))
+		}
+		for _, file := range group.Files {
+			body = append(body, helpStyle.Render("       "+file))
+		}
+	}
+
+	visible := max(1, height-len(lines)-2)
+	offset := 0
+	if cursorLine >= visible {
+		offset = cursorLine - visible + 1
+	}
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	end := min(len(body), offset+visible)
+	lines = append(lines, body[offset:end]...)
+	if end < len(body) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCommitTarget says where the commit will go, who it will be
+// authored as and whether it will be signed
+func (m model) renderCommitTarget() string {
+	branchStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Bold(true)
+	parts := []string{helpStyle.Render("Committing to ") + branchStyle.Render(m.gitState.Branch)}
+
+	if author := m.commitOverride.Author; author.IsComplete() {
+		parts = append(parts, helpStyle.Render("as ")+warningStyle.Render(author.String()+" (override)"))
+	} else if m.identity.IsComplete() {
+		source := ""
+		if m.identity.Source != "" {
+			source = helpStyle.Render(" (" + m.identity.Source + ")")
+		}
+		parts = append(parts, helpStyle.Render("as ")+normalStyle.Render(m.identity.String())+source)
+	} else {
+		parts = append(parts, errorStyle.Render("no identity set"))
+	}
+
+	if m.signingConfig.SignCommits {
+		format := m.signingConfig.Format
+		if format == "" {
+			format = "gpg"
+		}
+		parts = append(parts, successStyle.Render("signed ("+format+")"))
+	} else {
+		parts = append(parts, helpStyle.Render("unsigned"))
+	}
+
+	actions := helpStyle.Render("  ") + keyBindStyle.Render(m.keys.keyFor(ctxCommit, "identity")) + helpStyle.Render(" identity") +
+		helpStyle.Render("  ") + keyBindStyle.Render(m.keys.keyFor(ctxCommit, "override")) + helpStyle.Render(" author/date")
+	if !m.readOnly {
+		actions += helpStyle.Render("  ") + keyBindStyle.Render(m.keys.keyFor(ctxCommit, "signing")) + helpStyle.Render(" signing")
+	}
+	return strings.Join(parts, helpStyle.Render(" · ")) + actions
+}
+
+// renderCommitPicker shows the type list, then the scope list once a type is chosen
+func (m model) renderCommitPicker(height int) string {
+	title := "Commit type:"
+	options := m.config.Commit.Types
+	if m.commitPicker == "scope" {
+		title = fmt.Sprintf("Scope for %s:", m.pickedType)
+		options = m.scopeOptions()
+	}
+
+	lines := []string{lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86")).Render(title)}
+
+	visible := max(1, height-len(m.recentCommits)-6)
+	offset := 0
+	if m.pickerCursor >= visible {
+		offset = m.pickerCursor - visible + 1
+	}
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	for i := offset; i < len(options) && i < offset+visible; i++ {
+		if i == m.pickerCursor {
+			lines = append(lines, selectedSuggestionStyle.Render("> "+options[i]))
+		} else {
+			lines = append(lines, suggestionStyle.Render("  "+options[i]))
+		}
+	}
+	if offset+visible < len(options) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSpellIssues shows the message with suspect words underlined, followed
+// by the suggested corrections
+func (m model) renderSpellIssues(width int) string {
+	text := m.commitInput.Value()
+	suspect := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Underline(true)
+
+	var b strings.Builder
+	last := 0
+	for _, issue := range m.spellIssues {
+		b.WriteString(normalStyle.Render(text[last:issue.Start]))
+		b.WriteString(suspect.Render(text[issue.Start:issue.End]))
+		last = issue.End
+	}
+	b.WriteString(normalStyle.Render(text[last:]))
+
+	var hints []string
+	for _, issue := range m.spellIssues {
+		hints = append(hints, warningStyle.Render(issue.Word)+helpStyle.Render(" → "+strings.Join(issue.Suggestions, ", ")))
+	}
+
+	lines := []string{
+		helpStyle.Render("Spelling:"),
+		"  " + b.String(),
+		"  " + strings.Join(hints, helpStyle.Render("  ")),
+	}
+	return lipgloss.NewStyle().MaxWidth(width - 4).Render(strings.Join(lines, "\n"))
+}
+
+func (m model) renderCommitSummary(width, height int) string {
+	summary := m.commitSummary
+
+	var lines []string
+
+	lines = append(lines, successStyle.Render(fmt.Sprintf("Commit %s", summary.hash)))
+	switch {
+	case summary.pushed != "":
+		lines = append(lines, successStyle.Render(summary.pushed))
+	case summary.pushErr != "":
+		lines = append(lines, errorStyle.Render("Push failed: "+summary.pushErr))
+	}
+	lines = append(lines, "")
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render("Message: ")+summary.message)
+	if !summary.override.IsZero() {
+		lines = append(lines, warningStyle.Render("Override: ")+summary.override.String())
+	}
+	lines = append(lines, "")
+
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Files (%d):", len(summary.files))))
+	for _, file := range summary.files {
+		lines = append(lines, "  "+file)
+	}
+	lines = append(lines, "")
+
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render("Diff:"))
+	diffLines := strings.Split(summary.diff, "\n")
+	for _, line := range diffLines {
+		lines = append(lines, colorizeDiffLine(line))
+	}
+	lines = append(lines, "")
+
+	if summary.pushed != "" {
+		lines = append(lines, warningStyle.Render("Actions: [c] Continue  [1] Workspace"))
+	} else {
+		lines = append(lines, warningStyle.Render("Actions: [p] Push  [c] Continue  [1] Workspace"))
+	}
+
+	// Apply scroll
+	maxLines := height - 2
+	hasTop := m.scrollOffset > 0
+	hasBottom := m.scrollOffset+maxLines < len(lines)
+
+	var result []string
+
+	if hasTop {
+		result = append(result, scrollIndicatorStyle.Render("scroll up..."))
+		maxLines--
+	}
+
+	endIdx := m.scrollOffset + maxLines
+	if endIdx > len(lines) {
+		endIdx = len(lines)
+	}
+
+	for i := m.scrollOffset; i < endIdx; i++ {
+		result = append(result, lines[i])
+	}
+
+	if hasBottom {
+		result = append(result, scrollIndicatorStyle.Render("scroll down..."))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// Branches tab content
+func (m model) renderBranchesContent(width, height int) (string, string) {
+	if m.loading["comparison"] {
+		return "", m.renderLoading("Comparing branches...")
+	}
+
+	if m.branchComparison != nil {
+		return "", m.renderBranchComparison(width, height)
+	}
+
+	if m.branchInput.Focused() {
+		return "", m.branchInput.View()
+	}
+
+	if m.prInput.Focused() {
+		via := "git fetch, as pr/<number>"
+		if m.forge != "" {
+			via = m.forge
+		}
+		return "", lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86")).Render("Check out pull request") +
+			helpStyle.Render(" via "+via) + "\n\n" + m.prInput.View()
+	}
+
+	if len(m.branches) == 0 {
+		if m.loading["branches"] {
+			return "", m.renderLoading("Loading branches...")
+		}
+		return "", helpStyle.Render("No branches")
+	}
+
+	return "", m.renderBranchList(width, height)
+}
+
+func (m model) renderBranchList(width, height int) string {
+	// Count local vs remote
+	localCount := 0
+	remoteCount := 0
+	for _, b := range m.branches {
+		if b.IsRemote {
+			remoteCount++
+		} else {
+			localCount++
+		}
+	}
+
+	header := sectionHeaderStyle.Render("Branches") + " " +
+		branchCurrentStyle.Render(fmt.Sprintf("🏠%d", localCount)) + " " +
+		branchRemoteStyle.Render(fmt.Sprintf("☁️%d", remoteCount))
+	if m.defaultBranch != "" {
+		header += helpStyle.Render(" (vs " + m.defaultBranch + ")")
+	}
+	if m.loading["branches"] {
+		header += " " + m.spinner.View()
+	}
+
+	maxItems := height - 4
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.branchOffset > 0
+	hasBottom := m.branchOffset+maxItems < len(m.branches)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+
+	endIdx := m.branchOffset + maxItems
+	if endIdx > len(m.branches) {
+		endIdx = len(m.branches)
+	}
+
+	// Pad names so the base/upstream columns line up
+	nameWidth := 0
+	for i := m.branchOffset; i < endIdx; i++ {
+		nameWidth = max(nameWidth, len(m.branches[i].Name))
+	}
+	nameWidth = min(nameWidth, 40)
+
+	for i := m.branchOffset; i < endIdx; i++ {
+		branch := m.branches[i]
+
+		// Icon based on branch type
+		var icon string
+		var nameStyle lipgloss.Style
+		if branch.IsCurrent {
+			icon = branchCurrentStyle.Render("🏠")
+			nameStyle = branchCurrentStyle
+		} else if branch.IsRemote {
+			icon = branchRemoteStyle.Render("☁️")
+			nameStyle = branchRemoteStyle
+		} else {
+			icon = helpStyle.Render("🌿")
+			nameStyle = normalStyle
+		}
+
+		// Tracking info with colored ahead/behind
+		tracking := ""
+		if branch.Upstream != "" {
+			tracking = helpStyle.Render(" → " + branch.Upstream)
+			if branch.Gone {
+				tracking += " " + warningStyle.Render("gone")
+			}
+			if branch.Ahead > 0 {
+				tracking += " " + branchAheadStyle.Render(fmt.Sprintf("↑%d", branch.Ahead))
+			}
+			if branch.Behind > 0 {
+				tracking += " " + branchBehindStyle.Render(fmt.Sprintf("↓%d", branch.Behind))
+			}
+		}
+
+		// Ahead/behind relative to the base branch
+		base := ""
+		if m.defaultBranch != "" && branch.Name != m.defaultBranch && branch.BaseCounted {
+			base = helpStyle.Render(" │ " + m.defaultBranch)
+			if branch.BaseAhead == 0 && branch.BaseBehind == 0 {
+				base += helpStyle.Render(" =")
+			}
+			if branch.BaseAhead > 0 {
+				base += " " + branchAheadStyle.Render(fmt.Sprintf("↑%d", branch.BaseAhead))
+			}
+			if branch.BaseBehind > 0 {
+				base += " " + branchBehindStyle.Render(fmt.Sprintf("↓%d", branch.BaseBehind))
+			}
+		}
+
+		name := fmt.Sprintf("%-*s", nameWidth, branch.Name)
+		lock := "  "
+		if m.config.Branches.IsProtected(branch.Name) {
+			lock = "🔒"
+		}
+		line := fmt.Sprintf(" %s %s %s%s%s", icon, nameStyle.Render(name), lock, base, tracking)
+
+		if i == m.branchCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderBranchComparison(width, height int) string {
+	if m.branchComparison == nil {
+		return ""
+	}
+	if m.loading["review"] && m.review == nil {
+		return m.renderLoading("Loading diff...")
+	}
+	if m.review != nil {
+		return m.renderReviewDiff(width, height)
+	}
+
+	var lines []string
+
+	lines = append(lines, fmt.Sprintf("%s vs %s",
+		m.branchComparison.SourceBranch,
+		m.branchComparison.TargetBranch))
+	lines = append(lines, "")
+
+	ahead, behind := "", ""
+	if m.comparingUpstream() {
+		ahead = helpStyle.Render("  (a push sends these)")
+		behind = helpStyle.Render("  (a pull brings these, as of the last fetch)")
+	}
+
+	// Both lists share columns so they line up
+	columns := []listColumn{{}, {min: 12, flex: true}, {min: 8, max: 20}, {min: 12, max: 17}}
+	commitRow := func(commit git.Commit) []string {
+		return []string{commit.Hash, printable(commit.Message), helpStyle.Render(printable(commit.Author)), helpStyle.Render(m.commitDate(commit))}
+	}
+	var rows [][]string
+	for _, commit := range slices.Concat(m.branchComparison.AheadCommits, m.branchComparison.BehindCommits) {
+		rows = append(rows, commitRow(commit))
+	}
+	widths := columnWidths(columns, rows, width-6)
+
+	lines = append(lines, fmt.Sprintf("Ahead: %d commits", len(m.branchComparison.AheadCommits))+ahead)
+	for _, commit := range m.branchComparison.AheadCommits {
+		lines = append(lines, "  "+renderColumns(columns, commitRow(commit), widths))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("Behind: %d commits", len(m.branchComparison.BehindCommits))+behind)
+	for _, commit := range m.branchComparison.BehindCommits {
+		lines = append(lines, "  "+renderColumns(columns, commitRow(commit), widths))
+	}
+
+	lines = append(lines, "")
+	title := fmt.Sprintf("Files changed: %d", len(m.branchComparison.DifferingFiles))
+	if n := len(m.reviewComments); n > 0 {
+		title += helpStyle.Render(fmt.Sprintf("  (%d review comment(s))", n))
+	}
+	lines = append(lines, title)
+
+	comments := make(map[string]int)
+	for _, comment := range m.reviewComments {
+		comments[comment.File]++
+	}
+	cursorLine := 0
+	for i, file := range m.branchComparison.DifferingFiles {
+		var suffix string
+		if file.OldPath != "" {
+			suffix = fmt.Sprintf(" (%d%%)", file.Similarity)
+		}
+		if n := comments[file.Path]; n > 0 {
+			suffix += fmt.Sprintf("  💬 %d", n)
+		}
+		room := width - 4 - 4 - lipgloss.Width(suffix)
+		name := fitPath(printable(file.Path), room)
+		if file.OldPath != "" {
+			name = fitRename(printable(file.OldPath), printable(file.Path), room)
+		}
+		line := fmt.Sprintf("  %s %s%s", file.Status, name, suffix)
+		if i == m.compareCursor {
+			cursorLine = len(lines)
+			line = selectedStyle.Width(width - 4).Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	// Keep the selected file in view
+	maxLines := max(1, height-2)
+	offset := min(max(0, cursorLine-maxLines+1), max(0, len(lines)-maxLines))
+	return strings.Join(lines[offset:min(len(lines), offset+maxLines)], "\n")
+}
+
+// renderReviewDiff shows a compared file's diff with a line cursor and the
+// review comments under the lines they belong to
+func (m model) renderReviewDiff(width, height int) string {
+	review := m.review
+
+	header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("105")).Render(
+		fmt.Sprintf("Review: %s", review.file.Path)) +
+		helpStyle.Render(fmt.Sprintf("  (file %d/%d)", m.compareCursor+1, len(m.branchComparison.DifferingFiles)))
+	if m.loading["review"] {
+		header += " " + m.spinner.View()
+	}
+
+	commentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("229")).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("214")).
+		PaddingLeft(1).
+		MarginLeft(2).
+		Width(width - 10)
+
+	var rows []string
+	cursorRow := 0
+	for i, line := range review.lines {
+		if i == review.cursor {
+			cursorRow = len(rows)
+			rows = append(rows, selectedStyle.Width(width-4).Render(line))
+		} else {
+			rows = append(rows, colorizeDiffLine(line))
+		}
+		if c := m.reviewCommentAt(review.file.Path, review.positions[i]); c >= 0 {
+			rows = append(rows, strings.Split(commentStyle.Render("💬 "+m.reviewComments[c].Body), "\n")...)
+		}
+		if i == review.cursor && m.reviewInput.Focused() {
+			rows = append(rows, "  "+m.reviewInput.View())
+		}
+	}
+
+	// Centre the cursor once it moves past the middle of the pane
+	maxRows := max(1, height-4)
+	offset := min(max(0, cursorRow-maxRows/2), max(0, len(rows)-maxRows))
+	end := min(len(rows), offset+maxRows)
+
+	return header + "\n\n" + strings.Join(rows[offset:end], "\n")
+}
+
+// PRs tab content
+
+// prLabel is the tab title: GitLab calls them merge requests
+func (m model) prLabel() string {
+	if m.forge == "glab" {
+		return "MRs"
+	}
+	return "PRs"
+}
+
+func (m model) renderPRsContent(width, height int) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86")).
+		Render(fmt.Sprintf("My open %ss", forge.Noun(m.forge))) + helpStyle.Render(" via "+m.forge)
+	if m.loading["prs"] {
+		title += " " + m.spinner.View()
+	}
+
+	lines := []string{title, ""}
+	switch {
+	case m.prsErr != nil:
+		lines = append(lines, errorStyle.Render(fmt.Sprintf("%s failed: %v", m.forge, m.prsErr)))
+		return strings.Join(lines, "\n")
+	case len(m.prs) == 0 && !m.loading["prs"]:
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("No open %ss by you in this repository", forge.Noun(m.forge))))
+		return strings.Join(lines, "\n")
+	}
+
+	// Each request takes two lines: number and title, then branch and state
+	maxItems := max(1, (height-4)/2)
+	offset := min(max(0, m.prCursor-maxItems+1), max(0, len(m.prs)-maxItems))
+	for i := offset; i < min(len(m.prs), offset+maxItems); i++ {
+		pr := m.prs[i]
+
+		head := fmt.Sprintf(" #%-5d %s", pr.Number, pr.Title)
+		if pr.Draft {
+			head += helpStyle.Render("  [draft]")
+		}
+		if i == m.prCursor {
+			head = selectedStyle.Width(width - 4).Render(head)
+		}
+
+		state := []string{branchRemoteStyle.Render(pr.Branch)}
+		if pr.Branch == m.gitState.Branch {
+			state[0] += helpStyle.Render(" (current)")
+		}
+		switch pr.CI {
+		case forge.CIPassing:
+			state = append(state, successStyle.Render("✓ CI passing"))
+		case forge.CIFailing:
+			state = append(state, errorStyle.Render("✗ CI failing"))
+		case forge.CIPending:
+			state = append(state, warningStyle.Render("● CI running"))
+		}
+		switch pr.Review {
+		case "":
+		case "approved":
+			state = append(state, successStyle.Render("approved"))
+		case "changes requested":
+			state = append(state, errorStyle.Render(pr.Review))
+		default:
+			state = append(state, warningStyle.Render(pr.Review))
+		}
+		if pr.Conflicts {
+			state = append(state, errorStyle.Render("conflicts"))
+		}
+
+		lines = append(lines, head, "        "+strings.Join(state, helpStyle.Render(" · ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Tools tab content
+func (m model) renderToolsContent(width, height int) (string, string) {
+	switch m.toolMode {
+	case "log":
+		return "", m.renderLogContent(width, height)
+	case "undo":
+		return "", m.renderUndoList(width, height)
+	case "undopush":
+		return "", m.renderPushUndoContent(width, height)
+	case "diverged":
+		return "", m.renderDivergedContent(width, height)
+	case "undotime":
+		return "", m.renderTimeRestoreContent(width, height)
+	case "rebase":
+		return "", m.renderRebaseContent(width, height)
+	case "history":
+		return "", m.renderHistoryList(width, height)
+	case "remote":
+		return "", m.renderRemoteContent(width, height)
+	case "stash":
+		return "", m.renderStashList(width, height)
+	case "tags":
+		return "", m.renderTagsList(width, height)
+	case "hooks":
+		return "", m.renderHooksContent(width, height)
+	case "clone":
+		return "", m.renderCloneContent(width, height)
+	case "init":
+		return "", m.renderInitContent(width, height)
+	case "clean":
+		return "", m.renderCleanContent(width, height)
+	case "identity":
+		return "", m.renderIdentityContent(width, height)
+	case "config":
+		return "", m.renderConfigContent(width, height)
+	case "aliases":
+		return "", m.renderAliasesContent(width, height)
+	case "recover":
+		return "", m.renderRecoverContent(width, height)
+	case "signing":
+		return "", m.renderSigningContent(width, height)
+	case "jobs":
+		return "", m.renderJobsContent(width, height)
+	case "snapshots":
+		return "", m.renderSnapshotsContent(width, height)
+	case "exclude":
+		return "", m.renderExcludeContent(width, height)
+	case "messages":
+		return "", m.renderMessagesContent(width, height)
+	case "contributors":
+		return "", m.renderContributorsContent(width, height)
+	default:
+		return "", m.renderToolsMenu(width, height)
+	}
+}
+
+func (m model) renderToolsMenu(width, height int) string {
+	key := func(action string) string { return m.keys.keyFor(ctxTools, action) }
+	tools := []struct {
+		key  string
+		icon string
+		name string
+		desc string
+	}{
+		{key("log"), "📜", "Log", "Browse commit history"},
+		{key("stash"), "📦", "Stash", "Save/restore work in progress"},
+		{key("tags"), "🏷️", "Tags", "Manage version tags"},
+		{key("history"), "📜", "History", "View reflog"},
+		{key("undo"), "⏪", "Undo", "Undo recent commits"},
+		{key("rebase"), "📝", "Rebase", "Interactive rebase"},
+		{key("push"), "⬆️", "Push", "Push to remote"},
+		{key("fetch"), "⬇️", "Fetch/Pull", "Sync with remote"},
+		{key("hooks"), "🔒", "Hooks", "Git hooks management"},
+		{key("clean"), "🧹", "Clean", "Remove untracked files"},
+		{key("clone"), "📥", "Clone", "Clone a repository"},
+		{key("init"), "🆕", "Init", "Initialize new repo"},
+		{key("identity"), "👤", "Identity", "Commit author for this repo"},
+		{key("config"), "⚙️", "Config", "View and edit git config"},
+		{key("aliases"), "⚡", "Aliases", "Run your git aliases"},
+		{key("recover"), "🛟", "Recover", "Find lost/dangling commits"},
+		{key("signing"), "🔏", "Signing", "Set up commit signing keys"},
+		{key("jobs"), "⏳", "Jobs", "Background operations and their status"},
+		{key("snapshots"), "🕓", "Snapshots", "Restore automatic snapshots of uncommitted work"},
+		{key("exclude"), "🙈", "Exclude", "Ignore files locally via .git/info/exclude"},
+		{key("messages"), "💬", "Messages", "Recent status messages, failures included"},
+		{key("contributors"), "👥", "Contributors", "Authors of this branch and their commits"},
+	}
+
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Git Tools"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	// Scroll with the cursor when the list and the hook status (two lines)
+	// don't fit, keeping a line for each "more" indicator shown
+	visible, offset := len(tools), 0
+	if len(lines)+len(tools)+2 > height {
+		visible = max(1, height-len(lines)-3)
+		if m.toolCursor >= visible {
+			visible = max(1, visible-1)
+			offset = m.toolCursor - visible + 1
+		}
+	}
+	end := min(len(tools), offset+visible)
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+
+	for i := offset; i < end; i++ {
+		tool := tools[i]
+		selBg := lipgloss.Color("236")
+
+		if i == m.toolCursor {
+			sp := lipgloss.NewStyle().Background(selBg).Render(" ")
+			sp2 := lipgloss.NewStyle().Background(selBg).Render("  ")
+			keyStyle := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("75")).
+				Background(selBg).
+				Bold(true)
+			iconStyle := lipgloss.NewStyle().Background(selBg)
+			nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(selBg).Bold(true)
+			descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(selBg)
+
+			line := sp + iconStyle.Render(tool.icon) + sp + keyStyle.Render("["+tool.key+"]") + sp + nameStyle.Render(tool.name) + sp2 + descStyle.Render(tool.desc)
+
+			lines = append(lines, lipgloss.NewStyle().Width(width-4).Background(selBg).Render(ansi.Truncate(line, width-4, "…")))
+		} else {
+			keyStyle := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("75")).
+				Bold(true)
+
+			line := fmt.Sprintf(" %s %s %s  %s",
+				tool.icon,
+				keyStyle.Render("["+tool.key+"]"),
+				tool.name,
+				helpStyle.Render(tool.desc))
+
+			lines = append(lines, ansi.Truncate(line, width-4, "…"))
+		}
+	}
+	if end < len(tools) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	// Show hook status indicator
+	lines = append(lines, "")
+	hookStatus := "❌ Hook not installed"
+	if m.commitMsgHookInstalled {
+		hookStatus = "✅ Commit-msg hook active"
+	}
+	lines = append(lines, helpStyle.Render(hookStatus))
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderUndoList(width, height int) string {
+	commits := m.commits
+	if len(commits) == 0 {
+		if m.loading["history"] {
+			return m.renderLoading("Loading history...")
+		}
+		return helpStyle.Render("No commits to undo")
+	}
+
+	// Summary of what the selected reset does
+	modeStyle := normalStyle
+	if m.undoMode == "hard" {
+		modeStyle = errorStyle
+	}
+	var summary string
+	switch {
+	case m.undoInput.Focused():
+		summary = "Undo how many commits? " + m.undoInput.View()
+	case m.undoCursor == 0:
+		summary = helpStyle.Render("Select the commit to go back to - everything above it is undone")
+	default:
+		summary = fmt.Sprintf("Reset to %s undoes %s (%s)",
+			commits[m.undoCursor].Hash,
+			warningStyle.Render(fmt.Sprintf("%d commit(s)", m.undoCursor)),
+			undoModeDesc[m.undoMode])
+	}
+	header := []string{
+		sectionHeaderStyle.Render("Undo") + helpStyle.Render("  mode: ") + modeStyle.Render("--"+m.undoMode),
+		summary,
+	}
+
+	maxItems := height - 4
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.undoOffset > 0
+	hasBottom := m.undoOffset+maxItems < len(commits)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	lines := header
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("more above..."))
+	}
+
+	endIdx := m.undoOffset + maxItems
+	if endIdx > len(commits) {
+		endIdx = len(commits)
+	}
+
+	columns := []listColumn{{}, {min: 12, flex: true}, {min: 12, max: 17}}
+	var rows [][]string
+	for _, commit := range commits[m.undoOffset:endIdx] {
+		rows = append(rows, []string{commit.Hash, printable(commit.Message), m.commitDate(commit)})
+	}
+	widths := columnWidths(columns, rows, width-6)
+
+	for i := m.undoOffset; i < endIdx; i++ {
+		line := renderColumns(columns, rows[i-m.undoOffset], widths)
+
+		if i == m.undoCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render("→ "+line))
+		} else if i < m.undoCursor {
+			lines = append(lines, diffRemoveStyle.Render("↶ "+line))
+		} else {
+			lines = append(lines, normalStyle.Render("  "+line))
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("more below..."))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderTimeRestoreContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Restore to a Point in Time"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "Go back: "+m.undoTimeInput.View())
+
+	restore := m.timeRestore
+	if restore == nil {
+		return strings.Join(lines, "\n")
+	}
+
+	target := restore.Target
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("HEAD was at %s on %s",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(target.Hash[:7]),
+		target.Time.Format("Mon Jan 2 15:04")))
+	lines = append(lines, helpStyle.Render("  "+target.Subject))
+
+	if len(restore.Undone) == 0 && len(restore.Restored) == 0 {
+		lines = append(lines, "", helpStyle.Render("Nothing to change - HEAD is already there"))
+		return strings.Join(lines, "\n")
+	}
+
+	room := max(2, (height-len(lines)-8)/2)
+	listCommits := func(title string, style lipgloss.Style, commits []git.Commit) {
+		if len(commits) == 0 {
+			return
+		}
+		lines = append(lines, "", style.Render(fmt.Sprintf("%s (%d):", title, len(commits))))
+		for i, commit := range commits {
+			if i == room {
+				lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  ... %d more", len(commits)-room)))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s %s", commit.Hash, commit.Message, helpStyle.Render("("+commit.Date+")")))
+		}
+	}
+	listCommits("Commits undone", diffRemoveStyle, restore.Undone)
+	listCommits("Commits brought back", diffAddStyle, restore.Restored)
+
+	if restore.DiffStat != "" {
+		statLines := strings.Split(restore.DiffStat, "\n")
+		lines = append(lines, "", helpStyle.Render(statLines[len(statLines)-1]))
+	}
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("The current state is saved on a gitty-backup/<time> branch first. Uncommitted changes are kept (the reset is refused if they conflict)."))
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderPushUndoContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Undo Last Push"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if m.pushUndoErr != "" {
+		lines = append(lines, errorStyle.Render("Cannot undo: "+m.pushUndoErr))
+		return strings.Join(lines, "\n")
+	}
+	undo := m.pushUndo
+	if undo == nil {
+		lines = append(lines, helpStyle.Render("Reading reflog..."))
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, fmt.Sprintf("%s moved %s → %s (%d commit(s)):",
+		branchRemoteStyle.Render(undo.Tracking()), undo.From[:7], undo.To[:7], len(undo.Commits)))
+
+	room := max(1, height-len(lines)-14)
+	for i, commit := range undo.Commits {
+		if i == room {
+			lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  ... %d more", len(undo.Commits)-room)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s",
+			lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(commit.Hash),
+			commit.Message, helpStyle.Render("("+commit.Author+", "+commit.Date+")")))
+	}
+
+	if !undo.ByPush {
+		lines = append(lines, "")
+		lines = append(lines, warningStyle.Render("⚠ The last update came from a fetch, not your push - these may be other people's commits"))
+	}
+	if !undo.LocalAtTo {
+		lines = append(lines, warningStyle.Render(fmt.Sprintf("⚠ Local %s no longer matches the pushed state", undo.Branch)))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, keyBindStyle.Render("r")+normalStyle.Render(" Revert (safe)"))
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("    Adds %d new commit(s) undoing the changes on top of %s. History is kept,", len(undo.Commits), undo.Branch)))
+	lines = append(lines, helpStyle.Render("    nobody else is affected. Push afterwards to publish the reverts."))
+	lines = append(lines, "")
+	lines = append(lines, keyBindStyle.Render("f")+normalStyle.Render(" Force-push previous state (rewrites history)"))
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("    Moves %s back to %s with --force-with-lease. Your local commits stay,", undo.Tracking(), undo.From[:7])))
+	lines = append(lines, helpStyle.Render("    but anyone who already pulled will diverge. Refused if someone pushed since."))
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderDivergedContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Diverged Branch"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if m.divergenceErr != "" {
+		lines = append(lines, errorStyle.Render("Cannot compare: "+m.divergenceErr))
+		return strings.Join(lines, "\n")
+	}
+	div := m.divergence
+	if div == nil {
+		lines = append(lines, m.renderLoading("Fetching and comparing..."))
+		return strings.Join(lines, "\n")
+	}
+	if !div.Diverged() {
+		lines = append(lines, successStyle.Render(fmt.Sprintf("%s and %s have not diverged (%d ahead, %d behind)",
+			div.Branch, div.Upstream, len(div.Local), len(div.Remote))))
+		lines = append(lines, helpStyle.Render("A plain push or pull works now."))
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, fmt.Sprintf("%s and %s have diverged since %s",
+		branchCurrentStyle.Render(div.Branch), branchRemoteStyle.Render(div.Upstream), div.MergeBase))
+
+	// Split the room left after the options between both sides
+	room := max(2, height-len(lines)-18)
+	section := func(title string, commits []git.Commit, limit int) {
+		lines = append(lines, "")
+		lines = append(lines, normalStyle.Render(title))
+		for i, commit := range commits {
+			if i == limit {
+				lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  ... %d more", len(commits)-limit)))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s %s",
+				lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(commit.Hash),
+				commit.Message, helpStyle.Render("("+commit.Author+", "+commit.Date+")")))
+		}
+	}
+	section(fmt.Sprintf("Only on %s (%d):", div.Branch, len(div.Local)), div.Local, max(1, room/2))
+	section(fmt.Sprintf("Only on %s (%d):", div.Upstream, len(div.Remote)), div.Remote, max(1, room-room/2))
+
+	lines = append(lines, "")
+	lines = append(lines, keyBindStyle.Render("r")+normalStyle.Render(" Rebase onto upstream (linear history)"))
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("    Replays your %d commit(s) on top of %s. Rewrites only unpushed commits;", len(div.Local), div.Upstream)))
+	lines = append(lines, helpStyle.Render("    conflicts open in the conflicts view."))
+	lines = append(lines, "")
+	lines = append(lines, keyBindStyle.Render("m")+normalStyle.Render(" Merge upstream (keeps both histories)"))
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("    Adds a merge commit joining %s into %s. Nothing is rewritten.", div.Upstream, div.Branch)))
+	lines = append(lines, "")
+	if m.config.Branches.IsProtected(div.Upstream) {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("f Force-push is disabled: %s is protected", div.Upstream)))
+	} else {
+		lines = append(lines, keyBindStyle.Render("f")+normalStyle.Render(" Force-push with lease (discards upstream commits)"))
+		lines = append(lines, warningStyle.Render(fmt.Sprintf("    Drops the %d commit(s) only on %s.", len(div.Remote), div.Upstream))+
+			helpStyle.Render(" Refused if someone pushed since this preview."))
+	}
+	lines = append(lines, "")
+	lines = append(lines, keyBindStyle.Render("g")+helpStyle.Render(" fetch and compare again"))
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderJobsContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Jobs"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	list := m.jobs.Jobs()
+	if len(list) == 0 {
+		lines = append(lines, helpStyle.Render("No background operations yet."))
+	}
+
+	// Newest first, limited to the room available
+	room := max(1, height-len(lines)-3)
+	for i := len(list) - 1; i >= 0 && len(list)-1-i < room; i-- {
+		job := list[i]
+		var state string
+		switch job.State {
+		case jobs.Queued:
+			state = warningStyle.Render("… queued ")
+		case jobs.Running:
+			state = m.spinner.View() + " running"
+		case jobs.Done:
+			state = successStyle.Render("✓ done   ")
+		default:
+			state = errorStyle.Render("✗ failed ")
+		}
+
+		kind := helpStyle.Render("read ")
+		if job.Write {
+			kind = keyDescStyle.Render("write")
+		}
+		line := fmt.Sprintf("  %s  %s  %s", state, kind, job.Name)
+		if elapsed := job.Elapsed(); elapsed > 0 {
+			line += helpStyle.Render(fmt.Sprintf("  %.1fs", elapsed.Seconds()))
+		}
+		lines = append(lines, line)
+		if job.Err != "" {
+			first, _, _ := strings.Cut(job.Err, "\n")
+			lines = append(lines, errorStyle.MaxWidth(width-6).Render("      "+first))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("Write jobs run one at a time in order; reads run alongside them."))
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderMessagesContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Messages"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if len(m.statusLog) == 0 {
+		lines = append(lines, helpStyle.Render("No status messages yet."))
+	}
+
+	// Newest first, limited to the room available
+	room := max(1, height-len(lines)-3)
+	for i := len(m.statusLog) - 1; i >= 0 && len(m.statusLog)-1-i < room; i-- {
+		entry := m.statusLog[i]
+		style, mark := normalStyle, " "
+		switch statusSeverity(entry.message) {
+		case "error":
+			style, mark = errorStyle, "✗"
+		case "warning":
+			style, mark = warningStyle, "!"
+		}
+		first, _, _ := strings.Cut(entry.message, "\n")
+		lines = append(lines, helpStyle.Render("  "+entry.at.Format("15:04:05")+"  ")+
+			style.MaxWidth(width-16).Render(mark+" "+first))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("The last %d messages; every git operation is under Jobs.", statusLogSize)))
+	return strings.Join(lines, "\n")
+}
+
+// statusSeverity guesses from its wording whether a status message reports
+// an "error", a "warning" or just "info"
+func statusSeverity(message string) string {
+	lower := strings.ToLower(message)
+	has := func(words ...string) bool {
+		return slices.ContainsFunc(words, func(word string) bool { return strings.Contains(lower, word) })
+	}
+	switch {
+	case has("failed", "error", "could not", "cannot", "can't"):
+		return "error"
+	case has("warning", "refus", "read-only", "protected", "conflict") || strings.HasPrefix(lower, "no "):
+		return "warning"
+	}
+	return "info"
+}
+
+func (m model) renderRebaseContent(width, height int) string {
+	if m.rebaseProgress != nil {
+		lines := m.renderRebaseProgress(width, height-2)
+		lines = append(lines, "", helpStyle.Render("enter opens the conflicts view to continue or abort it"))
+		return strings.Join(lines, "\n")
+	}
+
+	if m.rebaseInput.Focused() {
+		return "Enter number of commits: " + m.rebaseInput.View()
+	}
+
+	if len(m.rebaseCommits) == 0 {
+		return helpStyle.Render("Enter number of commits (1-50)")
+	}
+
+	columns := []listColumn{{}, {}, {min: 12, flex: true}}
+	var rows [][]string
+	for _, commit := range m.rebaseCommits {
+		action := commit.Action
+		if action == "" {
+			action = "pick"
+		}
+		rows = append(rows, []string{"[" + action + "]", commit.Hash, printable(commit.Message)})
+	}
+	widths := columnWidths(columns, rows, width-4)
+
+	var lines []string
+	for i, row := range rows {
+		line := renderColumns(columns, row, widths)
+
+		if i == m.rebaseCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("p=pick s=squash r=reword d=drop enter=execute"))
+
+	return strings.Join(lines, "\n")
+}
+
+// renderRebaseProgress shows where a stopped rebase is: the step and the
+// commit it stopped on, then as much of the remaining todo list as fits in
+// height lines
+func (m model) renderRebaseProgress(width, height int) []string {
+	progress := m.rebaseProgress
+	title := "Rebase in progress"
+	if progress.Branch != "" {
+		title = "Rebasing " + progress.Branch
+	}
+	if progress.Onto != "" {
+		title += " onto " + progress.Onto
+	}
+	lines := []string{sectionHeaderStyle.Render(title)}
+
+	step := fmt.Sprintf("Step %d of %d", progress.Step, progress.Total)
+	if progress.Current.Action != "" {
+		step += ": " + progress.Current.String()
+	}
+	lines = append(lines, warningStyle.MaxWidth(width-4).Render(step))
+
+	if len(progress.Todo) == 0 {
+		return append(lines, helpStyle.Render("Nothing left to replay after this step"))
+	}
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("Remaining (%d):", len(progress.Todo))))
+	todo := progress.Todo
+	room := max(1, height-len(lines))
+	if len(todo) > room {
+		todo = todo[:room-1]
+	}
+	for _, next := range todo {
+		lines = append(lines, normalStyle.MaxWidth(width-4).Render("  "+next.String()))
+	}
+	if len(todo) < len(progress.Todo) {
+		lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  ... %d more", len(progress.Todo)-len(todo))))
+	}
+	return lines
+}
+
+func (m model) renderHistoryList(width, height int) string {
+	if len(m.commits) == 0 {
+		if m.loading["history"] {
+			return m.renderLoading("Loading history...")
+		}
+		return helpStyle.Render("No history")
+	}
+
+	// Which branch, and what is marked
+	var header []string
+	if m.historyRef != "" {
+		header = append(header, sectionHeaderStyle.Render("History of "+m.historyRef)+
+			helpStyle.Render(fmt.Sprintf("  %s cherry-picks onto %s", m.keys.keyFor(ctxHistory, "cherry-pick"), m.gitState.Branch)))
+	}
+	if m.historyAuthor != "" {
+		header = append(header, sectionHeaderStyle.Render("Commits by "+m.historyAuthor)+
+			helpStyle.Render("  esc returns to the contributors"))
+	}
+	if m.historyCursor < len(m.commits) && redated(m.commits[m.historyCursor]) {
+		c := m.commits[m.historyCursor]
+		header = append(header, helpStyle.Render(ansi.Truncate(fmt.Sprintf("* %s written %s, committed %s",
+			c.Hash, m.formatDate(c.Date, c.Authored), m.formatDate(c.CommitDate, c.Committed)), width-6, "…")))
+	}
+	if m.historyMarked {
+		newest, oldest := m.historyRange()
+		header = append(header, warningStyle.Render(fmt.Sprintf("%d commit(s) marked", oldest-newest+1))+
+			helpStyle.Render(" - move to extend, esc to clear"))
+	}
+
+	maxItems := height - 2 - len(header)
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.historyOffset > 0
+	hasBottom := m.historyOffset+maxItems < len(m.commits)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	lines := header
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("more above..."))
+	}
+
+	endIdx := m.historyOffset + maxItems
+	if endIdx > len(m.commits) {
+		endIdx = len(m.commits)
+	}
+
+	// Hash, refs and subject, author, date, signature
+	columns := []listColumn{{}, {min: 12, flex: true}, {min: 8, max: 20}, {min: 12, max: 17}, {}}
+	var rows [][]string
+	for _, commit := range m.commits[m.historyOffset:endIdx] {
+		message := printable(commit.Message)
+		if len(commit.Branches)+len(commit.Tags) > 0 {
+			message = m.renderRefs(commit) + " " + message
+		}
+		var sig string
+		if s, ok := m.commitSigs[commit.Hash]; ok {
+			sig = signatureBadge(s)
+		}
+		rows = append(rows, []string{
+			lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(commit.Hash),
+			message, helpStyle.Render(printable(commit.Author)), helpStyle.Render(m.commitDate(commit)), sig,
+		})
+	}
+	// The panel's padding leaves width+2 for a row, the mark's two cells
+	// included
+	widths := columnWidths(columns, rows, width)
+
+	newest, oldest := m.historyRange()
+	for i := m.historyOffset; i < endIdx; i++ {
+		mark := "  "
+		if m.historyMarked && i >= newest && i <= oldest {
+			mark = warningStyle.Render("▌ ")
+		}
+		line := mark + renderColumns(columns, rows[i-m.historyOffset], widths)
+
+		if i == m.historyCursor {
+			lines = append(lines, selectedStyle.Width(width+2).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("more below..."))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderRefs renders the branches and tags pointing at commit like git log's
+// decorations, coloured as on the branches tab
+func (m model) renderRefs(commit git.Commit) string {
+	var refs []string
+	for _, name := range commit.Branches {
+		style := normalStyle
+		switch {
+		case name == m.gitState.Branch:
+			style = branchCurrentStyle
+		case slices.ContainsFunc(m.branches, func(b git.Branch) bool { return b.Name == name && b.IsRemote }):
+			style = branchRemoteStyle
+		}
+		refs = append(refs, style.Render(name))
+	}
+	for _, name := range commit.Tags {
+		refs = append(refs, warningStyle.Render("tag: "+name))
+	}
+	return helpStyle.Render("(") + strings.Join(refs, helpStyle.Render(", ")) + helpStyle.Render(")")
+}
+
+func (m model) renderRemoteContent(width, height int) string {
+	if m.pushOutput != "" {
+		return m.pushOutput
+	}
+
+	status := m.gitState
+	push := fmt.Sprintf("[p] Push to origin (%d ahead)", status.Ahead)
+	pull := fmt.Sprintf("[l] Pull from origin (%d behind)", status.Behind)
+	switch {
+	case status.UpstreamGone:
+		push = fmt.Sprintf("[p] Push to origin (%s no longer exists)", status.Upstream)
+		pull = fmt.Sprintf("[l] Pull from origin (%s no longer exists)", status.Upstream)
+	case m.needsPublish():
+		push = fmt.Sprintf("[p] Publish to %s (push -u, no upstream yet)", m.pushRemote())
+		pull = "[l] Pull from origin (no upstream yet)"
+	}
+	fetch := "[f] Fetch from origin"
+	if !status.LastFetch.IsZero() {
+		fetch += helpStyle.Render("  last fetched " + formatAgo(since(status.LastFetch)))
+	}
+
+	// The action preselected from the status bar waits for one more press
+	mark := func(line, action string) string {
+		if m.confirmAction == action {
+			return selectedStyle.Render("▶ " + line)
+		}
+		return "  " + line
+	}
+
+	lines := []string{
+		sectionHeaderStyle.Render("Remote"),
+		helpStyle.Render(strings.Repeat("─", width-6)),
+		mark(push, "push"),
+		"  " + fetch,
+		mark(pull, "pull"),
+	}
+	if behind := m.behindBranches(); len(behind) > 0 {
+		lines = append(lines, "  "+fmt.Sprintf("[F] Fast-forward %s (strictly behind)", strings.Join(behind, ", ")))
+	}
+
+	switch {
+	case m.upstreamErr != "":
+		lines = append(lines, "", helpStyle.Render("  "+m.upstreamErr+" - the first push sets one"))
+	case m.upstream != nil:
+		div := m.upstream
+		lines = append(lines, "")
+		lines = append(lines, m.renderTransferList(fmt.Sprintf("Outgoing to %s", div.Upstream), "@{u}..HEAD", div.Local, width)...)
+		lines = append(lines, "")
+		lines = append(lines, m.renderTransferList(fmt.Sprintf("Incoming from %s", div.Upstream), "HEAD..@{u}", div.Remote, width)...)
+	}
+
+	if len(m.fetchResults) > 0 {
+		lines = append(lines, "", normalStyle.Render("Last fetch (all remotes, pruned):"))
+		for _, result := range m.fetchResults {
+			var summary string
+			switch {
+			case result.Err != "":
+				summary = errorStyle.Render("✗ " + result.Err)
+			case result.Changed():
+				summary = successStyle.Render(fmt.Sprintf("✓ %d new, %d updated, %d pruned", result.New, result.Updated, result.Pruned))
+			default:
+				summary = helpStyle.Render("✓ up to date")
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s", branchRemoteStyle.Render(result.Remote), summary))
+		}
+	}
+
+	var tracking []string
+	for _, branch := range m.branches {
+		if branch.IsRemote || branch.Upstream == "" {
+			continue
+		}
+		counts := helpStyle.Render("in sync")
+		if branch.Gone {
+			counts = warningStyle.Render("gone")
+		} else if branch.Ahead > 0 || branch.Behind > 0 {
+			counts = branchAheadStyle.Render(fmt.Sprintf("↑%d", branch.Ahead)) + " " + branchBehindStyle.Render(fmt.Sprintf("↓%d", branch.Behind))
+		}
+		tracking = append(tracking, fmt.Sprintf("  %s → %s %s", branch.Name, branchRemoteStyle.Render(branch.Upstream), counts))
+	}
+	if len(tracking) > 0 {
+		lines = append(lines, "", normalStyle.Render("Tracking branches:"))
+		room := max(1, height-len(lines)-1)
+		if len(tracking) > room {
+			tracking = append(tracking[:room-1:room-1], scrollIndicatorStyle.Render(fmt.Sprintf("  ... %d more", len(tracking)-room+1)))
+		}
+		lines = append(lines, tracking...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTransferList shows the commits a push or pull would move, as a
+// table of hash, subject, author and date
+func (m model) renderTransferList(title, revRange string, commits []git.Commit, width int) []string {
+	const maxRows = 8
+	lines := []string{normalStyle.Render(fmt.Sprintf("%s (%d)", title, len(commits))) + helpStyle.Render("  "+revRange)}
+	if len(commits) == 0 {
+		return append(lines, helpStyle.Render("  nothing"))
+	}
+
+	shown := commits[:min(len(commits), maxRows)]
+	columns := []listColumn{{}, {min: 10, flex: true}, {min: 8, max: 20}, {min: 12, max: 17}}
+	var rows [][]string
+	for _, commit := range shown {
+		rows = append(rows, []string{
+			lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(commit.Hash),
+			printable(commit.Message), helpStyle.Render(printable(commit.Author)), helpStyle.Render(m.commitDate(commit)),
+		})
+	}
+	widths := columnWidths(columns, rows, width-6)
+	for _, row := range rows {
+		lines = append(lines, "  "+renderColumns(columns, row, widths))
+	}
+	if len(commits) > maxRows {
+		lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  … %d more", len(commits)-maxRows)))
+	}
+	return lines
+}
+
+// stashListRows is how many stashes fit above the diff preview
+func (m model) stashListRows(height int) int {
+	return max(3, (height-4)/3)
+}
+
+func (m model) renderStashList(width, height int) string {
+	k := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxStash, action)) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Stash List")
+	help := k("stash") + d(": stash") + sep + k("pop") + d(": pop") + sep +
+		k("apply") + d(": apply") + sep + k("files") + d(": apply files") + sep +
+		k("branch") + d(": to branch") + sep + k("drop") + d(": drop")
+
+	if len(m.stashes) == 0 {
+		return header + "\n" + helpStyle.Render(strings.Repeat("─", width-6)) + "\n\n" +
+			helpStyle.Render(fmt.Sprintf("No stashes. Press '%s' to stash current changes.", m.keys.keyFor(ctxStash, "stash"))) + "\n\n" + help
+	}
+
+	if m.stashFiles != nil {
+		return m.renderStashFilePicker(width, height)
+	}
+
+	maxItems := min(len(m.stashes), m.stashListRows(height))
+
+	hasTop := m.stashOffset > 0
+	hasBottom := m.stashOffset+maxItems < len(m.stashes)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+
+	endIdx := m.stashOffset + maxItems
+	if endIdx > len(m.stashes) {
+		endIdx = len(m.stashes)
+	}
+
+	columns := []listColumn{{}, {min: 12, flex: true}, {min: 12, max: 16}}
+	var rows [][]string
+	for _, stash := range m.stashes[m.stashOffset:endIdx] {
+		rows = append(rows, []string{fmt.Sprintf("stash@{%d}:", stash.Index), printable(stash.Message), helpStyle.Render(stash.Date)})
+	}
+	widths := columnWidths(columns, rows, width-8)
+
+	for i := m.stashOffset; i < endIdx; i++ {
+		line := " 📦 " + renderColumns(columns, rows[i-m.stashOffset], widths)
+
+		if i == m.stashCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	if m.stashBranchInput.Focused() {
+		lines = append(lines, "", warningStyle.Render(fmt.Sprintf("New branch from stash@{%d}:", m.stashCursor)), m.stashBranchInput.View())
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	// Diff preview of the selected stash fills the rest
+	if room := height - len(lines) - 3; room > 0 && m.stashDiff != "" {
+		diff := strings.Split(strings.TrimRight(m.stashDiff, "\n"), "\n")
+		offset := min(m.stashDiffOffset, len(diff)-1)
+		end := min(len(diff), offset+room)
+		lines = append(lines, "", sectionHeaderStyle.Render(fmt.Sprintf("stash@{%d} diff", m.stashCursor))+
+			helpStyle.Render(fmt.Sprintf("  (lines %d-%d of %d)", offset+1, end, len(diff))))
+		for _, line := range diff[offset:end] {
+			lines = append(lines, colorizeDiffLine(line))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderSnapshotsContent lists WIP snapshots, newest first, above a diff of
+// the selected one
+// excludeListRows is how many exclude lines fit, leaving room for the
+// header, the pattern input and help
+func (m model) excludeListRows(height int) int {
+	return max(3, height-10)
+}
+
+func (m model) renderExcludeContent(width, height int) string {
+	k := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxExclude, action)) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Exclude") + helpStyle.Render("  "+git.ExcludeFile(m.repoPath))
+	help := k("new") + d(": add pattern") + sep + k("edit") + d(": edit") + sep + k("delete") + d(": delete") + sep + k("back") + d(": back")
+
+	lines := []string{header, helpStyle.Render(strings.Repeat("─", width-6)),
+		helpStyle.Render("Patterns here are ignored like .gitignore, but only in this clone.")}
+	if len(m.excludeLines) == 0 {
+		lines = append(lines, "", helpStyle.Render(fmt.Sprintf("No local excludes. Press '%s' to add a pattern.", m.keys.keyFor(ctxExclude, "new"))))
+	} else {
+		lines = append(lines, "")
+		maxItems := min(len(m.excludeLines), m.excludeListRows(height))
+		end := min(len(m.excludeLines), m.excludeOffset+maxItems)
+		if m.excludeOffset > 0 {
+			lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+		}
+		for i := m.excludeOffset; i < end; i++ {
+			line := " " + m.excludeLines[i]
+			if trimmed := strings.TrimSpace(m.excludeLines[i]); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				line = helpStyle.Render(line)
+			}
+			if i == m.excludeCursor {
+				line = selectedStyle.Width(width - 4).Render(" " + m.excludeLines[i])
+			}
+			lines = append(lines, line)
+		}
+		if end < len(m.excludeLines) {
+			lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+		}
+	}
+
+	if m.excludeInput.Focused() {
+		title := "Add pattern:"
+		if m.excludeEdit >= 0 {
+			title = fmt.Sprintf("Edit line %d:", m.excludeEdit+1)
+		}
+		lines = append(lines, "", warningStyle.Render(title), m.excludeInput.View())
+		input := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxExcludeInput, action)) }
+		help = input("save") + d(": save") + sep + input("cancel") + d(": cancel")
+	}
+	lines = append(lines, "", help)
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderContributorsContent(width, height int) string {
+	k := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxContributors, action)) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Contributors") + helpStyle.Render("  "+m.gitState.Branch)
+	help := k("history") + d(": their commits") + sep + k("back") + d(": back")
+
+	lines := []string{header, helpStyle.Render(strings.Repeat("─", width-6))}
+	if len(m.contributors) == 0 {
+		if m.loading["contributors"] {
+			return m.renderLoading("Loading contributors...")
+		}
+		lines = append(lines, helpStyle.Render("No commits yet."), "", help)
+		return strings.Join(lines, "\n")
+	}
+
+	total := 0
+	nameWidth := 0
+	for _, c := range m.contributors {
+		total += c.Commits
+		nameWidth = max(nameWidth, lipgloss.Width(c.Name))
+	}
+	nameWidth = min(nameWidth, max(10, width/3))
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("%d author(s), %d commit(s), counted with .mailmap", len(m.contributors), total)), "")
+
+	maxItems := min(len(m.contributors), m.excludeListRows(height))
+	end := min(len(m.contributors), m.contributorOffset+maxItems)
+	if m.contributorOffset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	for i := m.contributorOffset; i < end; i++ {
+		c := m.contributors[i]
+		name := lipgloss.NewStyle().Width(nameWidth).MaxWidth(nameWidth).Render(c.Name)
+		last := ""
+		if !c.LastActive.IsZero() {
+			last = "last " + formatAgo(since(c.LastActive))
+		}
+		line := fmt.Sprintf(" %5d  %s  %s", c.Commits, name, helpStyle.Render(fmt.Sprintf("%-12s <%s>", last, c.Email)))
+		if i == m.contributorCursor {
+			line = selectedStyle.Width(width - 4).Render(fmt.Sprintf(" %5d  %s  %-12s <%s>", c.Commits, name, last, c.Email))
+		}
+		lines = append(lines, line)
+	}
+	if end < len(m.contributors) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	lines = append(lines, "", help)
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderSnapshotsContent(width, height int) string {
+	k := func(action string) string { return keyBindStyle.Render(m.keys.keyFor(ctxSnapshots, action)) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Snapshots")
+	help := k("restore") + d(": restore") + sep + k("snapshot") + d(": snapshot now") + sep + k("delete") + d(": delete")
+
+	status := "Automatic snapshots are off; set [snapshots] interval_minutes in the config to turn them on."
+	if minutes := m.config.Snapshot.IntervalMinutes; minutes > 0 {
+		status = fmt.Sprintf("Uncommitted changes to tracked files are snapshotted every %d min (keeping %d).", minutes, m.config.Snapshot.Keep)
+	}
+
+	lines := []string{header, helpStyle.Render(strings.Repeat("─", width-6)), helpStyle.Render(status)}
+	if len(m.snapshots) == 0 {
+		lines = append(lines, "", helpStyle.Render(fmt.Sprintf("No snapshots. Press '%s' to snapshot current changes.", m.keys.keyFor(ctxSnapshots, "snapshot"))), "", help)
+		return strings.Join(lines, "\n")
+	}
+
+	maxItems := min(len(m.snapshots), m.stashListRows(height))
+	hasTop := m.snapshotOffset > 0
+	hasBottom := m.snapshotOffset+maxItems < len(m.snapshots)
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	end := min(len(m.snapshots), m.snapshotOffset+maxItems)
+	for i := m.snapshotOffset; i < end; i++ {
+		snap := m.snapshots[i]
+		line := fmt.Sprintf(" 🕓 %s  %s  %s",
+			snap.Time.Format("Jan 2 15:04"),
+			snap.Message,
+			helpStyle.Render(formatAgo(since(snap.Time))))
+		if i == m.snapshotCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	lines = append(lines, "", help)
+
+	if room := height - len(lines) - 3; room > 0 && m.snapshotDiff != "" {
+		diff := strings.Split(strings.TrimRight(m.snapshotDiff, "\n"), "\n")
+		offset := min(m.snapshotDiffOffset, len(diff)-1)
+		end := min(len(diff), offset+room)
+		lines = append(lines, "", sectionHeaderStyle.Render("Snapshot diff")+
+			helpStyle.Render(fmt.Sprintf("  (lines %d-%d of %d)", offset+1, end, len(diff))))
+		for _, line := range diff[offset:end] {
+			lines = append(lines, colorizeDiffLine(line))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderStashFilePicker lists the selected stash's files to apply some of
+func (m model) renderStashFilePicker(width, height int) string {
+	lines := []string{
+		sectionHeaderStyle.Render(fmt.Sprintf("Apply files from stash@{%d} (%d/%d)",
+			m.stashCursor, len(m.stashFileSelection()), len(m.stashFiles))),
+		helpStyle.Render(strings.Repeat("─", width-6)),
+	}
+
+	visible := max(1, height-len(lines)-2)
+	offset := 0
+	if m.stashFileCursor >= visible {
+		offset = m.stashFileCursor - visible + 1
+	}
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	end := min(len(m.stashFiles), offset+visible)
+	for i := offset; i < end; i++ {
+		file := m.stashFiles[i]
+		check := "[ ]"
+		if m.stashFileSelected[file.Path] {
+			check = iconStagedStyle.Render("[✓]")
+		}
+		line := fmt.Sprintf("%s %s %s", check, file.Status, file.Path)
+		if i == m.stashFileCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+	if end < len(m.stashFiles) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderReleasePreview lists what a new tag would release since the last one
+func (m model) renderReleasePreview(height int) string {
+	since := m.releasePreview
+	if since == nil {
+		return ""
+	}
+	lines := []string{"", "",
+		normalStyle.Render(fmt.Sprintf("Since %s: %d commit(s), %d file(s) changed",
+			since.TargetBranch, len(since.AheadCommits), len(since.DifferingFiles)))}
+	room := max(1, height-3)
+	for i, commit := range since.AheadCommits {
+		if i == room {
+			lines = append(lines, scrollIndicatorStyle.Render(fmt.Sprintf("  … %d more", len(since.AheadCommits)-room)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s",
+			lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(commit.Hash), printable(commit.Message)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderTagsList(width, height int) string {
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Tags")
+	help := k("n") + d(": new tag") + sep + k("d") + d(": delete") + sep +
+		k("p") + d(": push tag") + sep + k("P") + d(": push all") + sep + k("v") + d(": verify") + sep +
+		k("c") + d(": changes since")
+
+	if m.tagInput.Focused() {
+		return header + "\n" + helpStyle.Render(strings.Repeat("─", width-6)) + "\n\n" +
+			"Create new tag:\n" + m.tagInput.View() + m.renderReleasePreview(height-6)
+	}
+
+	if len(m.tags) == 0 {
+		return header + "\n" + helpStyle.Render(strings.Repeat("─", width-6)) + "\n\n" +
+			helpStyle.Render("No tags. Press 'n' to create a new tag.") + "\n\n" + help
+	}
+
+	maxItems := height - 4
+	if m.tagCursor < len(m.tags) {
+		if _, ok := m.tagSigs[m.tags[m.tagCursor].Name]; ok {
+			maxItems -= 3
+		}
+	}
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.tagOffset > 0
+	hasBottom := m.tagOffset+maxItems < len(m.tags)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+
+	endIdx := m.tagOffset + maxItems
+	if endIdx > len(m.tags) {
+		endIdx = len(m.tags)
+	}
+
+	for i := m.tagOffset; i < endIdx; i++ {
+		tag := m.tags[i]
+		icon := "🏷️"
+		if tag.IsAnnotated {
+			icon = "📝"
+		}
+
+		commitInfo := ""
+		if tag.Commit != "" {
+			commitInfo = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(" " + tag.Commit[:7])
+		}
+
+		line := fmt.Sprintf(" %s %s%s  %s",
+			icon,
+			tag.Name,
+			commitInfo,
+			helpStyle.Render(tag.Date))
+		if sigs, ok := m.tagSigs[tag.Name]; ok {
+			sig := sigs.commit
+			if sigs.tag != nil {
+				sig = *sigs.tag
+			}
+			line += "  " + signatureBadge(sig)
+		}
+
+		if i == m.tagCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	// Full verification of the selected tag, for auditing a release
+	if m.tagCursor < len(m.tags) {
+		tag := m.tags[m.tagCursor]
+		if sigs, ok := m.tagSigs[tag.Name]; ok {
+			lines = append(lines, "")
+			if sigs.tag != nil {
+				lines = append(lines, fmt.Sprintf(" Tag %s: %s", tag.Name, describeSignature(*sigs.tag)))
+			} else {
+				lines = append(lines, helpStyle.Render(fmt.Sprintf(" Tag %s is lightweight and cannot be signed", tag.Name)))
+			}
+			lines = append(lines, fmt.Sprintf(" Commit %s: %s", tag.Commit[:7], describeSignature(sigs.commit)))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	return strings.Join(lines, "\n")
+}
+
+// signatureBadge is a short coloured marker for a checked signature
+func signatureBadge(sig git.Signature) string {
+	switch sig.Status {
+	case "G":
+		return successStyle.Render("✓ " + sig.Signer)
+	case "U":
+		return warningStyle.Render("? untrusted")
+	case "N":
+		return helpStyle.Render("unsigned")
+	case "E":
+		return warningStyle.Render("? unchecked")
+	default:
+		return errorStyle.Render("✗ " + sig.Describe())
+	}
+}
+
+// describeSignature spells out signer, key and trust of a checked signature
+func describeSignature(sig git.Signature) string {
+	text := sig.Describe()
+	if sig.Signer != "" {
+		text += " by " + sig.Signer
+	}
+	var details []string
+	if sig.Key != "" {
+		details = append(details, "key "+sig.Key)
+	}
+	if sig.Trust != "" {
+		details = append(details, "trust: "+sig.Trust)
+	}
+	if sig.Detail != "" {
+		details = append(details, sig.Detail)
+	}
+	if len(details) > 0 {
+		text += " (" + strings.Join(details, ", ") + ")"
+	}
+	return text
+}
+
+func (m model) renderHooksContent(width, height int) string {
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Git Hooks")
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "")
+
+	// Available hooks
+	hooks := []struct {
+		name      string
+		desc      string
+		installed bool
+		key       string
+	}{
+		{"Conventional Commits", "Enforce commit message format", m.commitMsgHookInstalled, "1"},
+		{"No Large Files", "Block files >5MB", m.preCommitHookInstalled, "2"},
+		{"Detect Secrets", "Block passwords/API keys", m.preCommitHookInstalled, "3"},
+	}
+
+	for i, hook := range hooks {
+		status := warningStyle.Render("○")
+		if hook.installed {
+			status = successStyle.Render("●")
+		}
+
+		line := fmt.Sprintf(" %s [%s] %s  %s", status, hook.key, hook.name, helpStyle.Render(hook.desc))
+		if i == m.hookCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "")
+
+	// Help text
+	help := k("1/2/3") + d(": install") + sep + k("r") + d(": remove selected") + sep + k("j/k") + d(": nav")
+	lines = append(lines, help)
+
+	return strings.Join(lines, "\n")
+}
+
+// Helper functions
+
+// clock is the time ages are measured against; golden renders pin it so
+// their output doesn't drift
+var clock = time.Now
+
+func since(t time.Time) time.Duration {
+	return clock().Sub(t)
+}
+
+// absoluteDate is how commit lists write dates when absolute dates are on:
+// ISO 8601 order, in the local time zone
+const absoluteDate = "2006-01-02 15:04"
+
+// formatDate is git's relative date ("2 days ago"), or t in local time
+// when absolute dates are on
+func (m model) formatDate(relative string, t time.Time) string {
+	if m.absoluteDates && !t.IsZero() {
+		return t.Local().Format(absoluteDate)
+	}
+	return relative
+}
+
+// commitDate is the date column of the commit lists: when the commit was
+// written, with a * when it was committed at another time (amended,
+// rebased or cherry-picked)
+func (m model) commitDate(commit git.Commit) string {
+	date := m.formatDate(commit.Date, commit.Authored)
+	if redated(commit) {
+		date += "*"
+	}
+	return date
+}
+
+// redated reports whether a commit's committer date differs from its
+// author date
+func redated(commit git.Commit) bool {
+	return !commit.Committed.IsZero() && !commit.Committed.Equal(commit.Authored)
+}
+
+// formatAgo renders a duration the way git's relative dates read
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// repoKind labels repositories that are not a plain main checkout
+func (m model) repoKind() string {
+	switch {
+	case m.bare:
+		return " (bare)"
+	case m.gitDir != m.commonDir:
+		return " (worktree)"
+	}
+	return ""
+}
+
+// launchSubdir is the launch directory relative to the repo root, or "" when
+// gitty was started at the root (or the repo has since been switched)
+func (m model) launchSubdir() string {
+	rel, err := filepath.Rel(m.repoPath, m.launchDir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return rel
+}
+
+// displayPath shows a repo-relative path relative to the launch directory,
+// the way git status does when run from a subdirectory
+func (m model) displayPath(path string) string {
+	if m.launchSubdir() == "" {
+		return printable(path)
+	}
+	rel, err := filepath.Rel(m.launchDir, filepath.Join(m.repoPath, path))
+	if err != nil {
+		return printable(path)
+	}
+	return printable(rel)
+}
+
+// fitPath shortens a path to at most width terminal cells by cutting into
+// its directories, so the file name stays readable: "internal/gi…/parse.go".
+// Wide characters (CJK, most emoji) take two cells.
+func fitPath(path string, width int) string {
+	if lipgloss.Width(path) <= width {
+		return path
+	}
+	if width <= 1 {
+		return headCells("…", width)
+	}
+	dir, base := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, base = path[:i], path[i:]
+	}
+	if room := width - lipgloss.Width(base) - 1; dir != "" && room >= 0 {
+		return headCells(dir, room) + "…" + base
+	}
+	// Not even the file name fits: keep its end, extension included
+	return "…" + tailCells(strings.TrimPrefix(base, "/"), width-1)
+}
+
+// listColumn is a column of a list like the log: the bounds on its width
+// and whether it takes the room the others leave
+type listColumn struct {
+	min, max int  // cells; min 0 is never cut, only dropped; max 0 is no limit
+	flex     bool // the message: gets the slack, and gives way last
+}
+
+// columnGap is the space before a column: one, or two to set what follows
+// the message (author, date, ...) apart from it
+func columnGap(columns []listColumn, i int) int {
+	if flex := slices.IndexFunc(columns, func(c listColumn) bool { return c.flex }); flex >= 0 && i > flex {
+		return 2
+	}
+	return 1
+}
+
+// columnWidths sizes columns to fit rows of cells into width. Each column
+// is as wide as its widest cell within its bounds and the flex column gets
+// whatever is left. On a narrow terminal the flex column keeps up to a
+// third of the width: the others shrink to their minimum, then drop out,
+// starting with the one after it (the author before the date).
+func columnWidths(columns []listColumn, rows [][]string, width int) []int {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		for _, row := range rows {
+			widths[i] = max(widths[i], lipgloss.Width(row[i]))
+		}
+		if column.max > 0 {
+			widths[i] = min(widths[i], column.max)
+		}
+	}
+
+	flex := slices.IndexFunc(columns, func(c listColumn) bool { return c.flex })
+	// The space taken by all but the flex column, and the gaps before every
+	// column renderColumns writes but the first
+	used := func() int {
+		total, first := 0, true
+		for i, w := range widths {
+			if w == 0 && i != flex {
+				continue
+			}
+			if !first {
+				total += columnGap(columns, i)
+			}
+			first = false
+			if i != flex {
+				total += w
+			}
+		}
+		return total
+	}
+	if flex < 0 {
+		return widths
+	}
+	var order []int
+	for i := flex + 1; i < len(columns); i++ {
+		order = append(order, i)
+	}
+	for i := flex - 1; i >= 0; i-- {
+		order = append(order, i)
+	}
+	want := min(widths[flex], max(columns[flex].min, width/3))
+	for _, i := range order {
+		if short := want - (width - used()); short > 0 && widths[i] > 0 && columns[i].min > 0 {
+			widths[i] = max(columns[i].min, widths[i]-short)
+		}
+	}
+	for _, i := range order {
+		if width-used() < want {
+			widths[i] = 0
+		}
+	}
+	widths[flex] = max(0, min(widths[flex], width-used()))
+	return widths
+}
+
+// renderColumns lays out a row of cells at widths from columnWidths,
+// cutting long cells with … and padding short ones so the next column
+// lines up. Cells may be styled.
+func renderColumns(columns []listColumn, cells []string, widths []int) string {
+	var row strings.Builder
+	for i, cell := range cells {
+		if widths[i] == 0 {
+			continue
+		}
+		if lipgloss.Width(cell) > widths[i] {
+			cell = ansi.Truncate(cell, widths[i], "…")
+		}
+		if row.Len() > 0 {
+			row.WriteString(strings.Repeat(" ", columnGap(columns, i)))
+		}
+		row.WriteString(cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell)))
+	}
+	return strings.TrimRight(row.String(), " ")
+}
+
+// fitRename fits "old → new" into width, shortening the old path first
+func fitRename(oldPath, newPath string, width int) string {
+	if full := oldPath + " → " + newPath; lipgloss.Width(full) <= width {
+		return full
+	}
+	old := fitPath(oldPath, max(width/3, width-3-lipgloss.Width(newPath)))
+	return old + " → " + fitPath(newPath, width-3-lipgloss.Width(old))
+}
+
+// headCells keeps as much of the start of text as fits in width cells
+func headCells(text string, width int) string {
+	used := 0
+	for i, r := range text {
+		if used += lipgloss.Width(string(r)); used > width {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// tailCells keeps as much of the end of text as fits in width cells
+func tailCells(text string, width int) string {
+	runes := []rune(text)
+	used := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		if used += lipgloss.Width(string(runes[i])); used > width {
+			return string(runes[i+1:])
+		}
+	}
+	return text
+}
+
+// printable makes text from the repository safe to draw. Bytes that are
+// not UTF-8 are read as Windows-1252, the usual legacy encoding, and control
+// characters other than tab show as their Unicode symbols (␍, ␛, ...) so
+// they can neither move the cursor nor restyle the terminal.
+func printable(text string) string {
+	if !utf8.ValidString(text) {
+		if decoded, err := charmap.Windows1252.NewDecoder().String(text); err == nil {
+			text = decoded
+		}
+	}
+	if !strings.ContainsFunc(text, isControl) {
+		return text
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == 0x7f:
+			return '␡'
+		case r < 0x20 && r != '\t':
+			return 0x2400 + r
+		case isControl(r):
+			return utf8.RuneError
+		}
+		return r
+	}, text)
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 && r != '\t' || r >= 0x7f && r < 0xa0
+}
+
+// renderDiffBreadcrumb shows which file and hunk the top of the diff view is in
+func (m model) renderDiffBreadcrumb(lines []string, width int) string {
+	crumb := "diff"
+	if m.fileCursor < len(m.changes) {
+		crumb = fmt.Sprintf("%s (%d/%d)", m.displayPath(m.changes[m.fileCursor].File), m.fileCursor+1, len(m.changes))
+	}
+
+	if flags := m.diffOptions.Args(); len(flags) > 0 {
+		crumb += " [" + strings.Join(flags, " ") + "]"
+	}
+
+	hunks := diffHunkStarts(m.diffContent)
+	current := -1
+	for i, start := range hunks {
+		if start <= m.scrollOffset {
+			current = i
+		}
+	}
+	if len(hunks) > 0 {
+		if current < 0 {
+			crumb += fmt.Sprintf(" > header, %d hunks", len(hunks))
+		} else {
+			crumb += fmt.Sprintf(" > hunk %d/%d  %s", current+1, len(hunks), lines[hunks[current]])
+		}
+	}
+
+	if maxWidth := width - 4; maxWidth > 3 && lipgloss.Width(crumb) > maxWidth {
+		runes := []rune(crumb)
+		crumb = string(runes[:min(len(runes), maxWidth-3)]) + "..."
+	}
+	return sectionHeaderStyle.Render(crumb)
+}
+
+// diffHunkStarts returns the line indexes of the @@ hunk headers in a diff
+func diffHunkStarts(diff string) []int {
+	var starts []int
+	for i, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func colorizeDiffLine(line string) string {
+	line = printable(line)
+	if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+		return diffAddStyle.Render(line)
+	}
+	if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
+		return diffRemoveStyle.Render(line)
+	}
+	if strings.HasPrefix(line, "@@") {
+		return diffHunkStyle.Render(line)
+	}
+	if strings.HasPrefix(line, "diff ") || strings.HasPrefix(line, "index ") ||
+		strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") ||
+		strings.HasPrefix(line, "similarity index") || strings.HasPrefix(line, "rename ") ||
+		strings.HasPrefix(line, "copy ") || strings.HasPrefix(line, "old mode ") ||
+		strings.HasPrefix(line, "new mode ") || strings.HasPrefix(line, "new file mode ") ||
+		strings.HasPrefix(line, "deleted file mode ") {
+		return diffHeaderStyle.Render(line)
+	}
+	return line
+}
+
+// modeNote flags a mode, type or symlink target change, which the status
+// letter alone shows as a plain modification; the unstaged side wins when
+// both changed
+func modeNote(change git.Change) string {
+	for _, staged := range []bool{false, true} {
+		if staged && change.Status[0] == ' ' || !staged && change.Status[1] == ' ' {
+			continue
+		}
+		if mc := change.ModeChange(staged); mc.Changed() {
+			return mc.Short()
+		} else if mc.Symlink() {
+			return "symlink"
+		}
+	}
+	return ""
+}
+
+func getStatusIcon(status string) string {
+	switch status {
+	case "M ", "T ":
+		return iconStagedStyle.Render("✓") // Modified or type changed (staged)
+	case "MM":
+		return iconStagedStyle.Render("✓") + iconUnstagedStyle.Render("●") // Both
+	case " M", " T":
+		return iconUnstagedStyle.Render("●") // Modified (unstaged)
+	case "A ":
+		return iconStagedStyle.Render("+") // Added (staged)
+	case "D ":
+		return iconDeletedStyle.Render("−") // Deleted (staged)
+	case " D":
+		return iconDeletedStyle.Render("×") // Deleted (unstaged)
+	case "R ":
+		return iconStagedStyle.Render("→") // Renamed
+	case "??":
+		return iconUntrackedStyle.Render("?") // Untracked
+	case "UU":
+		return iconConflictStyle.Render("⚠") // Conflict
+	default:
+		return " "
+	}
+}
+
+func getStatusIconParts(status string) (string, lipgloss.Color) {
+	switch status {
+	case "M ", "T ":
+		return "✓", colors.good
+	case "MM":
+		return "✓●", colors.good
+	case " M", " T":
+		return "●", colors.warn
+	case "A ":
+		return "+", colors.good
+	case "D ":
+		return "−", colors.bad
+	case " D":
+		return "×", colors.bad
+	case "R ":
+		return "→", colors.good
+	case "??":
+		return "?", lipgloss.Color("245")
+	case "UU":
+		return "⚠", colors.bad
+	default:
+		return " ", lipgloss.Color("252")
+	}
+}
+
+// Log viewer
+
+func (m model) renderLogContent(width, height int) string {
+	// If viewing commit detail
+	if m.logDetail != nil {
+		return m.renderLogDetail(width, height)
+	}
+
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	searchInfo := ""
+	if m.logSearch != "" {
+		searchInfo = helpStyle.Render(fmt.Sprintf(" (filter: %s)", m.logSearch))
+	}
+
+	header := sectionHeaderStyle.Render("Commit Log") + searchInfo
+	help := k("/") + d(": search") + sep + k("enter") + d(": detail") + sep +
+		k("c") + d(": cherry-pick") + sep + k("R") + d(": revert") + sep + k("d") + d(": dates") + sep + k("esc") + d(": back")
+
+	if m.logSearchInput.Focused() {
+		return header + "\n" + helpStyle.Render(strings.Repeat("─", width-6)) + "\n\n" +
+			"Search: " + m.logSearchInput.View()
+	}
+
+	if len(m.logCommits) == 0 {
+		empty := helpStyle.Render("No commits found.")
+		if m.loading["log"] {
+			empty = m.renderLoading("Searching commits...")
+		}
+		return header + "\n" + helpStyle.Render(strings.Repeat("─", width-6)) + "\n\n" +
+			empty + "\n\n" + help
+	}
+
+	maxItems := height - 4
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.logOffset > 0
+	hasBottom := m.logOffset+maxItems < len(m.logCommits)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+
+	endIdx := m.logOffset + maxItems
+	if endIdx > len(m.logCommits) {
+		endIdx = len(m.logCommits)
+	}
+
+	hashStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	columns := []listColumn{{}, {min: 12, flex: true}, {min: 12, max: 17}}
+	var rows [][]string
+	for _, commit := range m.logCommits[m.logOffset:endIdx] {
+		rows = append(rows, []string{hashStyle.Render(commit.Hash), printable(commit.Message), dateStyle.Render(m.commitDate(commit))})
+	}
+	widths := columnWidths(columns, rows, width-5)
+
+	for i := m.logOffset; i < endIdx; i++ {
+		line := " " + renderColumns(columns, rows[i-m.logOffset], widths)
+
+		if i == m.logCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	return strings.Join(lines, "\n")
+}
+
+// logTreeRows is how many rows of a commit's file tree fit above its diff
+func (m model) logTreeRows(height int) int {
+	return max(3, height/3)
+}
+
+func (m model) renderLogDetail(width, height int) string {
+	detail := m.logDetail
+	if detail == nil {
+		return ""
+	}
+
+	var lines []string
+
+	// Header info
+	hashStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+	lines = append(lines, hashStyle.Render("Commit: "+detail.Hash))
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render("Author: ")+detail.Author+" <"+detail.Email+">")
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render("Date:   ")+m.formatDate(detail.Date, detail.Authored))
+	if !detail.Committed.Equal(detail.Authored) {
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render("Committed: ")+m.formatDate(detail.CommitDate, detail.Committed))
+	}
+	lines = append(lines, "")
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render("Message: ")+detail.Message)
+	if detail.Body != "" {
+		lines = append(lines, detail.Body)
+	}
+	lines = append(lines, "")
+
+	// Files, as a tree with a cursor
+	if len(detail.Files) > 0 {
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Files (%d): ", len(detail.Files)))+
+			diffAddStyle.Render(fmt.Sprintf("+%d", detail.Insertions))+" "+diffRemoveStyle.Render(fmt.Sprintf("-%d", detail.Deletions)))
+		rows := fileTreeRows(detail.Files, m.logCollapsed)
+		end := min(len(rows), m.logFileOffset+m.logTreeRows(height))
+		if m.logFileOffset > 0 {
+			lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+		}
+		for i := m.logFileOffset; i < end; i++ {
+			row := rows[i]
+			icon := "  "
+			if row.dir {
+				icon = "▾ "
+				if m.logCollapsed[row.path] {
+					icon = "▸ "
+				}
+			}
+			name := row.name
+			if row.dir {
+				name += "/"
+			} else if row.path == m.logFile {
+				name += " ●"
+			}
+			counts := diffAddStyle.Render(fmt.Sprintf("+%d", row.added)) + " " + diffRemoveStyle.Render(fmt.Sprintf("-%d", row.deleted))
+			if row.binary && !row.dir {
+				counts = helpStyle.Render("binary")
+			}
+			line := strings.Repeat("  ", row.depth) + icon + name
+			if i == m.logFileCursor {
+				line = selectedStyle.Render(" " + line)
+			} else {
+				line = " " + line
+			}
+			lines = append(lines, line+"  "+counts)
+		}
+		if end < len(rows) {
+			lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+		}
+		lines = append(lines, "")
+	}
+
+	// Diff of the whole commit, or of the file picked in the tree
+	diff := m.logDiff
+	title := "Diff:"
+	if m.logFile != "" {
+		diff = m.logFileDiff
+		title = "Diff of " + m.logFile + ":"
+	}
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(title))
+	room := max(1, height-2-len(lines))
+	var diffLines []string
+	if diff != "" {
+		diffLines = strings.Split(diff, "\n")
+	}
+	offset := min(m.scrollOffset, max(0, len(diffLines)-1))
+	if offset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("scroll up..."))
+		room--
+	}
+	end := min(len(diffLines), offset+max(1, room))
+	for _, dl := range diffLines[offset:end] {
+		lines = append(lines, colorizeDiffLine(dl))
+	}
+	if end < len(diffLines) {
+		lines = append(lines, scrollIndicatorStyle.Render("scroll down..."))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Blame view
+
+func (m model) renderBlame(width, height int) string {
+	if len(m.blameLines) == 0 {
+		return helpStyle.Render("Loading blame...")
+	}
+
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+
+	header := sectionHeaderStyle.Render("Blame: " + m.displayPath(m.blameFile))
+	help := k("j/k") + d(": nav") + " | " + k("esc") + d(": back")
+
+	maxItems := height - 4
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.blameOffset > 0
+	hasBottom := m.blameOffset+maxItems < len(m.blameLines)
+
+	if hasTop {
+		maxItems--
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if hasTop {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+
+	endIdx := m.blameOffset + maxItems
+	if endIdx > len(m.blameLines) {
+		endIdx = len(m.blameLines)
+	}
+
+	hashStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	authorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	lineNumStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	for i := m.blameOffset; i < endIdx; i++ {
+		bl := m.blameLines[i]
+		// Truncate author name
+		author := bl.Author
+		if len(author) > 10 {
+			author = author[:10]
+		}
+
+		line := fmt.Sprintf("%s %s %s %s %s",
+			hashStyle.Render(bl.Hash),
+			authorStyle.Render(fmt.Sprintf("%-10s", author)),
+			dateStyle.Render(bl.Date),
+			lineNumStyle.Render(fmt.Sprintf("%4d", bl.LineNum)),
+			bl.Content)
+
+		// Truncate if too long
+		if len(line) > width-4 {
+			line = line[:width-7] + "..."
+		}
+
+		if i == m.blameCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	return strings.Join(lines, "\n")
+}
+
+// Clean view
+
+func (m model) renderCleanContent(width, height int) string {
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Clean Untracked Files")
+	help := k("d") + d(": delete all") + sep + k("r") + d(": refresh") + sep + k("esc") + d(": back")
+
+	if len(m.cleanFiles) == 0 {
+		return header + "\n" + helpStyle.Render(strings.Repeat("─", width-6)) + "\n\n" +
+			successStyle.Render("✨ No untracked files to clean") + "\n\n" + help
+	}
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "")
+	lines = append(lines, warningStyle.Render(fmt.Sprintf("⚠️  %d untracked file(s) will be deleted:", len(m.cleanFiles))))
+	lines = append(lines, "")
+
+	for i, file := range m.cleanFiles {
+		line := "  " + file
+		if i == m.cleanCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	return strings.Join(lines, "\n")
+}
+
+// Clone/Init views
+
+func (m model) renderCloneContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Clone Repository"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "")
+	lines = append(lines, normalStyle.Render("Enter repository URL:"))
+	lines = append(lines, "")
+	lines = append(lines, m.cloneInput.View())
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("Examples:"))
+	lines = append(lines, helpStyle.Render("  https://github.com/user/repo.git"))
+	lines = append(lines, helpStyle.Render("  git@github.com:user/repo.git"))
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("Press enter to clone, esc to cancel"))
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) renderInitContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Initialize Repository"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "")
+	lines = append(lines, normalStyle.Render("Enter directory path:"))
+	lines = append(lines, "")
+	lines = append(lines, m.initInput.View())
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("Leave empty for current directory, or enter a path"))
+	lines = append(lines, "")
+	lines = append(lines, helpStyle.Render("Press enter to init, esc to cancel"))
+
+	return strings.Join(lines, "\n")
+}
+
+// Identity view
+
+func (m model) renderIdentityContent(width, height int) string {
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Commit Identity"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+	lines = append(lines, "")
+
+	if m.identity.IsComplete() {
+		lines = append(lines, normalStyle.Render("Current: ")+successStyle.Render(m.identity.String()))
+	} else {
+		lines = append(lines, errorStyle.Render("⚠ user.name/user.email not set - commits will fail"))
+	}
+	lines = append(lines, "")
+
+	if m.identityInput.Focused() {
+		lines = append(lines, normalStyle.Render("New identity (saved to this repo's config):"))
+		lines = append(lines, m.identityInput.View())
+		return strings.Join(lines, "\n")
+	}
+
+	if len(m.identities) == 0 {
+		lines = append(lines, helpStyle.Render("No known identities. Press 'n' to add one."))
+	}
+
+	for i, id := range m.identities {
+		marker := "  "
+		if strings.EqualFold(id.Email, m.identity.Email) {
+			marker = "● "
+		}
+		line := fmt.Sprintf(" %s%s  %s", marker, id.String(), helpStyle.Render(id.Source))
+		if i == m.identityCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, normalStyle.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, k("enter")+d(": use in this repo")+sep+k("n")+d(": new")+sep+k("esc")+d(": back"))
+
+	return strings.Join(lines, "\n")
+}
+
+// Aliases view
+
+func (m model) renderAliasesContent(width, height int) string {
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Git Aliases")
+	help := k("enter") + d(": run (press twice)") + sep + k("r") + d(": reload") + sep + k("esc") + d(": back")
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if len(m.aliases) == 0 {
+		lines = append(lines, "")
+		lines = append(lines, helpStyle.Render("No aliases defined. Add some with: git config --global alias.<name> <command>"))
+		lines = append(lines, "")
+		lines = append(lines, help)
+		return strings.Join(lines, "\n")
+	}
+
+	nameWidth := 0
+	for _, a := range m.aliases {
+		nameWidth = max(nameWidth, len(a.Name))
+	}
+
+	for i, alias := range m.aliases {
+		kind := helpStyle.Render("git")
+		if alias.IsShell() {
+			kind = warningStyle.Render("sh ")
+		}
+		line := fmt.Sprintf(" %s %-*s  %s", kind, nameWidth, alias.Name, helpStyle.Render(alias.Expansion))
+		if i == m.aliasCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	if m.aliasOutput != "" {
+		lines = append(lines, "")
+		lines = append(lines, sectionHeaderStyle.Render("Output"))
+		outputLines := strings.Split(strings.TrimRight(m.aliasOutput, "\n"), "\n")
+		room := height - len(lines) - 1
+		if room < 1 {
+			room = 1
+		}
+		if len(outputLines) > room {
+			outputLines = append(outputLines[:room-1], scrollIndicatorStyle.Render(fmt.Sprintf("... %d more lines", len(outputLines)-room+1)))
+		}
+		lines = append(lines, outputLines...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Signing setup view
+
+func (m model) renderSigningContent(width, height int) string {
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Commit Signing")+
+		helpStyle.Render(fmt.Sprintf("  changes go to: %s config", m.configScope)))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	cfg := m.signingConfig
+	format := cfg.Format
+	if format == "" {
+		format = "openpgp (default)"
+	}
+	key := cfg.Key
+	if key == "" {
+		key = helpStyle.Render("not set")
+	}
+	status := warningStyle.Render("off")
+	if cfg.SignCommits {
+		status = successStyle.Render("on")
+	}
+	lines = append(lines, fmt.Sprintf("  %s %s", helpStyle.Render("commit.gpgsign "), status))
+	lines = append(lines, fmt.Sprintf("  %s %s", helpStyle.Render("gpg.format     "), format))
+	lines = append(lines, fmt.Sprintf("  %s %s", helpStyle.Render("user.signingkey"), key))
+
+	if m.signingResult != "" {
+		lines = append(lines, "")
+		if m.signingOK {
+			lines = append(lines, successStyle.Render("✓ "+m.signingResult))
+		} else {
+			lines = append(lines, errorStyle.Render("✗ ")+normalStyle.Render(m.signingResult))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, sectionHeaderStyle.Render("Available keys"))
+	if len(m.signingKeys) == 0 {
+		lines = append(lines, helpStyle.Render("  No GPG secret keys or ~/.ssh/*.pub keys found."))
+		lines = append(lines, helpStyle.Render("  Create one with: ssh-keygen -t ed25519   or   gpg --full-generate-key"))
+		return strings.Join(lines, "\n")
+	}
+
+	for i, k := range m.signingKeys {
+		kind := branchRemoteStyle.Render("gpg")
+		if k.Format == "ssh" {
+			kind = iconStagedStyle.Render("ssh")
+		}
+		current := "  "
+		if k.ID == cfg.Key {
+			current = successStyle.Render("● ")
+		}
+		line := fmt.Sprintf("%s%s %s %s", current, kind, k.ID, helpStyle.Render(k.Desc))
+		if i == m.signingCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Lost commit recovery view
+
+func (m model) renderRecoverContent(width, height int) string {
+	if m.lostDiff != "" {
+		return m.renderScrolledLines(strings.Split(m.lostDiff, "\n"), height, colorizeDiffLine)
+	}
+
+	var lines []string
+	lines = append(lines, sectionHeaderStyle.Render("Recover Lost Commits")+
+		helpStyle.Render("  commits no branch points to (reset, rebased, dropped stashes...)"))
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if m.lostStatus != "" {
+		lines = append(lines, helpStyle.Render(m.lostStatus))
+		return strings.Join(lines, "\n")
+	}
+
+	maxItems := max(1, height-len(lines)-2)
+	if m.lostOffset > 0 {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	end := min(len(m.lostCommits), m.lostOffset+maxItems)
+	// Hash, source, time, subject, author
+	columns := []listColumn{{}, {}, {min: 10}, {min: 12, flex: true}, {min: 8, max: 22}}
+	var rows [][]string
+	for _, lost := range m.lostCommits[m.lostOffset:end] {
+		source := helpStyle.Render("dangling")
+		if lost.Source == "reflog" {
+			source = branchRemoteStyle.Render("reflog")
+		}
+		rows = append(rows, []string{
+			lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(lost.Hash),
+			source,
+			helpStyle.Render(lost.Time.Format("2006-01-02 15:04")),
+			printable(lost.Message),
+			helpStyle.Render("(" + printable(lost.Author) + ")"),
+		})
+	}
+	widths := columnWidths(columns, rows, width-4)
+	for i := m.lostOffset; i < end; i++ {
+		line := renderColumns(columns, rows[i-m.lostOffset], widths)
+		if i == m.lostCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	if end < len(m.lostCommits) {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderScrolledLines shows a window of lines starting at scrollOffset
+func (m model) renderScrolledLines(lines []string, height int, render func(string) string) string {
+	maxLines := max(1, height-2)
+	offset := min(m.scrollOffset, max(0, len(lines)-1))
+
+	var result []string
+	if offset > 0 {
+		result = append(result, scrollIndicatorStyle.Render("scroll up..."))
+		maxLines--
+	}
+	end := min(len(lines), offset+maxLines)
+	for _, line := range lines[offset:end] {
+		result = append(result, render(line))
+	}
+	if end < len(lines) {
+		result = append(result, scrollIndicatorStyle.Render("scroll down..."))
+	}
+	return strings.Join(result, "\n")
+}
+
+// Config editor view
+
+func (m model) renderConfigContent(width, height int) string {
+	k := func(key string) string { return keyBindStyle.Render(key) }
+	d := func(desc string) string { return keyDescStyle.Render(desc) }
+	sep := keyDescStyle.Render(" | ")
+
+	header := sectionHeaderStyle.Render("Git Config") +
+		helpStyle.Render(fmt.Sprintf("  edits go to: %s config", m.configScope))
+	help := k("enter") + d(": edit") + sep + k("space") + d(": cycle") + sep + k("u") + d(": unset") + sep +
+		k("g") + d(": local/global") + sep + k("esc") + d(": back")
+
+	var lines []string
+	lines = append(lines, header)
+	lines = append(lines, helpStyle.Render(strings.Repeat("─", width-6)))
+
+	if m.configInput.Focused() {
+		lines = append(lines, "")
+		lines = append(lines, normalStyle.Render(fmt.Sprintf("%s (%s):", m.configEditKey, m.configScope)))
+		lines = append(lines, m.configInput.View())
+		for _, key := range git.EditableConfigKeys() {
+			if key.Key == m.configEditKey && len(key.Options) > 0 {
+				lines = append(lines, helpStyle.Render("Allowed: "+strings.Join(key.Options, ", ")))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	rows := m.configRows()
+	maxItems := height - 6
+	if maxItems < 1 {
+		maxItems = 1
+	}
+
+	hasTop := m.configOffset > 0
+	hasBottom := m.configOffset+maxItems < len(rows)
+
+	if hasTop {
+		maxItems--
+		lines = append(lines, scrollIndicatorStyle.Render("  ▲ more above"))
+	}
+	if hasBottom {
+		maxItems--
+	}
+
+	endIdx := m.configOffset + maxItems
+	if endIdx > len(rows) {
+		endIdx = len(rows)
+	}
+
+	scopeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("141"))
+	for i := m.configOffset; i < endIdx; i++ {
+		row := rows[i]
+		marker := "  "
+		if row.editable {
+			marker = "✎ "
+		}
+		value := row.value
+		if value == "" {
+			value = helpStyle.Render("(unset)")
+		}
+		scope := ""
+		if row.scope != "" {
+			scope = scopeStyle.Render(" [" + row.scope + "]")
+		}
+		line := fmt.Sprintf(" %s%-24s %s%s", marker, row.key, value, scope)
+		if row.editable && row.desc != "" {
+			line += helpStyle.Render("  " + row.desc)
+		}
+
+		if i == m.configCursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render(line))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	if hasBottom {
+		lines = append(lines, scrollIndicatorStyle.Render("  ▼ more below"))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, help)
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// When the flex column is cut, the columns fill the width exactly: no gap
+// counted that renderColumns doesn't write, none left over
+func TestColumnWidths(t *testing.T) {
+	history := []listColumn{{}, {min: 12, flex: true}, {min: 8, max: 20}, {min: 12, max: 17}, {}}
+	subject := strings.Repeat("a long subject ", 10)
+	tests := []struct {
+		name    string
+		columns []listColumn
+		row     []string
+		width   int
+	}{
+		{"flex in the middle", history, []string{"17c38c5", subject, "Fixture Author", "24 hours ago", ""}, 76},
+		{"narrow", history, []string{"17c38c5", subject, "Fixture Author", "24 hours ago", ""}, 54},
+		{"with signature", history, []string{"17c38c5", subject, "Fixture Author", "24 hours ago", "✓"}, 118},
+		{"flex first", []listColumn{{min: 12, flex: true}, {min: 8, max: 20}}, []string{subject, "Fixture Author"}, 60},
+	}
+	for _, tt := range tests {
+		widths := columnWidths(tt.columns, [][]string{tt.row}, tt.width)
+		if got := lipgloss.Width(renderColumns(tt.columns, tt.row, widths)); got != tt.width {
+			t.Errorf("%s: row is %d wide at %v, want all of %d", tt.name, got, widths, tt.width)
+		}
+	}
+}
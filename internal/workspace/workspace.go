@@ -0,0 +1,408 @@
+// Package workspace detects monorepo layouts so commit scopes can name the
+// package a change belongs to rather than its top-level directory
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Package is one member of a workspace
+type Package struct {
+	Name string // scope-friendly name, e.g. "api" for @acme/api
+	Dir  string // slash-separated, relative to the repository root
+}
+
+// Workspace lists the packages found in a repository, deepest first so the
+// first match for a file is its owner
+type Workspace struct {
+	Kinds    []string // layouts found: "go.work", "npm", "nx", "cargo"
+	Packages []Package
+
+	// Go modules (every go.mod in the repository and the go.work members),
+	// deepest first. Go files are scoped by their package path.
+	GoModules []GoModule
+}
+
+// GoModule is a directory holding a go.mod
+type GoModule struct {
+	Dir  string // slash-separated, relative to the repository root
+	Path string // the module line, e.g. "go.acme.dev/billing/v2"
+}
+
+const (
+	// maxProjectDepth bounds the search for nx project.json files
+	maxProjectDepth = 4
+	// maxModuleDepth bounds the search for nested go.mod files
+	maxModuleDepth = 6
+)
+
+// Detect reads the workspace files at root. Layouts can be combined, e.g. a
+// Go backend next to npm workspaces.
+func Detect(root string) Workspace {
+	var ws Workspace
+	add := func(kind string, pkgs []Package) {
+		if len(pkgs) == 0 {
+			return
+		}
+		ws.Kinds = append(ws.Kinds, kind)
+		ws.Packages = append(ws.Packages, pkgs...)
+	}
+	add("go.work", goWork(root))
+	add("npm", npmWorkspaces(root))
+	add("nx", nxProjects(root))
+	add("cargo", cargoWorkspace(root))
+
+	// Deepest first; a directory claimed twice keeps its first name
+	seen := make(map[string]bool)
+	var unique []Package
+	for _, pkg := range ws.Packages {
+		if pkg.Dir != "." && pkg.Dir != "" && !seen[pkg.Dir] {
+			seen[pkg.Dir] = true
+			unique = append(unique, pkg)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return strings.Count(unique[i].Dir, "/") > strings.Count(unique[j].Dir, "/")
+	})
+	ws.Packages = unique
+
+	ws.GoModules = goModules(root)
+	sort.SliceStable(ws.GoModules, func(i, j int) bool {
+		return strings.Count(ws.GoModules[i].Dir, "/") > strings.Count(ws.GoModules[j].Dir, "/")
+	})
+	return ws
+}
+
+// Owner returns the package containing file (a repository-relative path)
+func (w Workspace) Owner(file string) (Package, bool) {
+	for _, pkg := range w.Packages {
+		if file == pkg.Dir || strings.HasPrefix(file, pkg.Dir+"/") {
+			return pkg, true
+		}
+	}
+	return Package{}, false
+}
+
+// Scope is the name of the package containing file, for use as a commit
+// scope: a Go file's package path, otherwise its workspace package
+func (w Workspace) Scope(file string) (string, bool) {
+	if scope := w.goScope(file); scope != "" {
+		return scope, true
+	}
+	pkg, ok := w.Owner(file)
+	return pkg.Name, ok
+}
+
+// goScope scopes a Go file by its package's path within its module, less the
+// internal and pkg segments that say nothing about the code:
+// internal/api/auth/token.go gives "api/auth". In a nested module the last
+// element of its module path leads ("billing/invoice" for
+// services/billing-svc/internal/invoice of go.acme.dev/billing/v2). Files of
+// a root module's root package, and anything outside a module, give "".
+func (w Workspace) goScope(file string) string {
+	if !strings.HasSuffix(file, ".go") {
+		return ""
+	}
+	for _, mod := range w.GoModules {
+		rel, ok := file, mod.Dir == "."
+		if !ok {
+			rel, ok = strings.CutPrefix(file, mod.Dir+"/")
+		}
+		if !ok {
+			continue
+		}
+		var parts []string
+		if mod.Dir != "." {
+			parts = append(parts, moduleName(mod))
+		}
+		if dir := path.Dir(rel); dir != "." {
+			for _, part := range strings.Split(dir, "/") {
+				if part != "internal" && part != "pkg" {
+					parts = append(parts, part)
+				}
+			}
+		}
+		return strings.Join(parts, "/")
+	}
+	return ""
+}
+
+// moduleName is the last element of a module path, skipping a major version
+// suffix: "go.acme.dev/billing/v2" gives "billing". A go.mod without a
+// module line falls back to its directory.
+func moduleName(mod GoModule) string {
+	elems := strings.Split(mod.Path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	if name == "" {
+		return path.Base(mod.Dir)
+	}
+	return name
+}
+
+// majorVersion reports whether elem is a module path's /vN suffix
+func majorVersion(elem string) bool {
+	digits, ok := strings.CutPrefix(elem, "v")
+	return ok && digits != "" && strings.Trim(digits, "0123456789") == ""
+}
+
+// goModules finds the go.mod files below root, skipping vendor and testdata,
+// plus any go.work members deeper than the search goes
+func goModules(root string) []GoModule {
+	seen := make(map[string]bool)
+	var mods []GoModule
+	add := func(dir string) {
+		if seen[dir] || dir == ".." || strings.HasPrefix(dir, "../") {
+			return
+		}
+		seen[dir] = true
+		if modPath, ok := modulePath(filepath.Join(root, dir, "go.mod")); ok {
+			mods = append(mods, GoModule{Dir: dir, Path: modPath})
+		}
+	}
+
+	filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		name := d.Name()
+		if rel != "." && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" ||
+			name == "node_modules" || strings.Count(rel, "/") >= maxModuleDepth) {
+			return filepath.SkipDir
+		}
+		add(rel)
+		return nil
+	})
+	for _, pkg := range goWork(root) {
+		add(pkg.Dir)
+	}
+	return mods
+}
+
+// modulePath reads the module line of a go.mod. ok is false when the file
+// does not exist.
+func modulePath(file string) (modPath string, ok bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`"), true
+		}
+	}
+	return "", true
+}
+
+// goWork reads the use directives of go.work
+func goWork(root string) []Package {
+	file, err := os.Open(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var pkgs []Package
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		var dir string
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock:
+			dir = line
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			dir = strings.TrimSpace(strings.TrimPrefix(line, "use "))
+		}
+		if dir = strings.Trim(dir, `"`); dir != "" {
+			dir = clean(dir)
+			pkgs = append(pkgs, Package{Name: path.Base(dir), Dir: dir})
+		}
+	}
+	return pkgs
+}
+
+// npmWorkspaces reads the workspaces globs of package.json (npm, yarn and
+// turborepo all use them)
+func npmWorkspaces(root string) []Package {
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if !readJSON(filepath.Join(root, "package.json"), &manifest) || len(manifest.Workspaces) == 0 {
+		return nil
+	}
+
+	// Either a list of globs or yarn's {"packages": [...]}
+	var globs []string
+	if json.Unmarshal(manifest.Workspaces, &globs) != nil {
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(manifest.Workspaces, &object) != nil {
+			return nil
+		}
+		globs = object.Packages
+	}
+
+	var pkgs []Package
+	for _, dir := range expand(root, globs, "package.json") {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		readJSON(filepath.Join(root, dir, "package.json"), &pkg)
+		pkgs = append(pkgs, Package{Name: scopeName(pkg.Name, dir), Dir: dir})
+	}
+	return pkgs
+}
+
+// nxProjects finds nx project.json files (only when nx.json is present)
+func nxProjects(root string) []Package {
+	if _, err := os.Stat(filepath.Join(root, "nx.json")); err != nil {
+		return nil
+	}
+
+	var pkgs []Package
+	filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			name := d.Name()
+			if rel != "." && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "dist" ||
+				strings.Count(rel, "/") >= maxProjectDepth) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "project.json" {
+			return nil
+		}
+		dir := path.Dir(rel)
+		var project struct {
+			Name string `json:"name"`
+		}
+		readJSON(p, &project)
+		pkgs = append(pkgs, Package{Name: scopeName(project.Name, dir), Dir: dir})
+		return nil
+	})
+	return pkgs
+}
+
+// cargoWorkspace reads [workspace] members of Cargo.toml
+func cargoWorkspace(root string) []Package {
+	var manifest struct {
+		Workspace struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(root, "Cargo.toml"), &manifest); err != nil {
+		return nil
+	}
+
+	excluded := make(map[string]bool)
+	for _, dir := range manifest.Workspace.Exclude {
+		excluded[clean(dir)] = true
+	}
+
+	var pkgs []Package
+	for _, dir := range expand(root, manifest.Workspace.Members, "Cargo.toml") {
+		if excluded[dir] {
+			continue
+		}
+		var crate struct {
+			Package struct {
+				Name string `toml:"name"`
+			} `toml:"package"`
+		}
+		toml.DecodeFile(filepath.Join(root, dir, "Cargo.toml"), &crate)
+		pkgs = append(pkgs, Package{Name: scopeName(crate.Package.Name, dir), Dir: dir})
+	}
+	return pkgs
+}
+
+// expand resolves workspace globs to directories holding marker. A trailing
+// "/**" matches any depth below its prefix.
+func expand(root string, globs []string, marker string) []string {
+	var dirs []string
+	for _, glob := range globs {
+		if strings.HasPrefix(glob, "!") {
+			continue
+		}
+		glob = clean(glob)
+		if base, ok := strings.CutSuffix(glob, "/**"); ok {
+			filepath.WalkDir(filepath.Join(root, base), func(p string, d os.DirEntry, err error) error {
+				if err != nil || !d.IsDir() {
+					return nil
+				}
+				if d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(p, marker)); err == nil {
+					rel, _ := filepath.Rel(root, p)
+					dirs = append(dirs, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(glob), marker))
+		for _, match := range matches {
+			rel, _ := filepath.Rel(root, filepath.Dir(match))
+			dirs = append(dirs, filepath.ToSlash(rel))
+		}
+	}
+	return dirs
+}
+
+// scopeName turns a package name into a commit scope: npm scopes and
+// organisation prefixes are dropped, and unnamed packages use their directory
+func scopeName(name, dir string) string {
+	if name == "" {
+		return path.Base(dir)
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func clean(dir string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(dir)), "./")
+}
+
+func readJSON(file string, v any) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
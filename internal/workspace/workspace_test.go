@@ -0,0 +1,63 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoScope(t *testing.T) {
+	root := t.TempDir()
+	write := func(file, content string) {
+		t.Helper()
+		p := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module github.com/acme/platform\n\ngo 1.22\n")
+	// Nested module, not in a go.work, with a vanity path and a major version
+	write("services/billing-svc/go.mod", "// billing service\nmodule \"go.acme.dev/billing/v2\" // vanity\n")
+	write("tools/go.mod", "go 1.22\n")
+	write("vendor/example.com/dep/go.mod", "module example.com/dep\n")
+
+	ws := Detect(root)
+	for _, tt := range []struct {
+		file, want string
+	}{
+		{"main.go", ""},
+		{"internal/api/auth/token.go", "api/auth"},
+		{"pkg/cache/lru.go", "cache"},
+		{"services/billing-svc/main.go", "billing"},
+		{"services/billing-svc/internal/invoice/pdf.go", "billing/invoice"},
+		{"tools/gen/main.go", "tools/gen"},
+		{"services/billing-svc/README.md", ""},
+	} {
+		if got := ws.goScope(tt.file); got != tt.want {
+			t.Errorf("goScope(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+	if len(ws.GoModules) != 3 {
+		t.Errorf("found %d modules, want 3 (vendor skipped): %+v", len(ws.GoModules), ws.GoModules)
+	}
+}
+
+func TestModuleName(t *testing.T) {
+	for _, tt := range []struct {
+		mod  GoModule
+		want string
+	}{
+		{GoModule{Dir: "api", Path: "github.com/acme/api"}, "api"},
+		{GoModule{Dir: "services/x", Path: "go.acme.dev/billing/v3"}, "billing"},
+		{GoModule{Dir: "services/x", Path: "example.com/version"}, "version"},
+		{GoModule{Dir: "services/x", Path: "v2"}, "v2"},
+		{GoModule{Dir: "services/x"}, "x"},
+	} {
+		if got := moduleName(tt.mod); got != tt.want {
+			t.Errorf("moduleName(%+v) = %q, want %q", tt.mod, got, tt.want)
+		}
+	}
+}
@@ -1,36 +1,53 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
-	tea "github.com/charmbracelet/bubbletea"
-
-	"github.com/LFroesch/gitty/internal/git"
 	"github.com/LFroesch/gitty/internal/logger"
+	"github.com/LFroesch/gitty/internal/ui"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "prompt":
+			os.Exit(runPrompt(os.Args[2:]))
+		case "completion":
+			os.Exit(runCompletion(os.Args[2:]))
+		case "__complete":
+			os.Exit(runComplete(os.Args[2:]))
+		case "devtools":
+			os.Exit(runDevtools(os.Args[2:]))
+		}
+	}
+
+	flags := flag.NewFlagSet("gitty", flag.ExitOnError)
+	presenter := flags.Bool("presenter", false, "show pressed keys and keep status messages up longer, for demos")
+	readOnly := flags.Bool("read-only", false, "browse without changing the repository: no staging, commits, pushes, resets or deletes")
+	debug := flags.Bool("debug", false, "log every message handled and git command run, with timings, as JSON to ~/.config/gitty/debug.log; F12 shows the latest")
+	flags.Parse(os.Args[1:])
+
 	// Initialize logger
 	if err := logger.Init(); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Could not initialize logger: %v\n", err)
 	}
 	defer logger.Close()
 
-	// Check if we're in a git repo
-	cwd, _ := os.Getwd()
-	if !git.IsRepo(cwd) {
-		fmt.Fprintln(os.Stderr, "Error: Not a git repository")
-		os.Exit(1)
+	if *debug {
+		path, err := logger.DebugPath()
+		if err == nil {
+			err = logger.InitDebug(path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer fmt.Fprintf(os.Stderr, "Debug log: %s\n", path)
 	}
 
-	// Run the TUI
-	p := tea.NewProgram(
-		initialModel(),
-		tea.WithAltScreen(),
-	)
-
-	if _, err := p.Run(); err != nil {
+	if err := ui.Run(ui.Options{Presenter: *presenter, ReadOnly: *readOnly, Debug: *debug}); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
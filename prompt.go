@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LFroesch/gitty/internal/config"
+	"github.com/LFroesch/gitty/internal/git"
+)
+
+// ANSI colours for the prompt segments
+const (
+	promptBranch   = "35" // magenta
+	promptAhead    = "36" // cyan
+	promptStaged   = "32" // green
+	promptUnstaged = "33" // yellow
+	promptConflict = "31" // red
+	promptDim      = "90" // grey
+)
+
+// runPrompt prints a one-line repository summary for PS1, starship and the
+// like, e.g. "main ↑1 ↓2 +3 ~1 ?2 !1". It prints nothing and exits 1 outside
+// a repository so prompts can hide the segment.
+func runPrompt(args []string) int {
+	flags := flag.NewFlagSet("prompt", flag.ExitOnError)
+	color := flags.String("color", "auto", "colour output: auto, always or never")
+	shell := flags.String("shell", "", "wrap colour codes for a PS1 prompt: bash or zsh")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gitty prompt [--color auto|always|never] [--shell bash|zsh]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	// Only the git executable and environment matter here; the rest of the
+	// config (and the repo's .gitty.toml) is not worth reading per prompt
+	cfg, _ := config.Load()
+	git.Configure(cfg.Git.Binary, cfg.Git.Env, cfg.Git.RepoEnv())
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return 1
+	}
+	status, err := git.GetPromptStatus(dir)
+	if err != nil {
+		return 1
+	}
+
+	useColor := *color == "always"
+	if *color == "auto" {
+		info, err := os.Stdout.Stat()
+		useColor = err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("NO_COLOR") == ""
+	}
+	paint := func(code, text string) string {
+		if !useColor {
+			return text
+		}
+		start, end := "\x1b["+code+"m", "\x1b[0m"
+		switch *shell {
+		case "bash":
+			start, end = `\[`+start+`\]`, `\[`+end+`\]`
+		case "zsh":
+			start, end = "%{"+start+"%}", "%{"+end+"%}"
+		}
+		return start + text + end
+	}
+
+	branch := status.Branch
+	if status.Detached {
+		branch = "@" + branch
+	}
+	segments := []string{paint(promptBranch, branch)}
+	counts := []struct {
+		n      int
+		symbol string
+		code   string
+	}{
+		{status.Ahead, "↑", promptAhead},
+		{status.Behind, "↓", promptAhead},
+		{status.Staged, "+", promptStaged},
+		{status.Unstaged, "~", promptUnstaged},
+		{status.Untracked, "?", promptDim},
+		{status.Conflicts, "!", promptConflict},
+	}
+	for _, count := range counts {
+		if count.n > 0 {
+			segments = append(segments, paint(count.code, fmt.Sprintf("%s%d", count.symbol, count.n)))
+		}
+	}
+	fmt.Println(strings.Join(segments, " "))
+	return 0
+}
@@ -0,0 +1,141 @@
+package suggest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Source is what Generate reads: the changed paths and their diffs
+type Source interface {
+	// Changes lists changed paths
+	Changes() []Change
+	// StagedDiff is the diff about to be committed, scanned for breaking changes
+	StagedDiff() string
+	// Diff is the diff of files, scanned for evidence
+	Diff(files []string) string
+}
+
+// FromDiff reads the output of git diff (or git show, format-patch) as if
+// every file in it were staged
+func FromDiff(diff string) Source {
+	src := diffSource{diff: diff, files: make(map[string]string)}
+	for _, block := range splitDiff(diff) {
+		change := parseDiffHeader(block)
+		if change.File == "" {
+			continue
+		}
+		if _, ok := src.files[change.File]; !ok {
+			src.changes = append(src.changes, change)
+		}
+		src.files[change.File] += block
+	}
+	return src
+}
+
+type diffSource struct {
+	diff    string
+	changes []Change
+	files   map[string]string // each file's part of diff
+}
+
+func (d diffSource) Changes() []Change { return d.changes }
+
+func (d diffSource) StagedDiff() string { return d.diff }
+
+func (d diffSource) Diff(files []string) string {
+	var diff strings.Builder
+	for _, file := range files {
+		diff.WriteString(d.files[file])
+	}
+	return diff.String()
+}
+
+// splitDiff cuts a diff into one block per "diff --git" header, dropping
+// anything before the first (e.g. a commit message from git show)
+func splitDiff(diff string) []string {
+	var blocks []string
+	var block strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") && block.Len() > 0 {
+			blocks = append(blocks, block.String())
+			block.Reset()
+		}
+		if strings.HasPrefix(line, "diff --git ") || block.Len() > 0 {
+			block.WriteString(line)
+		}
+	}
+	if block.Len() > 0 {
+		blocks = append(blocks, block.String())
+	}
+	return blocks
+}
+
+// parseDiffHeader reads the path and status of one file's diff from the
+// extended header lines before its first hunk
+func parseDiffHeader(block string) Change {
+	change := Change{Status: "M "}
+	var oldPath, newPath string
+	lines := strings.Split(block, "\n")
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "@@") {
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, "new file mode"):
+			change.Status = "A "
+		case strings.HasPrefix(line, "deleted file mode"):
+			change.Status = "D "
+		case strings.HasPrefix(line, "rename to "):
+			change.Status = "R "
+			newPath = unquotePath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy to "):
+			change.Status = "C "
+			newPath = unquotePath(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "--- "):
+			oldPath = diffPath(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "+++ "):
+			newPath = diffPath(strings.TrimPrefix(line, "+++ "))
+		}
+	}
+
+	switch {
+	case newPath != "":
+		change.File = newPath
+	case oldPath != "":
+		change.File = oldPath
+	default:
+		// Binary or mode-only change: only the "diff --git a/x b/x" header
+		// names the file
+		header := strings.TrimPrefix(lines[0], "diff --git ")
+		if i := strings.LastIndex(header, " b/"); i >= 0 {
+			change.File = header[i+3:]
+		} else if i := strings.LastIndex(header, ` "b/`); i >= 0 {
+			change.File = unquotePath(`"` + header[i+4:])
+		}
+	}
+	return change
+}
+
+// diffPath turns "a/dir/file" or "b/dir/file" from a ---/+++ line into a
+// repo-relative path, or "" for /dev/null
+func diffPath(name string) string {
+	name = unquotePath(strings.TrimRight(name, "\t"))
+	if name == "/dev/null" {
+		return ""
+	}
+	if len(name) > 2 && (name[:2] == "a/" || name[:2] == "b/") {
+		return name[2:]
+	}
+	return name
+}
+
+// unquotePath undoes git's C-style quoting of paths with unusual characters
+func unquotePath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
@@ -0,0 +1,542 @@
+// Package suggest turns a set of changes into conventional commit message
+// suggestions, with the evidence behind each one. It is the engine behind
+// gitty's commit tab and can be used on its own: point Generate at the
+// output of git diff with FromDiff (e.g. in a server-side hook, where there
+// is no worktree), or at any other Source.
+package suggest
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Suggestion is a suggested commit message
+type Suggestion struct {
+	Message  string
+	Type     string
+	Breaking string   // BREAKING CHANGE footer, set for breaking suggestions
+	Info     DiffInfo // evidence behind the suggestion
+}
+
+// DiffInfo is what the suggestion heuristic saw in the diff
+type DiffInfo struct {
+	Files     []string
+	Added     int
+	Removed   int
+	Functions []string // functions declared on changed lines
+	Keywords  []string // notable words in added lines
+	Context   string   // why the files were classified as this type
+}
+
+// Change is a changed path and its two-letter status as git status
+// --porcelain prints it: index then worktree, e.g. "A " for a staged new
+// file or " M" for an unstaged modification
+type Change struct {
+	File   string
+	Status string
+}
+
+// Rule assigns a commit type and optional scope to matching paths
+type Rule struct {
+	// Path is a glob against the repo-relative path; a trailing "/**"
+	// matches everything below a directory, and patterns without a "/"
+	// also match the file name alone
+	Path  string `toml:"path"`
+	Type  string `toml:"type"`
+	Scope string `toml:"scope"`
+}
+
+// Matches reports whether the rule applies to a repo-relative file path
+func (r Rule) Matches(file string) bool {
+	if dir, ok := strings.CutSuffix(r.Path, "/**"); ok {
+		return strings.HasPrefix(file, dir+"/")
+	}
+	if ok, _ := path.Match(r.Path, file); ok {
+		return true
+	}
+	if !strings.Contains(r.Path, "/") {
+		ok, _ := path.Match(r.Path, path.Base(file))
+		return ok
+	}
+	return false
+}
+
+// Classifier recognises a language's or framework's domain in a diff, such
+// as Rails migrations or gRPC handlers, so suggestions use its terms
+type Classifier struct {
+	// Name joins DiffInfo.Context when the classifier matches, e.g.
+	// "migration"
+	Name string `toml:"name"`
+	// Files limits it to matching paths, globs as in Rule.Path; empty
+	// covers every file
+	Files []string `toml:"files"`
+	// Keywords are looked for, case-insensitively, in the added lines of
+	// those files; with none, matching files are enough
+	Keywords []string `toml:"keywords"`
+	// Describe replaces the generic description in the message, e.g.
+	// "add database migration"; optional
+	Describe string `toml:"describe"`
+}
+
+// covers reports whether file is one the classifier looks at
+func (c Classifier) covers(file string) bool {
+	if len(c.Files) == 0 {
+		return true
+	}
+	for _, glob := range c.Files {
+		if (Rule{Path: glob}).Matches(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns whether the classifier applies to files, and the keywords
+// it found in their added lines
+func (c Classifier) match(src Source, files []string) (bool, []string) {
+	var covered []string
+	for _, file := range files {
+		if c.covers(file) {
+			covered = append(covered, file)
+		}
+	}
+	if len(covered) == 0 {
+		return false, nil
+	}
+	if len(c.Keywords) == 0 {
+		return true, nil
+	}
+
+	var found []string
+	for _, line := range strings.Split(src.Diff(covered), "\n") {
+		if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		lower := strings.ToLower(line[1:])
+		for _, kw := range c.Keywords {
+			if strings.Contains(lower, strings.ToLower(kw)) {
+				found = appendUnique(found, kw)
+			}
+		}
+	}
+	return len(found) > 0, found
+}
+
+// Group is a set of files to commit together under one message
+type Group struct {
+	Message string
+	Files   []string
+}
+
+// Options tune Generate
+type Options struct {
+	Rules []Rule
+	// Classifiers add project-specific context (and wording) to the
+	// suggestions whose files they match
+	Classifiers []Classifier
+	// Owner names the package owning a file, used as the scope when every
+	// file in a suggestion shares one (e.g. a monorepo workspace); may be nil
+	Owner func(file string) (scope string, ok bool)
+	Max   int // keep at most this many suggestions; 0 keeps all
+}
+
+// ConventionalPrefix matches a conventional commit prefix such as
+// "feat(ui)!: "
+var ConventionalPrefix = regexp.MustCompile(`^[a-z]+(\([^)]*\))?!?:\s*`)
+
+// Generate suggests commit messages for the changes in repo, one per change
+// type, and lists likely breaking changes in the staged diff
+func Generate(src Source, opts Options) (suggestions []Suggestion, breaking []string) {
+	changes := src.Changes()
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	var order []string
+	groups := make(map[string][]Change)
+	reasons := make(map[string][]string)
+
+	for _, change := range changes {
+		changeType, reason := Classify(change, opts.Rules)
+		if _, ok := groups[changeType]; !ok {
+			order = append(order, changeType)
+		}
+		groups[changeType] = append(groups[changeType], change)
+		reasons[changeType] = appendUnique(reasons[changeType], reason)
+	}
+
+	// Generate suggestions based on change patterns
+	for _, changeType := range order {
+		info := CollectDiffInfo(src, groups[changeType])
+		context := reasons[changeType]
+		var describe string
+		for _, classifier := range opts.Classifiers {
+			ok, found := classifier.match(src, info.Files)
+			if !ok {
+				continue
+			}
+			if len(found) > 0 {
+				context = append(context, classifier.Name+": "+strings.Join(found, ", "))
+			} else {
+				context = append(context, classifier.Name)
+			}
+			for _, kw := range found {
+				info.Keywords = appendUnique(info.Keywords, kw)
+			}
+			if describe == "" {
+				describe = classifier.Describe
+			}
+		}
+		info.Context = strings.Join(context, ", ")
+
+		scope := Scope(groups[changeType], opts.Rules, opts.Owner)
+		msg := Message(changeType, scope, len(groups[changeType]))
+		if describe != "" {
+			msg = describedMessage(changeType, scope, describe, len(groups[changeType]))
+		}
+		suggestions = append(suggestions, Suggestion{Message: msg, Type: changeType, Info: info})
+	}
+
+	// Offer a breaking variant of the suggestion covering the file that
+	// removes or changes public API
+	staged := src.StagedDiff()
+	breaking = DetectBreaking(staged)
+	if len(breaking) > 0 && len(suggestions) > 0 {
+		base := suggestions[0]
+		files := breakingFiles(staged, breaking)
+		for _, suggestion := range suggestions {
+			if slices.ContainsFunc(groups[suggestion.Type], func(c Change) bool { return slices.Contains(files, c.File) }) {
+				base = suggestion
+				break
+			}
+		}
+		suggestions = append([]Suggestion{{
+			Message:  MarkBreaking(base.Message),
+			Type:     base.Type,
+			Info:     base.Info,
+			Breaking: strings.Join(breaking, "; "),
+		}}, suggestions...)
+	}
+
+	if opts.Max > 0 && len(suggestions) > opts.Max {
+		suggestions = suggestions[:opts.Max]
+	}
+	return suggestions, breaking
+}
+
+// Message builds a conventional commit message for count files of changeType
+func Message(changeType, scope string, count int) string {
+	var description string
+	switch changeType {
+	case "feat":
+		description = "add new feature"
+	case "fix":
+		description = "resolve issue"
+	case "docs":
+		description = "update documentation"
+	case "style":
+		description = "improve formatting"
+	case "refactor":
+		description = "improve code structure"
+	case "test":
+		description = "add/update tests"
+	case "chore":
+		description = "update build/config"
+	default:
+		changeType = "chore"
+		description = "update files"
+	}
+	return describedMessage(changeType, scope, description, count)
+}
+
+// describedMessage builds a conventional commit message with the given
+// description
+func describedMessage(changeType, scope, description string, count int) string {
+	if scope != "" {
+		changeType += "(" + scope + ")"
+	}
+	return fmt.Sprintf("%s: %s (%d files)", changeType, description, count)
+}
+
+// PlanGroups splits staged changes into groups by type and package
+// (or top-level directory outside a workspace), each with its own suggested
+// message
+func PlanGroups(changes []Change, rules []Rule, owner func(string) (string, bool)) []Group {
+	type groupKey struct{ changeType, scope string }
+	var order []groupKey
+	files := make(map[groupKey][]string)
+
+	for _, change := range changes {
+		changeType, _ := Classify(change, rules)
+		scope := Scope([]Change{change}, rules, owner)
+		if dir, _, ok := strings.Cut(change.File, "/"); ok && scope == "" {
+			scope = dir
+		}
+		key := groupKey{changeType, scope}
+		if _, ok := files[key]; !ok {
+			order = append(order, key)
+		}
+		files[key] = append(files[key], change.File)
+	}
+
+	var groups []Group
+	for _, key := range order {
+		groups = append(groups, Group{
+			Message: Message(key.changeType, key.scope, len(files[key])),
+			Files:   files[key],
+		})
+	}
+	return groups
+}
+
+var (
+	// Public declarations per language; group 1 is the declared name
+	goFuncRe      = regexp.MustCompile(`^func\s+(?:\(\s*(?:\w+\s+)?\*?(\w+)[^)]*\)\s*)?([A-Z]\w*)\s*[(\[]`)
+	goTypeRe      = regexp.MustCompile(`^type\s+([A-Z]\w*)\s`)
+	jsExportRe    = regexp.MustCompile(`^export\s+(?:default\s+)?(?:async\s+)?(?:function\*?|class|const|let|interface|type|enum)\s+(\w+)`)
+	pyPublicDefRe = regexp.MustCompile(`^(?:async\s+)?def\s+([a-zA-Z]\w*)\s*\(`)
+)
+
+// publicDecl returns the name of the public API declared on a source line, if any
+func publicDecl(line string) string {
+	if match := goFuncRe.FindStringSubmatch(line); match != nil {
+		if match[1] != "" {
+			// Methods on unexported types are not public API
+			if !unicode.IsUpper(rune(match[1][0])) {
+				return ""
+			}
+			return match[1] + "." + match[2]
+		}
+		return match[2]
+	}
+	for _, re := range []*regexp.Regexp{goTypeRe, jsExportRe, pyPublicDefRe} {
+		if match := re.FindStringSubmatch(line); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// DetectBreaking scans a diff for public declarations that were
+// removed or whose signature changed
+func DetectBreaking(diff string) []string {
+	removed := make(map[string]string)
+	added := make(map[string]string)
+	var order []string
+
+	normalize := func(line string) string {
+		return strings.Join(strings.Fields(strings.TrimRight(strings.TrimSpace(line), "{:")), " ")
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "-"):
+			if name := publicDecl(line[1:]); name != "" {
+				if _, ok := removed[name]; !ok {
+					order = append(order, name)
+				}
+				removed[name] = normalize(line[1:])
+			}
+		case strings.HasPrefix(line, "+"):
+			if name := publicDecl(line[1:]); name != "" {
+				added[name] = normalize(line[1:])
+			}
+		}
+	}
+
+	var breaking []string
+	for _, name := range order {
+		newDecl, ok := added[name]
+		switch {
+		case !ok:
+			breaking = append(breaking, "removed "+name)
+		case newDecl != removed[name]:
+			breaking = append(breaking, "changed signature of "+name)
+		}
+	}
+	return breaking
+}
+
+// breakingFiles lists the files of diff whose own part removes or changes a
+// declaration named in breaking, the result of DetectBreaking on all of it
+func breakingFiles(diff string, breaking []string) []string {
+	// By name: a declaration moved to another file with a new signature is
+	// "changed" overall but "removed" from the file it left
+	name := func(b string) string {
+		return strings.TrimPrefix(strings.TrimPrefix(b, "removed "), "changed signature of ")
+	}
+	names := make(map[string]bool)
+	for _, b := range breaking {
+		names[name(b)] = true
+	}
+	var files []string
+	for _, block := range splitDiff(diff) {
+		for _, b := range DetectBreaking(block) {
+			if names[name(b)] {
+				files = append(files, parseDiffHeader(block).File)
+				break
+			}
+		}
+	}
+	return files
+}
+
+// MarkBreaking adds the ! marker to a conventional prefix (feat: -> feat!:)
+func MarkBreaking(message string) string {
+	loc := ConventionalPrefix.FindStringIndex(message)
+	if loc == nil {
+		return message
+	}
+	prefix := message[:loc[1]]
+	colon := strings.Index(prefix, ":")
+	if colon > 0 && prefix[colon-1] == '!' {
+		return message
+	}
+	return message[:colon] + "!" + message[colon:]
+}
+
+// WithBreakingFooter appends a BREAKING CHANGE footer to a commit message
+func WithBreakingFooter(message, note string) string {
+	if note == "" {
+		return message
+	}
+	return MarkBreaking(message) + "\n\nBREAKING CHANGE: " + note
+}
+
+// matchRule returns the first configured suggestion rule for a file
+func matchRule(file string, rules []Rule) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.Type != "" && rule.Matches(file) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Scope is the scope for all of changes: the configured rules win, then the
+// package owner gives every one of them
+func Scope(changes []Change, rules []Rule, owner func(string) (string, bool)) string {
+	if scope := ruleScope(changes, rules); scope != "" {
+		return scope
+	}
+	if owner == nil {
+		return ""
+	}
+	var scope string
+	for i, change := range changes {
+		pkg, ok := owner(change.File)
+		if !ok || (i > 0 && pkg != scope) {
+			return ""
+		}
+		scope = pkg
+	}
+	return scope
+}
+
+// ruleScope is the scope the configured rules give all of changes, or ""
+// when they disagree or set none
+func ruleScope(changes []Change, rules []Rule) string {
+	scope := ""
+	for i, change := range changes {
+		rule, _ := matchRule(change.File, rules)
+		if rule.Scope == "" || (i > 0 && rule.Scope != scope) {
+			return ""
+		}
+		scope = rule.Scope
+	}
+	return scope
+}
+
+// Classify returns the commit type for a change and the rule that
+// chose it. Configured rules take precedence over the built-in heuristics.
+func Classify(change Change, rules []Rule) (string, string) {
+	if rule, ok := matchRule(change.File, rules); ok {
+		return rule.Type, "matches " + rule.Path
+	}
+
+	file := strings.ToLower(change.File)
+
+	if strings.Contains(file, "test") || strings.HasSuffix(file, "_test.go") {
+		return "test", "path mentions test"
+	}
+	if strings.HasSuffix(file, ".md") || strings.Contains(file, "doc") {
+		return "docs", "markdown or doc path"
+	}
+	if strings.Contains(file, "config") || strings.HasPrefix(file, ".") ||
+		file == "makefile" || file == "dockerfile" {
+		return "chore", "config, dotfile or build file"
+	}
+	if change.Status == "A " {
+		return "feat", "new file"
+	}
+	if strings.Contains(change.Status, "M") {
+		return "refactor", "modified source file"
+	}
+	return "chore", "other change (" + strings.TrimSpace(change.Status) + ")"
+}
+
+// suggestionKeywords are words in added lines worth surfacing as evidence
+var suggestionKeywords = []string{
+	"fix", "bug", "error", "panic", "todo", "fixme", "deprecated", "test",
+	"refactor", "rename", "performance", "cache", "security", "typo",
+}
+
+var funcDeclRe = regexp.MustCompile(`^\s*(?:func\s+(?:\([^)]*\)\s*)?|(?:async\s+)?def\s+|(?:export\s+)?(?:async\s+)?function\*?\s+)(\w+)`)
+
+// CollectDiffInfo gathers the evidence behind a suggestion from the diff of its files
+func CollectDiffInfo(src Source, changes []Change) DiffInfo {
+	var info DiffInfo
+	for _, change := range changes {
+		info.Files = append(info.Files, change.File)
+	}
+
+	keywords := make(map[string]bool)
+	for _, line := range strings.Split(src.Diff(info.Files), "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		var body string
+		switch {
+		case strings.HasPrefix(line, "+"):
+			info.Added++
+			body = line[1:]
+			lower := strings.ToLower(body)
+			for _, kw := range suggestionKeywords {
+				if strings.Contains(lower, kw) {
+					keywords[kw] = true
+				}
+			}
+		case strings.HasPrefix(line, "-"):
+			info.Removed++
+			body = line[1:]
+		default:
+			continue
+		}
+		if match := funcDeclRe.FindStringSubmatch(body); match != nil {
+			info.Functions = appendUnique(info.Functions, match[1])
+		}
+	}
+
+	for _, kw := range suggestionKeywords {
+		if keywords[kw] {
+			info.Keywords = append(info.Keywords, kw)
+		}
+	}
+	return info
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
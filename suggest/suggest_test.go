@@ -0,0 +1,117 @@
+package suggest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeSource serves recorded changes and diffs
+type fakeSource struct {
+	changes []Change
+	staged  string
+	diffs   map[string]string
+}
+
+func (f fakeSource) Changes() []Change { return f.changes }
+
+func (f fakeSource) StagedDiff() string { return f.staged }
+
+func (f fakeSource) Diff(files []string) string {
+	var diff strings.Builder
+	for _, file := range files {
+		diff.WriteString(f.diffs[file])
+	}
+	return diff.String()
+}
+
+// clientDiff unexports a function, which counts as a breaking change
+const clientDiff = `diff --git a/api/client.go b/api/client.go
+index 3b18e51..a9c7d20 100644
+--- a/api/client.go
++++ b/api/client.go
+@@ -1,3 +1,3 @@
+ package api
+-func Fetch(url string) error {
++func fetch(url string) error {
+`
+
+func TestGenerate(t *testing.T) {
+	src := fakeSource{
+		changes: []Change{
+			{File: "api/client.go", Status: "M "},
+			{File: "README.md", Status: " M"},
+			{File: "api/client_test.go", Status: "??"},
+		},
+		staged: clientDiff,
+		diffs:  map[string]string{"api/client.go": clientDiff},
+	}
+
+	suggestions, breaking := Generate(src, Options{})
+
+	var messages []string
+	for _, s := range suggestions {
+		messages = append(messages, s.Message)
+	}
+	want := []string{
+		"refactor!: improve code structure (1 files)",
+		"refactor: improve code structure (1 files)",
+		"docs: update documentation (1 files)",
+		"test: add/update tests (1 files)",
+	}
+	if !slices.Equal(messages, want) {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+	if !slices.Equal(breaking, []string{"removed Fetch"}) {
+		t.Errorf("breaking = %q, want [removed Fetch]", breaking)
+	}
+	if got := suggestions[1].Info.Functions; !slices.Equal(got, []string{"Fetch", "fetch"}) {
+		t.Errorf("functions = %q, want both names", got)
+	}
+}
+
+func TestGenerateMax(t *testing.T) {
+	src := fakeSource{changes: []Change{{File: "main.go", Status: "A "}, {File: "docs/guide.md", Status: "A "}}}
+	if suggestions, _ := Generate(src, Options{Max: 1}); len(suggestions) != 1 {
+		t.Errorf("got %d suggestions, want Max 1", len(suggestions))
+	}
+}
+
+// The breaking variant follows the file that broke the API, not whichever
+// suggestion comes first
+func TestGenerateBreakingFollowsFile(t *testing.T) {
+	docsDiff := "diff --git a/docs/guide.md b/docs/guide.md\n--- a/docs/guide.md\n+++ b/docs/guide.md\n@@ -1 +1 @@\n-old\n+new\n"
+	src := fakeSource{
+		changes: []Change{
+			{File: "docs/guide.md", Status: "M "},
+			{File: "api/client.go", Status: "M "},
+		},
+		staged: docsDiff + clientDiff,
+		diffs:  map[string]string{"docs/guide.md": docsDiff, "api/client.go": clientDiff},
+	}
+
+	suggestions, _ := Generate(src, Options{})
+	if len(suggestions) == 0 || suggestions[0].Breaking == "" {
+		t.Fatalf("suggestions = %+v, want a breaking variant first", suggestions)
+	}
+	if got := suggestions[0].Message; got != "refactor!: improve code structure (1 files)" {
+		t.Errorf("breaking variant = %q, want the refactor of api/client.go", got)
+	}
+	if got := suggestions[1].Message; got != "docs: update documentation (1 files)" {
+		t.Errorf("first suggestion = %q, want docs", got)
+	}
+}
+
+func TestBreakingFilesMovedDeclaration(t *testing.T) {
+	// Fetch moves from a.go to b.go with a new signature: changed overall,
+	// and a.go is where it was removed
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +0,0 @@\n-func Fetch(url string) error {\n" +
+		"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -0,0 +1 @@\n+func Fetch(url string, retries int) error {\n"
+	breaking := DetectBreaking(diff)
+	if !slices.Equal(breaking, []string{"changed signature of Fetch"}) {
+		t.Fatalf("breaking = %q", breaking)
+	}
+	if got := breakingFiles(diff, breaking); !slices.Equal(got, []string{"a.go"}) {
+		t.Errorf("breakingFiles = %q, want [a.go]", got)
+	}
+}